@@ -1,12 +1,17 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"pairwise/internal/api"
 	"pairwise/internal/domain"
+	"pairwise/internal/oidc"
+	"pairwise/internal/oidc/fakeidp"
 	"pairwise/internal/repository"
 	"pairwise/internal/service"
 	"pairwise/internal/websocket"
@@ -35,27 +40,104 @@ func main() {
 	attendeeRepo := repository.NewAttendeeRepository(sqlDB)
 	featureRepo := repository.NewFeatureRepository(sqlDB)
 	pairwiseRepo := repository.NewPairwiseRepository(sqlDB)
+	fibonacciRepo := repository.NewFibonacciRepository(sqlDB)
 	priorityRepo := repository.NewPriorityRepository(sqlDB)
 	progressRepo := repository.NewProgressRepository(sqlDB)
+	criteriaRepo := repository.NewCriteriaRepository(sqlDB)
+	inviteRepo := repository.NewInviteRepository(sqlDB)
+	apiKeyRepo := repository.NewAPIKeyRepository(sqlDB)
+	auditRepo := repository.NewAuditRepository(sqlDB)
+	userRepo := repository.NewUserRepository(sqlDB)
 
 	// Initialize services
-	projectService := service.NewProjectService(projectRepo)
+	projectService := service.NewProjectService(projectRepo, criteriaRepo)
 	attendeeService := service.NewAttendeeService(attendeeRepo)
 	featureService := service.NewFeatureService(featureRepo, projectRepo)
-	pairwiseService := service.NewPairwiseService(pairwiseRepo, featureRepo, attendeeRepo, projectRepo)
+	pairwiseService := service.NewPairwiseService(pairwiseRepo, featureRepo, attendeeRepo, projectRepo, criteriaRepo)
+	fibonacciService := service.NewFibonacciService(fibonacciRepo, featureRepo, attendeeRepo, projectRepo, criteriaRepo)
 	pairwiseCalcService := service.NewPWVCService()
-	resultsService := service.NewResultsService(priorityRepo, featureRepo, pairwiseRepo)
-	progressService := service.NewProgressService(progressRepo, projectRepo, attendeeRepo, featureRepo)
+	resultsService := service.NewResultsService(priorityRepo, featureRepo, pairwiseRepo, fibonacciRepo, criteriaRepo)
+	progressService := service.NewProgressService(progressRepo, projectRepo, attendeeRepo, featureRepo, criteriaRepo)
+	criteriaService := service.NewCriteriaService(criteriaRepo, projectRepo, pairwiseRepo, fibonacciRepo)
+	tokenService, err := initTokenService()
+	if err != nil {
+		log.Fatalf("Failed to initialize session tokens: %v", err)
+	}
+	if ballotKeys := initBallotKeys(); ballotKeys != nil {
+		pairwiseService.SetBallotKeyer(ballotKeys)
+	}
+	inviteService := service.NewInviteService(inviteRepo, attendeeService, tokenService)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, projectRepo)
+	if err := initBootstrapAPIKey(apiKeyService); err != nil {
+		log.Fatalf("Failed to initialize bootstrap API key: %v", err)
+	}
+	userService := service.NewUserService(userRepo, projectRepo, projectService, attendeeService, tokenService)
+	if err := initOIDC(userService); err != nil {
+		log.Fatalf("Failed to initialize OpenID Connect login: %v", err)
+	}
+
+	// Record every mutating action in the audit log
+	auditService := service.NewAuditService(auditRepo, projectRepo)
+	projectService.SetAuditor(auditService)
+	attendeeService.SetAuditor(auditService)
+	featureService.SetAuditor(auditService)
+	pairwiseService.SetAuditor(auditService)
+	fibonacciService.SetAuditor(auditService)
+	resultsService.SetAuditor(auditService)
+	progressService.SetAuditor(auditService)
+	criteriaService.SetAuditor(auditService)
+	inviteService.SetAuditor(auditService)
+	apiKeyService.SetAuditor(auditService)
+	userService.SetAuditor(auditService)
 
 	// Initialize WebSocket hub
-	wsHub := websocket.NewHub(attendeeRepo)
+	wsHub := websocket.NewHub()
 	go wsHub.Run() // Start the hub in a goroutine
 
+	// Services publish real-time updates to the hub's project topics
+	pairwiseService.SetWebSocketBroadcaster(wsHub)
+	fibonacciService.SetWebSocketBroadcaster(wsHub)
+	resultsService.SetWebSocketBroadcaster(wsHub)
+
+	// Votes sent over WebSocket connections are cast like REST votes
+	wsHub.SetVoteSubmitter(pairwiseService)
+
+	// Presence shows facilitators who has voted on the current comparison
+	wsHub.SetVotingStatusProvider(pairwiseService)
+
+	// Facilitators pace driven sessions with control messages
+	wsHub.SetSessionDriver(pairwiseService)
+
+	// Open connections lose access with their attendee's role or session token
+	wsHub.SetAccessChecker(attendeeService)
+
+	// Reconnecting clients too far behind to replay get the topic's state instead
+	wsHub.SetSnapshotProvider(service.NewTopicSnapshots(pairwiseService, fibonacciService, resultsService))
+
 	// Initialize API handlers
-	apiHandler := api.NewHandler(attendeeService, featureService, projectService, pairwiseService, pairwiseCalcService, resultsService, progressService, priorityRepo, wsHub)
+	apiHandler := api.NewHandler(attendeeService, featureService, projectService, pairwiseService, fibonacciService, pairwiseCalcService, resultsService, progressService, criteriaService, tokenService, inviteService, apiKeyService, auditService, userService, priorityRepo, wsHub)
+
+	rateLimitPolicies, err := initRateLimitPolicies()
+	if err != nil {
+		log.Fatalf("Failed to initialize rate limits: %v", err)
+	}
+
+	originPolicy, err := initOriginPolicy()
+	if err != nil {
+		log.Fatalf("Failed to initialize allowed origins: %v", err)
+	}
+	apiHandler.SetOriginPolicy(originPolicy)
+
+	trustedProxies, err := initTrustedProxies()
+	if err != nil {
+		log.Fatalf("Failed to initialize trusted proxies: %v", err)
+	}
 
 	// Set up Gin router
-	router := setupRouter(apiHandler)
+	router, err := setupRouter(apiHandler, rateLimitPolicies, originPolicy, trustedProxies)
+	if err != nil {
+		log.Fatalf("Failed to set up router: %v", err)
+	}
 
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
@@ -82,18 +164,7 @@ func initDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	// Auto-migrate all models
-	err = db.AutoMigrate(
-		&domain.Project{},
-		&domain.Attendee{},
-		&domain.Feature{},
-		&domain.PairwiseSession{},
-		&domain.SessionComparison{},
-		&domain.AttendeeVote{},
-		&domain.PriorityCalculation{},
-		&domain.ProjectProgress{},
-	)
-	if err != nil {
+	if err := repository.Migrate(db); err != nil {
 		return nil, err
 	}
 
@@ -101,7 +172,144 @@ func initDB() (*gorm.DB, error) {
 	return db, nil
 }
 
-func setupRouter(apiHandler *api.Handler) *gin.Engine {
+// initTokenService configures session token signing from AUTH_SECRET and AUTH_TOKEN_TTL
+func initTokenService() (*service.TokenService, error) {
+	ttl := domain.DefaultTokenTTL
+	if value := os.Getenv("AUTH_TOKEN_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTH_TOKEN_TTL: %w", err)
+		}
+		ttl = parsed
+	}
+
+	secret := []byte(os.Getenv("AUTH_SECRET"))
+	if len(secret) == 0 {
+		generated, err := service.GenerateTokenSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+		log.Println("Warning: AUTH_SECRET is not set; session tokens will not survive a restart")
+	}
+
+	return service.NewTokenService(secret, ttl), nil
+}
+
+// initBallotKeys configures anonymous ballot keys from BALLOT_SECRET. Ballots can only be
+// changed while the secret stays the same, so without one anonymous sessions are refused.
+func initBallotKeys() *service.BallotKeys {
+	secret := []byte(os.Getenv("BALLOT_SECRET"))
+	if len(secret) == 0 {
+		log.Println("Warning: BALLOT_SECRET is not set; anonymous pairwise sessions are disabled")
+		return nil
+	}
+	return service.NewBallotKeys(secret)
+}
+
+// initBootstrapAPIKey stores BOOTSTRAP_ADMIN_API_KEY as a global admin key, so that the first
+// keys can be created through the API. It can be revoked like any other key once others exist.
+func initBootstrapAPIKey(apiKeyService *service.APIKeyService) error {
+	raw := os.Getenv("BOOTSTRAP_ADMIN_API_KEY")
+	if raw == "" {
+		return nil
+	}
+
+	key, err := apiKeyService.EnsureAPIKey(raw, "bootstrap", []domain.APIScope{domain.ScopeAdmin})
+	if err != nil {
+		return err
+	}
+
+	if key.RevokedAt != nil {
+		log.Println("Warning: BOOTSTRAP_ADMIN_API_KEY has been revoked and will not be accepted")
+	}
+	return nil
+}
+
+// initOIDC enables facilitator login through the OpenID provider at OIDC_ISSUER. With
+// OIDC_FAKE_IDP=true an in-process fake provider signs everyone in, for local development.
+func initOIDC(userService *service.UserService) error {
+	config := oidc.Config{
+		IssuerURL:    os.Getenv("OIDC_ISSUER"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+	}
+	client := http.DefaultClient
+
+	if os.Getenv("OIDC_FAKE_IDP") == "true" {
+		if config.ClientID == "" {
+			config.ClientID = "pairwise-dev"
+		}
+		idp, err := fakeidp.New(config.ClientID, config.ClientSecret)
+		if err != nil {
+			return err
+		}
+		config.IssuerURL = idp.Issuer()
+		client = idp.Client()
+		log.Printf("Warning: OIDC_FAKE_IDP is set; anyone can sign in as a facilitator through %s", idp.Issuer())
+	}
+
+	if config.IssuerURL == "" {
+		return nil
+	}
+	if config.ClientID == "" {
+		return fmt.Errorf("OIDC_CLIENT_ID is required with OIDC_ISSUER")
+	}
+	if config.RedirectURL == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		config.RedirectURL = "http://localhost:" + port + "/api/auth/oidc/callback"
+	}
+
+	userService.EnableOIDC(oidc.NewProvider(config, client), os.Getenv("OIDC_POST_LOGIN_URL"))
+	log.Printf("OpenID Connect login enabled with %s", config.IssuerURL)
+	return nil
+}
+
+// initRateLimitPolicies applies RATE_LIMIT_POLICIES overrides to the default per-route policies
+func initRateLimitPolicies() (map[string]api.RateLimitPolicy, error) {
+	policies := api.DefaultRateLimitPolicies()
+	if err := api.ParseRateLimitPolicies(os.Getenv("RATE_LIMIT_POLICIES"), policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// initOriginPolicy reads the origins allowed to call the API and open WebSocket connections
+// from CORS_ALLOWED_ORIGINS, CORS_ALLOW_CREDENTIALS and CORS_MAX_AGE
+func initOriginPolicy() (api.OriginPolicy, error) {
+	policy, err := api.ParseOriginPolicy(
+		os.Getenv("CORS_ALLOWED_ORIGINS"),
+		os.Getenv("CORS_ALLOW_CREDENTIALS"),
+		os.Getenv("CORS_MAX_AGE"),
+	)
+	if err != nil {
+		return policy, err
+	}
+
+	log.Printf("Allowed cross-origin callers: %s", strings.Join(policy.AllowedOrigins, ", "))
+	return policy, nil
+}
+
+// initTrustedProxies reads the reverse proxies trusted to name clients in X-Forwarded-For
+// from TRUSTED_PROXIES. Without it, no proxy is trusted.
+func initTrustedProxies() ([]string, error) {
+	proxies, err := api.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(proxies) > 0 {
+		log.Printf("Trusted proxies: %s", strings.Join(proxies, ", "))
+	}
+	return proxies, nil
+}
+
+func setupRouter(apiHandler *api.Handler, rateLimitPolicies map[string]api.RateLimitPolicy, originPolicy api.OriginPolicy, trustedProxies []string) (*gin.Engine, error) {
 	// Set Gin mode from environment
 	if os.Getenv("GIN_MODE") == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -109,6 +317,11 @@ func setupRouter(apiHandler *api.Handler) *gin.Engine {
 
 	router := gin.New()
 
+	// Client IPs come from X-Forwarded-For only when a trusted proxy sent it
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+
 	// Initialize logger
 	logger := api.NewLogger()
 
@@ -117,9 +330,9 @@ func setupRouter(apiHandler *api.Handler) *gin.Engine {
 	router.Use(api.RecoveryMiddleware())    // Use custom recovery middleware
 	router.Use(api.RequestIDMiddleware())   // Add request ID tracking
 	router.Use(api.PerformanceMiddleware()) // Add performance monitoring
-	router.Use(corsMiddleware())
+	router.Use(api.CORSMiddleware(originPolicy))
 	router.Use(api.ValidationMiddleware()) // Add validation middleware
-	router.Use(api.RateLimitMiddleware())  // Add basic rate limiting
+	router.Use(apiHandler.RateLimitMiddleware(api.NewTokenBucketLimiter(time.Minute), rateLimitPolicies))
 
 	// Root endpoint
 	router.GET("/", func(c *gin.Context) {
@@ -132,20 +345,5 @@ func setupRouter(apiHandler *api.Handler) *gin.Engine {
 	// API routes
 	apiHandler.RegisterRoutes(router)
 
-	return router
-}
-
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
-	}
+	return router, nil
 }
@@ -7,6 +7,7 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.19.0
 	github.com/gorilla/websocket v1.5.3
 	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.40.0
 )
 
 require (
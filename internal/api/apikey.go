@@ -0,0 +1,144 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectAPIKeys handles GET /api/projects/:id/api-keys
+func (h *Handler) GetProjectAPIKeys(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	keys, err := h.apiKeyService.GetAPIKeys(&projectID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": keys,
+	})
+}
+
+// CreateProjectAPIKey handles POST /api/projects/:id/api-keys. The key is scoped to the
+// project whatever the payload says, and is only shown in this response.
+func (h *Handler) CreateProjectAPIKey(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var req domain.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var createdBy *int
+	if claims, ok := tokenClaims(c); ok {
+		createdBy = &claims.AttendeeID
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), &projectID, createdBy, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeProjectAPIKey handles DELETE /api/projects/:id/api-keys/:keyId
+func (h *Handler) RevokeProjectAPIKey(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	keyID, err := strconv.Atoi(c.Param("keyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), &projectID, keyID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// GetAPIKeys handles GET /api/api-keys, listing global and project keys
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.GetAPIKeys(nil)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": keys,
+	})
+}
+
+// CreateAPIKey handles POST /api/api-keys. Without a project_id the key is global.
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req domain.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), req.ProjectID, nil, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey handles DELETE /api/api-keys/:keyId
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	keyID, err := strconv.Atoi(c.Param("keyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), nil, keyID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
@@ -28,7 +28,7 @@ func (h *Handler) CreateAttendee(c *gin.Context) {
 		return
 	}
 
-	attendee, err := h.attendeeService.CreateAttendee(projectID, req)
+	attendee, err := h.attendeeService.CreateAttendee(c.Request.Context(), projectID, req)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
@@ -86,7 +86,7 @@ func (h *Handler) DeleteAttendee(c *gin.Context) {
 		return
 	}
 
-	err = h.attendeeService.DeleteAttendee(attendeeID)
+	err = h.attendeeService.DeleteAttendee(c.Request.Context(), attendeeID)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
@@ -103,3 +103,66 @@ func (h *Handler) DeleteAttendee(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// SetAttendeePIN handles PUT /api/projects/:id/attendees/:attendeeId/pin
+func (h *Handler) SetAttendeePIN(c *gin.Context) {
+	projectID, attendeeID, ok := attendeeRouteIDs(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	credentials, err := h.attendeeService.SetPIN(c.Request.Context(), projectID, attendeeID, req.PIN)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
+// RegenerateAttendeePIN handles POST /api/projects/:id/attendees/:attendeeId/pin/regenerate.
+// The new PIN is only shown in this response.
+func (h *Handler) RegenerateAttendeePIN(c *gin.Context) {
+	projectID, attendeeID, ok := attendeeRouteIDs(c)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.attendeeService.SetPIN(c.Request.Context(), projectID, attendeeID, "")
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
+// attendeeRouteIDs parses the project and attendee IDs from the route, writing a 400 on failure
+func attendeeRouteIDs(c *gin.Context) (int, int, bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return 0, 0, false
+	}
+
+	attendeeID, err := strconv.Atoi(c.Param("attendeeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid attendee ID",
+		})
+		return 0, 0, false
+	}
+
+	return projectID, attendeeID, true
+}
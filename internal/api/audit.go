@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectAuditEvents handles GET /api/projects/:id/audit. Events can be filtered by
+// action, entity_type, entity_id, actor_type, actor_id and an RFC 3339 since/until range,
+// and are paged with page and page_size.
+func (h *Handler) GetProjectAuditEvents(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid audit filter",
+			"details": err.Error(),
+		})
+		return
+	}
+	filter.ProjectID = projectID
+
+	page, err := h.auditService.GetProjectAuditEvents(filter)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseAuditFilter reads an audit filter from the query string
+func parseAuditFilter(c *gin.Context) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		ActorType:  domain.ActorType(c.Query("actor_type")),
+	}
+
+	var err error
+	if filter.EntityID, err = optionalQueryInt(c, "entity_id"); err != nil {
+		return filter, err
+	}
+	if filter.ActorID, err = optionalQueryInt(c, "actor_id"); err != nil {
+		return filter, err
+	}
+	if filter.Since, err = optionalQueryTime(c, "since"); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = optionalQueryTime(c, "until"); err != nil {
+		return filter, err
+	}
+
+	page, err := optionalQueryInt(c, "page")
+	if err != nil {
+		return filter, err
+	}
+	if page != nil {
+		filter.Page = *page
+	}
+
+	pageSize, err := optionalQueryInt(c, "page_size")
+	if err != nil {
+		return filter, err
+	}
+	if pageSize != nil {
+		filter.PageSize = *pageSize
+	}
+
+	return filter, nil
+}
+
+// optionalQueryInt parses an optional integer query parameter
+func optionalQueryInt(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+	return &parsed, nil
+}
+
+// optionalQueryTime parses an optional RFC 3339 time query parameter
+func optionalQueryTime(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 time", name)
+	}
+	return &parsed, nil
+}
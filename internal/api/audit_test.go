@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestParseAuditFilter tests reading audit filters from the query string
+func TestParseAuditFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+		check   func(domain.AuditFilter) bool
+	}{
+		{
+			name:  "empty",
+			query: "",
+			check: func(f domain.AuditFilter) bool {
+				return f.Action == "" && f.EntityID == nil && f.Since == nil && f.Page == 0
+			},
+		},
+		{
+			name:  "all filters",
+			query: "action=delete&entity_type=feature&entity_id=4&actor_type=api_key&actor_id=2&since=2026-01-01T00:00:00Z&until=2026-02-01T00:00:00Z&page=3&page_size=20",
+			check: func(f domain.AuditFilter) bool {
+				return f.Action == domain.AuditActionDelete && f.EntityType == domain.AuditEntityFeature &&
+					*f.EntityID == 4 && f.ActorType == domain.ActorAPIKey && *f.ActorID == 2 &&
+					f.Since.Month() == 1 && f.Until.Month() == 2 && f.Page == 3 && f.PageSize == 20
+			},
+		},
+		{name: "bad entity id", query: "entity_id=x", wantErr: true},
+		{name: "bad since", query: "since=yesterday", wantErr: true},
+		{name: "bad page", query: "page=first", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/projects/1/audit?"+tt.query, nil)
+
+			filter, err := parseAuditFilter(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && !tt.check(filter) {
+				t.Errorf("Unexpected filter: %+v", filter)
+			}
+		})
+	}
+}
+
+// TestRequestIDContext tests that request IDs are unique and reach services through the
+// request context
+func TestRequestIDContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+
+	var fromContext string
+	router.GET("/test", func(c *gin.Context) {
+		fromContext = domain.RequestIDFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		requestID := w.Header().Get("X-Request-ID")
+		if requestID != fromContext {
+			t.Errorf("Expected request context to carry %q, got %q", requestID, fromContext)
+		}
+		if seen[requestID] {
+			t.Errorf("Request ID %q was generated twice", requestID)
+		}
+		seen[requestID] = true
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("X-Request-ID", "client-supplied")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if fromContext != "client-supplied" {
+		t.Errorf("Expected the client's request ID to be kept, got %q", fromContext)
+	}
+}
@@ -1,10 +1,11 @@
 package api
 
 import (
-	"crypto/sha256"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"pairwise/internal/domain"
 
@@ -19,10 +20,21 @@ type AttendeeLoginRequest struct {
 
 // AttendeeLoginResponse represents the login response
 type AttendeeLoginResponse struct {
-	Attendee *domain.Attendee `json:"attendee"`
-	Token    string           `json:"token"` // Simple token for now
+	Attendee  *domain.Attendee `json:"attendee"`
+	Token     string           `json:"token"` // Signed session token, sent as "Authorization: Bearer <token>"
+	Role      domain.Role      `json:"role"`
+	ExpiresAt time.Time        `json:"expires_at"`
 }
 
+// claimsContextKey is the gin context key holding the verified token claims
+const claimsContextKey = "token_claims"
+
+// apiKeyContextKey is the gin context key holding the authenticated API key
+const apiKeyContextKey = "api_key"
+
+// userClaimsContextKey is the gin context key holding the verified user token claims
+const userClaimsContextKey = "user_claims"
+
 // LoginAttendee handles POST /api/projects/:id/attendees/login
 func (h *Handler) LoginAttendee(c *gin.Context) {
 	projectID, err := strconv.Atoi(c.Param("id"))
@@ -42,44 +54,294 @@ func (h *Handler) LoginAttendee(c *gin.Context) {
 		return
 	}
 
-	// Get attendee
-	attendee, err := h.attendeeService.GetAttendee(req.AttendeeID)
+	attendee, err := h.attendeeService.Login(projectID, req.AttendeeID, req.PIN)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	// Verify attendee belongs to project
-	if attendee.ProjectID != projectID {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Attendee not found in this project",
+	token, claims, err := h.tokenService.Issue(attendee)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue session token",
 		})
 		return
 	}
 
-	// Debug logging
-	hashedPIN := hashPIN(req.PIN)
-	fmt.Printf("DEBUG: Attendee ID %d, PinHash from DB: '%s', Computed hash: '%s'\n", attendee.ID, attendee.PinHash, hashedPIN)
+	c.JSON(http.StatusOK, AttendeeLoginResponse{
+		Attendee:  attendee,
+		Token:     token,
+		Role:      claims.Role,
+		ExpiresAt: claims.ExpiresAtTime(),
+	})
+}
+
+// RequireRole is middleware that admits requests carrying a valid session token scoped to
+// the project in the route whose role grants the required role. The token is read from the
+// Authorization header, or from the token query parameter on WebSocket upgrades since
+// browsers cannot set headers there. API keys covering the project are admitted with the
+// admin scope or one of the given scopes.
+func (h *Handler) RequireRole(required domain.Role, scopes ...domain.APIScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if domain.IsAPIKey(bearerToken(c)) {
+			h.authorizeAPIKey(c, required, scopes)
+			return
+		}
+
+		claims, ok := h.authenticate(c)
+		if !ok {
+			return
+		}
+
+		if !claims.Role.Allows(required) {
+			NewErrorHandler().HandleError(c, domain.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireFacilitator admits facilitators, for structural changes and session and workflow control
+func (h *Handler) RequireFacilitator(scopes ...domain.APIScope) gin.HandlerFunc {
+	return h.RequireRole(domain.RoleFacilitator, scopes...)
+}
+
+// RequireAttendee admits attendees and facilitators, for voting and reading their own state
+func (h *Handler) RequireAttendee() gin.HandlerFunc {
+	return h.RequireRole(domain.RoleAttendee)
+}
+
+// RequireObserver admits any role, for reading the project
+func (h *Handler) RequireObserver(scopes ...domain.APIScope) gin.HandlerFunc {
+	return h.RequireRole(domain.RoleObserver, scopes...)
+}
+
+// RequireGlobalAdmin admits global API keys with the admin scope, for managing all API keys
+func (h *Handler) RequireGlobalAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := h.authenticateAPIKey(c)
+		if !ok {
+			return
+		}
+
+		if key.ProjectID != nil || !key.Scopes.Has(domain.ScopeAdmin) {
+			NewErrorHandler().HandleError(c, domain.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireUser is middleware that admits requests carrying a valid user session token, issued
+// after an OpenID Connect login
+func (h *Handler) RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Missing user session token",
+			})
+			return
+		}
+
+		claims, err := h.tokenService.VerifyUser(token)
+		if err != nil {
+			message := "Invalid user session token"
+			if err == domain.ErrTokenExpired {
+				message = "User session token has expired"
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		c.Set(userClaimsContextKey, claims)
+		c.Set("user_id", fmt.Sprintf("user:%d", claims.UserID))
+		setActor(c, domain.Actor{Type: domain.ActorUser, ID: &claims.UserID})
+		c.Next()
+	}
+}
+
+// RequireFacilitatorOrSetup admits facilitators, and anyone while the project has no
+// facilitator yet so that the first one can be created
+func (h *Handler) RequireFacilitatorOrSetup() gin.HandlerFunc {
+	requireFacilitator := h.RequireFacilitator()
+	return func(c *gin.Context) {
+		projectID, err := strconv.Atoi(c.Param("id"))
+		if err == nil {
+			hasFacilitator, err := h.attendeeService.HasFacilitator(projectID)
+			if err != nil {
+				handleServiceError(c, err)
+				c.Abort()
+				return
+			}
+			if !hasFacilitator {
+				c.Next()
+				return
+			}
+		}
+
+		requireFacilitator(c)
+	}
+}
+
+// authenticate verifies the request's session token and its project scope, and checks that
+// the token's attendee still exists in the project and has not had their tokens revoked. It
+// stores the claims in the context with the attendee's current role, and writes the error
+// response and aborts when it fails.
+func (h *Handler) authenticate(c *gin.Context) (*domain.TokenClaims, bool) {
+	token := bearerToken(c)
+	if token == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "Missing session token",
+		})
+		return nil, false
+	}
+
+	claims, err := h.tokenService.Verify(token)
+	if err != nil {
+		message := "Invalid session token"
+		if err == domain.ErrTokenExpired {
+			message = "Session token has expired"
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": message,
+		})
+		return nil, false
+	}
+
+	projectID, err := strconv.Atoi(routeProjectID(c))
+	if err != nil || projectID != claims.ProjectID {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "Session token is not valid for this project",
+		})
+		return nil, false
+	}
+
+	// Tokens outlive changes to the attendee, so the attendee's current state decides
+	role, err := h.attendeeService.CurrentRole(claims.ProjectID, claims.AttendeeID, claims.Version)
+	if err != nil {
+		handleServiceError(c, err)
+		c.Abort()
+		return nil, false
+	}
+
+	current := *claims
+	current.Role = role
+
+	c.Set(claimsContextKey, &current)
+	c.Set("user_id", fmt.Sprintf("attendee:%d", current.AttendeeID))
+	setActor(c, domain.Actor{Type: domain.ActorAttendee, ID: &current.AttendeeID})
+	return &current, true
+}
+
+// authorizeAPIKey admits a request authenticated by an API key that covers the project in
+// the route and grants the route's role or one of its scopes
+func (h *Handler) authorizeAPIKey(c *gin.Context, required domain.Role, scopes []domain.APIScope) {
+	key, ok := h.authenticateAPIKey(c)
+	if !ok {
+		return
+	}
 
-	// Verify PIN (simple hash comparison for now)
-	if attendee.PinHash != hashedPIN {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid PIN",
+	projectID, err := strconv.Atoi(routeProjectID(c))
+	if err != nil || !key.CoversProject(projectID) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "API key is not valid for this project",
 		})
 		return
 	}
 
-	// Create simple token (project:attendee format for now)
-	token := fmt.Sprintf("%d:%d", projectID, attendee.ID)
+	if !key.Allows(required, scopes) {
+		NewErrorHandler().HandleError(c, domain.ErrForbidden)
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
 
-	c.JSON(http.StatusOK, AttendeeLoginResponse{
-		Attendee: attendee,
-		Token:    token,
-	})
+// authenticateAPIKey looks up the request's API key, storing it in the context. It writes
+// the error response and aborts when it fails.
+func (h *Handler) authenticateAPIKey(c *gin.Context) (*domain.APIKey, bool) {
+	raw := bearerToken(c)
+	if !domain.IsAPIKey(raw) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "Missing API key",
+		})
+		return nil, false
+	}
+
+	key, err := h.apiKeyService.Authenticate(raw)
+	if err != nil {
+		if err == domain.ErrUnauthorized {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid API key",
+			})
+		} else {
+			handleServiceError(c, err)
+			c.Abort()
+		}
+		return nil, false
+	}
+
+	c.Set(apiKeyContextKey, key)
+	c.Set("user_id", fmt.Sprintf("apikey:%d", key.ID))
+	setActor(c, domain.Actor{Type: domain.ActorAPIKey, ID: &key.ID})
+	return key, true
+}
+
+// setActor records who is making the request in the request context, where services read
+// it for the audit log
+func setActor(c *gin.Context, actor domain.Actor) {
+	c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), actor))
+}
+
+// tokenClaims returns the claims verified by RequireRole
+func tokenClaims(c *gin.Context) (*domain.TokenClaims, bool) {
+	value, exists := c.Get(claimsContextKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*domain.TokenClaims)
+	return claims, ok
 }
 
-// hashPIN creates a simple hash of the PIN
-func hashPIN(pin string) string {
-	hash := sha256.Sum256([]byte(pin))
-	return fmt.Sprintf("%x", hash)
+// userClaims returns the claims verified by RequireUser
+func userClaims(c *gin.Context) (*domain.UserClaims, bool) {
+	value, exists := c.Get(userClaimsContextKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*domain.UserClaims)
+	return claims, ok
+}
+
+// bearerToken extracts the session token from the request
+func bearerToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		return c.Query("token")
+	}
+
+	return ""
+}
+
+// routeProjectID returns the project ID route parameter, which the WebSocket route names differently
+func routeProjectID(c *gin.Context) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	return c.Param("projectId")
 }
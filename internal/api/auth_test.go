@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+	"pairwise/internal/service"
+	"pairwise/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAuthHandler returns a handler that verifies session tokens against the attendees stored
+// in a database
+func newAuthHandler(t *testing.T, db *sql.DB) *Handler {
+	t.Helper()
+
+	return &Handler{
+		tokenService:    service.NewTokenService([]byte("test-secret"), time.Hour),
+		attendeeService: service.NewAttendeeService(repository.NewAttendeeRepository(db)),
+	}
+}
+
+// createAttendee adds an attendee to a project, failing the test when it cannot
+func createAttendee(t *testing.T, h *Handler, projectID int, req domain.CreateAttendeeRequest) *domain.Attendee {
+	t.Helper()
+
+	req.PIN = "1234"
+	credentials, err := h.attendeeService.CreateAttendee(context.Background(), projectID, req)
+	if err != nil {
+		t.Fatalf("Failed to create attendee: %v", err)
+	}
+	return credentials.Attendee
+}
+
+// issueToken issues a session token for an attendee, failing the test when it cannot
+func issueToken(t *testing.T, h *Handler, attendee *domain.Attendee) string {
+	t.Helper()
+
+	token, _, err := h.tokenService.Issue(attendee)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	return token
+}
+
+// TestRequireAttendee tests that the auth middleware checks token signature and project scope
+func TestRequireAttendee(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newAuthHandler(t, testutil.NewTestDB(t))
+
+	router := gin.New()
+	router.GET("/projects/:id/me", h.RequireAttendee(), func(c *gin.Context) {
+		claims, ok := tokenClaims(c)
+		if !ok {
+			t.Error("Claims should exist in context")
+		}
+		c.JSON(http.StatusOK, gin.H{"attendee_id": claims.AttendeeID})
+	})
+	router.GET("/ws/:projectId", h.RequireAttendee(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	token := issueToken(t, h, createAttendee(t, h, 1, domain.CreateAttendeeRequest{Name: "Alice"}))
+	// Signed by the server, but for an attendee that does not exist
+	unknown := issueToken(t, h, &domain.Attendee{ID: 99, ProjectID: 1})
+
+	tests := []struct {
+		name     string
+		path     string
+		header   map[string]string
+		expected int
+	}{
+		{name: "Valid bearer token", path: "/projects/1/me", header: map[string]string{"Authorization": "Bearer " + token}, expected: http.StatusOK},
+		{name: "Missing token", path: "/projects/1/me", expected: http.StatusUnauthorized},
+		{name: "Forged legacy token", path: "/projects/1/me", header: map[string]string{"Authorization": "Bearer 1:5"}, expected: http.StatusUnauthorized},
+		{name: "Other project", path: "/projects/2/me", header: map[string]string{"Authorization": "Bearer " + token}, expected: http.StatusForbidden},
+		{name: "Unknown attendee", path: "/projects/1/me", header: map[string]string{"Authorization": "Bearer " + unknown}, expected: http.StatusUnauthorized},
+		{name: "Query token on plain request", path: "/projects/1/me?token=" + token, expected: http.StatusUnauthorized},
+		{name: "Query token on WebSocket upgrade", path: "/ws/1?token=" + token, header: map[string]string{"Upgrade": "websocket"}, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestRequireRole tests that each route role admits the roles that include it
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newAuthHandler(t, testutil.NewTestDB(t))
+
+	router := gin.New()
+	router.GET("/projects/:id/read", h.RequireObserver(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/projects/:id/vote", h.RequireAttendee(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/projects/:id/control", h.RequireFacilitator(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tokens := map[domain.Role]string{
+		domain.RoleObserver:    issueToken(t, h, createAttendee(t, h, 1, domain.CreateAttendeeRequest{Name: "Olivia", IsObserver: true})),
+		domain.RoleAttendee:    issueToken(t, h, createAttendee(t, h, 1, domain.CreateAttendeeRequest{Name: "Alice"})),
+		domain.RoleFacilitator: issueToken(t, h, createAttendee(t, h, 1, domain.CreateAttendeeRequest{Name: "Fred", IsFacilitator: true})),
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		role     domain.Role
+		expected int
+	}{
+		{name: "Observer reads", method: "GET", path: "/projects/1/read", role: domain.RoleObserver, expected: http.StatusOK},
+		{name: "Observer votes", method: "POST", path: "/projects/1/vote", role: domain.RoleObserver, expected: http.StatusForbidden},
+		{name: "Attendee reads", method: "GET", path: "/projects/1/read", role: domain.RoleAttendee, expected: http.StatusOK},
+		{name: "Attendee votes", method: "POST", path: "/projects/1/vote", role: domain.RoleAttendee, expected: http.StatusOK},
+		{name: "Attendee controls", method: "POST", path: "/projects/1/control", role: domain.RoleAttendee, expected: http.StatusForbidden},
+		{name: "Facilitator votes", method: "POST", path: "/projects/1/vote", role: domain.RoleFacilitator, expected: http.StatusOK},
+		{name: "Facilitator controls", method: "POST", path: "/projects/1/control", role: domain.RoleFacilitator, expected: http.StatusOK},
+		{name: "Facilitator of another project", method: "POST", path: "/projects/2/control", role: domain.RoleFacilitator, expected: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tokens[tt.role])
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestRequireRoleRevocation tests that tokens stop working once their attendee has changed:
+// the attendee's current role is used, and deleting the attendee or setting a new PIN
+// revokes their tokens
+func TestRequireRoleRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newAuthHandler(t, testutil.NewTestDB(t))
+
+	router := gin.New()
+	router.POST("/projects/:id/control", h.RequireFacilitator(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(token string) int {
+		req, _ := http.NewRequest("POST", "/projects/1/control", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	facilitator := createAttendee(t, h, 1, domain.CreateAttendeeRequest{Name: "Fred", IsFacilitator: true})
+	token := issueToken(t, h, facilitator)
+	if code := request(token); code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+
+	t.Run("Role claimed by the token", func(t *testing.T) {
+		attendee := createAttendee(t, h, 1, domain.CreateAttendeeRequest{Name: "Alice"})
+		claimed := *attendee
+		claimed.IsFacilitator = true
+		if code := request(issueToken(t, h, &claimed)); code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, code)
+		}
+	})
+
+	t.Run("New PIN", func(t *testing.T) {
+		credentials, err := h.attendeeService.SetPIN(context.Background(), 1, facilitator.ID, "5678")
+		if err != nil {
+			t.Fatalf("Failed to set PIN: %v", err)
+		}
+		if code := request(token); code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d for the old token, got %d", http.StatusUnauthorized, code)
+		}
+		if code := request(issueToken(t, h, credentials.Attendee)); code != http.StatusOK {
+			t.Errorf("Expected status %d for a new token, got %d", http.StatusOK, code)
+		}
+	})
+
+	t.Run("Deleted attendee", func(t *testing.T) {
+		attendee := createAttendee(t, h, 1, domain.CreateAttendeeRequest{Name: "Frida", IsFacilitator: true})
+		deleted := issueToken(t, h, attendee)
+		if err := h.attendeeService.DeleteAttendee(context.Background(), attendee.ID); err != nil {
+			t.Fatalf("Failed to delete attendee: %v", err)
+		}
+		if code := request(deleted); code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, code)
+		}
+	})
+}
+
+// TestRequireUser tests that user routes accept user session tokens and nothing else
+func TestRequireUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := service.NewTokenService([]byte("test-secret"), time.Hour)
+	h := &Handler{tokenService: tokens}
+
+	router := gin.New()
+	router.GET("/users/me", h.RequireUser(), func(c *gin.Context) {
+		claims, ok := userClaims(c)
+		if !ok {
+			t.Error("Claims should exist in context")
+		}
+		actor := domain.ActorFromContext(c.Request.Context())
+		if actor.Type != domain.ActorUser || actor.ID == nil || *actor.ID != claims.UserID {
+			t.Errorf("Unexpected actor %+v", actor)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	userToken, _, err := tokens.IssueUser(4)
+	if err != nil {
+		t.Fatalf("Failed to issue user token: %v", err)
+	}
+	sessionToken, _, _ := tokens.Issue(&domain.Attendee{ID: 5, ProjectID: 1, IsFacilitator: true})
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{name: "User token", token: userToken, expected: http.StatusOK},
+		{name: "Missing token", token: "", expected: http.StatusUnauthorized},
+		{name: "Project session token", token: sessionToken, expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/users/me", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectCriteria handles GET /api/projects/:id/criteria
+func (h *Handler) GetProjectCriteria(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	criteria, err := h.criteriaService.GetProjectCriteria(projectID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"criteria": criteria,
+	})
+}
+
+// CreateCriterion handles POST /api/projects/:id/criteria
+func (h *Handler) CreateCriterion(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var req domain.CreateCriterionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	criterion, err := h.criteriaService.CreateCriterion(c.Request.Context(), projectID, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, criterion)
+}
+
+// UpdateCriterion handles PUT /api/projects/:id/criteria/:criterionId
+func (h *Handler) UpdateCriterion(c *gin.Context) {
+	projectID, criterionID, ok := criterionRequestParams(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateCriterionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	criterion, err := h.criteriaService.UpdateCriterion(c.Request.Context(), projectID, criterionID, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, criterion)
+}
+
+// DeleteCriterion handles DELETE /api/projects/:id/criteria/:criterionId
+func (h *Handler) DeleteCriterion(c *gin.Context) {
+	projectID, criterionID, ok := criterionRequestParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.criteriaService.DeleteCriterion(c.Request.Context(), projectID, criterionID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// criterionRequestParams parses the project and criterion IDs, writing a 400 response when invalid
+func criterionRequestParams(c *gin.Context) (int, int, bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return 0, 0, false
+	}
+
+	criterionID, err := strconv.Atoi(c.Param("criterionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid criterion ID",
+		})
+		return 0, 0, false
+	}
+
+	return projectID, criterionID, true
+}
@@ -190,19 +190,11 @@ func ValidationMiddleware() gin.HandlerFunc {
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Generate or get request ID
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
+		requestID := domain.RequestIDFrom(c.GetHeader("X-Request-ID"))
 
 		c.Header("X-Request-ID", requestID)
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(domain.WithRequestID(c.Request.Context(), requestID))
 		c.Next()
 	}
 }
-
-// generateRequestID creates a unique request identifier
-func generateRequestID() string {
-	// Simple implementation - in production, use UUID or similar
-	return "req_" + string(debug.Stack()[:8])
-}
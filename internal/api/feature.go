@@ -30,7 +30,7 @@ func (h *Handler) CreateFeature(c *gin.Context) {
 		return
 	}
 
-	feature, err := h.featureService.CreateFeature(projectID, req)
+	feature, err := h.featureService.CreateFeature(c.Request.Context(), projectID, req)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
@@ -125,7 +125,7 @@ func (h *Handler) UpdateFeature(c *gin.Context) {
 		return
 	}
 
-	feature, err := h.featureService.UpdateFeature(featureID, req)
+	feature, err := h.featureService.UpdateFeature(c.Request.Context(), featureID, req)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
@@ -153,7 +153,7 @@ func (h *Handler) DeleteFeature(c *gin.Context) {
 		return
 	}
 
-	err = h.featureService.DeleteFeature(featureID)
+	err = h.featureService.DeleteFeature(c.Request.Context(), featureID)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
@@ -201,7 +201,7 @@ func (h *Handler) ImportFeatures(c *gin.Context) {
 	}
 
 	// Import features from CSV
-	result, err := h.featureService.ImportFeaturesFromCSV(projectID, file)
+	result, err := h.featureService.ImportFeaturesFromCSV(c.Request.Context(), projectID, file)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
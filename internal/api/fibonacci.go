@@ -0,0 +1,208 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartFibonacciSession handles POST /api/projects/:id/fibonacci
+func (h *Handler) StartFibonacciSession(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var req domain.CreateFibonacciSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	session, err := h.fibonacciService.StartSession(c.Request.Context(), projectID, req.CriterionType)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"session": session,
+	})
+}
+
+// GetFibonacciSession handles GET /api/projects/:id/fibonacci?type=value|complexity
+func (h *Handler) GetFibonacciSession(c *gin.Context) {
+	projectID, criterionType, ok := fibonacciRequestParams(c)
+	if !ok {
+		return
+	}
+
+	session, progress, err := h.fibonacciService.GetActiveSession(projectID, criterionType)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session":  session,
+		"progress": progress,
+	})
+}
+
+// GetFibonacciScores handles GET /api/projects/:id/fibonacci/scores?type=value|complexity
+func (h *Handler) GetFibonacciScores(c *gin.Context) {
+	projectID, criterionType, ok := fibonacciRequestParams(c)
+	if !ok {
+		return
+	}
+
+	session, _, err := h.fibonacciService.GetActiveSession(projectID, criterionType)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	scores, err := h.fibonacciService.GetSessionScores(session.ID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session": session,
+		"scores":  scores,
+	})
+}
+
+// SubmitFibonacciScore handles POST /api/projects/:id/fibonacci/scores?type=value|complexity
+func (h *Handler) SubmitFibonacciScore(c *gin.Context) {
+	projectID, criterionType, ok := fibonacciRequestParams(c)
+	if !ok {
+		return
+	}
+
+	session, _, err := h.fibonacciService.GetActiveSession(projectID, criterionType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "No active scoring session found",
+		})
+		return
+	}
+
+	var req domain.SubmitFibonacciScoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// The scorer is the token holder, never a client-supplied ID
+	claims, _ := tokenClaims(c)
+	if req.AttendeeID != 0 && req.AttendeeID != claims.AttendeeID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Cannot score on behalf of another attendee",
+		})
+		return
+	}
+	req.AttendeeID = claims.AttendeeID
+
+	score, err := h.fibonacciService.SubmitScore(c.Request.Context(), session.ID, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"score": score,
+	})
+}
+
+// SetFibonacciConsensus handles POST /api/projects/:id/fibonacci/consensus?type=value|complexity
+func (h *Handler) SetFibonacciConsensus(c *gin.Context) {
+	projectID, criterionType, ok := fibonacciRequestParams(c)
+	if !ok {
+		return
+	}
+
+	session, _, err := h.fibonacciService.GetActiveSession(projectID, criterionType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "No active scoring session found",
+		})
+		return
+	}
+
+	var req domain.SetConsensusScoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	consensus, err := h.fibonacciService.SetConsensusScore(c.Request.Context(), session.ID, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consensus": consensus,
+	})
+}
+
+// CompleteFibonacciSession handles POST /api/projects/:id/fibonacci/complete?type=value|complexity
+func (h *Handler) CompleteFibonacciSession(c *gin.Context) {
+	projectID, criterionType, ok := fibonacciRequestParams(c)
+	if !ok {
+		return
+	}
+
+	session, _, err := h.fibonacciService.GetActiveSession(projectID, criterionType)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	if err := h.fibonacciService.CompleteSession(c.Request.Context(), session.ID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scoring session completed successfully",
+	})
+}
+
+// fibonacciRequestParams parses the project ID and criterion type shared by the scoring endpoints
+func fibonacciRequestParams(c *gin.Context) (int, domain.CriterionType, bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return 0, "", false
+	}
+
+	criterionType := c.DefaultQuery("type", "value")
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
+		})
+		return 0, "", false
+	}
+
+	return projectID, domain.CriterionType(criterionType), true
+}
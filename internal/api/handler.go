@@ -14,15 +14,23 @@ import (
 
 // Handler holds all the services needed by the API handlers
 type Handler struct {
-	attendeeService *service.AttendeeService
-	featureService  *service.FeatureService
-	projectService  *service.ProjectService
-	pairwiseService *service.PairwiseService
-	pwvcService     *service.PWVCService
-	resultsService  *service.ResultsService
-	progressService *service.ProgressService
-	wsHub           *websocket.Hub
-	priorityRepo    *repository.PriorityRepository
+	attendeeService  *service.AttendeeService
+	featureService   *service.FeatureService
+	projectService   *service.ProjectService
+	pairwiseService  *service.PairwiseService
+	fibonacciService *service.FibonacciService
+	pwvcService      *service.PWVCService
+	resultsService   *service.ResultsService
+	progressService  *service.ProgressService
+	criteriaService  *service.CriteriaService
+	tokenService     *service.TokenService
+	inviteService    *service.InviteService
+	apiKeyService    *service.APIKeyService
+	auditService     *service.AuditService
+	userService      *service.UserService
+	wsHub            *websocket.Hub
+	originPolicy     OriginPolicy
+	priorityRepo     *repository.PriorityRepository
 }
 
 // NewHandler creates a new API handler with the required services
@@ -31,78 +39,169 @@ func NewHandler(
 	featureService *service.FeatureService,
 	projectService *service.ProjectService,
 	pairwiseService *service.PairwiseService,
+	fibonacciService *service.FibonacciService,
 	pwvcService *service.PWVCService,
 	resultsService *service.ResultsService,
 	progressService *service.ProgressService,
+	criteriaService *service.CriteriaService,
+	tokenService *service.TokenService,
+	inviteService *service.InviteService,
+	apiKeyService *service.APIKeyService,
+	auditService *service.AuditService,
+	userService *service.UserService,
 	priorityRepo *repository.PriorityRepository,
 	hub *websocket.Hub,
 ) *Handler {
 	return &Handler{
-		attendeeService: attendeeService,
-		featureService:  featureService,
-		projectService:  projectService,
-		pairwiseService: pairwiseService,
-		pwvcService:     pwvcService,
-		resultsService:  resultsService,
-		progressService: progressService,
-		priorityRepo:    priorityRepo,
-		wsHub:           hub,
+		attendeeService:  attendeeService,
+		featureService:   featureService,
+		projectService:   projectService,
+		pairwiseService:  pairwiseService,
+		fibonacciService: fibonacciService,
+		pwvcService:      pwvcService,
+		resultsService:   resultsService,
+		progressService:  progressService,
+		criteriaService:  criteriaService,
+		tokenService:     tokenService,
+		inviteService:    inviteService,
+		apiKeyService:    apiKeyService,
+		auditService:     auditService,
+		userService:      userService,
+		priorityRepo:     priorityRepo,
+		wsHub:            hub,
+		originPolicy:     DefaultOriginPolicy(),
 	}
 }
 
+// SetOriginPolicy sets which browser origins may open WebSocket connections
+func (h *Handler) SetOriginPolicy(policy OriginPolicy) {
+	h.originPolicy = policy
+}
+
 // RegisterRoutes sets up all the API routes
 func (h *Handler) RegisterRoutes(router *gin.Engine) {
 	api := router.Group("/api")
 	{
-		// Project endpoints
+		// Project endpoints. Creating and listing projects is open; everything scoped to a
+		// project needs a session token or API key, with facilitators alone changing
+		// structure and controlling sessions and workflow. API keys reach a route with the
+		// admin scope or a scope listed for it.
 		projects := api.Group("/projects")
 		{
+			observer := h.RequireObserver()
+			attendee := h.RequireAttendee()
+			facilitator := h.RequireFacilitator()
+			readProject := h.RequireObserver(domain.ScopeReadResults, domain.ScopeWriteFeatures)
+			readResults := h.RequireObserver(domain.ScopeReadResults)
+			readFeatures := h.RequireObserver(domain.ScopeWriteFeatures)
+			writeFeatures := h.RequireFacilitator(domain.ScopeWriteFeatures)
+
 			projects.GET("", h.GetProjects)
 			projects.POST("", h.CreateProject)
-			projects.GET("/:id", h.GetProject)
-			projects.PUT("/:id", h.UpdateProject)
-			projects.DELETE("/:id", h.DeleteProject)
+			projects.GET("/:id", readProject, h.GetProject)
+			projects.PUT("/:id", facilitator, h.UpdateProject)
+			projects.DELETE("/:id", facilitator, h.DeleteProject)
 
-			// Attendee endpoints
-			projects.GET("/:id/attendees", h.GetProjectAttendees)
-			projects.POST("/:id/attendees", h.CreateAttendee)
+			// Criteria endpoints
+			projects.GET("/:id/criteria", readProject, h.GetProjectCriteria)
+			projects.POST("/:id/criteria", facilitator, h.CreateCriterion)
+			projects.PUT("/:id/criteria/:criterionId", facilitator, h.UpdateCriterion)
+			projects.DELETE("/:id/criteria/:criterionId", facilitator, h.DeleteCriterion)
+
+			// Attendee endpoints. Until a project has a facilitator anyone may add attendees,
+			// so that the first facilitator can be created.
+			projects.GET("/:id/attendees", observer, h.GetProjectAttendees)
+			projects.POST("/:id/attendees", h.RequireFacilitatorOrSetup(), h.CreateAttendee)
 			projects.POST("/:id/attendees/login", h.LoginAttendee)
-			projects.DELETE("/:id/attendees/:attendeeId", h.DeleteAttendee)
+			projects.DELETE("/:id/attendees/:attendeeId", facilitator, h.DeleteAttendee)
+			projects.PUT("/:id/attendees/:attendeeId/pin", facilitator, h.SetAttendeePIN)
+			projects.POST("/:id/attendees/:attendeeId/pin/regenerate", facilitator, h.RegenerateAttendeePIN)
+
+			// Invite endpoints
+			projects.GET("/:id/invites", facilitator, h.GetProjectInvites)
+			projects.POST("/:id/invites", facilitator, h.CreateInvite)
+			projects.DELETE("/:id/invites/:inviteId", facilitator, h.RevokeInvite)
+
+			// API key endpoints
+			projects.GET("/:id/api-keys", facilitator, h.GetProjectAPIKeys)
+			projects.POST("/:id/api-keys", facilitator, h.CreateProjectAPIKey)
+			projects.DELETE("/:id/api-keys/:keyId", facilitator, h.RevokeProjectAPIKey)
+
+			// Audit log endpoints
+			projects.GET("/:id/audit", facilitator, h.GetProjectAuditEvents)
 
 			// Feature endpoints
-			projects.GET("/:id/features", h.GetProjectFeatures)
-			projects.POST("/:id/features", h.CreateFeature)
-			projects.GET("/:id/features/:featureId", h.GetFeature)
-			projects.PUT("/:id/features/:featureId", h.UpdateFeature)
-			projects.DELETE("/:id/features/:featureId", h.DeleteFeature)
-			projects.POST("/:id/features/import", h.ImportFeatures)
-			projects.GET("/:id/features/export", h.ExportFeatures)
+			projects.GET("/:id/features", readFeatures, h.GetProjectFeatures)
+			projects.POST("/:id/features", writeFeatures, h.CreateFeature)
+			projects.GET("/:id/features/:featureId", readFeatures, h.GetFeature)
+			projects.PUT("/:id/features/:featureId", writeFeatures, h.UpdateFeature)
+			projects.DELETE("/:id/features/:featureId", writeFeatures, h.DeleteFeature)
+			projects.POST("/:id/features/import", writeFeatures, h.ImportFeatures)
+			projects.GET("/:id/features/export", readFeatures, h.ExportFeatures)
 
 			// Pairwise comparison endpoints
-			projects.POST("/:id/pairwise", h.StartPairwiseSession)
-			projects.GET("/:id/pairwise", h.GetPairwiseSession)
-			projects.GET("/:id/pairwise/comparisons", h.GetPairwiseSessionComparisons)
-			projects.POST("/:id/pairwise/votes", h.SubmitPairwiseVote)
-			projects.POST("/:id/pairwise/complete", h.CompletePairwiseSession)
-			projects.GET("/:id/pairwise/next", h.GetNextComparison)
+			projects.POST("/:id/pairwise", facilitator, h.StartPairwiseSession)
+			projects.GET("/:id/pairwise", observer, h.GetPairwiseSession)
+			projects.GET("/:id/pairwise/comparisons", observer, h.GetPairwiseSessionComparisons)
+			projects.POST("/:id/pairwise/votes", attendee, h.SubmitPairwiseVote)
+			projects.POST("/:id/pairwise/resolve", facilitator, h.ResolvePairwiseComparison)
+			projects.POST("/:id/pairwise/complete", facilitator, h.CompletePairwiseSession)
+			projects.GET("/:id/pairwise/next", attendee, h.GetNextComparison)
+			projects.GET("/:id/pairwise/cycles", observer, h.GetPairwiseCycles)
+			projects.GET("/:id/pairwise/presence", facilitator, h.GetPairwisePresence)
+
+			// Fibonacci scoring endpoints
+			projects.POST("/:id/fibonacci", facilitator, h.StartFibonacciSession)
+			projects.GET("/:id/fibonacci", observer, h.GetFibonacciSession)
+			projects.GET("/:id/fibonacci/scores", observer, h.GetFibonacciScores)
+			projects.POST("/:id/fibonacci/scores", attendee, h.SubmitFibonacciScore)
+			projects.POST("/:id/fibonacci/consensus", facilitator, h.SetFibonacciConsensus)
+			projects.POST("/:id/fibonacci/complete", facilitator, h.CompleteFibonacciSession)
 
 			// Results endpoints
-			projects.POST("/:id/calculate-results", h.CalculateResults)
-			projects.GET("/:id/results", h.GetResults)
-			projects.GET("/:id/results/export", h.ExportResults)
-			projects.GET("/:id/results/summary", h.GetResultsSummary)
-			projects.GET("/:id/results/status", h.CheckResultsStatus)
-			projects.GET("/:id/results/preview", h.PreviewExport)
+			projects.POST("/:id/calculate-results", facilitator, h.CalculateResults)
+			projects.GET("/:id/results", readResults, h.GetResults)
+			projects.GET("/:id/results/export", readResults, h.ExportResults)
+			projects.GET("/:id/results/summary", readResults, h.GetResultsSummary)
+			projects.GET("/:id/results/status", readResults, h.CheckResultsStatus)
+			projects.GET("/:id/results/preview", readResults, h.PreviewExport)
+			projects.GET("/:id/results/ahp", readResults, h.GetAHPAnalysis)
 
 			// Progress endpoints
-			projects.GET("/:id/progress", h.GetProjectProgress)
-			projects.POST("/:id/progress/advance", h.AdvancePhase)
-			projects.POST("/:id/progress/complete", h.CompletePhase)
-			projects.GET("/:id/progress/phases", h.GetAvailablePhases)
+			projects.GET("/:id/progress", observer, h.GetProjectProgress)
+			projects.POST("/:id/progress/advance", facilitator, h.AdvancePhase)
+			projects.POST("/:id/progress/complete", facilitator, h.CompletePhase)
+			projects.GET("/:id/progress/phases", observer, h.GetAvailablePhases)
+		}
+
+		// Global API key management, for holders of a global admin key
+		apiKeys := api.Group("/api-keys", h.RequireGlobalAdmin())
+		{
+			apiKeys.GET("", h.GetAPIKeys)
+			apiKeys.POST("", h.CreateAPIKey)
+			apiKeys.DELETE("/:keyId", h.RevokeAPIKey)
+		}
+
+		// Self-registration with a join code or invite link
+		api.POST("/join", h.JoinProject)
+
+		// Facilitator login through OpenID Connect
+		oidcLogin := api.Group("/auth/oidc")
+		{
+			oidcLogin.GET("/login", h.BeginOIDCLogin)
+			oidcLogin.GET("/callback", h.OIDCCallback)
+		}
+
+		// Signed-in users and the projects they own
+		users := api.Group("/users/me", h.RequireUser())
+		{
+			users.GET("", h.GetCurrentUser)
+			users.POST("/projects", h.CreateUserProject)
+			users.POST("/projects/:id/session", h.StartFacilitatorSession)
 		}
 
 		// WebSocket endpoint
-		api.GET("/ws/:projectId", h.HandleWebSocket)
+		api.GET("/ws/:projectId", h.RequireObserver(), h.HandleWebSocket)
 		api.GET("/ws/stats", h.GetWebSocketStats)
 	}
 
@@ -153,6 +252,12 @@ func handleServiceError(c *gin.Context, err error) {
 		c.JSON(apiErr.Code, gin.H{
 			"error": apiErr.Message,
 		})
+	} else if bizErr, ok := err.(*domain.BusinessError); ok {
+		c.JSON(mapBusinessErrorToHTTP(bizErr.Type), gin.H{
+			"error":   bizErr.Message,
+			"type":    bizErr.Type,
+			"context": bizErr.Context,
+		})
 	} else {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Internal server error",
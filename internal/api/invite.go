@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JoinProjectResponse is a self-registered attendee with their one-time PIN and a session token
+type JoinProjectResponse struct {
+	Attendee  *domain.Attendee `json:"attendee"`
+	PIN       string           `json:"pin"` // Shown only once, for logging in again later
+	Token     string           `json:"token"`
+	Role      domain.Role      `json:"role"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+// CreateInvite handles POST /api/projects/:id/invites
+func (h *Handler) CreateInvite(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var req domain.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var createdBy *int
+	if claims, ok := tokenClaims(c); ok {
+		createdBy = &claims.AttendeeID
+	}
+
+	invite, err := h.inviteService.CreateInvite(c.Request.Context(), projectID, createdBy, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+// GetProjectInvites handles GET /api/projects/:id/invites
+func (h *Handler) GetProjectInvites(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	invites, err := h.inviteService.GetProjectInvites(projectID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invites": invites,
+	})
+}
+
+// RevokeInvite handles DELETE /api/projects/:id/invites/:inviteId
+func (h *Handler) RevokeInvite(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	inviteID, err := strconv.Atoi(c.Param("inviteId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid invite ID",
+		})
+		return
+	}
+
+	invite, err := h.inviteService.RevokeInvite(c.Request.Context(), projectID, inviteID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invite)
+}
+
+// JoinProject handles POST /api/join. Attendees register themselves with a join code or a
+// signed invite token and get a session token straight away.
+func (h *Handler) JoinProject(c *gin.Context) {
+	var req domain.JoinProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	credentials, err := h.inviteService.Join(c.Request.Context(), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	attendee := credentials.Attendee
+	token, claims, err := h.tokenService.Issue(attendee)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue session token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, JoinProjectResponse{
+		Attendee:  attendee,
+		PIN:       credentials.PIN,
+		Token:     token,
+		Role:      claims.Role,
+		ExpiresAt: claims.ExpiresAtTime(),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"time"
 
@@ -135,7 +136,7 @@ func (l *Logger) LoggingMiddleware() gin.HandlerFunc {
 		// Add query parameters for GET requests
 		if c.Request.Method == "GET" && len(c.Request.URL.RawQuery) > 0 {
 			entry.Context = map[string]interface{}{
-				"query": c.Request.URL.RawQuery,
+				"query": redactQuery(c.Request.URL),
 			}
 		}
 
@@ -283,6 +284,17 @@ func (l *Logger) logTextEntry(entry LogEntry) {
 	l.output.Println(output)
 }
 
+// redactQuery returns the raw query with session tokens masked, since WebSocket upgrades
+// carry them in the URL
+func redactQuery(u *url.URL) string {
+	query := u.Query()
+	if query.Get("token") == "" {
+		return u.RawQuery
+	}
+	query.Set("token", "REDACTED")
+	return query.Encode()
+}
+
 // Performance monitoring middleware
 func PerformanceMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -304,44 +316,3 @@ func PerformanceMiddleware() gin.HandlerFunc {
 		c.Header("X-Response-Time", duration.String())
 	}
 }
-
-// Rate limiting middleware (basic implementation)
-func RateLimitMiddleware() gin.HandlerFunc {
-	// Simple in-memory rate limiter
-	// In production, use Redis or a proper rate limiting library
-
-	requests := make(map[string][]time.Time)
-	const maxRequests = 100
-	const timeWindow = time.Minute
-
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		now := time.Now()
-
-		// Clean old requests
-		if times, exists := requests[clientIP]; exists {
-			var validTimes []time.Time
-			for _, t := range times {
-				if now.Sub(t) < timeWindow {
-					validTimes = append(validTimes, t)
-				}
-			}
-			requests[clientIP] = validTimes
-		}
-
-		// Check rate limit
-		if times, exists := requests[clientIP]; exists && len(times) >= maxRequests {
-			c.JSON(429, gin.H{
-				"error":       "Rate limit exceeded",
-				"retry_after": timeWindow.Seconds(),
-			})
-			c.Abort()
-			return
-		}
-
-		// Add current request
-		requests[clientIP] = append(requests[clientIP], now)
-
-		c.Next()
-	}
-}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcLoginCookie holds the signed state of a login between the redirect to the identity
+// provider and the callback
+const oidcLoginCookie = "pairwise_oidc_login"
+
+// oidcCookiePath limits the login cookie to the login routes
+const oidcCookiePath = "/api/auth/oidc"
+
+// UserLoginResponse is a user signed in with OpenID Connect and their session token
+type UserLoginResponse struct {
+	User      *domain.User `json:"user"`
+	Token     string       `json:"token"` // User session token, sent as "Authorization: Bearer <token>"
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// BeginOIDCLogin handles GET /api/auth/oidc/login, sending the browser to the identity provider
+func (h *Handler) BeginOIDCLogin(c *gin.Context) {
+	authURL, stateToken, err := h.userService.BeginLogin(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	setLoginCookie(c, stateToken, int(domain.OIDCLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback handles GET /api/auth/oidc/callback, where the identity provider sends the
+// browser back. The session token goes to the configured post-login URL in the fragment, so
+// that it stays out of server logs, or is returned as JSON.
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	stateToken, _ := c.Cookie(oidcLoginCookie)
+	setLoginCookie(c, "", -1)
+
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "OpenID Connect login failed",
+			"details": strings.TrimSpace(providerError + " " + c.Query("error_description")),
+		})
+		return
+	}
+
+	user, err := h.userService.CompleteLogin(c.Request.Context(), stateToken, c.Query("state"), c.Query("code"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	token, claims, err := h.tokenService.IssueUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue session token",
+		})
+		return
+	}
+
+	if postLoginURL := h.userService.PostLoginURL(); postLoginURL != "" {
+		fragment := url.Values{
+			"token":      {token},
+			"expires_at": {claims.ExpiresAtTime().Format(time.RFC3339)},
+		}
+		c.Redirect(http.StatusFound, postLoginURL+"#"+fragment.Encode())
+		return
+	}
+
+	c.JSON(http.StatusOK, UserLoginResponse{
+		User:      user,
+		Token:     token,
+		ExpiresAt: claims.ExpiresAtTime(),
+	})
+}
+
+// GetCurrentUser handles GET /api/users/me
+func (h *Handler) GetCurrentUser(c *gin.Context) {
+	claims, _ := userClaims(c)
+
+	profile, err := h.userService.GetProfile(claims.UserID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// CreateUserProject handles POST /api/users/me/projects, creating a project the user owns
+// and facilitates
+func (h *Handler) CreateUserProject(c *gin.Context) {
+	claims, _ := userClaims(c)
+
+	var req domain.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	project, err := h.userService.CreateProject(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// StartFacilitatorSession handles POST /api/users/me/projects/:id/session, trading a user
+// session token for a facilitator session token in a project the user owns
+func (h *Handler) StartFacilitatorSession(c *gin.Context) {
+	claims, _ := userClaims(c)
+
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	attendee, err := h.userService.FacilitatorFor(c.Request.Context(), claims.UserID, projectID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	token, sessionClaims, err := h.tokenService.Issue(attendee)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue session token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AttendeeLoginResponse{
+		Attendee:  attendee,
+		Token:     token,
+		Role:      sessionClaims.Role,
+		ExpiresAt: sessionClaims.ExpiresAtTime(),
+	})
+}
+
+// setLoginCookie sets or, with a negative max age, clears the login state cookie. It is sent
+// on the provider's top-level redirect back, which SameSite=Lax allows.
+func setLoginCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcLoginCookie, value, maxAge, oidcCookiePath, "", secure, true)
+}
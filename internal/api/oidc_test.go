@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pairwise/internal/oidc"
+	"pairwise/internal/oidc/fakeidp"
+	"pairwise/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestOIDCLoginRedirects tests the browser side of a login against the fake provider: the
+// login cookie, the redirect to the provider and the checks on the way back
+func TestOIDCLoginRedirects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idp, err := fakeidp.New("pairwise", "secret")
+	if err != nil {
+		t.Fatalf("Failed to start fake provider: %v", err)
+	}
+	defer idp.Close()
+
+	tokens := service.NewTokenService([]byte("test-secret"), time.Hour)
+	users := service.NewUserService(nil, nil, nil, nil, tokens)
+	h := &Handler{tokenService: tokens, userService: users}
+
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", h.BeginOIDCLogin)
+	router.GET("/api/auth/oidc/callback", h.OIDCCallback)
+
+	get := func(path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/api/auth/oidc/login"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before OpenID Connect is enabled, got %d", w.Code)
+	}
+
+	users.EnableOIDC(oidc.NewProvider(oidc.Config{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     "pairwise",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/api/auth/oidc/callback",
+	}, idp.Client()), "")
+
+	w := get("/api/auth/oidc/login")
+	if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), idp.Issuer()+"/authorize?") {
+		t.Fatalf("Expected a redirect to the provider, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcLoginCookie || !cookies[0].HttpOnly || cookies[0].Path != oidcCookiePath {
+		t.Fatalf("Expected an HttpOnly login cookie, got %+v", cookies)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		cookie   bool
+		expected int
+	}{
+		{name: "Provider error", path: "/api/auth/oidc/callback?error=access_denied", cookie: true, expected: http.StatusUnauthorized},
+		{name: "Missing cookie", path: "/api/auth/oidc/callback?state=x&code=y", expected: http.StatusBadRequest},
+		{name: "Mismatched state", path: "/api/auth/oidc/callback?state=x&code=y", cookie: true, expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []*http.Cookie
+			if tt.cookie {
+				sent = cookies
+			}
+			w := get(tt.path, sent...)
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+			if cleared := w.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+				t.Errorf("Expected the login cookie to be cleared, got %+v", cleared)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsAllowedMethods and corsAllowedHeaders are what cross-origin requests may use
+const (
+	corsAllowedMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	corsAllowedHeaders = "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Request-ID, Authorization"
+)
+
+// OriginPolicy decides which browser origins may call the API and open WebSocket connections.
+// Requests from the server's own origin and from non-browser clients, which send no Origin
+// header, are always allowed.
+type OriginPolicy struct {
+	// AllowedOrigins are scheme://host[:port] origins. "*" allows any origin and
+	// "https://*.example.com" allows any subdomain of example.com.
+	AllowedOrigins   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // How long browsers may cache a preflight response
+}
+
+// DefaultOriginPolicy allows the development frontend
+func DefaultOriginPolicy() OriginPolicy {
+	return OriginPolicy{
+		AllowedOrigins: []string{"http://localhost:3000"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// ParseOriginPolicy builds a policy from a comma-separated origin list, an optional boolean
+// and an optional duration. Empty values keep the defaults.
+func ParseOriginPolicy(origins, credentials, maxAge string) (OriginPolicy, error) {
+	policy := DefaultOriginPolicy()
+
+	if strings.TrimSpace(origins) != "" {
+		policy.AllowedOrigins = nil
+		for _, origin := range strings.Split(origins, ",") {
+			origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+			if origin == "" {
+				continue
+			}
+			if origin != "*" {
+				parsed, err := url.Parse(origin)
+				if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Path != "" {
+					return policy, fmt.Errorf("invalid allowed origin %q: expected scheme://host[:port]", origin)
+				}
+			}
+			policy.AllowedOrigins = append(policy.AllowedOrigins, strings.ToLower(origin))
+		}
+	}
+
+	if credentials != "" {
+		allow, err := strconv.ParseBool(credentials)
+		if err != nil {
+			return policy, fmt.Errorf("invalid credentials setting %q", credentials)
+		}
+		policy.AllowCredentials = allow
+	}
+
+	if maxAge != "" {
+		parsed, err := time.ParseDuration(maxAge)
+		if err != nil || parsed < 0 {
+			return policy, fmt.Errorf("invalid preflight max age %q", maxAge)
+		}
+		policy.MaxAge = parsed
+	}
+
+	// Credentials for any origin would let every website act as the signed-in user
+	if policy.AllowCredentials {
+		for _, origin := range policy.AllowedOrigins {
+			if origin == "*" {
+				return policy, fmt.Errorf("credentials cannot be allowed for every origin")
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+// AllowsOrigin reports whether an origin is on the allowlist
+func (p OriginPolicy) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+
+		// https://*.example.com matches https://app.example.com but not https://example.com
+		scheme, host, found := strings.Cut(allowed, "://*.")
+		if found && strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRequest reports whether a request may proceed under the policy: it has no Origin
+// header, comes from the server's own origin or from an allowed one
+func (p OriginPolicy) AllowsRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+
+	return p.AllowsOrigin(origin)
+}
+
+// CORSMiddleware answers cross-origin requests according to the origin policy. Other origins
+// get no CORS headers, so browsers withhold the response, and their preflights are refused.
+func CORSMiddleware(policy OriginPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions
+
+		if origin != "" {
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		if origin != "" && policy.AllowsOrigin(origin) {
+			// Reflect the origin rather than "*" so that caches keep responses per origin
+			c.Header("Access-Control-Allow-Origin", origin)
+			if policy.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+			c.Header("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
+
+			if preflight {
+				c.Header("Access-Control-Allow-Methods", corsAllowedMethods)
+				c.Header("Access-Control-Allow-Headers", corsAllowedHeaders)
+				if policy.MaxAge > 0 {
+					c.Header("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+				}
+			}
+		} else if origin != "" && !policy.AllowsRequest(c.Request) {
+			log.Printf("Rejected cross-origin request from %s to %s %s", origin, c.Request.Method, c.Request.URL.Path)
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+
+		if preflight {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestParseOriginPolicy tests reading the origin policy from configuration
+func TestParseOriginPolicy(t *testing.T) {
+	policy, err := ParseOriginPolicy(" https://App.example.com/ , https://*.example.org", "true", "1h")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(policy.AllowedOrigins) != 2 || policy.AllowedOrigins[0] != "https://app.example.com" {
+		t.Errorf("Unexpected origins %v", policy.AllowedOrigins)
+	}
+	if !policy.AllowCredentials || policy.MaxAge != time.Hour {
+		t.Errorf("Unexpected policy %+v", policy)
+	}
+
+	defaults, err := ParseOriginPolicy("", "", "")
+	if err != nil || len(defaults.AllowedOrigins) != 1 || defaults.AllowCredentials {
+		t.Errorf("Expected the default policy, got %+v (%v)", defaults, err)
+	}
+
+	for _, config := range [][3]string{
+		{"example.com", "", ""},
+		{"https://example.com/app", "", ""},
+		{"*", "true", ""},
+		{"https://example.com", "maybe", ""},
+		{"https://example.com", "", "soon"},
+	} {
+		if _, err := ParseOriginPolicy(config[0], config[1], config[2]); err == nil {
+			t.Errorf("Expected %q to be rejected", config)
+		}
+	}
+}
+
+// TestOriginPolicyAllowsRequest tests the checks shared by CORS and WebSocket upgrades
+func TestOriginPolicyAllowsRequest(t *testing.T) {
+	policy := OriginPolicy{AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"}}
+
+	tests := []struct {
+		name     string
+		origin   string
+		expected bool
+	}{
+		{name: "No origin", origin: "", expected: true},
+		{name: "Same origin", origin: "http://api.example.com", expected: true},
+		{name: "Allowed origin", origin: "https://app.example.com", expected: true},
+		{name: "Allowed origin in other case", origin: "https://APP.example.com", expected: true},
+		{name: "Allowed subdomain", origin: "https://vote.example.org", expected: true},
+		{name: "Bare wildcard domain", origin: "https://example.org", expected: false},
+		{name: "Lookalike domain", origin: "https://evilexample.org", expected: false},
+		{name: "Other scheme", origin: "http://app.example.com", expected: false},
+		{name: "Other site", origin: "https://evil.example.net", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://api.example.com/api/ws/1", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if allowed := policy.AllowsRequest(req); allowed != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, allowed)
+			}
+		})
+	}
+}
+
+// TestCORSMiddleware tests the CORS headers sent to allowed and other origins
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := OriginPolicy{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true, MaxAge: time.Minute}
+
+	router := gin.New()
+	router.Use(CORSMiddleware(policy))
+	router.GET("/api/projects", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name          string
+		method        string
+		origin        string
+		expected      int
+		allowOrigin   string
+		maxAge        string
+		allowsCookies bool
+	}{
+		{name: "Allowed request", method: "GET", origin: "https://app.example.com", expected: http.StatusOK, allowOrigin: "https://app.example.com", allowsCookies: true},
+		{name: "Allowed preflight", method: "OPTIONS", origin: "https://app.example.com", expected: http.StatusNoContent, allowOrigin: "https://app.example.com", maxAge: "60", allowsCookies: true},
+		{name: "Other origin request", method: "GET", origin: "https://evil.example.net", expected: http.StatusOK},
+		{name: "Other origin preflight", method: "OPTIONS", origin: "https://evil.example.net", expected: http.StatusForbidden},
+		{name: "Request without origin", method: "GET", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/api/projects", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", tt.allowOrigin, got)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != tt.maxAge {
+				t.Errorf("Expected Access-Control-Max-Age %q, got %q", tt.maxAge, got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.allowsCookies {
+				t.Errorf("Expected credentials allowed %v, got %v", tt.allowsCookies, got)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"pairwise/internal/domain"
+	"pairwise/internal/websocket"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,7 +29,7 @@ func (h *Handler) StartPairwiseSession(c *gin.Context) {
 		return
 	}
 
-	session, err := h.pairwiseService.StartPairwiseSession(projectID, req.CriterionType)
+	session, err := h.pairwiseService.StartPairwiseSession(c.Request.Context(), projectID, req.CriterionType, req.Strategy, req.Anonymous, req.Driven)
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -51,9 +52,9 @@ func (h *Handler) GetPairwiseSession(c *gin.Context) {
 
 	// Get query parameter for criterion type (default to complexity)
 	criterionType := c.DefaultQuery("type", "complexity")
-	if criterionType != "value" && criterionType != "complexity" {
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid criterion type. Must be 'value' or 'complexity'",
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
 		})
 		return
 	}
@@ -83,9 +84,9 @@ func (h *Handler) GetPairwiseSessionComparisons(c *gin.Context) {
 
 	// Get query parameter for criterion type (default to complexity)
 	criterionType := c.DefaultQuery("type", "complexity")
-	if criterionType != "value" && criterionType != "complexity" {
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid criterion type. Must be 'value' or 'complexity'",
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
 		})
 		return
 	}
@@ -120,14 +121,63 @@ func (h *Handler) SubmitPairwiseVote(c *gin.Context) {
 
 	// Get query parameter for criterion type (default to complexity)
 	criterionType := c.DefaultQuery("type", "complexity")
-	if criterionType != "value" && criterionType != "complexity" {
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid criterion type. Must be 'value' or 'complexity'",
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
+		})
+		return
+	}
+
+	var req domain.SubmitVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// The voter is the token holder, never a client-supplied ID
+	claims, _ := tokenClaims(c)
+	if req.AttendeeID != 0 && req.AttendeeID != claims.AttendeeID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Cannot vote on behalf of another attendee",
+		})
+		return
+	}
+	req.AttendeeID = claims.AttendeeID
+
+	// The vote goes to the current active session for the project and criterion
+	vote, err := h.pairwiseService.SubmitActiveSessionVote(c.Request.Context(), projectID, domain.CriterionType(criterionType), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"vote": vote,
+	})
+}
+
+// ResolvePairwiseComparison handles POST /api/projects/:id/pairwise/resolve?type=value|complexity
+func (h *Handler) ResolvePairwiseComparison(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	// Get query parameter for criterion type (default to complexity)
+	criterionType := c.DefaultQuery("type", "complexity")
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
 		})
 		return
 	}
 
-	// Get the current active session for the project
 	session, _, err := h.pairwiseService.GetActiveSession(projectID, domain.CriterionType(criterionType))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -135,9 +185,8 @@ func (h *Handler) SubmitPairwiseVote(c *gin.Context) {
 		})
 		return
 	}
-	sessionID := session.ID
 
-	var req domain.SubmitVoteRequest
+	var req domain.ResolveComparisonRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request payload",
@@ -146,21 +195,35 @@ func (h *Handler) SubmitPairwiseVote(c *gin.Context) {
 		return
 	}
 
-	// Session is already verified as belonging to the project from GetActiveSession above
+	// The facilitator is the token holder, never a client-supplied ID, so API keys cannot decide
+	claims, ok := tokenClaims(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Resolving a comparison needs a facilitator session token",
+		})
+		return
+	}
+	if req.FacilitatorID != 0 && req.FacilitatorID != claims.AttendeeID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Cannot resolve a comparison on behalf of another facilitator",
+		})
+		return
+	}
+	req.FacilitatorID = claims.AttendeeID
 
-	vote, err := h.pairwiseService.SubmitVote(sessionID, req)
+	comparison, err := h.pairwiseService.ResolveComparison(c.Request.Context(), session.ID, req)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"vote": vote,
+	c.JSON(http.StatusOK, gin.H{
+		"comparison": comparison,
 	})
 }
 
-// CompletePairwiseSession handles POST /api/projects/:id/pairwise-sessions/:session_id/complete
-func (h *Handler) CompletePairwiseSession(c *gin.Context) {
+// GetPairwiseCycles handles GET /api/projects/:id/pairwise/cycles?criterion=value|complexity
+func (h *Handler) GetPairwiseCycles(c *gin.Context) {
 	projectID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -169,29 +232,51 @@ func (h *Handler) CompletePairwiseSession(c *gin.Context) {
 		return
 	}
 
-	sessionID, err := strconv.Atoi(c.Param("session_id"))
-	if err != nil {
+	criterionType := c.DefaultQuery("criterion", "value")
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid session ID",
+			"error": "Invalid criterion. Must be a criterion key such as 'value' or 'complexity'",
 		})
 		return
 	}
 
-	// Verify session belongs to the project first
-	session, _, err := h.pairwiseService.GetSession(sessionID)
+	analysis, err := h.pairwiseService.FindPreferenceCycles(projectID, domain.CriterionType(criterionType))
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	if session.ProjectID != projectID {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Session not found",
+	c.JSON(http.StatusOK, analysis)
+}
+
+// CompletePairwiseSession handles POST /api/projects/:id/pairwise/complete?type=value|complexity
+func (h *Handler) CompletePairwiseSession(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	// Get query parameter for criterion type (default to complexity)
+	criterionType := c.DefaultQuery("type", "complexity")
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
 		})
 		return
 	}
 
-	err = h.pairwiseService.CompleteSession(sessionID)
+	session, _, err := h.pairwiseService.GetActiveSession(projectID, domain.CriterionType(criterionType))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "No active pairwise session found",
+		})
+		return
+	}
+
+	err = h.pairwiseService.CompleteSession(c.Request.Context(), session.ID)
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -202,7 +287,7 @@ func (h *Handler) CompletePairwiseSession(c *gin.Context) {
 	})
 }
 
-// GetNextComparison handles GET /api/projects/:id/pairwise-sessions/:session_id/next-comparison/:attendee_id
+// GetNextComparison handles GET /api/projects/:id/pairwise/next?type=value|complexity for the token holder
 func (h *Handler) GetNextComparison(c *gin.Context) {
 	projectID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -212,43 +297,80 @@ func (h *Handler) GetNextComparison(c *gin.Context) {
 		return
 	}
 
-	sessionID, err := strconv.Atoi(c.Param("session_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid session ID",
+	claims, _ := tokenClaims(c)
+	attendeeID := claims.AttendeeID
+	if requested := c.Query("attendee_id"); requested != "" && requested != strconv.Itoa(attendeeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Cannot get comparisons for another attendee",
 		})
 		return
 	}
 
-	attendeeID, err := strconv.Atoi(c.Param("attendee_id"))
-	if err != nil {
+	// Get query parameter for criterion type (default to complexity)
+	criterionType := c.DefaultQuery("type", "complexity")
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid attendee ID",
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
 		})
 		return
 	}
 
-	// Verify session belongs to the project first
-	session, _, err := h.pairwiseService.GetSession(sessionID)
+	session, _, err := h.pairwiseService.GetActiveSession(projectID, domain.CriterionType(criterionType))
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	if session.ProjectID != projectID {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Session not found",
+	comparison, err := h.pairwiseService.GetNextComparison(session.ID, attendeeID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comparison": comparison,
+	})
+}
+
+// GetPairwisePresence handles GET /api/projects/:id/pairwise/presence. It lists who follows
+// the criterion's pairwise topic over WebSocket, who is idle, and which voters have voted on
+// a comparison, by default the one waiting longest for a result, without their votes.
+func (h *Handler) GetPairwisePresence(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	// Get query parameter for criterion type (default to complexity)
+	criterionType := c.DefaultQuery("type", "complexity")
+	if !domain.IsValidCriterionKey(domain.CriterionType(criterionType)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid criterion type. Must be a criterion key such as 'value' or 'complexity'",
 		})
 		return
 	}
 
-	comparison, err := h.pairwiseService.GetNextComparison(sessionID, attendeeID)
+	comparisonID := 0
+	if requested := c.Query("comparison_id"); requested != "" {
+		comparisonID, err = strconv.Atoi(requested)
+		if err != nil || comparisonID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid comparison ID",
+			})
+			return
+		}
+	}
+
+	presence, err := h.wsHub.Presence(websocket.PairwiseTopic(projectID, domain.CriterionType(criterionType)), comparisonID)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"comparison": comparison,
+		"presence": presence,
 	})
 }
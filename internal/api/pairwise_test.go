@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+	"pairwise/internal/service"
+	"pairwise/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestResolvePairwiseComparison tests that a split comparison is resolved by the facilitator
+// holding the session token, whatever facilitator the request names, and never by an API key
+func TestResolvePairwiseComparison(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	db := testutil.NewTestDB(t)
+	h := newAuthHandler(t, db)
+
+	projectRepo := repository.NewProjectRepository(db)
+	criteriaRepo := repository.NewCriteriaRepository(db)
+	featureRepo := repository.NewFeatureRepository(db)
+	h.pairwiseService = service.NewPairwiseService(repository.NewPairwiseRepository(db), featureRepo,
+		repository.NewAttendeeRepository(db), projectRepo, criteriaRepo)
+
+	project, err := service.NewProjectService(projectRepo, criteriaRepo).CreateProject(ctx, domain.CreateProjectRequest{
+		Name:            "Test Project",
+		ConsensusPolicy: domain.ConsensusPolicyFacilitator,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	fred := createAttendee(t, h, project.ID, domain.CreateAttendeeRequest{Name: "Fred", IsFacilitator: true})
+	alice := createAttendee(t, h, project.ID, domain.CreateAttendeeRequest{Name: "Alice"})
+	var featureIDs []int
+	for _, title := range []string{"Search", "Export"} {
+		feature, err := featureRepo.Create(project.ID, domain.CreateFeatureRequest{Title: title, Description: title})
+		if err != nil {
+			t.Fatalf("Failed to create feature: %v", err)
+		}
+		featureIDs = append(featureIDs, feature.ID)
+	}
+
+	session, err := h.pairwiseService.StartPairwiseSession(ctx, project.ID, domain.CriterionTypeValue, "", false, false)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	comparisons, err := h.pairwiseService.GetSessionComparisons(session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get comparisons: %v", err)
+	}
+	comparisonID := comparisons[0].Comparison.ID
+	for i, attendee := range []*domain.Attendee{fred, alice} {
+		_, err := h.pairwiseService.SubmitVote(ctx, session.ID, domain.SubmitVoteRequest{
+			ComparisonID:       comparisonID,
+			AttendeeID:         attendee.ID,
+			PreferredFeatureID: &featureIDs[i],
+		})
+		if err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+
+	h.apiKeyService = service.NewAPIKeyService(repository.NewAPIKeyRepository(db), projectRepo)
+	apiKey, err := h.apiKeyService.CreateAPIKey(ctx, nil, nil, domain.CreateAPIKeyRequest{
+		Name:   "Admin",
+		Scopes: []domain.APIScope{domain.ScopeAdmin},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/projects/:id/pairwise/resolve", h.RequireFacilitator(), h.ResolvePairwiseComparison)
+	path := "/projects/" + strconv.Itoa(project.ID) + "/pairwise/resolve?type=value"
+
+	tests := []struct {
+		name     string
+		token    string
+		body     map[string]interface{}
+		expected int
+	}{
+		{name: "Attendee", token: issueToken(t, h, alice), body: map[string]interface{}{"comparison_id": comparisonID, "winner_id": featureIDs[0]}, expected: http.StatusForbidden},
+		{name: "On behalf of another attendee", token: issueToken(t, h, fred), body: map[string]interface{}{"comparison_id": comparisonID, "facilitator_id": alice.ID, "winner_id": featureIDs[0]}, expected: http.StatusForbidden},
+		{name: "Admin API key", token: apiKey.Key, body: map[string]interface{}{"comparison_id": comparisonID, "winner_id": featureIDs[0]}, expected: http.StatusForbidden},
+		{name: "Token holder", token: issueToken(t, h, fred), body: map[string]interface{}{"comparison_id": comparisonID, "winner_id": featureIDs[0]}, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest("POST", path, bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestCompletePairwiseSession tests that the facilitator completes the active session of the
+// criterion named in the query
+func TestCompletePairwiseSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	db := testutil.NewTestDB(t)
+	h := newAuthHandler(t, db)
+
+	projectRepo := repository.NewProjectRepository(db)
+	criteriaRepo := repository.NewCriteriaRepository(db)
+	featureRepo := repository.NewFeatureRepository(db)
+	h.pairwiseService = service.NewPairwiseService(repository.NewPairwiseRepository(db), featureRepo,
+		repository.NewAttendeeRepository(db), projectRepo, criteriaRepo)
+
+	project, err := service.NewProjectService(projectRepo, criteriaRepo).CreateProject(ctx, domain.CreateProjectRequest{Name: "Test Project"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	fred := createAttendee(t, h, project.ID, domain.CreateAttendeeRequest{Name: "Fred", IsFacilitator: true})
+	for _, title := range []string{"Search", "Export"} {
+		if _, err := featureRepo.Create(project.ID, domain.CreateFeatureRequest{Title: title, Description: title}); err != nil {
+			t.Fatalf("Failed to create feature: %v", err)
+		}
+	}
+
+	session, err := h.pairwiseService.StartPairwiseSession(ctx, project.ID, domain.CriterionTypeValue, "", false, false)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/projects/:id/pairwise/complete", h.RequireFacilitator(), h.CompletePairwiseSession)
+	token := issueToken(t, h, fred)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{name: "Criterion without an active session", query: "?type=complexity", expected: http.StatusBadRequest},
+		{name: "Active session", query: "?type=value", expected: http.StatusOK},
+		{name: "Already completed", query: "?type=value", expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/projects/"+strconv.Itoa(project.ID)+"/pairwise/complete"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	completed, _, err := h.pairwiseService.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if completed.Status != domain.SessionStatusCompleted {
+		t.Errorf("Expected the session to be completed, got %s", completed.Status)
+	}
+}
@@ -58,32 +58,15 @@ func (h *ProgressHandler) AdvancePhase(c *gin.Context) {
 
 	phase := domain.WorkflowPhase(request.Phase)
 
-	// Validate phase
-	validPhases := []domain.WorkflowPhase{
-		domain.PhaseSetup,
-		domain.PhaseAttendees,
-		domain.PhaseFeatures,
-		domain.PhasePairwiseValue,
-		domain.PhasePairwiseComplexity,
-		domain.PhaseFibonacciValue,
-		domain.PhaseFibonacciComplexity,
-		domain.PhaseResults,
-	}
-
-	isValid := false
-	for _, validPhase := range validPhases {
-		if phase == validPhase {
-			isValid = true
-			break
-		}
-	}
+	// Validate phase; whether the project has the phase is checked when advancing
+	isValid := domain.IsValidWorkflowPhase(phase)
 
 	if !isValid {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phase"})
 		return
 	}
 
-	err = h.progressService.AdvanceToPhase(projectID, phase)
+	err = h.progressService.AdvanceToPhase(c.Request.Context(), projectID, phase)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -119,7 +102,7 @@ func (h *ProgressHandler) CompletePhase(c *gin.Context) {
 
 	phase := domain.WorkflowPhase(request.Phase)
 
-	err = h.progressService.CompletePhase(projectID, phase)
+	err = h.progressService.CompletePhase(c.Request.Context(), projectID, phase)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
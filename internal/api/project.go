@@ -20,7 +20,7 @@ func (h *Handler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := h.projectService.CreateProject(req)
+	project, err := h.projectService.CreateProject(c.Request.Context(), req)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
@@ -102,7 +102,7 @@ func (h *Handler) UpdateProject(c *gin.Context) {
 		return
 	}
 
-	project, err := h.projectService.UpdateProject(projectID, req)
+	project, err := h.projectService.UpdateProject(c.Request.Context(), projectID, req)
 	if err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
@@ -129,7 +129,7 @@ func (h *Handler) DeleteProject(c *gin.Context) {
 		return
 	}
 
-	if err := h.projectService.DeleteProject(projectID); err != nil {
+	if err := h.projectService.DeleteProject(c.Request.Context(), projectID); err != nil {
 		if apiErr, ok := err.(*domain.APIError); ok {
 			c.JSON(apiErr.Code, gin.H{
 				"error": apiErr.Message,
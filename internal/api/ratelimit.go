@@ -0,0 +1,319 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pairwise/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKeyBy says what a rate limit is counted against
+type RateLimitKeyBy string
+
+const (
+	// RateLimitByIP counts requests per client IP
+	RateLimitByIP RateLimitKeyBy = "ip"
+	// RateLimitByAttendee counts requests per attendee session, falling back to the client IP
+	// for requests without a valid session token
+	RateLimitByAttendee RateLimitKeyBy = "attendee"
+)
+
+// Rate limit route groups
+const (
+	RateLimitGroupLogin     = "login"     // PIN logins, joins with an invite and OpenID Connect logins
+	RateLimitGroupWebSocket = "websocket" // WebSocket upgrades
+	RateLimitGroupVotes     = "votes"     // Pairwise votes and Fibonacci scores
+	RateLimitGroupWrites    = "writes"    // Other non-GET requests
+	RateLimitGroupReads     = "reads"     // GET requests
+)
+
+// RateLimitPolicy allows Limit requests per Window, in bursts of up to Limit requests
+type RateLimitPolicy struct {
+	Limit  int
+	Window time.Duration
+	KeyBy  RateLimitKeyBy
+}
+
+// RateLimitDecision is a limiter's answer for one request
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Until the full limit is available again
+	RetryAfter time.Duration // Until the next request is allowed, when this one is not
+}
+
+// Limiter decides whether a request counted against key may proceed under a policy.
+// Implementations must be safe for concurrent use.
+type Limiter interface {
+	Allow(key string, policy RateLimitPolicy) RateLimitDecision
+}
+
+// DefaultRateLimitPolicies returns the policy of each route group. Logins are strict to slow
+// PIN guessing, while votes allow the bursts of a live session.
+func DefaultRateLimitPolicies() map[string]RateLimitPolicy {
+	return map[string]RateLimitPolicy{
+		RateLimitGroupLogin:     {Limit: 10, Window: time.Minute, KeyBy: RateLimitByIP},
+		RateLimitGroupWebSocket: {Limit: 20, Window: time.Minute, KeyBy: RateLimitByAttendee},
+		RateLimitGroupVotes:     {Limit: 120, Window: time.Minute, KeyBy: RateLimitByAttendee},
+		RateLimitGroupWrites:    {Limit: 60, Window: time.Minute, KeyBy: RateLimitByAttendee},
+		RateLimitGroupReads:     {Limit: 300, Window: time.Minute, KeyBy: RateLimitByAttendee},
+	}
+}
+
+// ParseRateLimitPolicies overrides default policies from a comma-separated list of
+// group=limit/window[/keyby] entries, such as "login=5/1m/ip,reads=600/1m"
+func ParseRateLimitPolicies(config string, policies map[string]RateLimitPolicy) error {
+	for _, entry := range strings.Split(config, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		group, spec, ok := strings.Cut(entry, "=")
+		policy, known := policies[group]
+		if !ok || !known {
+			return fmt.Errorf("invalid rate limit policy %q: unknown route group", entry)
+		}
+
+		parts := strings.Split(spec, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf("invalid rate limit policy %q: expected limit/window[/keyby]", entry)
+		}
+
+		limit, err := strconv.Atoi(parts[0])
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid rate limit policy %q: limit must be a positive number", entry)
+		}
+
+		window, err := time.ParseDuration(parts[1])
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid rate limit policy %q: window must be a positive duration", entry)
+		}
+
+		policy.Limit = limit
+		policy.Window = window
+		if len(parts) == 3 {
+			policy.KeyBy = RateLimitKeyBy(parts[2])
+			if policy.KeyBy != RateLimitByIP && policy.KeyBy != RateLimitByAttendee {
+				return fmt.Errorf("invalid rate limit policy %q: key must be ip or attendee", entry)
+			}
+		}
+		policies[group] = policy
+	}
+	return nil
+}
+
+// ParseTrustedProxies parses a comma-separated list of the IP addresses and CIDR ranges of
+// reverse proxies whose X-Forwarded-For headers name the client. Rate limits keyed by IP
+// count against the connecting address when it is not listed, so clients cannot escape
+// them by making up the header. An empty list trusts no proxy.
+func ParseTrustedProxies(config string) ([]string, error) {
+	var proxies []string
+	for _, entry := range strings.Split(config, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: expected an IP address or CIDR range", entry)
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies, nil
+}
+
+// rateLimitGroup returns the route group of a request from its method and route template
+func rateLimitGroup(method, route string) string {
+	switch {
+	case strings.HasSuffix(route, "/attendees/login"), route == "/api/join", strings.HasPrefix(route, "/api/auth/"):
+		return RateLimitGroupLogin
+	case strings.HasPrefix(route, "/api/ws/") && route != "/api/ws/stats":
+		return RateLimitGroupWebSocket
+	case method == http.MethodPost && (strings.HasSuffix(route, "/pairwise/votes") || strings.HasSuffix(route, "/fibonacci/scores")):
+		return RateLimitGroupVotes
+	case method == http.MethodGet || method == http.MethodHead:
+		return RateLimitGroupReads
+	default:
+		return RateLimitGroupWrites
+	}
+}
+
+// RateLimitMiddleware limits requests by the policy of their route group and sets the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers. Attendee keys come from
+// a valid session token, since the middleware runs before the route's auth middleware.
+func (h *Handler) RateLimitMiddleware(limiter Limiter, policies map[string]RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group := rateLimitGroup(c.Request.Method, c.FullPath())
+		policy, ok := policies[group]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		key := group + ":" + h.rateLimitKey(c, policy.KeyBy)
+		decision := limiter.Allow(key, policy)
+
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+
+		if !decision.Allowed {
+			retryAfter := ceilSeconds(decision.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Rate limit exceeded",
+				"retry_after": retryAfter,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies who a request is counted against
+func (h *Handler) rateLimitKey(c *gin.Context, keyBy RateLimitKeyBy) string {
+	if keyBy == RateLimitByAttendee && h.tokenService != nil {
+		if token := bearerToken(c); token != "" && !domain.IsAPIKey(token) {
+			if claims, err := h.tokenService.Verify(token); err == nil {
+				return fmt.Sprintf("attendee:%d:%d", claims.ProjectID, claims.AttendeeID)
+			}
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// tokenBucket holds the tokens left for one key
+type tokenBucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64 // Tokens added per second
+	updated  time.Time
+}
+
+// refill adds the tokens earned since the last update
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+		b.updated = now
+	}
+}
+
+// TokenBucketLimiter is an in-memory Limiter with a token bucket per key. Buckets that have
+// refilled completely are evicted periodically, which loses nothing since a full bucket
+// behaves like a new one.
+type TokenBucketLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewTokenBucketLimiter creates a limiter evicting full buckets every evictEvery. A zero
+// interval disables the background eviction.
+func NewTokenBucketLimiter(evictEvery time.Duration) *TokenBucketLimiter {
+	l := &TokenBucketLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+
+	if evictEvery > 0 {
+		go l.evictLoop(evictEvery)
+	}
+
+	return l
+}
+
+// Allow takes a token from the key's bucket if one is left
+func (l *TokenBucketLimiter) Allow(key string, policy RateLimitPolicy) RateLimitDecision {
+	capacity := float64(policy.Limit)
+	rate := capacity / policy.Window.Seconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, exists := l.buckets[key]
+	if !exists || bucket.capacity != capacity || bucket.rate != rate {
+		bucket = &tokenBucket{tokens: capacity, capacity: capacity, rate: rate, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.refill(now)
+
+	decision := RateLimitDecision{Limit: policy.Limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsDuration((1 - bucket.tokens) / rate)
+	}
+
+	decision.Remaining = int(math.Floor(bucket.tokens))
+	decision.Reset = secondsDuration((capacity - bucket.tokens) / rate)
+	return decision
+}
+
+// Evict drops the buckets that have refilled completely
+func (l *TokenBucketLimiter) Evict() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of buckets held
+func (l *TokenBucketLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// Stop ends the background eviction
+func (l *TokenBucketLimiter) Stop() {
+	l.once.Do(func() { close(l.stop) })
+}
+
+// evictLoop evicts full buckets until the limiter is stopped
+func (l *TokenBucketLimiter) evictLoop(every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.Evict()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// secondsDuration converts fractional seconds to a duration
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
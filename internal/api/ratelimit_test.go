@@ -0,0 +1,230 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestTokenBucketLimiter tests bursts, refill and eviction with a fake clock
+func TestTokenBucketLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewTokenBucketLimiter(0)
+	limiter.now = func() time.Time { return now }
+	policy := RateLimitPolicy{Limit: 3, Window: 3 * time.Second}
+
+	for i := 0; i < 3; i++ {
+		decision := limiter.Allow("a", policy)
+		if !decision.Allowed || decision.Remaining != 2-i {
+			t.Fatalf("Request %d: expected to be allowed with %d remaining, got %+v", i+1, 2-i, decision)
+		}
+	}
+
+	decision := limiter.Allow("a", policy)
+	if decision.Allowed || decision.RetryAfter != time.Second || decision.Reset != 3*time.Second {
+		t.Fatalf("Expected the burst to be exhausted, got %+v", decision)
+	}
+
+	if !limiter.Allow("b", policy).Allowed {
+		t.Error("Expected other keys to have their own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if !limiter.Allow("a", policy).Allowed {
+		t.Error("Expected a token to refill after a second")
+	}
+
+	// b has refilled since its single request, while a is still short of tokens
+	limiter.Evict()
+	if limiter.Len() != 1 {
+		t.Errorf("Expected only the partly used bucket to be kept, got %d", limiter.Len())
+	}
+
+	now = now.Add(3 * time.Second)
+	limiter.Evict()
+	if limiter.Len() != 0 {
+		t.Errorf("Expected full buckets to be evicted, got %d", limiter.Len())
+	}
+}
+
+// TestTokenBucketLimiterConcurrency tests that concurrent requests never exceed the limit
+func TestTokenBucketLimiterConcurrency(t *testing.T) {
+	limiter := NewTokenBucketLimiter(time.Millisecond)
+	defer limiter.Stop()
+	policy := RateLimitPolicy{Limit: 50, Window: time.Hour}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.Allow("shared", policy).Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 50 {
+		t.Errorf("Expected exactly 50 allowed requests, got %d", allowed)
+	}
+}
+
+// TestParseRateLimitPolicies tests overriding route group policies
+func TestParseRateLimitPolicies(t *testing.T) {
+	policies := DefaultRateLimitPolicies()
+	if err := ParseRateLimitPolicies("login=5/30s, reads=600/1m/ip", policies); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if login := policies[RateLimitGroupLogin]; login.Limit != 5 || login.Window != 30*time.Second || login.KeyBy != RateLimitByIP {
+		t.Errorf("Unexpected login policy %+v", login)
+	}
+	if reads := policies[RateLimitGroupReads]; reads.Limit != 600 || reads.KeyBy != RateLimitByIP {
+		t.Errorf("Unexpected reads policy %+v", reads)
+	}
+
+	for _, config := range []string{"unknown=5/1m", "login=5", "login=0/1m", "login=5/soon", "login=5/1m/user"} {
+		if err := ParseRateLimitPolicies(config, DefaultRateLimitPolicies()); err == nil {
+			t.Errorf("Expected %q to be rejected", config)
+		}
+	}
+}
+
+// TestRateLimitMiddleware tests route groups, attendee keys and the RateLimit headers
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := service.NewTokenService([]byte("test-secret"), time.Hour)
+	h := &Handler{tokenService: tokens}
+
+	policies := map[string]RateLimitPolicy{
+		RateLimitGroupLogin: {Limit: 1, Window: time.Minute, KeyBy: RateLimitByIP},
+		RateLimitGroupReads: {Limit: 1, Window: time.Minute, KeyBy: RateLimitByAttendee},
+	}
+
+	router := gin.New()
+	router.Use(h.RateLimitMiddleware(NewTokenBucketLimiter(0), policies))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/api/projects/:id/attendees/login", ok)
+	router.GET("/api/auth/oidc/callback", ok)
+	router.GET("/api/projects/:id/features", ok)
+	router.POST("/api/projects/:id/features", ok)
+
+	first, _, _ := tokens.Issue(&domain.Attendee{ID: 5, ProjectID: 1})
+	second, _, _ := tokens.Issue(&domain.Attendee{ID: 6, ProjectID: 1})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		expected int
+	}{
+		{name: "First login", method: "POST", path: "/api/projects/1/attendees/login", expected: http.StatusOK},
+		{name: "Second login from the same IP", method: "POST", path: "/api/projects/1/attendees/login", token: first, expected: http.StatusTooManyRequests},
+		{name: "OpenID Connect callback from the same IP", method: "GET", path: "/api/auth/oidc/callback", expected: http.StatusTooManyRequests},
+		{name: "First attendee reads", method: "GET", path: "/api/projects/1/features", token: first, expected: http.StatusOK},
+		{name: "First attendee reads again", method: "GET", path: "/api/projects/1/features", token: first, expected: http.StatusTooManyRequests},
+		{name: "Second attendee reads", method: "GET", path: "/api/projects/1/features", token: second, expected: http.StatusOK},
+		{name: "Group without a policy", method: "POST", path: "/api/projects/1/features", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Fatalf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+			if tt.expected == http.StatusTooManyRequests && (w.Header().Get("Retry-After") != "60" || w.Header().Get("RateLimit-Remaining") != "0") {
+				t.Errorf("Expected rate limit headers, got %v", w.Header())
+			}
+			if tt.name == "First attendee reads" && (w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Policy") != "1;w=60") {
+				t.Errorf("Expected rate limit headers, got %v", w.Header())
+			}
+		})
+	}
+}
+
+// TestRateLimitTrustedProxies tests that X-Forwarded-For only names the client when a
+// trusted proxy sent it, so that clients cannot reset their login limit by making it up
+func TestRateLimitTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+	policies := map[string]RateLimitPolicy{
+		RateLimitGroupLogin: {Limit: 1, Window: time.Minute, KeyBy: RateLimitByIP},
+	}
+
+	// login posts a login from the test request's address, 192.0.2.1, forwarded for the
+	// given client
+	login := func(t *testing.T, router *gin.Engine, forwardedFor string) int {
+		t.Helper()
+
+		req := httptest.NewRequest("POST", "/api/projects/1/attendees/login", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	newRouter := func(t *testing.T, config string) *gin.Engine {
+		t.Helper()
+
+		proxies, err := ParseTrustedProxies(config)
+		if err != nil {
+			t.Fatalf("Failed to parse trusted proxies: %v", err)
+		}
+		router := gin.New()
+		if err := router.SetTrustedProxies(proxies); err != nil {
+			t.Fatalf("Failed to set trusted proxies: %v", err)
+		}
+		router.Use(h.RateLimitMiddleware(NewTokenBucketLimiter(0), policies))
+		router.POST("/api/projects/:id/attendees/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return router
+	}
+
+	t.Run("Spoofed header from an untrusted address", func(t *testing.T) {
+		router := newRouter(t, "")
+		if code := login(t, router, "203.0.113.1"); code != http.StatusOK {
+			t.Fatalf("Expected the first login to pass, got %d", code)
+		}
+		if code := login(t, router, "203.0.113.2"); code != http.StatusTooManyRequests {
+			t.Errorf("Expected the connecting address to be limited, got %d", code)
+		}
+	})
+
+	t.Run("Header from a trusted proxy", func(t *testing.T) {
+		router := newRouter(t, "10.0.0.0/8, 192.0.2.1")
+		if code := login(t, router, "203.0.113.1"); code != http.StatusOK {
+			t.Fatalf("Expected the first login to pass, got %d", code)
+		}
+		if code := login(t, router, "203.0.113.2"); code != http.StatusOK {
+			t.Errorf("Expected another client behind the proxy to pass, got %d", code)
+		}
+		if code := login(t, router, "203.0.113.1"); code != http.StatusTooManyRequests {
+			t.Errorf("Expected the forwarded client to be limited, got %d", code)
+		}
+	})
+
+	t.Run("Invalid proxy", func(t *testing.T) {
+		if _, err := ParseTrustedProxies("proxy.internal"); err == nil {
+			t.Error("Expected a host name to be rejected")
+		}
+	})
+}
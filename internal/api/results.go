@@ -12,7 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// CalculateResults handles POST /api/projects/{id}/calculate-results
+// CalculateResults handles POST /api/projects/{id}/calculate-results?mode=consensus|vote&model=win_count|vote_win_count|bradley_terry|elo|ahp
 func (h *Handler) CalculateResults(c *gin.Context) {
 	projectID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -22,7 +22,24 @@ func (h *Handler) CalculateResults(c *gin.Context) {
 		return
 	}
 
-	results, err := h.resultsService.CalculateResults(projectID)
+	mode := domain.WinCountMode(c.DefaultQuery("mode", string(domain.WinCountModeConsensus)))
+	if !domain.IsValidWinCountMode(mode) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid win-count mode. Must be 'consensus' or 'vote'",
+		})
+		return
+	}
+
+	// Without a model, the win-count model of the mode is used
+	model := domain.RankingModelType(c.Query("model"))
+	if model != "" && !domain.IsValidRankingModel(model) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ranking model. Must be 'win_count', 'vote_win_count', 'bradley_terry', 'elo' or 'ahp'",
+		})
+		return
+	}
+
+	results, err := h.resultsService.CalculateResults(c.Request.Context(), projectID, mode, model)
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -50,6 +67,25 @@ func (h *Handler) GetResults(c *gin.Context) {
 	c.JSON(http.StatusOK, results)
 }
 
+// GetAHPAnalysis handles GET /api/projects/{id}/results/ahp
+func (h *Handler) GetAHPAnalysis(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	analysis, err := h.resultsService.GetAHPAnalysis(projectID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
 // ExportResults handles GET /api/projects/{id}/results/export?format=csv|json|jira
 func (h *Handler) ExportResults(c *gin.Context) {
 	projectID, err := strconv.Atoi(c.Param("id"))
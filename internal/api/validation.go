@@ -246,23 +246,19 @@ func (v *InputValidator) ValidateFeature(name, description string) error {
 
 // ValidateWorkflowPhase validates workflow phase transitions
 func (v *InputValidator) ValidateWorkflowPhase(phase string) error {
+	if domain.IsValidWorkflowPhase(domain.WorkflowPhase(phase)) {
+		return nil
+	}
+
 	validPhases := []string{
 		string(domain.PhaseSetup),
 		string(domain.PhaseAttendees),
 		string(domain.PhaseFeatures),
-		string(domain.PhasePairwiseValue),
-		string(domain.PhasePairwiseComplexity),
-		string(domain.PhaseFibonacciValue),
-		string(domain.PhaseFibonacciComplexity),
+		"pairwise_<criterion>",
+		"fibonacci_<criterion>",
 		string(domain.PhaseResults),
 	}
 
-	for _, validPhase := range validPhases {
-		if phase == validPhase {
-			return nil
-		}
-	}
-
 	return &domain.ValidationError{
 		Field:   "phase",
 		Message: fmt.Sprintf("Invalid phase. Must be one of: %s", strings.Join(validPhases, ", ")),
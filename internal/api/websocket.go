@@ -1,8 +1,9 @@
 package api
 
 import (
+	"log"
 	"net/http"
-	"strconv"
+	"strings"
 
 	"pairwise/internal/websocket"
 
@@ -10,63 +11,52 @@ import (
 	gorilla_websocket "github.com/gorilla/websocket"
 )
 
-// HandleWebSocket handles WebSocket connections for pairwise sessions
+// HandleWebSocket handles GET /api/ws/:projectId, opening one connection per project for an
+// attendee. The attendee and project come from the session token verified by
+// RequireObserver. Clients subscribe to topics such as "pairwise:value", "fibonacci:value"
+// or "results" with subscribe messages, or when connecting with the topics query parameter,
+// e.g. ?topics=pairwise:value,pairwise:complexity.
 func (h *Handler) HandleWebSocket(c *gin.Context) {
-	projectID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid project ID",
+	// Browsers send session tokens from any page, so the page's origin must be allowed
+	if !h.originPolicy.AllowsRequest(c.Request) {
+		log.Printf("Rejected WebSocket connection from origin %s", c.GetHeader("Origin"))
+		h.wsHub.RecordRejectedConnection()
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Origin not allowed",
 		})
 		return
 	}
 
-	sessionID, err := strconv.Atoi(c.Param("session_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid session ID",
+	// Connections belong to an attendee, so API keys cannot open them
+	claims, ok := tokenClaims(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "WebSocket connections need an attendee session token",
 		})
 		return
 	}
 
-	// Get attendee ID from query parameter
-	attendeeIDStr := c.Query("attendee_id")
-	if attendeeIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Missing attendee_id parameter",
-		})
-		return
+	var topicNames []string
+	if query := c.Query("topics"); query != "" {
+		topicNames = strings.Split(query, ",")
 	}
-
-	attendeeID, err := strconv.Atoi(attendeeIDStr)
+	topics, err := websocket.ParseTopics(claims.ProjectID, topicNames)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid attendee ID",
-		})
-		return
-	}
-
-	// Validate session exists and belongs to the project
-	session, _, err := h.pairwiseService.GetSession(sessionID)
-	if err != nil {
-		handleServiceError(c, err)
-		return
-	}
-
-	if session.ProjectID != projectID {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Session not found",
+			"error":   "Invalid topic",
+			"details": err.Error(),
 		})
 		return
 	}
 
 	// Validate attendee exists and belongs to the project
-	attendee, err := h.attendeeService.GetAttendee(attendeeID)
+	attendee, err := h.attendeeService.GetAttendee(claims.AttendeeID)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	if attendee.ProjectID != projectID {
+	if attendee.ProjectID != claims.ProjectID {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "Attendee does not belong to this project",
 		})
@@ -77,28 +67,19 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 	upgrader := gorilla_websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			// In production, implement proper origin validation
-			return true
-		},
+		CheckOrigin:     h.originPolicy.AllowsRequest,
 	}
 
+	// The upgrader writes the error response itself
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to upgrade to WebSocket",
-		})
+		log.Printf("WebSocket upgrade failed for attendee %d: %v", attendee.ID, err)
 		return
 	}
 
 	// Create and register the WebSocket client
-	client := websocket.NewClient(h.wsHub, conn, sessionID, attendeeID)
-
-	// Set user agent if available
-	if userAgent := c.GetHeader("User-Agent"); userAgent != "" {
-		// We can't modify the client after creation, but we could log it
-		// In a more sophisticated implementation, we'd pass this during creation
-	}
+	client := websocket.NewClient(h.wsHub, conn, attendee, attendee.AccessRole(), topics...)
+	client.SetUserAgent(c.GetHeader("User-Agent"))
 
 	// Register the client with the hub
 	h.wsHub.RegisterClient(client)
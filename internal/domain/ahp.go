@@ -0,0 +1,252 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// AHP (Analytic Hierarchy Process) support for pairwise judgments on Saaty's 1-9 scale
+
+const (
+	// MinAHPIntensity means both features are equally preferred
+	MinAHPIntensity = 1
+	// MaxAHPIntensity means one feature is extremely preferred over the other
+	MaxAHPIntensity = 9
+	// DefaultAHPIntensity is used for a preference given without an intensity ("moderately preferred")
+	DefaultAHPIntensity = 3
+	// AHPConsistencyThreshold is the largest consistency ratio Saaty considers acceptable
+	AHPConsistencyThreshold = 0.1
+)
+
+// ahpRandomIndex holds Saaty's random consistency index for matrices of size 1 to 15
+var ahpRandomIndex = []float64{0, 0, 0.58, 0.90, 1.12, 1.24, 1.32, 1.41, 1.45, 1.49, 1.51, 1.48, 1.56, 1.57, 1.59}
+
+// AHPWeight represents the priority weight derived for a single feature
+type AHPWeight struct {
+	FeatureID int     `json:"feature_id"`
+	Weight    float64 `json:"weight"`
+}
+
+// AHPResult represents the AHP priority weights and consistency for one criterion
+type AHPResult struct {
+	Criterion        ComparisonCriterion `json:"criterion"`
+	FeatureIDs       []int               `json:"feature_ids"`
+	Matrix           [][]float64         `json:"matrix"` // Reciprocal judgment matrix, rows and columns ordered by FeatureIDs
+	Weights          []AHPWeight         `json:"weights"`
+	LambdaMax        float64             `json:"lambda_max"`
+	ConsistencyIndex float64             `json:"consistency_index"`
+	ConsistencyRatio float64             `json:"consistency_ratio"`
+	IsConsistent     bool                `json:"is_consistent"`
+	MissingPairs     int                 `json:"missing_pairs"`
+}
+
+// AHPAnalysis represents the AHP results for each criterion of a project
+type AHPAnalysis struct {
+	ProjectID int         `json:"project_id"`
+	Criteria  []AHPResult `json:"criteria"`
+}
+
+// ValidateAHPIntensity validates an optional intensity; zero means no intensity was given
+func ValidateAHPIntensity(intensity int) error {
+	if intensity == 0 || (intensity >= MinAHPIntensity && intensity <= MaxAHPIntensity) {
+		return nil
+	}
+	return fmt.Errorf("intensity must be between %d and %d, got: %d", MinAHPIntensity, MaxAHPIntensity, intensity)
+}
+
+// JudgmentIntensity returns the effective intensity of a judgment, applying the default
+// to preferences given without one. Ties are always equal preference.
+func JudgmentIntensity(intensity int, isTie bool) int {
+	if isTie {
+		return MinAHPIntensity
+	}
+	if intensity == 0 {
+		return DefaultAHPIntensity
+	}
+	return intensity
+}
+
+// CalculateAHP builds the reciprocal judgment matrix for a criterion and derives priority
+// weights from its principal eigenvector. Several judgments on the same pair (for example
+// individual ballots) are combined with the geometric mean. Pairs that were never compared
+// are handled with Harker's method for incomplete matrices.
+func CalculateAHP(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion) (*AHPResult, error) {
+	n := len(featureIDs)
+	if n == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	index := make(map[int]int, n)
+	for i, featureID := range featureIDs {
+		index[featureID] = i
+	}
+
+	// Sum log judgments per pair so they can be averaged geometrically
+	logSums := make([][]float64, n)
+	counts := make([][]int, n)
+	for i := range logSums {
+		logSums[i] = make([]float64, n)
+		counts[i] = make([]int, n)
+	}
+
+	judgments := 0
+	for _, comp := range comparisons {
+		if comp.Criterion != criterion {
+			continue
+		}
+		a, okA := index[comp.FeatureAID]
+		b, okB := index[comp.FeatureBID]
+		if !okA || !okB || a == b {
+			continue
+		}
+
+		intensity := float64(JudgmentIntensity(comp.Intensity, comp.Result == ResultTie))
+		var judgment float64
+		switch comp.Result {
+		case ResultAWins:
+			judgment = intensity
+		case ResultBWins:
+			judgment = 1 / intensity
+		case ResultTie:
+			judgment = 1
+		default:
+			return nil, fmt.Errorf("%w: unknown result %q", ErrInvalidComparison, comp.Result)
+		}
+
+		logSums[a][b] += math.Log(judgment)
+		logSums[b][a] -= math.Log(judgment)
+		counts[a][b]++
+		counts[b][a]++
+		judgments++
+	}
+
+	if n > 1 && judgments == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	// Harker's method: missing entries are zero and each missing pair adds one to the diagonal
+	matrix := make([][]float64, n)
+	missingPairs := 0
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		matrix[i][i] = 1
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			if counts[i][j] == 0 {
+				matrix[i][i]++
+				if i < j {
+					missingPairs++
+				}
+				continue
+			}
+			matrix[i][j] = math.Exp(logSums[i][j] / float64(counts[i][j]))
+		}
+	}
+
+	weights, lambdaMax := principalEigenvector(matrix)
+
+	result := &AHPResult{
+		Criterion:    criterion,
+		FeatureIDs:   featureIDs,
+		Matrix:       matrix,
+		Weights:      make([]AHPWeight, n),
+		LambdaMax:    lambdaMax,
+		MissingPairs: missingPairs,
+	}
+
+	for i, featureID := range featureIDs {
+		result.Weights[i] = AHPWeight{FeatureID: featureID, Weight: weights[i]}
+	}
+
+	if n > 2 {
+		result.ConsistencyIndex = math.Max(0, (lambdaMax-float64(n))/float64(n-1))
+		result.ConsistencyRatio = result.ConsistencyIndex / ahpRandomIndexFor(n)
+	}
+	result.IsConsistent = result.ConsistencyRatio <= AHPConsistencyThreshold
+
+	return result, nil
+}
+
+// principalEigenvector finds the normalized principal eigenvector and eigenvalue of a
+// positive reciprocal matrix by power iteration
+func principalEigenvector(matrix [][]float64) ([]float64, float64) {
+	n := len(matrix)
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1 / float64(n)
+	}
+
+	product := make([]float64, n)
+	for iteration := 0; iteration < 1000; iteration++ {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			product[i] = 0
+			for j := 0; j < n; j++ {
+				product[i] += matrix[i][j] * weights[j]
+			}
+			sum += product[i]
+		}
+
+		maxChange := 0.0
+		for i := 0; i < n; i++ {
+			next := product[i] / sum
+			maxChange = math.Max(maxChange, math.Abs(next-weights[i]))
+			weights[i] = next
+		}
+
+		if maxChange < 1e-12 {
+			break
+		}
+	}
+
+	// λmax is the average ratio of (A·w)ᵢ to wᵢ
+	lambdaMax := 0.0
+	for i := 0; i < n; i++ {
+		row := 0.0
+		for j := 0; j < n; j++ {
+			row += matrix[i][j] * weights[j]
+		}
+		lambdaMax += row / weights[i]
+	}
+
+	return weights, lambdaMax / float64(n)
+}
+
+// ahpRandomIndexFor returns Saaty's random index for an n×n matrix
+func ahpRandomIndexFor(n int) float64 {
+	if n > len(ahpRandomIndex) {
+		return ahpRandomIndex[len(ahpRandomIndex)-1]
+	}
+	return ahpRandomIndex[n-1]
+}
+
+// AHPModel uses AHP priority weights as a ranking model. The weights sum to 1 across
+// features, so they are on a different scale from win-counts but still between 0 and 1.
+type AHPModel struct{}
+
+// Type returns the model type
+func (AHPModel) Type() RankingModelType {
+	return RankingModelAHP
+}
+
+// Estimate derives AHP priority weights for all features
+func (AHPModel) Estimate(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error) {
+	ahp, err := CalculateAHP(featureIDs, comparisons, criterion)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[int]int, len(featureIDs))
+	for i, featureID := range featureIDs {
+		index[featureID] = i
+	}
+
+	results := observedRecords(featureIDs, index, comparisons, criterion)
+	for i := range results {
+		results[i].WinCount = ahp.Weights[i].Weight
+	}
+
+	return results, nil
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+// Test AHP priority weights and consistency ratio
+func TestCalculateAHP(t *testing.T) {
+	featureIDs := []int{1, 2, 3}
+
+	tests := []struct {
+		name             string
+		comparisons      []PairwiseComparison
+		expectedWeights  []float64
+		expectConsistent bool
+		expectedMissing  int
+		expectPositiveCR bool
+		expectError      bool
+	}{
+		{
+			name: "Perfectly consistent judgments",
+			comparisons: []PairwiseComparison{
+				{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins, Intensity: 3},
+				{FeatureAID: 2, FeatureBID: 3, Criterion: CriterionValue, Result: ResultAWins, Intensity: 3},
+				{FeatureAID: 1, FeatureBID: 3, Criterion: CriterionValue, Result: ResultAWins, Intensity: 9},
+			},
+			expectedWeights:  []float64{9.0 / 13.0, 3.0 / 13.0, 1.0 / 13.0},
+			expectConsistent: true,
+		},
+		{
+			name: "Circular judgments are inconsistent",
+			comparisons: []PairwiseComparison{
+				{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins, Intensity: 9},
+				{FeatureAID: 2, FeatureBID: 3, Criterion: CriterionValue, Result: ResultAWins, Intensity: 9},
+				{FeatureAID: 3, FeatureBID: 1, Criterion: CriterionValue, Result: ResultAWins, Intensity: 9},
+			},
+			expectConsistent: false,
+			expectPositiveCR: true,
+		},
+		{
+			name: "Missing pair is completed from the others",
+			comparisons: []PairwiseComparison{
+				{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins, Intensity: 3},
+				{FeatureAID: 2, FeatureBID: 3, Criterion: CriterionValue, Result: ResultAWins, Intensity: 3},
+			},
+			expectedWeights:  []float64{9.0 / 13.0, 3.0 / 13.0, 1.0 / 13.0},
+			expectConsistent: true,
+			expectedMissing:  1,
+		},
+		{
+			name: "Ballots on the same pair are averaged geometrically",
+			comparisons: []PairwiseComparison{
+				{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins, Intensity: 9},
+				{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins, Intensity: 1},
+				{FeatureAID: 2, FeatureBID: 3, Criterion: CriterionValue, Result: ResultTie},
+				{FeatureAID: 1, FeatureBID: 3, Criterion: CriterionValue, Result: ResultAWins, Intensity: 3},
+			},
+			expectedWeights:  []float64{0.6, 0.2, 0.2},
+			expectConsistent: true,
+		},
+		{
+			name:        "No judgments",
+			comparisons: []PairwiseComparison{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CalculateAHP(featureIDs, tt.comparisons, CriterionValue)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for i, expected := range tt.expectedWeights {
+				if math.Abs(result.Weights[i].Weight-expected) > 0.0001 {
+					t.Errorf("Expected feature %d weight %.4f, got %.4f", result.Weights[i].FeatureID, expected, result.Weights[i].Weight)
+				}
+			}
+
+			if result.IsConsistent != tt.expectConsistent {
+				t.Errorf("Expected consistent %v, got %v (CR %.4f)", tt.expectConsistent, result.IsConsistent, result.ConsistencyRatio)
+			}
+
+			if tt.expectPositiveCR && result.ConsistencyRatio <= AHPConsistencyThreshold {
+				t.Errorf("Expected consistency ratio above %.2f, got %.4f", AHPConsistencyThreshold, result.ConsistencyRatio)
+			}
+
+			if result.MissingPairs != tt.expectedMissing {
+				t.Errorf("Expected %d missing pairs, got %d", tt.expectedMissing, result.MissingPairs)
+			}
+		})
+	}
+}
+
+// Test AHP intensity validation
+func TestValidateAHPIntensity(t *testing.T) {
+	for _, intensity := range []int{0, 1, 5, 9} {
+		if err := ValidateAHPIntensity(intensity); err != nil {
+			t.Errorf("Expected intensity %d to be valid, got %v", intensity, err)
+		}
+	}
+
+	for _, intensity := range []int{-1, 10} {
+		if err := ValidateAHPIntensity(intensity); err == nil {
+			t.Errorf("Expected intensity %d to be invalid", intensity)
+		}
+	}
+}
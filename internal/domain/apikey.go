@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, which tells keys apart from session tokens
+const APIKeyPrefix = "pwk_"
+
+// APIKeyDisplayLength is how many leading characters of a key are kept to identify it in listings
+const APIKeyDisplayLength = 12
+
+// APIScope is a permission granted to an API key
+type APIScope string
+
+const (
+	// ScopeReadResults can read projects and their results
+	ScopeReadResults APIScope = "read:results"
+	// ScopeWriteFeatures can read, create, update, delete and import features
+	ScopeWriteFeatures APIScope = "write:features"
+	// ScopeAdmin can do everything a facilitator can, and manage API keys
+	ScopeAdmin APIScope = "admin"
+)
+
+// IsValidAPIScope checks if a scope is known
+func IsValidAPIScope(scope APIScope) bool {
+	switch scope {
+	case ScopeReadResults, ScopeWriteFeatures, ScopeAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIScopes is a list of scopes, stored as a space-separated string
+type APIScopes []APIScope
+
+// Value implements driver.Valuer
+func (s APIScopes) Value() (driver.Value, error) {
+	scopes := make([]string, len(s))
+	for i, scope := range s {
+		scopes[i] = string(scope)
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+// Scan implements sql.Scanner
+func (s *APIScopes) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into APIScopes", value)
+	}
+
+	*s = APIScopes{}
+	for _, scope := range strings.Fields(text) {
+		*s = append(*s, APIScope(scope))
+	}
+	return nil
+}
+
+// GormDataType stores scopes in a text column
+func (APIScopes) GormDataType() string {
+	return "text"
+}
+
+// Has reports whether the list contains a scope
+func (s APIScopes) Has(scope APIScope) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a credential for automation and integrations. A key without a project is global.
+// Only the SHA-256 hash of the key is stored; the key itself is shown once on creation.
+type APIKey struct {
+	ID         int        `json:"id" db:"id"`
+	ProjectID  *int       `json:"project_id,omitempty" db:"project_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"` // Leading characters of the key, to identify it
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     APIScopes  `json:"scopes" db:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy  *int       `json:"created_by,omitempty" db:"created_by"` // Facilitator who created the key
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for GORM
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// CreateAPIKeyRequest represents the request payload for creating an API key. ProjectID is
+// only read by the global endpoint; keys created under a project are scoped to it.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,min=1,max=100"`
+	Scopes    []APIScope `json:"scopes" binding:"required,min=1,dive,oneof=read:results write:features admin"`
+	ProjectID *int       `json:"project_id,omitempty"`
+}
+
+// APIKeyCredentials is a newly created API key. The key is only returned on creation.
+type APIKeyCredentials struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a session token
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// Allows reports whether the key may use a route that needs the given role, or one of the
+// given scopes. Keys never act as an attendee, since they have no one to vote as.
+func (k *APIKey) Allows(required Role, scopes []APIScope) bool {
+	if required == RoleAttendee {
+		return false
+	}
+	if k.Scopes.Has(ScopeAdmin) {
+		return true
+	}
+	for _, scope := range scopes {
+		if k.Scopes.Has(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// CoversProject reports whether the key may be used for a project
+func (k *APIKey) CoversProject(projectID int) bool {
+	return k.ProjectID == nil || *k.ProjectID == projectID
+}
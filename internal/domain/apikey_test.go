@@ -0,0 +1,61 @@
+package domain
+
+import "testing"
+
+// Test which routes an API key's scopes reach
+func TestAPIKeyAllows(t *testing.T) {
+	results := &APIKey{Scopes: APIScopes{ScopeReadResults}}
+	features := &APIKey{Scopes: APIScopes{ScopeWriteFeatures}}
+	admin := &APIKey{Scopes: APIScopes{ScopeAdmin}}
+
+	tests := []struct {
+		name     string
+		key      *APIKey
+		required Role
+		scopes   []APIScope
+		expected bool
+	}{
+		{name: "Results key reads results", key: results, required: RoleObserver, scopes: []APIScope{ScopeReadResults}, expected: true},
+		{name: "Results key writes features", key: results, required: RoleFacilitator, scopes: []APIScope{ScopeWriteFeatures}, expected: false},
+		{name: "Features key writes features", key: features, required: RoleFacilitator, scopes: []APIScope{ScopeWriteFeatures}, expected: true},
+		{name: "Features key on an unscoped route", key: features, required: RoleObserver, expected: false},
+		{name: "Admin key controls sessions", key: admin, required: RoleFacilitator, expected: true},
+		{name: "Admin key votes", key: admin, required: RoleAttendee, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allowed := tt.key.Allows(tt.required, tt.scopes); allowed != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, allowed)
+			}
+		})
+	}
+
+	projectID := 3
+	scoped := &APIKey{ProjectID: &projectID}
+	if !scoped.CoversProject(3) || scoped.CoversProject(4) {
+		t.Error("Expected a project key to cover only its project")
+	}
+	if !admin.CoversProject(4) {
+		t.Error("Expected a global key to cover every project")
+	}
+}
+
+// Test that scopes round-trip through their column value
+func TestAPIScopesColumn(t *testing.T) {
+	value, err := APIScopes{ScopeReadResults, ScopeWriteFeatures}.Value()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value != "read:results write:features" {
+		t.Errorf("Unexpected column value %q", value)
+	}
+
+	var scopes APIScopes
+	if err := scopes.Scan([]byte("read:results  admin")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(scopes) != 2 || !scopes.Has(ScopeAdmin) || scopes.Has(ScopeWriteFeatures) {
+		t.Errorf("Unexpected scopes %v", scopes)
+	}
+}
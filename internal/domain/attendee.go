@@ -1,24 +1,124 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+// PIN and login lockout settings
+const (
+	MinPINLength       = 4
+	MaxPINLength       = 8
+	GeneratedPINLength = 6
+	MaxFailedLogins    = 5
+	LoginLockout       = 15 * time.Minute
+)
+
+// ErrInvalidPIN is returned for a PIN that is not 4 to 8 digits
+var ErrInvalidPIN = errors.New("invalid PIN")
+
 // Attendee represents a project attendee
 type Attendee struct {
-	ID            int       `json:"id" db:"id"`
-	ProjectID     int       `json:"project_id" db:"project_id"`
-	Name          string    `json:"name" db:"name" binding:"required,min=1,max=255"`
-	Role          string    `json:"role" db:"role"`
-	IsFacilitator bool      `json:"is_facilitator" db:"is_facilitator"`
-	Email         string    `json:"email,omitempty" db:"email"`    // Optional for now
-	PinHash       string    `json:"-" db:"pin_hash"`              // Hidden in JSON, stores hashed PIN
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	ID            int        `json:"id" db:"id"`
+	ProjectID     int        `json:"project_id" db:"project_id"`
+	Name          string     `json:"name" db:"name" binding:"required,min=1,max=255"`
+	Role          string     `json:"role" db:"role"`
+	IsFacilitator bool       `json:"is_facilitator" db:"is_facilitator"`
+	IsObserver    bool       `json:"is_observer" db:"is_observer"`
+	Email         string     `json:"email,omitempty" db:"email"` // Optional for now
+	PinHash       string     `json:"-" db:"pin_hash"`            // Hidden in JSON, stores the salted PIN hash
+	FailedLogins  int        `json:"-" db:"failed_logins"`       // Consecutive failed logins since the last success
+	LockedUntil   *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	TokenVersion  int        `json:"-" db:"token_version"`               // Bumped to revoke the attendee's session tokens
+	InviteID      *int       `json:"invite_id,omitempty" db:"invite_id"` // Invite the attendee joined with, if any
+	UserID        *int       `json:"user_id,omitempty" db:"user_id"`     // User account acting as this facilitator, if any
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 }
 
-// CreateAttendeeRequest represents the request payload for creating an attendee
+// CreateAttendeeRequest represents the request payload for creating an attendee.
+// An empty PIN generates one, which is returned once in the response.
 type CreateAttendeeRequest struct {
 	Name          string `json:"name" binding:"required,min=1,max=255"`
 	Role          string `json:"role"`
 	IsFacilitator bool   `json:"is_facilitator"`
+	IsObserver    bool   `json:"is_observer"`
+	PIN           string `json:"pin,omitempty" binding:"omitempty,numeric,min=4,max=8"`
+	InviteID      *int   `json:"-"` // Set when attendees join with an invite
+	UserID        *int   `json:"-"` // Set for the facilitator of a user's project
+}
+
+// SetPINRequest represents a facilitator setting an attendee's PIN
+type SetPINRequest struct {
+	PIN string `json:"pin" binding:"required,numeric,min=4,max=8"`
+}
+
+// AttendeeCredentials is an attendee together with a newly provisioned PIN. The PIN is only
+// returned when it is generated, since only its hash is stored.
+type AttendeeCredentials struct {
+	*Attendee
+	PIN string `json:"pin,omitempty"`
+}
+
+// ValidatePIN checks that a PIN is 4 to 8 digits
+func ValidatePIN(pin string) error {
+	if len(pin) < MinPINLength || len(pin) > MaxPINLength {
+		return ErrInvalidPIN
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return ErrInvalidPIN
+		}
+	}
+	return nil
+}
+
+// AccessRole returns the role an attendee's session tokens carry. Role itself is a free-text
+// title and does not grant permissions.
+func (a *Attendee) AccessRole() Role {
+	switch {
+	case a.IsFacilitator:
+		return RoleFacilitator
+	case a.IsObserver:
+		return RoleObserver
+	default:
+		return RoleAttendee
+	}
+}
+
+// Voters returns the attendees who vote and score, leaving out observers
+func Voters(attendees []Attendee) []Attendee {
+	voters := make([]Attendee, 0, len(attendees))
+	for _, attendee := range attendees {
+		if attendee.AccessRole().Allows(RoleAttendee) {
+			voters = append(voters, attendee)
+		}
+	}
+	return voters
+}
+
+// IsLocked reports whether logins are locked out at the given time
+func (a *Attendee) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// RegisterFailedLogin counts a failed login, locking the attendee out once
+// MaxFailedLogins consecutive failures are reached
+func (a *Attendee) RegisterFailedLogin(now time.Time) {
+	if a.LockedUntil != nil && !now.Before(*a.LockedUntil) {
+		// The previous lockout has expired, so counting starts over
+		a.FailedLogins = 0
+		a.LockedUntil = nil
+	}
+
+	a.FailedLogins++
+	if a.FailedLogins >= MaxFailedLogins {
+		lockedUntil := now.Add(LoginLockout)
+		a.LockedUntil = &lockedUntil
+	}
+}
+
+// RegisterSuccessfulLogin clears the failed login count and any lockout
+func (a *Attendee) RegisterSuccessfulLogin() {
+	a.FailedLogins = 0
+	a.LockedUntil = nil
 }
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// Test PIN format validation
+func TestValidatePIN(t *testing.T) {
+	tests := []struct {
+		pin   string
+		valid bool
+	}{
+		{pin: "1234", valid: true},
+		{pin: "12345678", valid: true},
+		{pin: "123", valid: false},
+		{pin: "123456789", valid: false},
+		{pin: "12a4", valid: false},
+		{pin: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidatePIN(tt.pin); (err == nil) != tt.valid {
+			t.Errorf("ValidatePIN(%q) = %v, expected valid=%v", tt.pin, err, tt.valid)
+		}
+	}
+}
+
+// Test that repeated failed logins lock an attendee out until the lockout expires
+func TestAttendeeLoginLockout(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	attendee := &Attendee{}
+
+	for i := 1; i < MaxFailedLogins; i++ {
+		attendee.RegisterFailedLogin(now)
+		if attendee.IsLocked(now) {
+			t.Fatalf("Should not be locked after %d failures", i)
+		}
+	}
+
+	attendee.RegisterFailedLogin(now)
+	if !attendee.IsLocked(now) {
+		t.Fatalf("Expected a lockout after %d failures", MaxFailedLogins)
+	}
+
+	later := now.Add(LoginLockout)
+	if attendee.IsLocked(later) {
+		t.Error("Expected the lockout to expire")
+	}
+
+	attendee.RegisterFailedLogin(later)
+	if attendee.FailedLogins != 1 || attendee.IsLocked(later) {
+		t.Errorf("Expected counting to start over after the lockout, got %d failures", attendee.FailedLogins)
+	}
+
+	attendee.RegisterSuccessfulLogin()
+	if attendee.FailedLogins != 0 || attendee.LockedUntil != nil {
+		t.Error("Expected a successful login to clear the failure count")
+	}
+}
+
+// Test that attendee flags map to roles and that roles include the roles below them
+func TestAttendeeAccessRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		attendee Attendee
+		role     Role
+	}{
+		{name: "Facilitator", attendee: Attendee{IsFacilitator: true}, role: RoleFacilitator},
+		{name: "Observer", attendee: Attendee{IsObserver: true}, role: RoleObserver},
+		{name: "Attendee with a title", attendee: Attendee{Role: "facilitator"}, role: RoleAttendee},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if role := tt.attendee.AccessRole(); role != tt.role {
+				t.Errorf("Expected role %s, got %s", tt.role, role)
+			}
+		})
+	}
+
+	if !RoleFacilitator.Allows(RoleAttendee) || !RoleAttendee.Allows(RoleObserver) {
+		t.Error("Expected higher roles to include lower ones")
+	}
+	if RoleObserver.Allows(RoleAttendee) || RoleAttendee.Allows(RoleFacilitator) {
+		t.Error("Expected lower roles not to include higher ones")
+	}
+	if Role("admin").Allows(RoleObserver) {
+		t.Error("Expected an unknown role to grant nothing")
+	}
+
+	voters := Voters([]Attendee{{ID: 1, IsFacilitator: true}, {ID: 2}, {ID: 3, IsObserver: true}})
+	if len(voters) != 2 || voters[0].ID != 1 || voters[1].ID != 2 {
+		t.Errorf("Expected observers to be left out of voters, got %+v", voters)
+	}
+}
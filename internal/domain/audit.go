@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// ActorType says who performed an audited action
+type ActorType string
+
+const (
+	// ActorAttendee is an attendee or facilitator with a session token
+	ActorAttendee ActorType = "attendee"
+	// ActorUser is a facilitator signed in with OpenID Connect, outside any project
+	ActorUser ActorType = "user"
+	// ActorAPIKey is an integration using an API key
+	ActorAPIKey ActorType = "api_key"
+	// ActorAnonymous is a caller without credentials, such as someone creating a project or joining one
+	ActorAnonymous ActorType = "anonymous"
+)
+
+// Audit actions
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionImport    = "import"
+	AuditActionStart     = "start"
+	AuditActionComplete  = "complete"
+	AuditActionVote      = "vote"
+	AuditActionResolve   = "resolve"
+	AuditActionCalculate = "calculate"
+	AuditActionAdvance   = "advance"
+	AuditActionSetPIN    = "set_pin"
+	AuditActionRevoke    = "revoke"
+	AuditActionDrive     = "drive"
+)
+
+// Audited entity types
+const (
+	AuditEntityProject          = "project"
+	AuditEntityCriterion        = "criterion"
+	AuditEntityAttendee         = "attendee"
+	AuditEntityFeature          = "feature"
+	AuditEntityPairwiseSession  = "pairwise_session"
+	AuditEntityComparison       = "comparison"
+	AuditEntityVote             = "vote"
+	AuditEntityFibonacciSession = "fibonacci_session"
+	AuditEntityFibonacciScore   = "fibonacci_score"
+	AuditEntityConsensusScore   = "consensus_score"
+	AuditEntityResults          = "results"
+	AuditEntityPhase            = "phase"
+	AuditEntityInvite           = "invite"
+	AuditEntityAPIKey           = "api_key"
+	AuditEntityUser             = "user"
+)
+
+// DefaultAuditPageSize and MaxAuditPageSize bound pages of audit events
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 200
+)
+
+// Actor is who performed an action
+type Actor struct {
+	Type ActorType
+	ID   *int
+}
+
+// AuditEvent is an immutable record of a mutating action. Before and After hold the JSON
+// state of the entity around the action; creations have no Before and deletions no After.
+type AuditEvent struct {
+	ID         int             `json:"id" db:"id"`
+	ProjectID  *int            `json:"project_id,omitempty" db:"project_id" gorm:"index"`
+	ActorType  ActorType       `json:"actor_type" db:"actor_type"`
+	ActorID    *int            `json:"actor_id,omitempty" db:"actor_id"`
+	Action     string          `json:"action" db:"action"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   *int            `json:"entity_id,omitempty" db:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before_state" gorm:"column:before_state;type:text"`
+	After      json.RawMessage `json:"after,omitempty" db:"after_state" gorm:"column:after_state;type:text"`
+	RequestID  string          `json:"request_id,omitempty" db:"request_id"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for GORM
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditFilter selects a page of a project's audit events, newest first
+type AuditFilter struct {
+	ProjectID  int
+	Action     string
+	EntityType string
+	EntityID   *int
+	ActorType  ActorType
+	ActorID    *int
+	Since      *time.Time
+	Until      *time.Time
+	Page       int
+	PageSize   int
+}
+
+// AuditPage is one page of audit events
+type AuditPage struct {
+	Events   []AuditEvent `json:"events"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// auditContextKey keys the audit values carried by a request context
+type auditContextKey int
+
+const (
+	actorContextKey auditContextKey = iota
+	requestIDContextKey
+)
+
+// WithActor returns a context carrying the actor of the request
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey, actor)
+}
+
+// ActorFromContext returns the actor carried by a context, or an anonymous actor
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorContextKey).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: ActorAnonymous}
+}
+
+// MaxRequestIDLength bounds request IDs supplied by clients, which end up in logs and the
+// audit log
+const MaxRequestIDLength = 128
+
+// RequestIDFrom returns the request ID a client supplied, or a new one when the client did
+// not supply a usable one
+func RequestIDFrom(clientID string) string {
+	if clientID == "" || len(clientID) > MaxRequestIDLength {
+		return NewRequestID()
+	}
+	return clientID
+}
+
+// NewRequestID creates a unique request identifier
+func NewRequestID() string {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		log.Printf("Failed to generate request ID: %v", err)
+	}
+	return "req_" + hex.EncodeToString(id)
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by a context, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultTokenTTL is how long an attendee session token stays valid when no lifetime is configured
+const DefaultTokenTTL = 12 * time.Hour
+
+// Role is the part a token holder plays in a project
+type Role string
+
+const (
+	// RoleFacilitator can also change the project's structure and control its sessions and workflow
+	RoleFacilitator Role = "facilitator"
+	// RoleAttendee can vote and score in the project the token is scoped to
+	RoleAttendee Role = "attendee"
+	// RoleObserver can only read the project
+	RoleObserver Role = "observer"
+)
+
+// roleRank orders roles so that each role includes the permissions of the roles below it
+var roleRank = map[Role]int{
+	RoleObserver:    1,
+	RoleAttendee:    2,
+	RoleFacilitator: 3,
+}
+
+// IsValidRole checks if a role is known
+func IsValidRole(role Role) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// Allows reports whether the role grants the permissions of the required role
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRank[r]
+	return ok && rank >= roleRank[required]
+}
+
+// Token errors
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// TokenClaims are the claims carried by a signed session token. The role is the attendee's
+// role when the token was issued; requests are authorized with the attendee's current role.
+// A token whose version is behind the attendee's token version has been revoked.
+type TokenClaims struct {
+	ProjectID  int   `json:"pid"`
+	AttendeeID int   `json:"aid"`
+	Role       Role  `json:"role"`
+	Version    int   `json:"ver,omitempty"`
+	IssuedAt   int64 `json:"iat"`
+	ExpiresAt  int64 `json:"exp"`
+}
+
+// Expired reports whether the claims have expired at the given time
+func (c TokenClaims) Expired(now time.Time) bool {
+	return now.Unix() >= c.ExpiresAt
+}
+
+// ExpiresAtTime returns the expiry as a time
+func (c TokenClaims) ExpiresAtTime() time.Time {
+	return time.Unix(c.ExpiresAt, 0).UTC()
+}
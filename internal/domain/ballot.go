@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrBallotNotFound means an attendee who voted has no ballot under their ballot key, which
+// happens when the secret the keys are derived with changes
+var ErrBallotNotFound = errors.New("ballot not found")
+
+// VoteParticipation records that an attendee voted on a comparison in an anonymous session,
+// without what they voted for
+type VoteParticipation struct {
+	ComparisonID int       `json:"comparison_id" db:"comparison_id" gorm:"primaryKey;autoIncrement:false"`
+	AttendeeID   int       `json:"attendee_id" db:"attendee_id" gorm:"primaryKey;autoIncrement:false"`
+	VotedAt      time.Time `json:"voted_at" db:"voted_at"`
+}
+
+// TableName returns the table name for GORM
+func (VoteParticipation) TableName() string {
+	return "vote_participations"
+}
+
+// AnonymousBallot is the content of a vote in an anonymous session. It is stored under a key
+// derived from the comparison and attendee with a server secret, so that the attendee can
+// change it while the ballot itself names nobody. Ballots carry no timestamp, and their
+// table has no rowid, so rows are stored in key order rather than the order they were cast
+// in and cannot be matched against participation.
+type AnonymousBallot struct {
+	BallotKey          string `json:"-" db:"ballot_key" gorm:"primaryKey"`
+	ComparisonID       int    `json:"comparison_id" db:"comparison_id" gorm:"index"`
+	PreferredFeatureID *int   `json:"preferred_feature_id,omitempty" db:"preferred_feature_id"`
+	IsTieVote          bool   `json:"is_tie_vote" db:"is_tie_vote"`
+	Intensity          int    `json:"intensity,omitempty" db:"intensity"`
+}
+
+// TableName returns the table name for GORM
+func (AnonymousBallot) TableName() string {
+	return "anonymous_ballots"
+}
+
+// ToVote converts a ballot into a vote that names no attendee, for tallies and consensus
+func (b AnonymousBallot) ToVote() AttendeeVote {
+	return AttendeeVote{
+		ComparisonID:       b.ComparisonID,
+		PreferredFeatureID: b.PreferredFeatureID,
+		IsTieVote:          b.IsTieVote,
+		Intensity:          b.Intensity,
+	}
+}
+
+// VoteTally is the aggregated count of the votes on a comparison
+type VoteTally struct {
+	VotesReceived int `json:"votes_received"`
+	TotalVoters   int `json:"total_voters"`
+	// TieVotes and FeatureVotes break the votes down by choice. In anonymous sessions they
+	// are withheld until every voter has voted or the comparison has a result, since a
+	// breakdown that changes with each vote would reveal how the latest voter voted.
+	TieVotes     *int        `json:"tie_votes,omitempty"`
+	FeatureVotes map[int]int `json:"feature_votes,omitempty"`
+}
+
+// TallyVotes counts the votes on a comparison, leaving out the breakdown by choice unless
+// withBreakdown is set
+func TallyVotes(votes []AttendeeVote, totalVoters int, withBreakdown bool) VoteTally {
+	tally := VoteTally{
+		VotesReceived: len(votes),
+		TotalVoters:   totalVoters,
+	}
+	if !withBreakdown {
+		return tally
+	}
+
+	ties := 0
+	tally.FeatureVotes = make(map[int]int)
+	for _, vote := range votes {
+		if vote.IsTieVote {
+			ties++
+		} else if vote.PreferredFeatureID != nil {
+			tally.FeatureVotes[*vote.PreferredFeatureID]++
+		}
+	}
+	tally.TieVotes = &ties
+
+	return tally
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+// TestTallyVotes tests vote counts with and without the breakdown by choice
+func TestTallyVotes(t *testing.T) {
+	featureA := 1
+	featureB := 2
+	votes := []AttendeeVote{
+		{PreferredFeatureID: &featureA},
+		{PreferredFeatureID: &featureA},
+		{PreferredFeatureID: &featureB},
+		{IsTieVote: true},
+	}
+
+	tally := TallyVotes(votes, 5, true)
+	if tally.VotesReceived != 4 || tally.TotalVoters != 5 {
+		t.Errorf("Unexpected counts %+v", tally)
+	}
+	if tally.TieVotes == nil || *tally.TieVotes != 1 {
+		t.Errorf("Expected 1 tie vote, got %v", tally.TieVotes)
+	}
+	if tally.FeatureVotes[featureA] != 2 || tally.FeatureVotes[featureB] != 1 {
+		t.Errorf("Unexpected feature votes %v", tally.FeatureVotes)
+	}
+
+	hidden := TallyVotes(votes, 5, false)
+	if hidden.VotesReceived != 4 || hidden.TieVotes != nil || hidden.FeatureVotes != nil {
+		t.Errorf("Expected only the vote count, got %+v", hidden)
+	}
+}
+
+// TestAnonymousBallotToVote tests that ballots become votes naming no attendee
+func TestAnonymousBallotToVote(t *testing.T) {
+	feature := 3
+	vote := AnonymousBallot{BallotKey: "key", ComparisonID: 7, PreferredFeatureID: &feature, Intensity: 5}.ToVote()
+
+	if vote.ID != 0 || vote.AttendeeID != 0 || vote.Attendee != nil {
+		t.Errorf("Expected a vote without identity, got %+v", vote)
+	}
+	if vote.ComparisonID != 7 || vote.PreferredFeatureID == nil || *vote.PreferredFeatureID != feature || vote.Intensity != 5 {
+		t.Errorf("Expected the ballot's choice, got %+v", vote)
+	}
+}
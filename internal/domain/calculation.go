@@ -14,6 +14,7 @@ type PairwiseComparison struct {
 	FeatureBID int                 `json:"feature_b_id"`
 	Criterion  ComparisonCriterion `json:"criterion"`
 	Result     ComparisonResult    `json:"result"`
+	Intensity  int                 `json:"intensity,omitempty"` // Saaty 1-9 strength of preference, 0 if not given
 	UserID     int                 `json:"user_id,omitempty"`
 }
 
@@ -34,6 +35,16 @@ const (
 	ResultTie   ComparisonResult = "tie"
 )
 
+// WinCountMode selects how win-count weights are derived from pairwise data
+type WinCountMode string
+
+const (
+	// WinCountModeConsensus counts one agreed result per comparison
+	WinCountModeConsensus WinCountMode = "consensus"
+	// WinCountModeVote counts individual attendee ballots, so a 4-1 split is a 0.8 win
+	WinCountModeVote WinCountMode = "vote"
+)
+
 // FeatureScore represents the scores assigned to a feature
 type FeatureScore struct {
 	FeatureID          int     `json:"feature_id"`
@@ -59,6 +70,8 @@ type WinCountResult struct {
 // PWVCCalculationResult represents the complete P-WVC calculation result
 type PWVCCalculationResult struct {
 	ProjectID           int              `json:"project_id"`
+	WinCountMode        WinCountMode     `json:"win_count_mode,omitempty"`
+	RankingModel        RankingModelType `json:"ranking_model"`
 	FeatureScores       []FeatureScore   `json:"feature_scores"`
 	ValueWinCounts      []WinCountResult `json:"value_win_counts"`
 	ComplexityWinCounts []WinCountResult `json:"complexity_win_counts"`
@@ -221,6 +234,112 @@ func IsValidFibonacciScore(score int) bool {
 
 // CalculateWinCountsForAllFeatures calculates win-counts for all features in a project
 func CalculateWinCountsForAllFeatures(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error) {
+	return calculateWinCountsWith(featureIDs, comparisons, criterion, CalculateWinCount)
+}
+
+// CalculateVoteWinCount calculates a fractional win-count weight from individual ballots.
+// Each feature pair contributes the feature's share of the ballots cast on it, with tie
+// ballots counted as half a win:
+// WFeature = Σ ((Ballot Wins + 0.5 × Ballot Ties) / Ballots) / (Total Comparisons)
+// Wins, Losses and Ties report ballot counts; TotalComparisons reports feature pairs.
+func CalculateVoteWinCount(featureID int, ballots []PairwiseComparison, criterion ComparisonCriterion) (*WinCountResult, error) {
+	if len(ballots) == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	type pairTally struct {
+		wins, losses, ties int
+	}
+
+	result := &WinCountResult{FeatureID: featureID}
+	tallies := make(map[int]*pairTally)
+
+	for _, ballot := range ballots {
+		if ballot.Criterion != criterion {
+			continue
+		}
+
+		var opponentID int
+		var won, lost bool
+		switch featureID {
+		case ballot.FeatureAID:
+			opponentID = ballot.FeatureBID
+			won = ballot.Result == ResultAWins
+			lost = ballot.Result == ResultBWins
+		case ballot.FeatureBID:
+			opponentID = ballot.FeatureAID
+			won = ballot.Result == ResultBWins
+			lost = ballot.Result == ResultAWins
+		default:
+			continue
+		}
+
+		tally, ok := tallies[opponentID]
+		if !ok {
+			tally = &pairTally{}
+			tallies[opponentID] = tally
+		}
+
+		switch {
+		case won:
+			tally.wins++
+			result.Wins++
+		case lost:
+			tally.losses++
+			result.Losses++
+		case ballot.Result == ResultTie:
+			tally.ties++
+			result.Ties++
+		}
+	}
+
+	share := 0.0
+	for _, tally := range tallies {
+		total := tally.wins + tally.losses + tally.ties
+		if total == 0 {
+			continue
+		}
+		share += (float64(tally.wins) + 0.5*float64(tally.ties)) / float64(total)
+		result.TotalComparisons++
+	}
+
+	if result.TotalComparisons == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	result.WinCount = share / float64(result.TotalComparisons)
+
+	return result, nil
+}
+
+// CalculateVoteWinCountsForAllFeatures calculates fractional win-counts from ballots for all features
+func CalculateVoteWinCountsForAllFeatures(featureIDs []int, ballots []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error) {
+	return calculateWinCountsWith(featureIDs, ballots, criterion, CalculateVoteWinCount)
+}
+
+// CalculateWinCountsForMode calculates win-counts for all features using the selected mode.
+// In vote mode the comparisons are expected to be individual ballots.
+func CalculateWinCountsForMode(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion, mode WinCountMode) ([]WinCountResult, error) {
+	model, err := RankingModelForMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.Estimate(featureIDs, comparisons, criterion)
+}
+
+// IsValidWinCountMode checks if a win-count mode is supported
+func IsValidWinCountMode(mode WinCountMode) bool {
+	return mode == WinCountModeConsensus || mode == WinCountModeVote
+}
+
+// calculateWinCountsWith applies a per-feature win-count calculation to every feature
+func calculateWinCountsWith(
+	featureIDs []int,
+	comparisons []PairwiseComparison,
+	criterion ComparisonCriterion,
+	calculate func(int, []PairwiseComparison, ComparisonCriterion) (*WinCountResult, error),
+) ([]WinCountResult, error) {
 	if len(featureIDs) == 0 {
 		return nil, ErrInsufficientData
 	}
@@ -228,7 +347,7 @@ func CalculateWinCountsForAllFeatures(featureIDs []int, comparisons []PairwiseCo
 	var results []WinCountResult
 
 	for _, featureID := range featureIDs {
-		winCount, err := CalculateWinCount(featureID, comparisons, criterion)
+		winCount, err := calculate(featureID, comparisons, criterion)
 		if err != nil {
 			// If a specific feature has no comparisons, set win count to 0
 			if err == ErrInsufficientData {
@@ -166,6 +166,82 @@ func TestCalculateWinCount(t *testing.T) {
 	}
 }
 
+// Test fractional win-count calculation from individual ballots
+func TestCalculateVoteWinCount(t *testing.T) {
+	split := func(aVotes, bVotes, tieVotes int) []PairwiseComparison {
+		var ballots []PairwiseComparison
+		for i := 0; i < aVotes; i++ {
+			ballots = append(ballots, PairwiseComparison{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins})
+		}
+		for i := 0; i < bVotes; i++ {
+			ballots = append(ballots, PairwiseComparison{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultBWins})
+		}
+		for i := 0; i < tieVotes; i++ {
+			ballots = append(ballots, PairwiseComparison{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultTie})
+		}
+		return ballots
+	}
+
+	tests := []struct {
+		name             string
+		featureID        int
+		ballots          []PairwiseComparison
+		expectedWinCount float64
+		expectError      bool
+	}{
+		{
+			name:             "Four to one split",
+			featureID:        1,
+			ballots:          split(4, 1, 0),
+			expectedWinCount: 0.8,
+		},
+		{
+			name:             "Losing side of a split",
+			featureID:        2,
+			ballots:          split(4, 1, 0),
+			expectedWinCount: 0.2,
+		},
+		{
+			name:             "Tie ballots count as half",
+			featureID:        1,
+			ballots:          split(1, 1, 2),
+			expectedWinCount: 0.5,
+		},
+		{
+			name:      "Each pair weighted equally",
+			featureID: 1,
+			ballots: append(split(3, 0, 0),
+				PairwiseComparison{FeatureAID: 3, FeatureBID: 1, Criterion: CriterionValue, Result: ResultAWins},
+			),
+			expectedWinCount: 0.5,
+		},
+		{
+			name:        "No ballots",
+			featureID:   1,
+			ballots:     []PairwiseComparison{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CalculateVoteWinCount(tt.featureID, tt.ballots, CriterionValue)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(result.WinCount-tt.expectedWinCount) > 0.0001 {
+				t.Errorf("Expected win count %.4f, got %.4f", tt.expectedWinCount, result.WinCount)
+			}
+		})
+	}
+}
+
 // Test Final Priority Score calculation
 func TestCalculateFinalPriorityScore(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,107 @@
+package domain
+
+import "sort"
+
+// ComparisonSeed derives the seed that fixes an attendee's comparison order in a session,
+// so the same session always presents comparisons to the same attendee in the same way
+func ComparisonSeed(sessionID, attendeeID int) uint64 {
+	return mixSeed(mixSeed(0, uint64(sessionID)), uint64(attendeeID))
+}
+
+// mixSeed combines a seed with a value using the SplitMix64 finalizer
+func mixSeed(seed, value uint64) uint64 {
+	z := seed + value*0x9e3779b97f4a7c15 + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// OrderComparisons orders an attendee's pending comparisons to limit position bias:
+//   - comparisons sharing a feature with the previous one are avoided where possible,
+//     so the same feature does not appear back to back
+//   - comparisons whose features the attendee has seen least come first, spreading exposure
+//   - remaining ties are broken by a seeded shuffle instead of comparison ID
+//   - each comparison's left/right placement is flipped by the seed
+//
+// voted lists the comparisons the attendee already voted on, oldest first. The order depends
+// only on its inputs, and a comparison's placement only on the seed and its ID, so it is
+// stable while the attendee works through the session.
+func OrderComparisons(pending, voted []SessionComparison, seed uint64) []SessionComparison {
+	exposure := make(map[int]int)
+	for _, comparison := range voted {
+		exposure[comparison.FeatureAID]++
+		exposure[comparison.FeatureBID]++
+	}
+
+	var previous *SessionComparison
+	if len(voted) > 0 {
+		previous = &voted[len(voted)-1]
+	}
+
+	remaining := append([]SessionComparison(nil), pending...)
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].ID < remaining[j].ID
+	})
+
+	ordered := make([]SessionComparison, 0, len(remaining))
+	for len(remaining) > 0 {
+		best := 0
+		for i := 1; i < len(remaining); i++ {
+			if comesBefore(remaining[i], remaining[best], previous, exposure, seed) {
+				best = i
+			}
+		}
+
+		next := remaining[best]
+		remaining = append(remaining[:best], remaining[best+1:]...)
+
+		exposure[next.FeatureAID]++
+		exposure[next.FeatureBID]++
+		previous = &next
+
+		if mixSeed(seed, uint64(next.ID))&1 == 1 {
+			next = next.Swapped()
+		}
+		ordered = append(ordered, next)
+	}
+
+	return ordered
+}
+
+// comesBefore reports whether comparison a should be presented before comparison b
+func comesBefore(a, b SessionComparison, previous *SessionComparison, exposure map[int]int, seed uint64) bool {
+	if repeatA, repeatB := sharesFeature(a, previous), sharesFeature(b, previous); repeatA != repeatB {
+		return !repeatA
+	}
+
+	maxA := max(exposure[a.FeatureAID], exposure[a.FeatureBID])
+	maxB := max(exposure[b.FeatureAID], exposure[b.FeatureBID])
+	if maxA != maxB {
+		return maxA < maxB
+	}
+
+	sumA := exposure[a.FeatureAID] + exposure[a.FeatureBID]
+	sumB := exposure[b.FeatureAID] + exposure[b.FeatureBID]
+	if sumA != sumB {
+		return sumA < sumB
+	}
+
+	return mixSeed(seed^0x5bd1e995, uint64(a.ID)) < mixSeed(seed^0x5bd1e995, uint64(b.ID))
+}
+
+// sharesFeature reports whether a comparison includes either feature of another comparison
+func sharesFeature(comparison SessionComparison, other *SessionComparison) bool {
+	if other == nil {
+		return false
+	}
+	return comparison.FeatureAID == other.FeatureAID || comparison.FeatureAID == other.FeatureBID ||
+		comparison.FeatureBID == other.FeatureAID || comparison.FeatureBID == other.FeatureBID
+}
+
+// Swapped returns the comparison with its two features placed the other way round.
+// Results are recorded by feature ID, so the swap only changes presentation.
+func (c SessionComparison) Swapped() SessionComparison {
+	c.FeatureAID, c.FeatureBID = c.FeatureBID, c.FeatureAID
+	c.FeatureA, c.FeatureB = c.FeatureB, c.FeatureA
+	return c
+}
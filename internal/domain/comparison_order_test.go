@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// allPairs builds the round-robin comparisons for n features
+func allPairs(n int) []SessionComparison {
+	var comparisons []SessionComparison
+	for a := 1; a <= n; a++ {
+		for b := a + 1; b <= n; b++ {
+			comparisons = append(comparisons, SessionComparison{ID: len(comparisons) + 1, FeatureAID: a, FeatureBID: b})
+		}
+	}
+	return comparisons
+}
+
+// Test that the order avoids repeats and spreads exposure across features
+func TestOrderComparisons(t *testing.T) {
+	const n = 6
+	pending := allPairs(n)
+	ordered := OrderComparisons(pending, nil, ComparisonSeed(1, 1))
+
+	if len(ordered) != len(pending) {
+		t.Fatalf("Expected %d comparisons, got %d", len(pending), len(ordered))
+	}
+
+	seen := make(map[int]bool)
+	for i, comparison := range ordered {
+		if seen[comparison.ID] {
+			t.Fatalf("Comparison %d appears twice", comparison.ID)
+		}
+		seen[comparison.ID] = true
+
+		// The very last comparison may have no choice but to repeat a feature
+		if i > 0 && i < len(ordered)-1 && sharesFeature(comparison, &ordered[i-1]) {
+			t.Errorf("Comparison %d repeats a feature of the previous comparison", comparison.ID)
+		}
+	}
+
+	// After the first n/2 comparisons every feature has been seen exactly once
+	exposure := make(map[int]int)
+	for _, comparison := range ordered[:n/2] {
+		exposure[comparison.FeatureAID]++
+		exposure[comparison.FeatureBID]++
+	}
+	for feature := 1; feature <= n; feature++ {
+		if exposure[feature] != 1 {
+			t.Errorf("Expected feature %d to be seen once in the first round, got %d", feature, exposure[feature])
+		}
+	}
+}
+
+// Test that orders are reproducible per attendee and differ between attendees
+func TestOrderComparisonsSeeded(t *testing.T) {
+	pending := allPairs(8)
+
+	ids := func(comparisons []SessionComparison) []int {
+		var result []int
+		for _, comparison := range comparisons {
+			result = append(result, comparison.ID)
+		}
+		return result
+	}
+
+	first := OrderComparisons(pending, nil, ComparisonSeed(3, 7))
+	again := OrderComparisons(pending, nil, ComparisonSeed(3, 7))
+	if !reflect.DeepEqual(first, again) {
+		t.Error("Expected the same seed to produce the same order")
+	}
+
+	other := OrderComparisons(pending, nil, ComparisonSeed(3, 8))
+	if reflect.DeepEqual(ids(first), ids(other)) {
+		t.Error("Expected different attendees to get different orders")
+	}
+
+	swapped := 0
+	for _, comparison := range first {
+		if comparison.FeatureAID > comparison.FeatureBID {
+			swapped++
+		}
+	}
+	if swapped == 0 || swapped == len(first) {
+		t.Errorf("Expected left/right placement to be mixed, %d of %d swapped", swapped, len(first))
+	}
+}
+
+// Test that voting history steers the next comparison away from the last one seen
+func TestOrderComparisonsUsesHistory(t *testing.T) {
+	voted := []SessionComparison{
+		{ID: 1, FeatureAID: 1, FeatureBID: 2},
+	}
+	pending := []SessionComparison{
+		{ID: 2, FeatureAID: 1, FeatureBID: 3},
+		{ID: 3, FeatureAID: 2, FeatureBID: 3},
+		{ID: 4, FeatureAID: 3, FeatureBID: 4},
+	}
+
+	for attendee := 1; attendee <= 10; attendee++ {
+		next := OrderComparisons(pending, voted, ComparisonSeed(1, attendee))[0]
+		if next.ID != 4 {
+			t.Errorf("Attendee %d: expected comparison 4 next, got %d", attendee, next.ID)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import "math"
+
+// ConsensusOutcome represents the result of applying a consensus policy to a comparison's votes
+type ConsensusOutcome struct {
+	Reached   bool
+	WinnerID  *int
+	IsTie     bool
+	Intensity int
+	Rule      ConsensusPolicy
+}
+
+// ResolveConsensus applies a project's consensus policy to the votes on a comparison.
+// A result is only produced once every attendee has voted. Identical votes always
+// resolve as unanimous; split votes resolve according to the policy, and stay open
+// under the unanimous and facilitator policies.
+func ResolveConsensus(votes []AttendeeVote, totalAttendees int, policy ConsensusPolicy, threshold float64) ConsensusOutcome {
+	if totalAttendees == 0 || len(votes) != totalAttendees {
+		return ConsensusOutcome{}
+	}
+
+	// Tally votes per option; ties are counted separately from feature preferences
+	tieVotes := 0
+	featureVotes := make(map[int]int)
+	for _, vote := range votes {
+		if vote.IsTieVote {
+			tieVotes++
+		} else if vote.PreferredFeatureID != nil {
+			featureVotes[*vote.PreferredFeatureID]++
+		}
+	}
+
+	// Find the option with the most votes. Equal leaders can never pass a
+	// majority or supermajority threshold, so the order of ties does not matter.
+	var winnerID *int
+	isTie := true
+	topVotes := tieVotes
+	for featureID, count := range featureVotes {
+		if count > topVotes {
+			id := featureID
+			winnerID = &id
+			isTie = false
+			topVotes = count
+		}
+	}
+
+	reached := func(rule ConsensusPolicy) ConsensusOutcome {
+		return ConsensusOutcome{
+			Reached:   true,
+			WinnerID:  winnerID,
+			IsTie:     isTie,
+			Intensity: groupIntensity(votes, winnerID, isTie),
+			Rule:      rule,
+		}
+	}
+
+	if topVotes == totalAttendees {
+		return reached(ConsensusPolicyUnanimous)
+	}
+
+	share := float64(topVotes) / float64(totalAttendees)
+
+	switch policy {
+	case ConsensusPolicyMajority:
+		if share > 0.5 {
+			return reached(ConsensusPolicyMajority)
+		}
+	case ConsensusPolicySupermajority:
+		if threshold <= 0.5 || threshold > 1 {
+			threshold = DefaultSupermajorityThreshold
+		}
+		if share >= threshold {
+			return reached(ConsensusPolicySupermajority)
+		}
+	}
+
+	return ConsensusOutcome{}
+}
+
+// groupIntensity combines the intensities of the votes for the winning option with the
+// geometric mean, rounded to the nearest point on the 1-9 scale. It returns 0 when no
+// winning vote gave an intensity, so results without intensities stay unchanged.
+func groupIntensity(votes []AttendeeVote, winnerID *int, isTie bool) int {
+	if isTie {
+		return 0
+	}
+
+	logSum := 0.0
+	count := 0
+	given := false
+	for _, vote := range votes {
+		if vote.IsTieVote || vote.PreferredFeatureID == nil || winnerID == nil || *vote.PreferredFeatureID != *winnerID {
+			continue
+		}
+		if vote.Intensity != 0 {
+			given = true
+		}
+		logSum += math.Log(float64(JudgmentIntensity(vote.Intensity, false)))
+		count++
+	}
+
+	if !given || count == 0 {
+		return 0
+	}
+
+	return int(math.Round(math.Exp(logSum / float64(count))))
+}
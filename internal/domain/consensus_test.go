@@ -0,0 +1,153 @@
+package domain
+
+import "testing"
+
+// Test consensus policies applied to comparison votes
+func TestResolveConsensus(t *testing.T) {
+	featureA := 1
+	featureB := 2
+
+	voteA := AttendeeVote{PreferredFeatureID: &featureA}
+	voteB := AttendeeVote{PreferredFeatureID: &featureB}
+	voteTie := AttendeeVote{IsTieVote: true}
+
+	tests := []struct {
+		name           string
+		votes          []AttendeeVote
+		totalAttendees int
+		policy         ConsensusPolicy
+		threshold      float64
+		expectedReach  bool
+		expectedWinner *int
+		expectedTie    bool
+		expectedRule   ConsensusPolicy
+	}{
+		{
+			name:           "Unanimous agreement under majority policy",
+			votes:          []AttendeeVote{voteA, voteA, voteA},
+			totalAttendees: 3,
+			policy:         ConsensusPolicyMajority,
+			expectedReach:  true,
+			expectedWinner: &featureA,
+			expectedRule:   ConsensusPolicyUnanimous,
+		},
+		{
+			name:           "Unanimous tie",
+			votes:          []AttendeeVote{voteTie, voteTie},
+			totalAttendees: 2,
+			policy:         ConsensusPolicyUnanimous,
+			expectedReach:  true,
+			expectedTie:    true,
+			expectedRule:   ConsensusPolicyUnanimous,
+		},
+		{
+			name:           "Split votes under unanimous policy",
+			votes:          []AttendeeVote{voteA, voteA, voteB},
+			totalAttendees: 3,
+			policy:         ConsensusPolicyUnanimous,
+			expectedReach:  false,
+		},
+		{
+			name:           "Simple majority",
+			votes:          []AttendeeVote{voteB, voteA, voteB},
+			totalAttendees: 3,
+			policy:         ConsensusPolicyMajority,
+			expectedReach:  true,
+			expectedWinner: &featureB,
+			expectedRule:   ConsensusPolicyMajority,
+		},
+		{
+			name:           "Even split has no majority",
+			votes:          []AttendeeVote{voteA, voteB, voteA, voteB},
+			totalAttendees: 4,
+			policy:         ConsensusPolicyMajority,
+			expectedReach:  false,
+		},
+		{
+			name:           "Majority waits for all attendees",
+			votes:          []AttendeeVote{voteA, voteA},
+			totalAttendees: 3,
+			policy:         ConsensusPolicyMajority,
+			expectedReach:  false,
+		},
+		{
+			name:           "Supermajority with default threshold",
+			votes:          []AttendeeVote{voteA, voteA, voteTie},
+			totalAttendees: 3,
+			policy:         ConsensusPolicySupermajority,
+			expectedReach:  true,
+			expectedWinner: &featureA,
+			expectedRule:   ConsensusPolicySupermajority,
+		},
+		{
+			name:           "Supermajority threshold not met",
+			votes:          []AttendeeVote{voteA, voteA, voteA, voteB, voteB},
+			totalAttendees: 5,
+			policy:         ConsensusPolicySupermajority,
+			threshold:      0.75,
+			expectedReach:  false,
+		},
+		{
+			name:           "Split votes wait for facilitator",
+			votes:          []AttendeeVote{voteA, voteA, voteB},
+			totalAttendees: 3,
+			policy:         ConsensusPolicyFacilitator,
+			expectedReach:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := ResolveConsensus(tt.votes, tt.totalAttendees, tt.policy, tt.threshold)
+			if outcome.Reached != tt.expectedReach {
+				t.Fatalf("Expected consensus %v, got %v", tt.expectedReach, outcome.Reached)
+			}
+			if !outcome.Reached {
+				return
+			}
+			if outcome.Rule != tt.expectedRule {
+				t.Errorf("Expected rule %s, got %s", tt.expectedRule, outcome.Rule)
+			}
+			if outcome.IsTie != tt.expectedTie {
+				t.Errorf("Expected tie %v, got %v", tt.expectedTie, outcome.IsTie)
+			}
+			if tt.expectedWinner == nil {
+				if outcome.WinnerID != nil {
+					t.Errorf("Expected no winner, got %d", *outcome.WinnerID)
+				}
+			} else if outcome.WinnerID == nil || *outcome.WinnerID != *tt.expectedWinner {
+				t.Errorf("Expected winner %d, got %v", *tt.expectedWinner, outcome.WinnerID)
+			}
+		})
+	}
+}
+
+// Test that the consensus intensity combines the winning votes
+func TestResolveConsensusIntensity(t *testing.T) {
+	featureA := 1
+	featureB := 2
+
+	votes := []AttendeeVote{
+		{PreferredFeatureID: &featureA, Intensity: 9},
+		{PreferredFeatureID: &featureA, Intensity: 1},
+		{PreferredFeatureID: &featureB, Intensity: 7},
+	}
+
+	outcome := ResolveConsensus(votes, 3, ConsensusPolicyMajority, 0)
+	if !outcome.Reached {
+		t.Fatal("Expected consensus to be reached")
+	}
+	if outcome.Intensity != 3 {
+		t.Errorf("Expected intensity 3, got %d", outcome.Intensity)
+	}
+
+	plain := []AttendeeVote{
+		{PreferredFeatureID: &featureA},
+		{PreferredFeatureID: &featureA},
+	}
+
+	outcome = ResolveConsensus(plain, 2, ConsensusPolicyUnanimous, 0)
+	if outcome.Intensity != 0 {
+		t.Errorf("Expected no intensity without intensity votes, got %d", outcome.Intensity)
+	}
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+// CriterionDirection says whether a higher score makes a feature more or less attractive
+type CriterionDirection string
+
+const (
+	// CriterionBenefit criteria raise priority, like value or strategic fit
+	CriterionBenefit CriterionDirection = "benefit"
+	// CriterionCost criteria lower priority, like complexity or risk
+	CriterionCost CriterionDirection = "cost"
+)
+
+// DefaultCriterionWeight is the weight of a criterion that does not set one
+const DefaultCriterionWeight = 1.0
+
+// MaxCriterionWeight is the largest weight a criterion can have
+const MaxCriterionWeight = 10.0
+
+// criterionKeyPattern limits keys to short slugs that fit the criterion_type columns
+var criterionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+// ErrNoCriteria is returned when a priority score is calculated without any criteria
+var ErrNoCriteria = errors.New("at least one criterion is required")
+
+// Criterion represents a project-defined prioritization criterion. Each criterion gets its
+// own pairwise and Fibonacci phases, and its weight is the exponent of its factor in the
+// Final Priority Score.
+type Criterion struct {
+	ID        int                `json:"id" db:"id"`
+	ProjectID int                `json:"project_id" db:"project_id"`
+	Key       CriterionType      `json:"key" db:"key"`
+	Name      string             `json:"name" db:"name"`
+	Direction CriterionDirection `json:"direction" db:"direction"`
+	Weight    float64            `json:"weight" db:"weight"`
+	Position  int                `json:"position" db:"position"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for GORM
+func (Criterion) TableName() string {
+	return "project_criteria"
+}
+
+// DefaultCriteria returns the classic P-WVC template: value as a benefit and complexity as a cost
+func DefaultCriteria() []Criterion {
+	return []Criterion{
+		{Key: CriterionTypeValue, Name: "Value", Direction: CriterionBenefit, Weight: DefaultCriterionWeight, Position: 0},
+		{Key: CriterionTypeComplexity, Name: "Complexity", Direction: CriterionCost, Weight: DefaultCriterionWeight, Position: 1},
+	}
+}
+
+// CreateCriterionRequest represents the request payload for adding a criterion to a project
+type CreateCriterionRequest struct {
+	Key       CriterionType      `json:"key" binding:"required"`
+	Name      string             `json:"name" binding:"required,min=1,max=100"`
+	Direction CriterionDirection `json:"direction" binding:"required,oneof=benefit cost"`
+	Weight    float64            `json:"weight" binding:"omitempty,gt=0,lte=10"`
+}
+
+// UpdateCriterionRequest represents the request payload for updating a criterion.
+// The key cannot change because sessions refer to it; a zero weight keeps the current weight.
+type UpdateCriterionRequest struct {
+	Name      string             `json:"name" binding:"required,min=1,max=100"`
+	Direction CriterionDirection `json:"direction" binding:"required,oneof=benefit cost"`
+	Weight    float64            `json:"weight" binding:"omitempty,gt=0,lte=10"`
+}
+
+// IsValidCriterionKey checks that a criterion key is a lowercase slug of at most 20 characters
+func IsValidCriterionKey(key CriterionType) bool {
+	return criterionKeyPattern.MatchString(string(key))
+}
+
+// IsValidCriterionDirection checks whether a criterion direction is supported
+func IsValidCriterionDirection(direction CriterionDirection) bool {
+	return direction == CriterionBenefit || direction == CriterionCost
+}
+
+// ValidateCriterion checks a criterion's key, direction and weight
+func ValidateCriterion(criterion Criterion) error {
+	if !IsValidCriterionKey(criterion.Key) {
+		return fmt.Errorf("criterion key must be a lowercase slug of at most 20 characters, got: %q", criterion.Key)
+	}
+	if !IsValidCriterionDirection(criterion.Direction) {
+		return fmt.Errorf("criterion direction must be 'benefit' or 'cost', got: %q", criterion.Direction)
+	}
+	if criterion.Weight <= 0 || criterion.Weight > MaxCriterionWeight {
+		return fmt.Errorf("criterion weight must be greater than 0 and at most %g, got: %g", MaxCriterionWeight, criterion.Weight)
+	}
+	return nil
+}
+
+// FindCriterion returns the criterion with the given key
+func FindCriterion(criteria []Criterion, key CriterionType) (Criterion, bool) {
+	for _, criterion := range criteria {
+		if criterion.Key == key {
+			return criterion, true
+		}
+	}
+	return Criterion{}, false
+}
+
+// CriterionKeys returns the keys of the criteria in order
+func CriterionKeys(criteria []Criterion) []CriterionType {
+	keys := make([]CriterionType, len(criteria))
+	for i, criterion := range criteria {
+		keys[i] = criterion.Key
+	}
+	return keys
+}
+
+// CriterionScore represents one criterion's contribution to a feature's Final Priority Score
+type CriterionScore struct {
+	ID            int                `json:"id" db:"id"`
+	CalculationID int                `json:"calculationId" db:"calculation_id"`
+	Criterion     CriterionType      `json:"criterion" db:"criterion"`
+	Direction     CriterionDirection `json:"direction" db:"direction"`
+	Weight        float64            `json:"weight" db:"weight"`      // Criterion weight (exponent in the FPS)
+	WinCount      float64            `json:"winCount" db:"win_count"` // W from pairwise comparisons
+	Score         int                `json:"score" db:"score"`        // S from Fibonacci scoring
+	Weighted      float64            `json:"weighted" db:"weighted"`  // S × W
+}
+
+// TableName returns the table name for GORM
+func (CriterionScore) TableName() string {
+	return "priority_criterion_scores"
+}
+
+// CriterionInput holds a feature's Fibonacci score and win-count for one criterion
+type CriterionInput struct {
+	Criterion Criterion
+	Score     int
+	WinCount  float64
+}
+
+// CalculateMultiCriteriaScore generalizes the Final Priority Score to any number of criteria
+// as a weighted product:
+//
+//	FPS = Π benefit (S × W)^weight ÷ Π cost (S × W)^weight
+//
+// With value and complexity at weight 1 this is exactly (SValue × WValue) ÷ (SComplexity × WComplexity).
+// As in the two-criterion formula, a cost criterion whose weighted score is 0 falls back to its
+// Fibonacci score so a feature that lost every comparison does not divide by zero.
+func CalculateMultiCriteriaScore(inputs []CriterionInput) (float64, []CriterionScore, error) {
+	if len(inputs) == 0 {
+		return 0, nil, ErrNoCriteria
+	}
+
+	fps := 1.0
+	scores := make([]CriterionScore, len(inputs))
+	for i, input := range inputs {
+		criterion := input.Criterion
+		if err := ValidateCriterion(criterion); err != nil {
+			return 0, nil, err
+		}
+		if err := ValidateFibonacciScore(input.Score); err != nil {
+			return 0, nil, fmt.Errorf("%s score validation failed: %w", criterion.Key, err)
+		}
+		if input.WinCount < 0 || input.WinCount > 1 {
+			return 0, nil, fmt.Errorf("%s weight must be between 0 and 1, got: %f", criterion.Key, input.WinCount)
+		}
+
+		weighted := float64(input.Score) * input.WinCount
+		scores[i] = CriterionScore{
+			Criterion: criterion.Key,
+			Direction: criterion.Direction,
+			Weight:    criterion.Weight,
+			WinCount:  input.WinCount,
+			Score:     input.Score,
+			Weighted:  weighted,
+		}
+
+		switch criterion.Direction {
+		case CriterionBenefit:
+			fps *= math.Pow(weighted, criterion.Weight)
+		case CriterionCost:
+			if weighted == 0 {
+				weighted = float64(input.Score)
+			}
+			fps /= math.Pow(weighted, criterion.Weight)
+		}
+	}
+
+	return fps, scores, nil
+}
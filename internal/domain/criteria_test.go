@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Test that the default template reproduces the two-criterion Final Priority Score
+func TestCalculateMultiCriteriaScoreDefaultTemplate(t *testing.T) {
+	criteria := DefaultCriteria()
+
+	tests := []struct {
+		name        string
+		sValue      int
+		wValue      float64
+		sComplexity int
+		wComplexity float64
+	}{
+		{name: "Typical feature", sValue: 13, wValue: 0.75, sComplexity: 5, wComplexity: 0.25},
+		{name: "Lost every complexity comparison", sValue: 8, wValue: 0.5, sComplexity: 3, wComplexity: 0},
+		{name: "Lost every value comparison", sValue: 21, wValue: 0, sComplexity: 8, wComplexity: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := CalculateFinalPriorityScore(tt.sValue, tt.wValue, tt.sComplexity, tt.wComplexity)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			fps, scores, err := CalculateMultiCriteriaScore([]CriterionInput{
+				{Criterion: criteria[0], Score: tt.sValue, WinCount: tt.wValue},
+				{Criterion: criteria[1], Score: tt.sComplexity, WinCount: tt.wComplexity},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if math.Abs(fps-expected.FinalPriorityScore) > 1e-9 {
+				t.Errorf("Expected FPS %f, got %f", expected.FinalPriorityScore, fps)
+			}
+			if scores[0].Weighted != expected.WeightedValue || scores[1].Weighted != expected.WeightedComplexity {
+				t.Errorf("Expected weighted scores %f and %f, got %f and %f",
+					expected.WeightedValue, expected.WeightedComplexity, scores[0].Weighted, scores[1].Weighted)
+			}
+		})
+	}
+}
+
+// Test weighted products across more than two criteria
+func TestCalculateMultiCriteriaScore(t *testing.T) {
+	value := Criterion{Key: "value", Direction: CriterionBenefit, Weight: 2}
+	fit := Criterion{Key: "strategic_fit", Direction: CriterionBenefit, Weight: 1}
+	risk := Criterion{Key: "risk", Direction: CriterionCost, Weight: 0.5}
+
+	fps, scores, err := CalculateMultiCriteriaScore([]CriterionInput{
+		{Criterion: value, Score: 8, WinCount: 0.5},
+		{Criterion: fit, Score: 3, WinCount: 1},
+		{Criterion: risk, Score: 5, WinCount: 0.8},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// (8 × 0.5)² × (3 × 1) ÷ (5 × 0.8)^0.5 = 16 × 3 ÷ 2
+	if math.Abs(fps-24) > 1e-9 {
+		t.Errorf("Expected FPS 24, got %f", fps)
+	}
+	if len(scores) != 3 || scores[2].Criterion != "risk" || scores[2].Direction != CriterionCost {
+		t.Errorf("Expected a score per criterion in input order, got %+v", scores)
+	}
+
+	invalid := []struct {
+		name   string
+		inputs []CriterionInput
+	}{
+		{name: "No criteria", inputs: nil},
+		{name: "Zero weight", inputs: []CriterionInput{{Criterion: Criterion{Key: "value", Direction: CriterionBenefit}, Score: 3, WinCount: 1}}},
+		{name: "Unknown direction", inputs: []CriterionInput{{Criterion: Criterion{Key: "value", Direction: "neutral", Weight: 1}, Score: 3, WinCount: 1}}},
+		{name: "Invalid Fibonacci score", inputs: []CriterionInput{{Criterion: value, Score: 4, WinCount: 1}}},
+		{name: "Win-count above 1", inputs: []CriterionInput{{Criterion: value, Score: 3, WinCount: 1.5}}},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := CalculateMultiCriteriaScore(tt.inputs); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+// Test that workflow phases follow the project's criteria
+func TestProjectProgressPhases(t *testing.T) {
+	progress := &ProjectProgress{
+		CurrentPhase: string(PhaseFeatures),
+		Criteria:     []CriterionType{"value", "risk", "complexity"},
+	}
+
+	expected := []WorkflowPhase{
+		PhaseSetup, PhaseAttendees, PhaseFeatures,
+		PhasePairwiseValue, "pairwise_risk", PhasePairwiseComplexity,
+		PhaseFibonacciValue, "fibonacci_risk", PhaseFibonacciComplexity,
+		PhaseResults,
+	}
+	if phases := progress.Phases(); !reflect.DeepEqual(phases, expected) {
+		t.Fatalf("Expected phases %v, got %v", expected, phases)
+	}
+
+	if next := progress.GetNextPhase(); next != PhasePairwiseValue {
+		t.Errorf("Expected next phase %s, got %s", PhasePairwiseValue, next)
+	}
+
+	progress.SetupCompleted, progress.AttendeesAdded, progress.FeaturesAdded = true, true, true
+	progress.MarkPhaseCompleted(PhasePairwiseValue)
+	if !progress.PairwiseValueCompleted {
+		t.Error("Expected the legacy value flag to follow the value phase")
+	}
+
+	if !progress.CanProgressTo("pairwise_risk") {
+		t.Error("Expected to progress to the risk phase after the value phase")
+	}
+	if progress.CanProgressTo(PhasePairwiseComplexity) {
+		t.Error("Should not progress to complexity before the risk phase is completed")
+	}
+
+	progress.MarkPhaseCompleted("pairwise_risk")
+	if !progress.CanProgressTo(PhasePairwiseComplexity) {
+		t.Error("Expected to progress to complexity after the risk phase")
+	}
+
+	if progress.CanProgressTo("pairwise_unknown") {
+		t.Error("Should not progress to a phase of another project's criterion")
+	}
+	if !IsValidWorkflowPhase("fibonacci_risk") || IsValidWorkflowPhase("fibonacci_Risk!") {
+		t.Error("Expected criterion phases to be validated by key")
+	}
+}
@@ -0,0 +1,278 @@
+package domain
+
+import "sort"
+
+// DefaultMaxPreferenceCycles caps how many cycles are enumerated, since a dense
+// set of contradictory results can contain exponentially many cycles
+const DefaultMaxPreferenceCycles = 100
+
+// PreferenceCycle represents an intransitive chain of consensus results where each
+// feature was preferred over the next and the last was preferred over the first
+type PreferenceCycle struct {
+	FeatureIDs  []int               `json:"feature_ids"`
+	Comparisons []SessionComparison `json:"comparisons"`
+}
+
+// CycleAnalysis represents the preference cycles found in a pairwise session
+type CycleAnalysis struct {
+	ProjectID             int               `json:"project_id"`
+	SessionID             int               `json:"session_id"`
+	Criterion             CriterionType     `json:"criterion"`
+	Cycles                []PreferenceCycle `json:"cycles"`
+	CycleCount            int               `json:"cycle_count"`
+	Truncated             bool              `json:"truncated"`
+	InvolvedComparisonIDs []int             `json:"involved_comparison_ids"`
+}
+
+// FindPreferenceCycles builds the preference graph from comparison winners (an edge
+// points from winner to loser; ties and open comparisons add no edge) and enumerates
+// its elementary cycles with Johnson's algorithm. At most limit cycles are returned;
+// truncated reports whether more exist.
+func FindPreferenceCycles(comparisons []SessionComparison, limit int) ([]PreferenceCycle, bool) {
+	if limit <= 0 {
+		limit = DefaultMaxPreferenceCycles
+	}
+
+	// Preference graph keyed by feature ID, remembering which comparison produced each edge
+	graph := make(map[int][]int)
+	edges := make(map[[2]int]SessionComparison)
+	for _, comparison := range comparisons {
+		if !comparison.ConsensusReached || comparison.IsTie || comparison.WinnerID == nil {
+			continue
+		}
+
+		winner := *comparison.WinnerID
+		loser := comparison.FeatureBID
+		if winner == comparison.FeatureBID {
+			loser = comparison.FeatureAID
+		} else if winner != comparison.FeatureAID {
+			continue
+		}
+
+		if _, exists := edges[[2]int{winner, loser}]; !exists {
+			graph[winner] = append(graph[winner], loser)
+		}
+		edges[[2]int{winner, loser}] = comparison
+		if _, ok := graph[loser]; !ok {
+			graph[loser] = nil
+		}
+	}
+
+	for node := range graph {
+		sort.Ints(graph[node])
+	}
+
+	var cycles []PreferenceCycle
+	truncated := false
+	emit := func(path []int) bool {
+		if len(cycles) == limit {
+			truncated = true
+			return false
+		}
+
+		cycle := PreferenceCycle{FeatureIDs: append([]int(nil), path...)}
+		for i, from := range path {
+			to := path[(i+1)%len(path)]
+			cycle.Comparisons = append(cycle.Comparisons, edges[[2]int{from, to}])
+		}
+		cycles = append(cycles, cycle)
+		return true
+	}
+
+	nodes := make(map[int]bool, len(graph))
+	for node := range graph {
+		nodes[node] = true
+	}
+
+	// Johnson's algorithm: repeatedly take the strongly connected component holding the
+	// smallest remaining node, report every cycle through that node, then remove it
+	for {
+		component, start := smallestCyclicComponent(graph, nodes)
+		if component == nil {
+			break
+		}
+
+		if !circuitsFrom(start, graph, component, emit) {
+			break
+		}
+
+		delete(nodes, start)
+	}
+
+	return cycles, truncated
+}
+
+// smallestCyclicComponent finds the strongly connected component (restricted to nodes)
+// that contains the smallest node lying on any cycle
+func smallestCyclicComponent(graph map[int][]int, nodes map[int]bool) (map[int]bool, int) {
+	var best map[int]bool
+	bestStart := 0
+
+	for _, component := range stronglyConnectedComponents(graph, nodes) {
+		if len(component) < 2 {
+			continue
+		}
+
+		start := component[0]
+		for _, node := range component[1:] {
+			if node < start {
+				start = node
+			}
+		}
+
+		if best == nil || start < bestStart {
+			best = make(map[int]bool, len(component))
+			for _, node := range component {
+				best[node] = true
+			}
+			bestStart = start
+		}
+	}
+
+	return best, bestStart
+}
+
+// circuitsFrom reports every elementary cycle through start within the component.
+// It returns false once emit asks to stop.
+func circuitsFrom(start int, graph map[int][]int, component map[int]bool, emit func([]int) bool) bool {
+	blocked := map[int]bool{start: true}
+	blockedBy := make(map[int]map[int]bool)
+	closed := make(map[int]bool)
+
+	var unblock func(node int)
+	unblock = func(node int) {
+		blocked[node] = false
+		for waiting := range blockedBy[node] {
+			delete(blockedBy[node], waiting)
+			if blocked[waiting] {
+				unblock(waiting)
+			}
+		}
+	}
+
+	type frame struct {
+		node int
+		next int
+	}
+
+	path := []int{start}
+	stack := []frame{{node: start}}
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		neighbors := graph[top.node]
+
+		advanced := false
+		for top.next < len(neighbors) {
+			neighbor := neighbors[top.next]
+			top.next++
+
+			if !component[neighbor] {
+				continue
+			}
+
+			if neighbor == start {
+				if !emit(path) {
+					return false
+				}
+				for _, node := range path {
+					closed[node] = true
+				}
+			} else if !blocked[neighbor] {
+				path = append(path, neighbor)
+				stack = append(stack, frame{node: neighbor})
+				blocked[neighbor] = true
+				closed[neighbor] = false
+				advanced = true
+				break
+			}
+		}
+
+		if advanced {
+			continue
+		}
+
+		node := top.node
+		if closed[node] {
+			unblock(node)
+		} else {
+			for _, neighbor := range neighbors {
+				if !component[neighbor] {
+					continue
+				}
+				if blockedBy[neighbor] == nil {
+					blockedBy[neighbor] = make(map[int]bool)
+				}
+				blockedBy[neighbor][node] = true
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		path = path[:len(path)-1]
+		if len(stack) > 0 && closed[node] {
+			closed[stack[len(stack)-1].node] = true
+		}
+	}
+
+	return true
+}
+
+// stronglyConnectedComponents finds the strongly connected components of the graph
+// restricted to nodes using Tarjan's algorithm
+func stronglyConnectedComponents(graph map[int][]int, nodes map[int]bool) [][]int {
+	ordered := make([]int, 0, len(nodes))
+	for node := range nodes {
+		ordered = append(ordered, node)
+	}
+	sort.Ints(ordered)
+
+	index := 0
+	indices := make(map[int]int)
+	lowLinks := make(map[int]int)
+	onStack := make(map[int]bool)
+	var stack []int
+	var components [][]int
+
+	var visit func(node int)
+	visit = func(node int) {
+		indices[node] = index
+		lowLinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, neighbor := range graph[node] {
+			if !nodes[neighbor] {
+				continue
+			}
+			if _, seen := indices[neighbor]; !seen {
+				visit(neighbor)
+				lowLinks[node] = min(lowLinks[node], lowLinks[neighbor])
+			} else if onStack[neighbor] {
+				lowLinks[node] = min(lowLinks[node], indices[neighbor])
+			}
+		}
+
+		if lowLinks[node] == indices[node] {
+			var component []int
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, node := range ordered {
+		if _, seen := indices[node]; !seen {
+			visit(node)
+		}
+	}
+
+	return components
+}
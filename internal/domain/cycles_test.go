@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// decided builds a consensus comparison where winner beat loser
+func decided(id, winner, loser int) SessionComparison {
+	return SessionComparison{
+		ID:               id,
+		FeatureAID:       min(winner, loser),
+		FeatureBID:       max(winner, loser),
+		WinnerID:         &winner,
+		ConsensusReached: true,
+	}
+}
+
+// Test preference cycle detection across consensus results
+func TestFindPreferenceCycles(t *testing.T) {
+	tests := []struct {
+		name        string
+		comparisons []SessionComparison
+		limit       int
+		expected    [][]int
+		truncated   bool
+	}{
+		{
+			name: "Transitive results have no cycles",
+			comparisons: []SessionComparison{
+				decided(1, 1, 2),
+				decided(2, 2, 3),
+				decided(3, 1, 3),
+			},
+			expected: nil,
+		},
+		{
+			name: "Three-way cycle",
+			comparisons: []SessionComparison{
+				decided(1, 1, 2),
+				decided(2, 2, 3),
+				decided(3, 3, 1),
+			},
+			expected: [][]int{{1, 2, 3}},
+		},
+		{
+			name: "Ties and open comparisons break cycles",
+			comparisons: []SessionComparison{
+				decided(1, 1, 2),
+				decided(2, 2, 3),
+				{ID: 3, FeatureAID: 1, FeatureBID: 3, IsTie: true, ConsensusReached: true},
+				{ID: 4, FeatureAID: 3, FeatureBID: 4},
+			},
+			expected: nil,
+		},
+		{
+			name: "Overlapping cycles are all reported",
+			comparisons: []SessionComparison{
+				decided(1, 1, 2),
+				decided(2, 2, 3),
+				decided(3, 3, 1),
+				decided(4, 3, 4),
+				decided(5, 4, 1),
+				decided(6, 2, 4),
+			},
+			expected: [][]int{{1, 2, 3}, {1, 2, 3, 4}, {1, 2, 4}},
+		},
+		{
+			name: "Limit truncates enumeration",
+			comparisons: []SessionComparison{
+				decided(1, 1, 2),
+				decided(2, 2, 3),
+				decided(3, 3, 1),
+				decided(4, 3, 4),
+				decided(5, 4, 1),
+				decided(6, 2, 4),
+			},
+			limit:     2,
+			expected:  [][]int{{1, 2, 3}, {1, 2, 3, 4}},
+			truncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycles, truncated := FindPreferenceCycles(tt.comparisons, tt.limit)
+			if truncated != tt.truncated {
+				t.Errorf("Expected truncated %v, got %v", tt.truncated, truncated)
+			}
+
+			var got [][]int
+			for _, cycle := range cycles {
+				got = append(got, cycle.FeatureIDs)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("Expected cycles %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Test that each cycle carries the comparisons that produced its edges
+func TestFindPreferenceCyclesComparisons(t *testing.T) {
+	comparisons := []SessionComparison{
+		decided(10, 1, 2),
+		decided(11, 2, 3),
+		decided(12, 3, 1),
+	}
+
+	cycles, _ := FindPreferenceCycles(comparisons, 0)
+	if len(cycles) != 1 {
+		t.Fatalf("Expected 1 cycle, got %d", len(cycles))
+	}
+
+	var ids []int
+	for _, comparison := range cycles[0].Comparisons {
+		ids = append(ids, comparison.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{10, 11, 12}) {
+		t.Errorf("Expected comparisons [10 11 12], got %v", ids)
+	}
+}
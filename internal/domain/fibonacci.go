@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"time"
+)
+
+// FibonacciSession represents a Fibonacci scoring session for a single criterion
+type FibonacciSession struct {
+	ID            int           `json:"id" db:"id"`
+	ProjectID     int           `json:"project_id" db:"project_id"`
+	CriterionType CriterionType `json:"criterion_type" db:"criterion_type"`
+	Status        SessionStatus `json:"status" db:"status"`
+	StartedAt     time.Time     `json:"started_at" db:"started_at"`
+	CompletedAt   *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// TableName returns the table name for GORM
+func (FibonacciSession) TableName() string {
+	return "fibonacci_sessions"
+}
+
+// FibonacciScore represents an individual attendee's Fibonacci score for a feature
+type FibonacciScore struct {
+	ID         int       `json:"id" db:"id"`
+	SessionID  int       `json:"session_id" db:"session_id"`
+	FeatureID  int       `json:"feature_id" db:"feature_id"`
+	AttendeeID int       `json:"attendee_id" db:"attendee_id"`
+	ScoreValue int       `json:"score_value" db:"score_value"`
+	ScoredAt   time.Time `json:"scored_at" db:"scored_at"`
+}
+
+// TableName returns the table name for GORM
+func (FibonacciScore) TableName() string {
+	return "fibonacci_scores"
+}
+
+// ConsensusScore represents the agreed final Fibonacci score for a feature
+type ConsensusScore struct {
+	ID                 int       `json:"id" db:"id"`
+	SessionID          int       `json:"session_id" db:"session_id"`
+	FeatureID          int       `json:"feature_id" db:"feature_id"`
+	FinalScore         int       `json:"final_score" db:"final_score"`
+	ConsensusReachedAt time.Time `json:"consensus_reached_at" db:"consensus_reached_at"`
+}
+
+// TableName returns the table name for GORM
+func (ConsensusScore) TableName() string {
+	return "consensus_scores"
+}
+
+// FibonacciSessionProgress represents the progress of a Fibonacci scoring session
+type FibonacciSessionProgress struct {
+	SessionID          int     `json:"session_id"`
+	TotalFeatures      int     `json:"total_features"`
+	ConsensusFeatures  int     `json:"consensus_features"`
+	RemainingFeatures  int     `json:"remaining_features"`
+	ProgressPercentage float64 `json:"progress_percentage"`
+}
+
+// FeatureScoringStatus represents the scoring state of a single feature in a session
+type FeatureScoringStatus struct {
+	Feature   *Feature         `json:"feature"`
+	Scores    []FibonacciScore `json:"scores"`
+	Consensus *ConsensusScore  `json:"consensus,omitempty"`
+}
+
+// CreateFibonacciSessionRequest represents the request to start a new Fibonacci scoring session
+type CreateFibonacciSessionRequest struct {
+	CriterionType CriterionType `json:"criterion_type" binding:"required,max=20"`
+}
+
+// SubmitFibonacciScoreRequest represents the request to submit an attendee's Fibonacci score
+type SubmitFibonacciScoreRequest struct {
+	FeatureID  int `json:"feature_id" binding:"required"`
+	AttendeeID int `json:"attendee_id,omitempty"`
+	ScoreValue int `json:"score_value" binding:"required"`
+}
+
+// SetConsensusScoreRequest represents the request to record a facilitator-agreed final score
+type SetConsensusScoreRequest struct {
+	FeatureID  int `json:"feature_id" binding:"required"`
+	FinalScore int `json:"final_score" binding:"required"`
+}
+
+// DetectFibonacciConsensus reports whether every attendee has scored a feature with the same value.
+// It returns the agreed score when consensus is reached.
+func DetectFibonacciConsensus(scores []FibonacciScore, totalAttendees int) (int, bool) {
+	if totalAttendees == 0 || len(scores) != totalAttendees {
+		return 0, false
+	}
+
+	agreed := scores[0].ScoreValue
+	for _, score := range scores[1:] {
+		if score.ScoreValue != agreed {
+			return 0, false
+		}
+	}
+
+	return agreed, true
+}
+
+// FibonacciSnapshot is the current state of a criterion's latest Fibonacci round, for
+// clients that missed too many updates to replay them. Session is nil before the first one.
+type FibonacciSnapshot struct {
+	Session  *FibonacciSession         `json:"session"`
+	Progress *FibonacciSessionProgress `json:"progress,omitempty"`
+	Features []FeatureScoringStatus    `json:"features"`
+}
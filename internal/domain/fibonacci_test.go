@@ -0,0 +1,61 @@
+package domain
+
+import "testing"
+
+// Test Fibonacci consensus detection
+func TestDetectFibonacciConsensus(t *testing.T) {
+	tests := []struct {
+		name           string
+		scores         []FibonacciScore
+		totalAttendees int
+		expectedScore  int
+		expectedReach  bool
+	}{
+		{
+			name: "All attendees agree",
+			scores: []FibonacciScore{
+				{AttendeeID: 1, ScoreValue: 8},
+				{AttendeeID: 2, ScoreValue: 8},
+				{AttendeeID: 3, ScoreValue: 8},
+			},
+			totalAttendees: 3,
+			expectedScore:  8,
+			expectedReach:  true,
+		},
+		{
+			name: "Attendees disagree",
+			scores: []FibonacciScore{
+				{AttendeeID: 1, ScoreValue: 5},
+				{AttendeeID: 2, ScoreValue: 8},
+			},
+			totalAttendees: 2,
+			expectedReach:  false,
+		},
+		{
+			name: "Not all attendees scored",
+			scores: []FibonacciScore{
+				{AttendeeID: 1, ScoreValue: 3},
+			},
+			totalAttendees: 2,
+			expectedReach:  false,
+		},
+		{
+			name:           "No attendees",
+			scores:         []FibonacciScore{},
+			totalAttendees: 0,
+			expectedReach:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reached := DetectFibonacciConsensus(tt.scores, tt.totalAttendees)
+			if reached != tt.expectedReach {
+				t.Errorf("Expected consensus %v, got %v", tt.expectedReach, reached)
+			}
+			if reached && score != tt.expectedScore {
+				t.Errorf("Expected score %d, got %d", tt.expectedScore, score)
+			}
+		})
+	}
+}
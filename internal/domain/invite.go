@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// JoinCodeLength is the number of characters in a generated join code
+const JoinCodeLength = 8
+
+// JoinCodeAlphabet leaves out characters that are easily confused when read aloud or typed
+const JoinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Invite errors
+var (
+	ErrInviteRevoked   = errors.New("invite has been revoked")
+	ErrInviteExpired   = errors.New("invite has expired")
+	ErrInviteExhausted = errors.New("invite has no uses left")
+)
+
+// ProjectInvite lets attendees register themselves into a project with a join code or a
+// signed invite URL
+type ProjectInvite struct {
+	ID        int        `json:"id" db:"id"`
+	ProjectID int        `json:"project_id" db:"project_id"`
+	Code      string     `json:"code" db:"code"`
+	Role      Role       `json:"role" db:"role"` // Role of the attendees who join, attendee or observer
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	MaxUses   *int       `json:"max_uses,omitempty" db:"max_uses"`
+	Uses      int        `json:"uses" db:"uses"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy *int       `json:"created_by,omitempty" db:"created_by"` // Facilitator who created the invite
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+
+	// Attendees who joined with this invite
+	Attendees []Attendee `json:"attendees,omitempty" gorm:"-"`
+}
+
+// TableName returns the table name for GORM
+func (ProjectInvite) TableName() string {
+	return "project_invites"
+}
+
+// CreateInviteRequest represents a facilitator creating an invite. Without an expiry or a
+// maximum number of uses the invite stays valid until it is revoked.
+type CreateInviteRequest struct {
+	Role      Role       `json:"role" binding:"omitempty,oneof=attendee observer"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxUses   *int       `json:"max_uses,omitempty" binding:"omitempty,min=1"`
+}
+
+// InviteResponse is a newly created invite with its signed invite token and URL
+type InviteResponse struct {
+	*ProjectInvite
+	InviteToken string `json:"invite_token"`
+	InviteURL   string `json:"invite_url"`
+}
+
+// JoinProjectRequest represents an attendee registering with a join code or an invite token
+type JoinProjectRequest struct {
+	Code   string `json:"code,omitempty"`
+	Invite string `json:"invite,omitempty"`
+	Name   string `json:"name" binding:"required,min=1,max=255"`
+}
+
+// InviteClaims are the claims carried by a signed invite token
+type InviteClaims struct {
+	ProjectID int    `json:"pid"`
+	Code      string `json:"code"`
+}
+
+// Check reports why an invite cannot be used at the given time, or nil if it can
+func (i *ProjectInvite) Check(now time.Time) error {
+	switch {
+	case i.RevokedAt != nil:
+		return ErrInviteRevoked
+	case i.ExpiresAt != nil && !now.Before(*i.ExpiresAt):
+		return ErrInviteExpired
+	case i.MaxUses != nil && i.Uses >= *i.MaxUses:
+		return ErrInviteExhausted
+	default:
+		return nil
+	}
+}
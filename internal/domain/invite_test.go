@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// Test why invites can or cannot be used
+func TestProjectInviteCheck(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+	two := 2
+
+	tests := []struct {
+		name     string
+		invite   ProjectInvite
+		expected error
+	}{
+		{name: "Open invite", invite: ProjectInvite{}, expected: nil},
+		{name: "Not yet expired", invite: ProjectInvite{ExpiresAt: &future}, expected: nil},
+		{name: "Expired", invite: ProjectInvite{ExpiresAt: &past}, expected: ErrInviteExpired},
+		{name: "Uses left", invite: ProjectInvite{MaxUses: &two, Uses: 1}, expected: nil},
+		{name: "Used up", invite: ProjectInvite{MaxUses: &two, Uses: 2}, expected: ErrInviteExhausted},
+		{name: "Revoked", invite: ProjectInvite{RevokedAt: &past, ExpiresAt: &future}, expected: ErrInviteRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.invite.Check(now); err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
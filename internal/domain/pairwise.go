@@ -20,6 +20,27 @@ const (
 	SessionStatusCompleted SessionStatus = "completed"
 )
 
+// FocusPhase represents where a driven session is with the comparison in focus
+type FocusPhase string
+
+const (
+	FocusPhaseDiscussing FocusPhase = "discussing" // On screen, voting not yet open
+	FocusPhaseVoting     FocusPhase = "voting"
+	FocusPhaseClosed     FocusPhase = "closed"   // Voting closed, result not yet shown
+	FocusPhaseRevealed   FocusPhase = "revealed" // Votes shown and the comparison decided
+)
+
+// DriverAction is a facilitator's step through a driven session
+type DriverAction string
+
+const (
+	DriverActionFocus       DriverAction = "focus_comparison"
+	DriverActionOpenVoting  DriverAction = "open_voting"
+	DriverActionCloseVoting DriverAction = "close_voting"
+	DriverActionReveal      DriverAction = "reveal"
+	DriverActionNext        DriverAction = "next"
+)
+
 // PairwiseSession represents a pairwise comparison session
 type PairwiseSession struct {
 	ID            int           `json:"id" db:"id"`
@@ -28,6 +49,35 @@ type PairwiseSession struct {
 	Status        SessionStatus `json:"status" db:"status"`
 	StartedAt     time.Time     `json:"started_at" db:"started_at"`
 	CompletedAt   *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+
+	// Strategy decides which pairs are compared; comparisons are generated as results come in
+	Strategy SchedulingStrategy `json:"strategy" db:"strategy"`
+	// Anonymous sessions keep ballots apart from who cast them and only show vote counts
+	Anonymous bool `json:"anonymous" db:"anonymous"`
+	// Driven sessions are paced by the facilitator, who puts one comparison in focus for
+	// everyone and opens voting on it, instead of each attendee working through their own
+	Driven            bool       `json:"driven" db:"driven"`
+	FocusComparisonID *int       `json:"focus_comparison_id,omitempty" db:"focus_comparison_id"`
+	FocusPhase        FocusPhase `json:"focus_phase,omitempty" db:"focus_phase"`
+}
+
+// AcceptsVotesOn reports whether votes on a comparison are accepted. Driven sessions only
+// accept votes on the comparison in focus while voting on it is open.
+func (s *PairwiseSession) AcceptsVotesOn(comparisonID int) bool {
+	if !s.Driven {
+		return true
+	}
+	return s.FocusPhase == FocusPhaseVoting && s.FocusComparisonID != nil && *s.FocusComparisonID == comparisonID
+}
+
+// HidesVotesOn reports whether votes on a comparison are withheld. Driven sessions only show
+// them once the facilitator reveals the comparison or it has a result.
+func (s *PairwiseSession) HidesVotesOn(comparison SessionComparison) bool {
+	if !s.Driven || comparison.ConsensusReached {
+		return false
+	}
+	revealed := s.FocusPhase == FocusPhaseRevealed && s.FocusComparisonID != nil && *s.FocusComparisonID == comparison.ID
+	return !revealed
 }
 
 // TableName returns the table name for GORM
@@ -38,14 +88,21 @@ func (PairwiseSession) TableName() string {
 // SessionComparison represents a comparison between two features in a session
 type SessionComparison struct {
 	ID               int       `json:"id" db:"id"`
-	SessionID        int       `json:"session_id" db:"session_id"`
-	FeatureAID       int       `json:"feature_a_id" db:"feature_a_id"`
-	FeatureBID       int       `json:"feature_b_id" db:"feature_b_id"`
+	SessionID        int       `json:"session_id" db:"session_id" gorm:"uniqueIndex:idx_pairwise_comparisons_pair"`
+	FeatureAID       int       `json:"feature_a_id" db:"feature_a_id" gorm:"uniqueIndex:idx_pairwise_comparisons_pair"`
+	FeatureBID       int       `json:"feature_b_id" db:"feature_b_id" gorm:"uniqueIndex:idx_pairwise_comparisons_pair"`
 	WinnerID         *int      `json:"winner_id,omitempty" db:"winner_id"`
 	IsTie            bool      `json:"is_tie" db:"is_tie"`
 	ConsensusReached bool      `json:"consensus_reached" db:"consensus_reached"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 
+	// ConsensusRule records which rule produced the result once consensus is reached
+	ConsensusRule ConsensusPolicy `json:"consensus_rule,omitempty" db:"consensus_rule"`
+	// Intensity is the group's strength of preference for the winner on Saaty's 1-9 scale
+	Intensity int `json:"intensity,omitempty" db:"intensity"`
+	// Round is the scheduling batch the comparison was generated in, starting at 1
+	Round int `json:"round" db:"round"`
+
 	// Populated via joins
 	FeatureA *Feature `json:"feature_a,omitempty"`
 	FeatureB *Feature `json:"feature_b,omitempty"`
@@ -57,6 +114,34 @@ func (SessionComparison) TableName() string {
 	return "pairwise_comparisons"
 }
 
+// ToPairwiseComparison converts a consensus result into a calculation input.
+// It returns false when the comparison has not reached consensus yet.
+func (c SessionComparison) ToPairwiseComparison(criterion ComparisonCriterion) (PairwiseComparison, bool) {
+	if !c.ConsensusReached {
+		return PairwiseComparison{}, false
+	}
+
+	comparison := PairwiseComparison{
+		FeatureAID: c.FeatureAID,
+		FeatureBID: c.FeatureBID,
+		Criterion:  criterion,
+		Intensity:  c.Intensity,
+	}
+
+	switch {
+	case c.IsTie:
+		comparison.Result = ResultTie
+	case c.WinnerID != nil && *c.WinnerID == c.FeatureAID:
+		comparison.Result = ResultAWins
+	case c.WinnerID != nil && *c.WinnerID == c.FeatureBID:
+		comparison.Result = ResultBWins
+	default:
+		return PairwiseComparison{}, false
+	}
+
+	return comparison, true
+}
+
 // AttendeeVote represents an individual attendee's vote for a comparison
 type AttendeeVote struct {
 	ID                 int       `json:"id" db:"id"`
@@ -64,6 +149,7 @@ type AttendeeVote struct {
 	AttendeeID         int       `json:"attendee_id" db:"attendee_id"`
 	PreferredFeatureID *int      `json:"preferred_feature_id,omitempty" db:"preferred_feature_id"`
 	IsTieVote          bool      `json:"is_tie_vote" db:"is_tie_vote"`
+	Intensity          int       `json:"intensity,omitempty" db:"intensity"` // Optional strength of preference on Saaty's 1-9 scale
 	VotedAt            time.Time `json:"voted_at" db:"voted_at"`
 
 	// Populated via joins
@@ -76,6 +162,31 @@ func (AttendeeVote) TableName() string {
 	return "attendee_votes"
 }
 
+// ToBallot converts an attendee's vote on a comparison into a single-ballot calculation input.
+// It returns false when the vote does not name a feature of the comparison.
+func (v AttendeeVote) ToBallot(comparison SessionComparison, criterion ComparisonCriterion) (PairwiseComparison, bool) {
+	ballot := PairwiseComparison{
+		FeatureAID: comparison.FeatureAID,
+		FeatureBID: comparison.FeatureBID,
+		Criterion:  criterion,
+		Intensity:  v.Intensity,
+		UserID:     v.AttendeeID,
+	}
+
+	switch {
+	case v.IsTieVote:
+		ballot.Result = ResultTie
+	case v.PreferredFeatureID != nil && *v.PreferredFeatureID == comparison.FeatureAID:
+		ballot.Result = ResultAWins
+	case v.PreferredFeatureID != nil && *v.PreferredFeatureID == comparison.FeatureBID:
+		ballot.Result = ResultBWins
+	default:
+		return PairwiseComparison{}, false
+	}
+
+	return ballot, true
+}
+
 // SessionProgress represents the progress of a pairwise session
 type SessionProgress struct {
 	SessionID            int     `json:"session_id"`
@@ -83,25 +194,77 @@ type SessionProgress struct {
 	CompletedComparisons int     `json:"completed_comparisons"`
 	ProgressPercentage   float64 `json:"progress_percentage"`
 	RemainingComparisons int     `json:"remaining_comparisons"`
+
+	// EstimatedComparisons is how many comparisons the session's strategy is expected to need
+	// in total, since adaptive strategies generate comparisons as results come in
+	EstimatedComparisons int `json:"estimated_comparisons,omitempty"`
 }
 
 // CreatePairwiseSessionRequest represents the request to start a new pairwise session
 type CreatePairwiseSessionRequest struct {
-	CriterionType CriterionType `json:"criterion_type" binding:"required,oneof=value complexity"`
+	CriterionType CriterionType      `json:"criterion_type" binding:"required,max=20"`
+	Strategy      SchedulingStrategy `json:"strategy,omitempty" binding:"omitempty,oneof=round_robin merge_insertion swiss active"`
+	Anonymous     bool               `json:"anonymous"`
+	Driven        bool               `json:"driven"`
 }
 
-// SubmitVoteRequest represents the request to submit an attendee vote
+// SubmitVoteRequest represents the request to submit an attendee vote. Over the API the
+// attendee comes from the session token; a supplied attendee ID must match it.
 type SubmitVoteRequest struct {
 	ComparisonID       int  `json:"comparison_id" binding:"required"`
-	AttendeeID         int  `json:"attendee_id" binding:"required"`
+	AttendeeID         int  `json:"attendee_id,omitempty"`
 	PreferredFeatureID *int `json:"preferred_feature_id,omitempty"`
 	IsTieVote          bool `json:"is_tie_vote"`
+	Intensity          int  `json:"intensity,omitempty" binding:"omitempty,min=1,max=9"`
+}
+
+// ResolveComparisonRequest represents a facilitator's decision on a split comparison. Over
+// the API the facilitator comes from the session token; a supplied facilitator ID must match it.
+type ResolveComparisonRequest struct {
+	ComparisonID  int  `json:"comparison_id" binding:"required"`
+	FacilitatorID int  `json:"facilitator_id,omitempty"`
+	WinnerID      *int `json:"winner_id,omitempty"`
+	IsTie         bool `json:"is_tie"`
+	Intensity     int  `json:"intensity,omitempty" binding:"omitempty,min=1,max=9"`
 }
 
-// ComparisonWithVotes represents a comparison with all attendee votes
+// ComparisonWithVotes represents a comparison with all attendee votes. In anonymous sessions
+// Votes is empty and only the tally is given.
 type ComparisonWithVotes struct {
 	Comparison *SessionComparison `json:"comparison"`
 	Votes      []AttendeeVote     `json:"votes"`
+	Tally      *VoteTally         `json:"tally,omitempty"`
+}
+
+// PairwiseSnapshot is the current state of a criterion's latest pairwise session, for
+// clients that missed too many updates to replay them. Session is nil before the first one.
+type PairwiseSnapshot struct {
+	Session     *PairwiseSession      `json:"session"`
+	Progress    *SessionProgress      `json:"progress,omitempty"`
+	Comparisons []ComparisonWithVotes `json:"comparisons"`
+}
+
+// SessionFocus is where a driven session is: the comparison everyone is looking at and
+// whether voting on it is open. Comparison is nil when nothing is in focus.
+type SessionFocus struct {
+	SessionID  int                  `json:"session_id"`
+	Phase      FocusPhase           `json:"phase,omitempty"`
+	Comparison *ComparisonWithVotes `json:"comparison,omitempty"` // Votes are withheld until revealed
+}
+
+// VotingStatus tells which voters of a project have voted on a comparison of the active
+// session, without how they voted
+type VotingStatus struct {
+	SessionID    int           `json:"session_id"`
+	ComparisonID int           `json:"comparison_id,omitempty"` // Zero once every comparison has a result
+	Voters       []VoterStatus `json:"voters"`
+}
+
+// VoterStatus tells whether a voter has voted on a comparison
+type VoterStatus struct {
+	AttendeeID   int    `json:"attendee_id"`
+	AttendeeName string `json:"attendee_name"`
+	Voted        bool   `json:"voted"`
 }
 
 // FeaturePair represents a pair of features to be compared
@@ -0,0 +1,138 @@
+package domain
+
+import "testing"
+
+// Test conversion of session consensus results into calculation inputs
+func TestSessionComparisonToPairwiseComparison(t *testing.T) {
+	featureA := 1
+	featureB := 2
+
+	tests := []struct {
+		name           string
+		comparison     SessionComparison
+		expectedResult ComparisonResult
+		expectedOK     bool
+	}{
+		{
+			name:           "Feature A wins",
+			comparison:     SessionComparison{FeatureAID: 1, FeatureBID: 2, WinnerID: &featureA, ConsensusReached: true},
+			expectedResult: ResultAWins,
+			expectedOK:     true,
+		},
+		{
+			name:           "Feature B wins",
+			comparison:     SessionComparison{FeatureAID: 1, FeatureBID: 2, WinnerID: &featureB, ConsensusReached: true},
+			expectedResult: ResultBWins,
+			expectedOK:     true,
+		},
+		{
+			name:           "Tie",
+			comparison:     SessionComparison{FeatureAID: 1, FeatureBID: 2, IsTie: true, ConsensusReached: true},
+			expectedResult: ResultTie,
+			expectedOK:     true,
+		},
+		{
+			name:       "No consensus yet",
+			comparison: SessionComparison{FeatureAID: 1, FeatureBID: 2, WinnerID: &featureA},
+			expectedOK: false,
+		},
+		{
+			name:       "Consensus without a winner",
+			comparison: SessionComparison{FeatureAID: 1, FeatureBID: 2, ConsensusReached: true},
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := tt.comparison.ToPairwiseComparison(CriterionValue)
+			if ok != tt.expectedOK {
+				t.Fatalf("Expected ok %v, got %v", tt.expectedOK, ok)
+			}
+			if !ok {
+				return
+			}
+			if result.Result != tt.expectedResult {
+				t.Errorf("Expected result %s, got %s", tt.expectedResult, result.Result)
+			}
+			if result.Criterion != CriterionValue {
+				t.Errorf("Expected criterion %s, got %s", CriterionValue, result.Criterion)
+			}
+		})
+	}
+}
+
+// Test which comparisons of driven sessions accept votes and show them
+func TestPairwiseSessionFocus(t *testing.T) {
+	focused := 5
+	open := SessionComparison{ID: 5}
+	other := SessionComparison{ID: 6}
+	decided := SessionComparison{ID: 7, ConsensusReached: true}
+
+	tests := []struct {
+		name         string
+		session      PairwiseSession
+		comparison   SessionComparison
+		acceptsVotes bool
+		hidesVotes   bool
+	}{
+		{
+			name:         "Self-paced session",
+			session:      PairwiseSession{},
+			comparison:   other,
+			acceptsVotes: true,
+		},
+		{
+			name:       "Nothing in focus",
+			session:    PairwiseSession{Driven: true},
+			comparison: open,
+			hidesVotes: true,
+		},
+		{
+			name:       "Discussing the comparison in focus",
+			session:    PairwiseSession{Driven: true, FocusComparisonID: &focused, FocusPhase: FocusPhaseDiscussing},
+			comparison: open,
+			hidesVotes: true,
+		},
+		{
+			name:         "Voting on the comparison in focus",
+			session:      PairwiseSession{Driven: true, FocusComparisonID: &focused, FocusPhase: FocusPhaseVoting},
+			comparison:   open,
+			acceptsVotes: true,
+			hidesVotes:   true,
+		},
+		{
+			name:       "Voting on another comparison",
+			session:    PairwiseSession{Driven: true, FocusComparisonID: &focused, FocusPhase: FocusPhaseVoting},
+			comparison: other,
+			hidesVotes: true,
+		},
+		{
+			name:       "Voting closed",
+			session:    PairwiseSession{Driven: true, FocusComparisonID: &focused, FocusPhase: FocusPhaseClosed},
+			comparison: open,
+			hidesVotes: true,
+		},
+		{
+			name:       "Revealed",
+			session:    PairwiseSession{Driven: true, FocusComparisonID: &focused, FocusPhase: FocusPhaseRevealed},
+			comparison: open,
+		},
+		{
+			name:       "Decided earlier",
+			session:    PairwiseSession{Driven: true, FocusComparisonID: &focused, FocusPhase: FocusPhaseVoting},
+			comparison: decided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.AcceptsVotesOn(tt.comparison.ID); got != tt.acceptsVotes {
+				t.Errorf("Expected accepts votes %v, got %v", tt.acceptsVotes, got)
+			}
+			if got := tt.session.HidesVotesOn(tt.comparison); got != tt.hidesVotes {
+				t.Errorf("Expected hides votes %v, got %v", tt.hidesVotes, got)
+			}
+		})
+	}
+}
@@ -4,18 +4,25 @@ import "time"
 
 // PriorityCalculation represents the final P-WVC calculation for a feature
 type PriorityCalculation struct {
-	ID                 int       `json:"id" db:"id"`
-	ProjectID          int       `json:"projectId" db:"project_id"`
-	FeatureID          int       `json:"featureId" db:"feature_id"`
-	WValue             float64   `json:"wValue" db:"w_value"`                          // Win-count weight for value
-	WComplexity        float64   `json:"wComplexity" db:"w_complexity"`                // Win-count weight for complexity
-	SValue             int       `json:"sValue" db:"s_value"`                          // Fibonacci score for value
-	SComplexity        int       `json:"sComplexity" db:"s_complexity"`                // Fibonacci score for complexity
-	WeightedValue      float64   `json:"weightedValue" db:"weighted_value"`            // SValue × WValue
-	WeightedComplexity float64   `json:"weightedComplexity" db:"weighted_complexity"`  // SComplexity × WComplexity
-	FinalPriorityScore float64   `json:"finalPriorityScore" db:"final_priority_score"` // Weighted Value ÷ Weighted Complexity
-	Rank               int       `json:"rank" db:"rank"`
-	CalculatedAt       time.Time `json:"calculatedAt" db:"calculated_at"`
+	ID                 int          `json:"id" db:"id"`
+	ProjectID          int          `json:"projectId" db:"project_id"`
+	FeatureID          int          `json:"featureId" db:"feature_id"`
+	WValue             float64      `json:"wValue" db:"w_value"`                          // Win-count weight for value
+	WComplexity        float64      `json:"wComplexity" db:"w_complexity"`                // Win-count weight for complexity
+	SValue             int          `json:"sValue" db:"s_value"`                          // Fibonacci score for value
+	SComplexity        int          `json:"sComplexity" db:"s_complexity"`                // Fibonacci score for complexity
+	WeightedValue      float64      `json:"weightedValue" db:"weighted_value"`            // SValue × WValue
+	WeightedComplexity float64      `json:"weightedComplexity" db:"weighted_complexity"`  // SComplexity × WComplexity
+	FinalPriorityScore float64      `json:"finalPriorityScore" db:"final_priority_score"` // Weighted Value ÷ Weighted Complexity
+	Rank               int          `json:"rank" db:"rank"`
+	WinCountMode       WinCountMode `json:"winCountMode" db:"win_count_mode"` // How WValue and WComplexity were derived
+	CalculatedAt       time.Time    `json:"calculatedAt" db:"calculated_at"`
+
+	// RankingModel is the model that estimated WValue and WComplexity from the comparisons
+	RankingModel RankingModelType `json:"rankingModel" db:"ranking_model"`
+
+	// Criteria breaks the Final Priority Score down by project criterion
+	Criteria []CriterionScore `json:"criteria,omitempty" gorm:"-"`
 
 	// Related data for display
 	Feature *Feature `json:"feature,omitempty"`
@@ -50,7 +57,8 @@ type ResultsSummary struct {
 
 // CalculateResultsRequest represents the request to calculate P-WVC results
 type CalculateResultsRequest struct {
-	ProjectID int `json:"projectId" binding:"required"`
+	ProjectID    int          `json:"projectId" binding:"required"`
+	WinCountMode WinCountMode `json:"winCountMode" binding:"omitempty,oneof=consensus vote"`
 }
 
 // ExportFormat represents the different export formats
@@ -1,30 +1,65 @@
 package domain
 
 import (
+	"strings"
 	"time"
 )
 
+// ConsensusPolicy determines how attendee votes on a comparison produce a result
+type ConsensusPolicy string
+
+const (
+	ConsensusPolicyUnanimous     ConsensusPolicy = "unanimous"
+	ConsensusPolicyMajority      ConsensusPolicy = "majority"
+	ConsensusPolicySupermajority ConsensusPolicy = "supermajority"
+	ConsensusPolicyFacilitator   ConsensusPolicy = "facilitator"
+)
+
+// DefaultSupermajorityThreshold is the share of votes required when a project does not set one
+const DefaultSupermajorityThreshold = 2.0 / 3.0
+
 // Project represents a P-WVC project
 type Project struct {
-	ID          int       `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name" binding:"required,min=1,max=255"`
-	Description string    `json:"description" db:"description"`
-	Status      string    `json:"status" db:"status"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID                     int             `json:"id" db:"id"`
+	Name                   string          `json:"name" db:"name" binding:"required,min=1,max=255"`
+	Description            string          `json:"description" db:"description"`
+	Status                 string          `json:"status" db:"status"`
+	ConsensusPolicy        ConsensusPolicy `json:"consensus_policy" db:"consensus_policy"`
+	SupermajorityThreshold float64         `json:"supermajority_threshold" db:"supermajority_threshold"`
+	OwnerID                *int            `json:"owner_id,omitempty" db:"owner_id"` // User who owns the project, if any
+	CreatedAt              time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time       `json:"updated_at" db:"updated_at"`
 }
 
 // CreateProjectRequest represents the request payload for creating a project
 type CreateProjectRequest struct {
-	Name        string `json:"name" binding:"required,min=1,max=255"`
-	Description string `json:"description"`
+	Name                   string          `json:"name" binding:"required,min=1,max=255"`
+	Description            string          `json:"description"`
+	ConsensusPolicy        ConsensusPolicy `json:"consensus_policy" binding:"omitempty,oneof=unanimous majority supermajority facilitator"`
+	SupermajorityThreshold float64         `json:"supermajority_threshold" binding:"omitempty,gt=0.5,lte=1"`
+	// Criteria defines the project's criteria in workflow order; empty uses value and complexity
+	Criteria []CreateCriterionRequest `json:"criteria" binding:"omitempty,dive"`
+	OwnerID  *int                     `json:"-"` // Set when a signed-in user creates the project
 }
 
-// UpdateProjectRequest represents the request payload for updating a project
+// UpdateProjectRequest represents the request payload for updating a project.
+// An empty consensus policy or zero threshold keeps the project's current setting.
 type UpdateProjectRequest struct {
-	Name        string `json:"name" binding:"required,min=1,max=255"`
-	Description string `json:"description"`
-	Status      string `json:"status" binding:"omitempty,oneof=active inactive completed"`
+	Name                   string          `json:"name" binding:"required,min=1,max=255"`
+	Description            string          `json:"description"`
+	Status                 string          `json:"status" binding:"omitempty,oneof=active inactive completed"`
+	ConsensusPolicy        ConsensusPolicy `json:"consensus_policy" binding:"omitempty,oneof=unanimous majority supermajority facilitator"`
+	SupermajorityThreshold float64         `json:"supermajority_threshold" binding:"omitempty,gt=0.5,lte=1"`
+}
+
+// IsValidConsensusPolicy checks whether a consensus policy is supported
+func IsValidConsensusPolicy(policy ConsensusPolicy) bool {
+	switch policy {
+	case ConsensusPolicyUnanimous, ConsensusPolicyMajority, ConsensusPolicySupermajority, ConsensusPolicyFacilitator:
+		return true
+	default:
+		return false
+	}
 }
 
 // ProjectProgress represents the workflow state of a P-WVC project
@@ -42,6 +77,29 @@ type ProjectProgress struct {
 	LastActivity                 time.Time `json:"last_activity" db:"last_activity"`
 	CreatedAt                    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt                    time.Time `json:"updated_at" db:"updated_at"`
+
+	// Criteria lists the project's criterion keys in workflow order; empty means value then complexity
+	Criteria []CriterionType `json:"criteria" gorm:"-"`
+	// CompletedPhases lists the completed pairwise and Fibonacci phases of every criterion
+	CompletedPhases []WorkflowPhase `json:"completed_phases" gorm:"-"`
+}
+
+// TableName returns the table name for GORM
+func (ProjectProgress) TableName() string {
+	return "project_progress"
+}
+
+// PhaseCompletion records that a project completed a criterion's pairwise or Fibonacci phase
+type PhaseCompletion struct {
+	ID          int           `json:"id" db:"id"`
+	ProjectID   int           `json:"project_id" db:"project_id"`
+	Phase       WorkflowPhase `json:"phase" db:"phase"`
+	CompletedAt time.Time     `json:"completed_at" db:"completed_at"`
+}
+
+// TableName returns the table name for GORM
+func (PhaseCompletion) TableName() string {
+	return "project_phase_completions"
 }
 
 // WorkflowPhase represents the different phases in the P-WVC workflow
@@ -58,26 +116,144 @@ const (
 	PhaseResults             WorkflowPhase = "results"
 )
 
+// PairwisePhase returns the pairwise comparison phase for a criterion
+func PairwisePhase(criterion CriterionType) WorkflowPhase {
+	return WorkflowPhase("pairwise_" + criterion)
+}
+
+// FibonacciPhase returns the Fibonacci scoring phase for a criterion
+func FibonacciPhase(criterion CriterionType) WorkflowPhase {
+	return WorkflowPhase("fibonacci_" + criterion)
+}
+
+// IsValidWorkflowPhase checks whether a phase is a fixed phase or a criterion phase with a valid key
+func IsValidWorkflowPhase(phase WorkflowPhase) bool {
+	switch phase {
+	case PhaseSetup, PhaseAttendees, PhaseFeatures, PhaseResults:
+		return true
+	}
+
+	for _, prefix := range []string{"pairwise_", "fibonacci_"} {
+		if key, ok := strings.CutPrefix(string(phase), prefix); ok {
+			return IsValidCriterionKey(CriterionType(key))
+		}
+	}
+	return false
+}
+
+// criterionKeys returns the project's criteria, defaulting to value and complexity
+func (p *ProjectProgress) criterionKeys() []CriterionType {
+	if len(p.Criteria) == 0 {
+		return CriterionKeys(DefaultCriteria())
+	}
+	return p.Criteria
+}
+
+// Phases returns the workflow phases in order: setup, attendees and features, a pairwise
+// phase for each criterion, a Fibonacci phase for each criterion, then results
+func (p *ProjectProgress) Phases() []WorkflowPhase {
+	criteria := p.criterionKeys()
+
+	phases := []WorkflowPhase{PhaseSetup, PhaseAttendees, PhaseFeatures}
+	for _, criterion := range criteria {
+		phases = append(phases, PairwisePhase(criterion))
+	}
+	for _, criterion := range criteria {
+		phases = append(phases, FibonacciPhase(criterion))
+	}
+	return append(phases, PhaseResults)
+}
+
+// HasPhase checks whether a phase is part of the project's workflow
+func (p *ProjectProgress) HasPhase(phase WorkflowPhase) bool {
+	for _, candidate := range p.Phases() {
+		if candidate == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPhaseCompleted checks whether a phase has been completed
+func (p *ProjectProgress) IsPhaseCompleted(phase WorkflowPhase) bool {
+	switch phase {
+	case PhaseSetup:
+		return p.SetupCompleted
+	case PhaseAttendees:
+		return p.AttendeesAdded
+	case PhaseFeatures:
+		return p.FeaturesAdded
+	case PhaseResults:
+		return p.ResultsCalculated
+	case PhasePairwiseValue:
+		if p.PairwiseValueCompleted {
+			return true
+		}
+	case PhasePairwiseComplexity:
+		if p.PairwiseComplexityCompleted {
+			return true
+		}
+	case PhaseFibonacciValue:
+		if p.FibonacciValueCompleted {
+			return true
+		}
+	case PhaseFibonacciComplexity:
+		if p.FibonacciComplexityCompleted {
+			return true
+		}
+	}
+
+	for _, completed := range p.CompletedPhases {
+		if completed == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkPhaseCompleted records a phase as completed. The value and complexity flags are kept
+// in step so existing clients see the same progress as before.
+func (p *ProjectProgress) MarkPhaseCompleted(phase WorkflowPhase) {
+	switch phase {
+	case PhaseSetup:
+		p.SetupCompleted = true
+		return
+	case PhaseAttendees:
+		p.AttendeesAdded = true
+		return
+	case PhaseFeatures:
+		p.FeaturesAdded = true
+		return
+	case PhaseResults:
+		p.ResultsCalculated = true
+		return
+	case PhasePairwiseValue:
+		p.PairwiseValueCompleted = true
+	case PhasePairwiseComplexity:
+		p.PairwiseComplexityCompleted = true
+	case PhaseFibonacciValue:
+		p.FibonacciValueCompleted = true
+	case PhaseFibonacciComplexity:
+		p.FibonacciComplexityCompleted = true
+	}
+
+	for _, completed := range p.CompletedPhases {
+		if completed == phase {
+			return
+		}
+	}
+	p.CompletedPhases = append(p.CompletedPhases, phase)
+}
+
 // GetNextPhase returns the next phase in the workflow
 func (p *ProjectProgress) GetNextPhase() WorkflowPhase {
-	switch p.CurrentPhase {
-	case string(PhaseSetup):
-		return PhaseAttendees
-	case string(PhaseAttendees):
-		return PhaseFeatures
-	case string(PhaseFeatures):
-		return PhasePairwiseValue
-	case string(PhasePairwiseValue):
-		return PhasePairwiseComplexity
-	case string(PhasePairwiseComplexity):
-		return PhaseFibonacciValue
-	case string(PhaseFibonacciValue):
-		return PhaseFibonacciComplexity
-	case string(PhaseFibonacciComplexity):
-		return PhaseResults
-	default:
-		return PhaseResults
+	phases := p.Phases()
+	for i, phase := range phases[:len(phases)-1] {
+		if string(phase) == p.CurrentPhase {
+			return phases[i+1]
+		}
 	}
+	return PhaseResults
 }
 
 // CanProgressTo checks if the project can progress to a specific phase
@@ -89,17 +265,19 @@ func (p *ProjectProgress) CanProgressTo(phase WorkflowPhase) bool {
 		return p.SetupCompleted
 	case PhaseFeatures:
 		return p.SetupCompleted && p.AttendeesAdded
-	case PhasePairwiseValue:
-		return p.SetupCompleted && p.AttendeesAdded && p.FeaturesAdded
-	case PhasePairwiseComplexity:
-		return p.PairwiseValueCompleted
-	case PhaseFibonacciValue:
-		return p.PairwiseComplexityCompleted
-	case PhaseFibonacciComplexity:
-		return p.FibonacciValueCompleted
-	case PhaseResults:
-		return p.FibonacciComplexityCompleted
-	default:
-		return false
 	}
+
+	phases := p.Phases()
+	for i, candidate := range phases {
+		if candidate != phase {
+			continue
+		}
+		// The first criterion phase needs the setup phases; every later phase needs the one before it
+		if phases[i-1] == PhaseFeatures {
+			return p.SetupCompleted && p.AttendeesAdded && p.FeaturesAdded
+		}
+		return p.IsPhaseCompleted(phases[i-1])
+	}
+
+	return false
 }
@@ -0,0 +1,325 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// RankingModelType identifies a strategy for turning pairwise comparisons into feature weights
+type RankingModelType string
+
+const (
+	RankingModelWinCount     RankingModelType = "win_count"
+	RankingModelVoteWinCount RankingModelType = "vote_win_count"
+	RankingModelBradleyTerry RankingModelType = "bradley_terry"
+	RankingModelElo          RankingModelType = "elo"
+	RankingModelAHP          RankingModelType = "ahp"
+)
+
+// RankingModel estimates a weight between 0 and 1 for every feature from pairwise comparisons.
+// The weight is reported in WinCountResult.WinCount so it can be used as W_value or W_complexity.
+type RankingModel interface {
+	Type() RankingModelType
+	Estimate(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error)
+}
+
+// NewRankingModel returns a ranking model with default settings for the given type
+func NewRankingModel(modelType RankingModelType) (RankingModel, error) {
+	switch modelType {
+	case RankingModelWinCount, "":
+		return WinCountModel{}, nil
+	case RankingModelVoteWinCount:
+		return VoteWinCountModel{}, nil
+	case RankingModelBradleyTerry:
+		return NewBradleyTerryModel(), nil
+	case RankingModelElo:
+		return NewEloModel(), nil
+	case RankingModelAHP:
+		return AHPModel{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported ranking model: %s", modelType)
+	}
+}
+
+// IsValidRankingModel checks if a ranking model type is supported
+func IsValidRankingModel(modelType RankingModelType) bool {
+	_, err := NewRankingModel(modelType)
+	return modelType != "" && err == nil
+}
+
+// RankingModelForMode returns the win-count model matching a win-count mode
+func RankingModelForMode(mode WinCountMode) (RankingModel, error) {
+	switch mode {
+	case WinCountModeConsensus, "":
+		return WinCountModel{}, nil
+	case WinCountModeVote:
+		return VoteWinCountModel{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported win-count mode: %s", mode)
+	}
+}
+
+// WinCountModel is the standard P-WVC model: (wins + 0.5 × ties) / comparisons
+type WinCountModel struct{}
+
+// Type returns the model type
+func (WinCountModel) Type() RankingModelType {
+	return RankingModelWinCount
+}
+
+// Estimate calculates win-counts for all features
+func (WinCountModel) Estimate(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error) {
+	return CalculateWinCountsForAllFeatures(featureIDs, comparisons, criterion)
+}
+
+// VoteWinCountModel calculates fractional win-counts from individual attendee ballots
+type VoteWinCountModel struct{}
+
+// Type returns the model type
+func (VoteWinCountModel) Type() RankingModelType {
+	return RankingModelVoteWinCount
+}
+
+// Estimate calculates fractional win-counts for all features
+func (VoteWinCountModel) Estimate(featureIDs []int, ballots []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error) {
+	return CalculateVoteWinCountsForAllFeatures(featureIDs, ballots, criterion)
+}
+
+// BradleyTerryModel fits Bradley–Terry strengths by maximum likelihood using the
+// minorization–maximization iteration. Every feature also plays Prior virtual
+// drawn games against a reference of strength 1, which keeps strengths finite for
+// unbeaten or winless features and anchors disconnected comparison graphs.
+type BradleyTerryModel struct {
+	MaxIterations int
+	Tolerance     float64
+	Prior         float64
+}
+
+// NewBradleyTerryModel creates a Bradley–Terry model with default settings
+func NewBradleyTerryModel() BradleyTerryModel {
+	return BradleyTerryModel{
+		MaxIterations: 1000,
+		Tolerance:     1e-9,
+		Prior:         0.5,
+	}
+}
+
+// Type returns the model type
+func (BradleyTerryModel) Type() RankingModelType {
+	return RankingModelBradleyTerry
+}
+
+// Estimate fits strengths and reports each feature's average probability of beating the others
+func (m BradleyTerryModel) Estimate(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error) {
+	if len(featureIDs) == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	index := make(map[int]int, len(featureIDs))
+	for i, featureID := range featureIDs {
+		index[featureID] = i
+	}
+
+	strengths := m.fit(index, comparisons, criterion)
+
+	return strengthResults(featureIDs, index, comparisons, criterion, func(i, j int) float64 {
+		return strengths[i] / (strengths[i] + strengths[j])
+	}), nil
+}
+
+// fit estimates a strength for every indexed feature
+func (m BradleyTerryModel) fit(index map[int]int, comparisons []PairwiseComparison, criterion ComparisonCriterion) []float64 {
+	n := len(index)
+	scores := make([]float64, n)  // wins plus half of ties
+	games := make([][]float64, n) // games played between each pair
+	for i := range games {
+		games[i] = make([]float64, n)
+	}
+
+	for _, comp := range comparisons {
+		if comp.Criterion != criterion {
+			continue
+		}
+		a, okA := index[comp.FeatureAID]
+		b, okB := index[comp.FeatureBID]
+		if !okA || !okB || a == b {
+			continue
+		}
+
+		games[a][b]++
+		games[b][a]++
+		switch comp.Result {
+		case ResultAWins:
+			scores[a]++
+		case ResultBWins:
+			scores[b]++
+		case ResultTie:
+			scores[a] += 0.5
+			scores[b] += 0.5
+		}
+	}
+
+	// Without the virtual games a winless feature would collapse to zero strength
+	prior := m.Prior
+	if prior <= 0 {
+		prior = NewBradleyTerryModel().Prior
+	}
+
+	strengths := make([]float64, n)
+	for i := range strengths {
+		strengths[i] = 1
+	}
+
+	for iteration := 0; iteration < m.MaxIterations; iteration++ {
+		next := make([]float64, n)
+		maxChange := 0.0
+		for i := 0; i < n; i++ {
+			denominator := 2 * prior / (strengths[i] + 1)
+			for j := 0; j < n; j++ {
+				if games[i][j] > 0 {
+					denominator += games[i][j] / (strengths[i] + strengths[j])
+				}
+			}
+			next[i] = (scores[i] + prior) / denominator
+			maxChange = math.Max(maxChange, math.Abs(next[i]-strengths[i])/strengths[i])
+		}
+		strengths = next
+		if maxChange < m.Tolerance {
+			break
+		}
+	}
+
+	return strengths
+}
+
+// EloModel rates features by replaying comparisons in order with Elo updates
+type EloModel struct {
+	InitialRating float64
+	KFactor       float64
+}
+
+// NewEloModel creates an Elo model with conventional chess settings
+func NewEloModel() EloModel {
+	return EloModel{
+		InitialRating: 1500,
+		KFactor:       32,
+	}
+}
+
+// Type returns the model type
+func (EloModel) Type() RankingModelType {
+	return RankingModelElo
+}
+
+// Estimate rates features and reports each feature's average expected score against the others
+func (m EloModel) Estimate(featureIDs []int, comparisons []PairwiseComparison, criterion ComparisonCriterion) ([]WinCountResult, error) {
+	if len(featureIDs) == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	index := make(map[int]int, len(featureIDs))
+	for i, featureID := range featureIDs {
+		index[featureID] = i
+	}
+
+	ratings := make([]float64, len(featureIDs))
+	for i := range ratings {
+		ratings[i] = m.InitialRating
+	}
+
+	expected := func(i, j int) float64 {
+		return 1 / (1 + math.Pow(10, (ratings[j]-ratings[i])/400))
+	}
+
+	for _, comp := range comparisons {
+		if comp.Criterion != criterion {
+			continue
+		}
+		a, okA := index[comp.FeatureAID]
+		b, okB := index[comp.FeatureBID]
+		if !okA || !okB || a == b {
+			continue
+		}
+
+		var scoreA float64
+		switch comp.Result {
+		case ResultAWins:
+			scoreA = 1
+		case ResultBWins:
+			scoreA = 0
+		case ResultTie:
+			scoreA = 0.5
+		default:
+			continue
+		}
+
+		delta := m.KFactor * (scoreA - expected(a, b))
+		ratings[a] += delta
+		ratings[b] -= delta
+	}
+
+	return strengthResults(featureIDs, index, comparisons, criterion, expected), nil
+}
+
+// strengthResults builds win-count results where the weight is a feature's average
+// probability of beating every other feature, which keeps it between 0 and 1
+func strengthResults(
+	featureIDs []int,
+	index map[int]int,
+	comparisons []PairwiseComparison,
+	criterion ComparisonCriterion,
+	winProbability func(i, j int) float64,
+) []WinCountResult {
+	results := observedRecords(featureIDs, index, comparisons, criterion)
+	if len(featureIDs) < 2 {
+		return results
+	}
+
+	for i := range results {
+		total := 0.0
+		for j := range featureIDs {
+			if j != i {
+				total += winProbability(i, j)
+			}
+		}
+		results[i].WinCount = total / float64(len(featureIDs)-1)
+	}
+
+	return results
+}
+
+// observedRecords counts each feature's wins, losses and ties so model estimates can be
+// reported alongside the observed record
+func observedRecords(featureIDs []int, index map[int]int, comparisons []PairwiseComparison, criterion ComparisonCriterion) []WinCountResult {
+	results := make([]WinCountResult, len(featureIDs))
+	for i, featureID := range featureIDs {
+		results[i].FeatureID = featureID
+	}
+
+	for _, comp := range comparisons {
+		if comp.Criterion != criterion {
+			continue
+		}
+		a, okA := index[comp.FeatureAID]
+		b, okB := index[comp.FeatureBID]
+		if !okA || !okB || a == b {
+			continue
+		}
+
+		results[a].TotalComparisons++
+		results[b].TotalComparisons++
+		switch comp.Result {
+		case ResultAWins:
+			results[a].Wins++
+			results[b].Losses++
+		case ResultBWins:
+			results[b].Wins++
+			results[a].Losses++
+		case ResultTie:
+			results[a].Ties++
+			results[b].Ties++
+		}
+	}
+
+	return results
+}
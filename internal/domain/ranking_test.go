@@ -0,0 +1,107 @@
+package domain
+
+import "testing"
+
+// Test that every ranking model produces bounded weights in a sensible order
+func TestRankingModels(t *testing.T) {
+	featureIDs := []int{1, 2, 3}
+
+	// Feature 1 beats 2 and 2 beats 3; 1 and 3 are never compared directly
+	comparisons := []PairwiseComparison{
+		{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins},
+		{FeatureAID: 2, FeatureBID: 3, Criterion: CriterionValue, Result: ResultAWins},
+		{FeatureAID: 1, FeatureBID: 3, Criterion: CriterionComplexity, Result: ResultBWins},
+	}
+
+	models := []RankingModelType{
+		RankingModelWinCount,
+		RankingModelBradleyTerry,
+		RankingModelElo,
+	}
+
+	for _, modelType := range models {
+		t.Run(string(modelType), func(t *testing.T) {
+			model, err := NewRankingModel(modelType)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if model.Type() != modelType {
+				t.Errorf("Expected model type %s, got %s", modelType, model.Type())
+			}
+
+			results, err := model.Estimate(featureIDs, comparisons, CriterionValue)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(results) != len(featureIDs) {
+				t.Fatalf("Expected %d results, got %d", len(featureIDs), len(results))
+			}
+
+			weights := make(map[int]float64)
+			for _, result := range results {
+				if result.WinCount < 0 || result.WinCount > 1 {
+					t.Errorf("Feature %d weight %.4f is outside [0, 1]", result.FeatureID, result.WinCount)
+				}
+				weights[result.FeatureID] = result.WinCount
+			}
+
+			if !(weights[1] > weights[2] && weights[2] > weights[3]) {
+				t.Errorf("Expected weights ordered 1 > 2 > 3, got %.4f, %.4f, %.4f", weights[1], weights[2], weights[3])
+			}
+		})
+	}
+}
+
+// Test that Bradley–Terry uses transitive information win-counts cannot see
+func TestBradleyTerryModelTransitivity(t *testing.T) {
+	featureIDs := []int{1, 2, 3, 4}
+
+	// Features 1 and 3 both have a single win, but 1 beat the stronger opponent
+	comparisons := []PairwiseComparison{
+		{FeatureAID: 2, FeatureBID: 4, Criterion: CriterionValue, Result: ResultAWins},
+		{FeatureAID: 2, FeatureBID: 4, Criterion: CriterionValue, Result: ResultAWins},
+		{FeatureAID: 1, FeatureBID: 2, Criterion: CriterionValue, Result: ResultAWins},
+		{FeatureAID: 3, FeatureBID: 4, Criterion: CriterionValue, Result: ResultAWins},
+	}
+
+	results, err := NewBradleyTerryModel().Estimate(featureIDs, comparisons, CriterionValue)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	weights := make(map[int]float64)
+	for _, result := range results {
+		weights[result.FeatureID] = result.WinCount
+	}
+
+	if weights[1] <= weights[3] {
+		t.Errorf("Expected feature 1 (%.4f) to outrank feature 3 (%.4f)", weights[1], weights[3])
+	}
+
+	for _, result := range results {
+		if result.FeatureID == 1 && (result.Wins != 1 || result.TotalComparisons != 1) {
+			t.Errorf("Expected feature 1 record 1 win in 1 comparison, got %d in %d", result.Wins, result.TotalComparisons)
+		}
+	}
+}
+
+// Test ranking model construction
+func TestNewRankingModel(t *testing.T) {
+	if _, err := NewRankingModel("unknown"); err == nil {
+		t.Error("Expected error for unknown ranking model")
+	}
+
+	model, err := NewRankingModel("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if model.Type() != RankingModelWinCount {
+		t.Errorf("Expected default model %s, got %s", RankingModelWinCount, model.Type())
+	}
+
+	if !IsValidRankingModel(RankingModelElo) || IsValidRankingModel("") || IsValidRankingModel("unknown") {
+		t.Error("Expected only named, supported ranking models to be valid")
+	}
+}
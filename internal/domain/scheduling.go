@@ -0,0 +1,401 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SchedulingStrategy decides which pairs a pairwise session compares
+type SchedulingStrategy string
+
+const (
+	// SchedulingRoundRobin compares every pair of features, n(n-1)/2 comparisons
+	SchedulingRoundRobin SchedulingStrategy = "round_robin"
+	// SchedulingMergeInsertion sorts features with Ford–Johnson merge-insertion, roughly n log n comparisons
+	SchedulingMergeInsertion SchedulingStrategy = "merge_insertion"
+	// SchedulingSwiss plays Swiss-style rounds pairing features with similar scores
+	SchedulingSwiss SchedulingStrategy = "swiss"
+	// SchedulingActive picks the pair whose outcome is least predictable under a Bradley–Terry fit
+	SchedulingActive SchedulingStrategy = "active"
+)
+
+// IsValidSchedulingStrategy checks if a scheduling strategy is supported
+func IsValidSchedulingStrategy(strategy SchedulingStrategy) bool {
+	switch strategy {
+	case SchedulingRoundRobin, SchedulingMergeInsertion, SchedulingSwiss, SchedulingActive:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScheduledPair represents a comparison a scheduler wants added to a session
+type ScheduledPair struct {
+	FeatureAID int `json:"feature_a_id"`
+	FeatureBID int `json:"feature_b_id"`
+}
+
+// ComparisonScheduler generates session comparisons lazily from the results so far.
+// Schedulers are stateless: everything they need is in the session's comparisons.
+type ComparisonScheduler interface {
+	Strategy() SchedulingStrategy
+	// NextPairs returns the comparisons to add once every existing comparison has reached
+	// consensus. An empty result means the session has gathered enough comparisons.
+	NextPairs(featureIDs []int, comparisons []SessionComparison) []ScheduledPair
+	// EstimatedComparisons returns roughly how many comparisons a session of n features needs
+	EstimatedComparisons(n int) int
+}
+
+// NewComparisonScheduler returns a scheduler with default settings for the given strategy
+func NewComparisonScheduler(strategy SchedulingStrategy) (ComparisonScheduler, error) {
+	switch strategy {
+	case SchedulingRoundRobin, "":
+		return RoundRobinScheduler{}, nil
+	case SchedulingMergeInsertion:
+		return MergeInsertionScheduler{}, nil
+	case SchedulingSwiss:
+		return SwissScheduler{}, nil
+	case SchedulingActive:
+		return ActiveLearningScheduler{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheduling strategy: %s", strategy)
+	}
+}
+
+// comparedPairs indexes the session's comparisons by unordered feature pair
+func comparedPairs(comparisons []SessionComparison) map[[2]int]SessionComparison {
+	pairs := make(map[[2]int]SessionComparison, len(comparisons))
+	for _, comparison := range comparisons {
+		pairs[pairKey(comparison.FeatureAID, comparison.FeatureBID)] = comparison
+	}
+	return pairs
+}
+
+// pairKey normalizes a feature pair so both orders map to the same key
+func pairKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// RoundRobinScheduler compares every pair of features in a single round
+type RoundRobinScheduler struct{}
+
+// Strategy returns the scheduling strategy
+func (RoundRobinScheduler) Strategy() SchedulingStrategy {
+	return SchedulingRoundRobin
+}
+
+// NextPairs returns every pair that has not been compared yet
+func (RoundRobinScheduler) NextPairs(featureIDs []int, comparisons []SessionComparison) []ScheduledPair {
+	compared := comparedPairs(comparisons)
+
+	var pairs []ScheduledPair
+	for i := 0; i < len(featureIDs); i++ {
+		for j := i + 1; j < len(featureIDs); j++ {
+			if _, done := compared[pairKey(featureIDs[i], featureIDs[j])]; !done {
+				pairs = append(pairs, ScheduledPair{FeatureAID: featureIDs[i], FeatureBID: featureIDs[j]})
+			}
+		}
+	}
+	return pairs
+}
+
+// EstimatedComparisons returns n(n-1)/2
+func (RoundRobinScheduler) EstimatedComparisons(n int) int {
+	return n * (n - 1) / 2
+}
+
+// errComparisonNeeded stops a sort when it reaches a pair without a result
+var errComparisonNeeded = errors.New("comparison needed")
+
+// MergeInsertionScheduler sorts features by preference with the Ford–Johnson algorithm,
+// which uses close to the minimum number of comparisons of any comparison sort. The sort
+// is replayed against the results so far and the first pair it cannot decide is scheduled,
+// so comparisons are added one at a time.
+type MergeInsertionScheduler struct{}
+
+// Strategy returns the scheduling strategy
+func (MergeInsertionScheduler) Strategy() SchedulingStrategy {
+	return SchedulingMergeInsertion
+}
+
+// NextPairs returns the next comparison the sort needs, or nothing once features are fully ordered
+func (MergeInsertionScheduler) NextPairs(featureIDs []int, comparisons []SessionComparison) []ScheduledPair {
+	compared := comparedPairs(comparisons)
+
+	var needed *ScheduledPair
+	// less reports whether a is less preferred than b; ties count as equal
+	less := func(a, b int) (bool, error) {
+		comparison, ok := compared[pairKey(a, b)]
+		if !ok || !comparison.ConsensusReached {
+			needed = &ScheduledPair{FeatureAID: a, FeatureBID: b}
+			return false, errComparisonNeeded
+		}
+		return !comparison.IsTie && comparison.WinnerID != nil && *comparison.WinnerID == b, nil
+	}
+
+	if _, err := mergeInsertionSort(featureIDs, less); err != nil && needed != nil {
+		return []ScheduledPair{*needed}
+	}
+	return nil
+}
+
+// EstimatedComparisons returns the Ford–Johnson worst case, the sum of ⌈log₂(3k/4)⌉ for k = 1..n
+func (MergeInsertionScheduler) EstimatedComparisons(n int) int {
+	total := 0
+	for k := 1; k <= n; k++ {
+		c := 0
+		for 4<<c < 3*k {
+			c++
+		}
+		total += c
+	}
+	return total
+}
+
+// mergeInsertionSort sorts items in ascending order with the Ford–Johnson algorithm
+func mergeInsertionSort(items []int, less func(a, b int) (bool, error)) ([]int, error) {
+	if len(items) < 2 {
+		return append([]int(nil), items...), nil
+	}
+
+	// Compare items in pairs; the larger of each pair becomes a leader
+	m := len(items) / 2
+	leaders := make([]int, m)
+	partners := make(map[int]int, m)
+	for i := 0; i < m; i++ {
+		a, b := items[2*i], items[2*i+1]
+		aLess, err := less(a, b)
+		if err != nil {
+			return nil, err
+		}
+		if !aLess {
+			a, b = b, a
+		}
+		leaders[i] = b
+		partners[b] = a
+	}
+
+	// Sort the leaders recursively
+	leaders, err := mergeInsertionSort(leaders, less)
+	if err != nil {
+		return nil, err
+	}
+
+	// The partner of the smallest leader is known to precede it
+	chain := make([]int, 0, len(items))
+	chain = append(chain, partners[leaders[0]])
+	chain = append(chain, leaders...)
+
+	// Pending elements are numbered 2..m by their leader, plus an unpaired straggler as m+1
+	pending := m
+	if len(items)%2 == 1 {
+		pending = m + 1
+	}
+
+	insert := func(k int) error {
+		item := items[len(items)-1]
+		bound := len(chain)
+		if k <= m {
+			item = partners[leaders[k-1]]
+			for bound = 0; chain[bound] != leaders[k-1]; bound++ {
+			}
+		}
+
+		lo, hi := 0, bound
+		for lo < hi {
+			mid := (lo + hi) / 2
+			itemLess, err := less(item, chain[mid])
+			if err != nil {
+				return err
+			}
+			if itemLess {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+
+		chain = append(chain, 0)
+		copy(chain[lo+1:], chain[lo:])
+		chain[lo] = item
+		return nil
+	}
+
+	// Insert in groups bounded by Jacobsthal numbers (3, 5, 11, 21, ...), each group in
+	// descending order, so every binary search runs over at most 2^k - 1 elements
+	previous, current := 1, 3
+	for previous < pending {
+		for k := min(current, pending); k > previous; k-- {
+			if err := insert(k); err != nil {
+				return nil, err
+			}
+		}
+		previous, current = current, current+2*previous
+	}
+
+	return chain, nil
+}
+
+// SwissScheduler plays rounds in which features with similar scores are paired,
+// never repeating a pair. With an odd number of features one sits out each round.
+type SwissScheduler struct {
+	// Rounds is the number of rounds to play; zero uses ⌈log₂ n⌉ + 1
+	Rounds int
+}
+
+// Strategy returns the scheduling strategy
+func (SwissScheduler) Strategy() SchedulingStrategy {
+	return SchedulingSwiss
+}
+
+// rounds returns the number of rounds for n features
+func (s SwissScheduler) rounds(n int) int {
+	if s.Rounds > 0 {
+		return min(s.Rounds, n-1)
+	}
+	if n < 2 {
+		return 0
+	}
+	return min(int(math.Ceil(math.Log2(float64(n))))+1, n-1)
+}
+
+// NextPairs pairs features for the next round, or returns nothing once all rounds are played
+func (s SwissScheduler) NextPairs(featureIDs []int, comparisons []SessionComparison) []ScheduledPair {
+	played := 0
+	for _, comparison := range comparisons {
+		played = max(played, comparison.Round)
+	}
+	if played >= s.rounds(len(featureIDs)) {
+		return nil
+	}
+
+	scores := make(map[int]float64, len(featureIDs))
+	for _, comparison := range comparisons {
+		if !comparison.ConsensusReached {
+			continue
+		}
+		if comparison.IsTie {
+			scores[comparison.FeatureAID] += 0.5
+			scores[comparison.FeatureBID] += 0.5
+		} else if comparison.WinnerID != nil {
+			scores[*comparison.WinnerID]++
+		}
+	}
+
+	standings := append([]int(nil), featureIDs...)
+	sort.SliceStable(standings, func(i, j int) bool {
+		return scores[standings[i]] > scores[standings[j]]
+	})
+
+	compared := comparedPairs(comparisons)
+	paired := make(map[int]bool, len(standings))
+
+	var pairs []ScheduledPair
+	for i, a := range standings {
+		if paired[a] {
+			continue
+		}
+		for _, b := range standings[i+1:] {
+			if paired[b] {
+				continue
+			}
+			if _, done := compared[pairKey(a, b)]; done {
+				continue
+			}
+			paired[a], paired[b] = true, true
+			pairs = append(pairs, ScheduledPair{FeatureAID: a, FeatureBID: b})
+			break
+		}
+	}
+	return pairs
+}
+
+// EstimatedComparisons returns the number of rounds times ⌊n/2⌋
+func (s SwissScheduler) EstimatedComparisons(n int) int {
+	return s.rounds(n) * (n / 2)
+}
+
+// ActiveLearningScheduler fits a Bradley–Terry model to the results so far and schedules
+// the uncompared pair whose outcome is most uncertain, preferring features that have been
+// compared least. It stops after Budget comparisons.
+type ActiveLearningScheduler struct {
+	// Budget is the number of comparisons to collect; zero uses n⌈log₂ n⌉
+	Budget int
+}
+
+// Strategy returns the scheduling strategy
+func (ActiveLearningScheduler) Strategy() SchedulingStrategy {
+	return SchedulingActive
+}
+
+// NextPairs returns the most informative uncompared pair, or nothing once the budget is spent
+func (s ActiveLearningScheduler) NextPairs(featureIDs []int, comparisons []SessionComparison) []ScheduledPair {
+	if len(comparisons) >= s.EstimatedComparisons(len(featureIDs)) {
+		return nil
+	}
+
+	index := make(map[int]int, len(featureIDs))
+	for i, featureID := range featureIDs {
+		index[featureID] = i
+	}
+
+	exposure := make([]int, len(featureIDs))
+	var results []PairwiseComparison
+	for _, comparison := range comparisons {
+		if a, ok := index[comparison.FeatureAID]; ok {
+			exposure[a]++
+		}
+		if b, ok := index[comparison.FeatureBID]; ok {
+			exposure[b]++
+		}
+		if result, ok := comparison.ToPairwiseComparison(CriterionValue); ok {
+			results = append(results, result)
+		}
+	}
+
+	strengths := NewBradleyTerryModel().fit(index, results, CriterionValue)
+	compared := comparedPairs(comparisons)
+
+	var best *ScheduledPair
+	bestInformation, bestExposure := -1.0, 0
+	for i := 0; i < len(featureIDs); i++ {
+		for j := i + 1; j < len(featureIDs); j++ {
+			if _, done := compared[pairKey(featureIDs[i], featureIDs[j])]; done {
+				continue
+			}
+
+			// Fisher information of a single Bradley–Terry comparison
+			p := strengths[i] / (strengths[i] + strengths[j])
+			information := p * (1 - p)
+			pairExposure := exposure[i] + exposure[j]
+
+			if information > bestInformation+1e-9 ||
+				(math.Abs(information-bestInformation) <= 1e-9 && pairExposure < bestExposure) {
+				best = &ScheduledPair{FeatureAID: featureIDs[i], FeatureBID: featureIDs[j]}
+				bestInformation, bestExposure = information, pairExposure
+			}
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+	return []ScheduledPair{*best}
+}
+
+// EstimatedComparisons returns the comparison budget for n features
+func (s ActiveLearningScheduler) EstimatedComparisons(n int) int {
+	all := n * (n - 1) / 2
+	if s.Budget > 0 {
+		return min(s.Budget, all)
+	}
+	if n < 2 {
+		return 0
+	}
+	return min(n*int(math.Ceil(math.Log2(float64(n)))), all)
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// simulateSchedule runs a scheduler to completion, deciding every comparison in favor of the
+// feature that comes first in preferred
+func simulateSchedule(t *testing.T, scheduler ComparisonScheduler, featureIDs, preferred []int) []SessionComparison {
+	t.Helper()
+
+	position := make(map[int]int, len(preferred))
+	for i, featureID := range preferred {
+		position[featureID] = i
+	}
+
+	var comparisons []SessionComparison
+	seen := make(map[[2]int]bool)
+	for round := 1; ; round++ {
+		pairs := scheduler.NextPairs(featureIDs, comparisons)
+		if len(pairs) == 0 {
+			return comparisons
+		}
+
+		for _, pair := range pairs {
+			key := pairKey(pair.FeatureAID, pair.FeatureBID)
+			if seen[key] {
+				t.Fatalf("Pair %v scheduled twice", key)
+			}
+			seen[key] = true
+
+			winner := pair.FeatureAID
+			if position[pair.FeatureBID] < position[winner] {
+				winner = pair.FeatureBID
+			}
+			comparisons = append(comparisons, SessionComparison{
+				ID:               len(comparisons) + 1,
+				FeatureAID:       pair.FeatureAID,
+				FeatureBID:       pair.FeatureBID,
+				WinnerID:         &winner,
+				ConsensusReached: true,
+				Round:            round,
+			})
+		}
+	}
+}
+
+// Test that merge-insertion recovers the full preference order within its comparison bound
+func TestMergeInsertionScheduler(t *testing.T) {
+	for _, n := range []int{2, 3, 5, 8, 13, 20} {
+		featureIDs := make([]int, n)
+		preferred := make([]int, n)
+		for i := range featureIDs {
+			featureIDs[i] = i + 1
+			// Interleave so the preferred order differs from ID order
+			preferred[i] = (i*7)%n + 1
+		}
+
+		scheduler := MergeInsertionScheduler{}
+		comparisons := simulateSchedule(t, scheduler, featureIDs, preferred)
+
+		if len(comparisons) > scheduler.EstimatedComparisons(n) {
+			t.Errorf("n=%d: expected at most %d comparisons, used %d", n, scheduler.EstimatedComparisons(n), len(comparisons))
+		}
+
+		compared := comparedPairs(comparisons)
+		sorted, err := mergeInsertionSort(featureIDs, func(a, b int) (bool, error) {
+			comparison := compared[pairKey(a, b)]
+			return *comparison.WinnerID == b, nil
+		})
+		if err != nil {
+			t.Fatalf("n=%d: unexpected error: %v", n, err)
+		}
+
+		// Ascending order puts the least preferred feature first
+		for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		}
+		if !reflect.DeepEqual(sorted, preferred) {
+			t.Errorf("n=%d: expected order %v, got %v", n, preferred, sorted)
+		}
+	}
+}
+
+// Test comparison counts of each strategy for a large backlog
+func TestSchedulerComparisonCounts(t *testing.T) {
+	const n = 60
+	featureIDs := make([]int, n)
+	for i := range featureIDs {
+		featureIDs[i] = i + 1
+	}
+	preferred := make([]int, n)
+	for i := range preferred {
+		preferred[i] = n - i
+	}
+
+	tests := []struct {
+		name      string
+		scheduler ComparisonScheduler
+		expected  int
+	}{
+		{name: "Round robin compares every pair", scheduler: RoundRobinScheduler{}, expected: 1770},
+		{name: "Swiss plays seven rounds", scheduler: SwissScheduler{}, expected: 7 * 30},
+		{name: "Active learning spends its budget", scheduler: ActiveLearningScheduler{}, expected: 360},
+		{name: "Custom Swiss rounds", scheduler: SwissScheduler{Rounds: 3}, expected: 90},
+		{name: "Custom active budget", scheduler: ActiveLearningScheduler{Budget: 100}, expected: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparisons := simulateSchedule(t, tt.scheduler, featureIDs, preferred)
+			if len(comparisons) != tt.expected {
+				t.Errorf("Expected %d comparisons, got %d", tt.expected, len(comparisons))
+			}
+			if estimate := tt.scheduler.EstimatedComparisons(n); estimate != tt.expected {
+				t.Errorf("Expected estimate %d, got %d", tt.expected, estimate)
+			}
+		})
+	}
+}
+
+// Test that Swiss rounds pair features with equal scores
+func TestSwissSchedulerPairsByScore(t *testing.T) {
+	featureIDs := []int{1, 2, 3, 4}
+	winner1, winner3 := 1, 3
+	comparisons := []SessionComparison{
+		{ID: 1, FeatureAID: 1, FeatureBID: 2, WinnerID: &winner1, ConsensusReached: true, Round: 1},
+		{ID: 2, FeatureAID: 3, FeatureBID: 4, WinnerID: &winner3, ConsensusReached: true, Round: 1},
+	}
+
+	pairs := SwissScheduler{}.NextPairs(featureIDs, comparisons)
+	expected := []ScheduledPair{{FeatureAID: 1, FeatureBID: 3}, {FeatureAID: 2, FeatureBID: 4}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected pairs %v, got %v", expected, pairs)
+	}
+}
+
+// Test that active learning starts with the least exposed features
+func TestActiveLearningSchedulerPrefersUnexposedPairs(t *testing.T) {
+	featureIDs := []int{1, 2, 3, 4}
+	winner := 1
+	comparisons := []SessionComparison{
+		{ID: 1, FeatureAID: 1, FeatureBID: 2, WinnerID: &winner, ConsensusReached: true, Round: 1},
+	}
+
+	pairs := ActiveLearningScheduler{}.NextPairs(featureIDs, comparisons)
+	expected := []ScheduledPair{{FeatureAID: 3, FeatureBID: 4}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected pairs %v, got %v", expected, pairs)
+	}
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// OIDCLoginTTL is how long a started OpenID Connect login may take to come back
+const OIDCLoginTTL = 10 * time.Minute
+
+// Errors for OpenID Connect logins
+var (
+	ErrOIDCNotConfigured = errors.New("OpenID Connect login is not configured")
+	ErrOIDCLoginState    = errors.New("OpenID Connect login state is missing, expired or does not match")
+)
+
+// User is a facilitator account signed in through OpenID Connect. Attendees only exist
+// within one project, while a user owns projects and acts in each one through a facilitator
+// attendee linked to the account.
+type User struct {
+	ID          int        `json:"id" db:"id"`
+	Issuer      string     `json:"issuer" db:"issuer" gorm:"uniqueIndex:idx_users_identity"`
+	Subject     string     `json:"subject" db:"subject" gorm:"uniqueIndex:idx_users_identity"`
+	Email       string     `json:"email,omitempty" db:"email"`
+	Name        string     `json:"name" db:"name"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for GORM
+func (User) TableName() string {
+	return "users"
+}
+
+// UserProfile is a user with the projects they own
+type UserProfile struct {
+	*User
+	Projects []Project `json:"projects"`
+}
+
+// OIDCIdentity is who an OpenID provider says signed in, taken from a verified ID token
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// DisplayName picks a name for a user from the identity claims
+func (i OIDCIdentity) DisplayName() string {
+	switch {
+	case i.Name != "":
+		return i.Name
+	case i.Email != "":
+		return i.Email
+	default:
+		return i.Subject
+	}
+}
+
+// UserClaims are the claims carried by a user session token. Unlike TokenClaims they are not
+// scoped to a project; users trade them for a facilitator token in a project they own.
+type UserClaims struct {
+	UserID    int   `json:"uid"`
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+// Expired reports whether the claims have expired at the given time
+func (c UserClaims) Expired(now time.Time) bool {
+	return now.Unix() >= c.ExpiresAt
+}
+
+// ExpiresAtTime returns the expiry as a time
+func (c UserClaims) ExpiresAtTime() time.Time {
+	return time.Unix(c.ExpiresAt, 0).UTC()
+}
+
+// OIDCLoginState is what the server remembers between sending a browser to the OpenID
+// provider and the provider sending it back. It travels in a signed cookie.
+type OIDCLoginState struct {
+	State        string `json:"st"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"cv"`
+	ExpiresAt    int64  `json:"exp"`
+}
@@ -0,0 +1,326 @@
+// Package fakeidp is an in-process OpenID provider for tests and local development. It
+// serves discovery, authorization, token and key set endpoints on a loopback listener and
+// signs in a configured user without asking for credentials.
+package fakeidp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// codeTTL is how long an authorization code can be exchanged
+const codeTTL = time.Minute
+
+// idTokenTTL is the lifetime of issued ID tokens
+const idTokenTTL = 5 * time.Minute
+
+// User is the identity the provider signs in
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// authorization is an issued authorization code waiting to be exchanged
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	user          User
+	expiresAt     time.Time
+}
+
+// IdP is a fake OpenID provider. Every authorization request signs in the current user.
+type IdP struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	keyID        string
+	clientID     string
+	clientSecret string
+
+	mu    sync.Mutex
+	user  User
+	codes map[string]authorization
+
+	// ClaimsHook, when set, may change the claims of each ID token before it is signed, so
+	// that tests can check how bad tokens are rejected
+	ClaimsHook func(claims map[string]interface{})
+}
+
+// New starts a fake provider for one registered client
+func New(clientID, clientSecret string) (*IdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	idp := &IdP{
+		key:          key,
+		keyID:        "fake-1",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		user:         User{Subject: "facilitator", Email: "facilitator@example.com", EmailVerified: true, Name: "Fake Facilitator"},
+		codes:        make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.handleDiscovery)
+	mux.HandleFunc("/authorize", idp.handleAuthorize)
+	mux.HandleFunc("/token", idp.handleToken)
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	idp.server = httptest.NewServer(mux)
+
+	return idp, nil
+}
+
+// Issuer returns the provider's issuer URL
+func (f *IdP) Issuer() string {
+	return f.server.URL
+}
+
+// Client returns an HTTP client for talking to the provider
+func (f *IdP) Client() *http.Client {
+	return f.server.Client()
+}
+
+// SetUser changes who the provider signs in
+func (f *IdP) SetUser(user User) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.user = user
+}
+
+// Close stops the provider
+func (f *IdP) Close() {
+	f.server.Close()
+}
+
+// SignIDToken signs arbitrary ID token claims with the provider's key
+func (f *IdP) SignIDToken(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": f.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// handleDiscovery serves the discovery document
+func (f *IdP) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                f.Issuer(),
+		"authorization_endpoint":                f.Issuer() + "/authorize",
+		"token_endpoint":                        f.Issuer() + "/token",
+		"jwks_uri":                              f.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// handleAuthorize signs in the current user and redirects back with a code
+func (f *IdP) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+
+	switch {
+	case query.Get("client_id") != f.clientID:
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	case redirectURI == "":
+		http.Error(w, "missing redirect_uri", http.StatusBadRequest)
+		return
+	case query.Get("response_type") != "code":
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	case !strings.Contains(" "+query.Get("scope")+" ", " openid "):
+		http.Error(w, "missing openid scope", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge") != "" && query.Get("code_challenge_method") != "S256":
+		http.Error(w, "unsupported code_challenge_method", http.StatusBadRequest)
+		return
+	}
+
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	f.mu.Lock()
+	f.codes[code] = authorization{
+		clientID:      f.clientID,
+		redirectURI:   redirectURI,
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		user:          f.user,
+		expiresAt:     time.Now().Add(codeTTL),
+	}
+	f.mu.Unlock()
+
+	callback, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	values := callback.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	callback.RawQuery = values.Encode()
+
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+// handleToken exchanges a code for an ID token
+func (f *IdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		tokenError(w, http.StatusMethodNotAllowed, "invalid_request")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != f.clientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(f.clientSecret)) != 1 {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	// Codes can only be used once
+	code := r.PostForm.Get("code")
+	f.mu.Lock()
+	auth, found := f.codes[code]
+	delete(f.codes, code)
+	f.mu.Unlock()
+
+	switch {
+	case !found || time.Now().After(auth.expiresAt):
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	case auth.redirectURI != r.PostForm.Get("redirect_uri"):
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	case auth.codeChallenge != "" && auth.codeChallenge != codeChallenge(r.PostForm.Get("code_verifier")):
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":            f.Issuer(),
+		"sub":            auth.user.Subject,
+		"aud":            auth.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(idTokenTTL).Unix(),
+		"email":          auth.user.Email,
+		"email_verified": auth.user.EmailVerified,
+		"name":           auth.user.Name,
+	}
+	if auth.nonce != "" {
+		claims["nonce"] = auth.nonce
+	}
+	if f.ClaimsHook != nil {
+		f.ClaimsHook(claims)
+	}
+
+	idToken, err := f.SignIDToken(claims)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	accessToken, err := randomString()
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(idTokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+// handleJWKS serves the provider's public signing key
+func (f *IdP) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": f.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(f.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(f.key.E)).Bytes()),
+		}},
+	})
+}
+
+// tokenError writes an OAuth error response
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// randomString returns a random base64url string for codes and access tokens
+func randomString() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// codeChallenge returns the S256 PKCE challenge for a code verifier
+func codeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,412 @@
+// Package oidc implements the relying party side of the OpenID Connect authorization code
+// flow: provider discovery, the code exchange with PKCE and ID token verification.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"pairwise/internal/domain"
+)
+
+// clockSkew is how far the provider's clock may be off when checking token times
+const clockSkew = time.Minute
+
+// maxResponseBytes bounds the documents read from the provider
+const maxResponseBytes = 1 << 20
+
+// keyRefreshInterval is the least time between fetches of the provider's signing keys when
+// a token names an unknown key
+const keyRefreshInterval = time.Minute
+
+// ErrInvalidIDToken is returned for ID tokens that fail verification
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// Config configures a relying party registered with an OpenID provider
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string // Defaults to openid, email and profile
+}
+
+// providerMetadata is the part of the discovery document the flow needs
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is an OpenID provider as seen by this server. Its discovery document and signing
+// keys are fetched on first use, so the server starts even when the provider is down.
+type Provider struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	metadata    *providerMetadata
+	keys        map[string]*rsa.PublicKey
+	keysFetched time.Time
+}
+
+// NewProvider creates a provider for a relying party configuration. A nil client uses
+// http.DefaultClient.
+func NewProvider(config Config, client *http.Client) *Provider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	config.IssuerURL = strings.TrimSuffix(config.IssuerURL, "/")
+
+	return &Provider{
+		config: config,
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// AuthCodeURL returns the provider URL that starts a login. The state and nonce tie the
+// callback and ID token to this login, and the code challenge binds the code to the
+// verifier only this server knows.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// tokenResponse is the provider's answer to a code exchange
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange trades an authorization code for tokens and returns the identity in the verified
+// ID token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*domain.OIDCIdentity, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("invalid token response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK || tokens.Error != "" {
+		return nil, fmt.Errorf("token request rejected (status %d): %s %s", resp.StatusCode, tokens.Error, tokens.ErrorDescription)
+	}
+
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no ID token", ErrInvalidIDToken)
+	}
+
+	return p.VerifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+// idTokenHeader is the JOSE header of an ID token
+type idTokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// idTokenClaims are the ID token claims the flow checks or uses
+type idTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   bool     `json:"email_verified"`
+	Name            string   `json:"name"`
+}
+
+// audience is the aud claim, which is either one string or a list of them
+type audience []string
+
+// UnmarshalJSON accepts both forms of the aud claim
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// contains reports whether the audience includes a client
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyIDToken checks an RS256 ID token's signature, issuer, audience, lifetime and nonce
+// and returns the identity it asserts
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*domain.OIDCIdentity, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+
+	var header idTokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidIDToken)
+	}
+	if header.Algorithm != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidIDToken, header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidIDToken)
+	}
+
+	key, err := p.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidIDToken)
+	}
+
+	var claims idTokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidIDToken)
+	}
+
+	if err := p.checkClaims(claims, nonce); err != nil {
+		return nil, err
+	}
+
+	return &domain.OIDCIdentity{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// checkClaims checks the claims of a token whose signature is valid
+func (p *Provider) checkClaims(claims idTokenClaims, nonce string) error {
+	now := p.now()
+
+	switch {
+	case claims.Issuer != p.config.IssuerURL:
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	case claims.Subject == "":
+		return fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	case !claims.Audience.contains(p.config.ClientID):
+		return fmt.Errorf("%w: token is not for this client", ErrInvalidIDToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID:
+		return fmt.Errorf("%w: token was not issued to this client", ErrInvalidIDToken)
+	case claims.ExpiresAt == 0 || now.Add(-clockSkew).Unix() >= claims.ExpiresAt:
+		return fmt.Errorf("%w: token has expired", ErrInvalidIDToken)
+	case claims.IssuedAt > now.Add(clockSkew).Unix():
+		return fmt.Errorf("%w: token was issued in the future", ErrInvalidIDToken)
+	case nonce == "" || claims.Nonce != nonce:
+		return fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+
+	return nil
+}
+
+// discover fetches and caches the provider's discovery document
+func (p *Provider) discover(ctx context.Context) (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata providerMetadata
+	if err := p.getJSON(ctx, p.config.IssuerURL+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("provider discovery failed: %w", err)
+	}
+
+	if metadata.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("provider discovery failed: issuer %q does not match %q", metadata.Issuer, p.config.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("provider discovery failed: missing endpoints")
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// jsonWebKey is an RSA key from the provider's key set
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// key returns the provider's signing key with an ID, fetching the key set again when the
+// key is unknown, since providers rotate keys
+func (p *Provider) key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[keyID]; ok {
+		return key, nil
+	}
+
+	if p.keys != nil && p.now().Sub(p.keysFetched) < keyRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidIDToken, keyID)
+	}
+
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, metadata.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	p.keys = make(map[string]*rsa.PublicKey)
+	p.keysFetched = p.now()
+	for _, jwk := range keySet.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		if key, err := jwk.rsaPublicKey(); err == nil {
+			p.keys[jwk.KeyID] = key
+		}
+	}
+
+	if key, ok := p.keys[keyID]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidIDToken, keyID)
+}
+
+// rsaPublicKey decodes the modulus and exponent of an RSA key
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// getJSON fetches and decodes a JSON document from the provider
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(v)
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,184 @@
+package oidc_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"pairwise/internal/oidc"
+	"pairwise/internal/oidc/fakeidp"
+)
+
+const redirectURL = "http://localhost/api/auth/oidc/callback"
+
+// authorize runs the browser half of a login against the fake provider and returns the code
+func authorize(t *testing.T, idp *fakeidp.IdP, provider *oidc.Provider, nonce, verifier string) string {
+	t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		t.Fatalf("Failed to build authorization URL: %v", err)
+	}
+
+	client := *idp.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("Authorization request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(callback.String(), redirectURL) {
+		t.Fatalf("Unexpected redirect %q", resp.Header.Get("Location"))
+	}
+	if state := callback.Query().Get("state"); state != "state-1" {
+		t.Fatalf("Expected state to round-trip, got %q", state)
+	}
+	return callback.Query().Get("code")
+}
+
+// TestLoginFlow tests the authorization code flow against the in-process fake provider
+func TestLoginFlow(t *testing.T) {
+	idp, err := fakeidp.New("pairwise", "secret")
+	if err != nil {
+		t.Fatalf("Failed to start fake provider: %v", err)
+	}
+	defer idp.Close()
+
+	newProvider := func(clientID, secret string) *oidc.Provider {
+		return oidc.NewProvider(oidc.Config{
+			IssuerURL:    idp.Issuer(),
+			ClientID:     clientID,
+			ClientSecret: secret,
+			RedirectURL:  redirectURL,
+		}, idp.Client())
+	}
+	provider := newProvider("pairwise", "secret")
+
+	t.Run("Valid login", func(t *testing.T) {
+		code := authorize(t, idp, provider, "nonce-1", "verifier-1")
+		identity, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if identity.Issuer != idp.Issuer() || identity.Subject != "facilitator" || !identity.EmailVerified {
+			t.Errorf("Unexpected identity %+v", identity)
+		}
+		if identity.DisplayName() != "Fake Facilitator" {
+			t.Errorf("Expected display name from the name claim, got %q", identity.DisplayName())
+		}
+	})
+
+	t.Run("Code reused", func(t *testing.T) {
+		code := authorize(t, idp, provider, "nonce-1", "verifier-1")
+		if _, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1"); err == nil {
+			t.Error("Expected a reused code to be rejected")
+		}
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		code := authorize(t, idp, provider, "nonce-1", "verifier-1")
+		if _, err := provider.Exchange(context.Background(), code, "verifier-2", "nonce-1"); err == nil {
+			t.Error("Expected a mismatched PKCE verifier to be rejected")
+		}
+	})
+
+	t.Run("Wrong client secret", func(t *testing.T) {
+		other := newProvider("pairwise", "wrong")
+		code := authorize(t, idp, other, "nonce-1", "verifier-1")
+		if _, err := other.Exchange(context.Background(), code, "verifier-1", "nonce-1"); err == nil {
+			t.Error("Expected client authentication to fail")
+		}
+	})
+
+	t.Run("Wrong nonce", func(t *testing.T) {
+		code := authorize(t, idp, provider, "nonce-1", "verifier-1")
+		if _, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-2"); err == nil {
+			t.Error("Expected a mismatched nonce to be rejected")
+		}
+	})
+
+	tamperTests := []struct {
+		name   string
+		tamper func(claims map[string]interface{})
+	}{
+		{name: "Other audience", tamper: func(claims map[string]interface{}) { claims["aud"] = "someone-else" }},
+		{name: "Other issuer", tamper: func(claims map[string]interface{}) { claims["iss"] = "https://evil.example.com" }},
+		{name: "Expired", tamper: func(claims map[string]interface{}) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "Missing subject", tamper: func(claims map[string]interface{}) { delete(claims, "sub") }},
+	}
+
+	for _, tt := range tamperTests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.ClaimsHook = tt.tamper
+			defer func() { idp.ClaimsHook = nil }()
+
+			code := authorize(t, idp, provider, "nonce-1", "verifier-1")
+			if _, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1"); err == nil {
+				t.Error("Expected the ID token to be rejected")
+			}
+		})
+	}
+}
+
+// TestVerifyIDToken tests that only ID tokens signed by the provider's key are accepted
+func TestVerifyIDToken(t *testing.T) {
+	idp, err := fakeidp.New("pairwise", "secret")
+	if err != nil {
+		t.Fatalf("Failed to start fake provider: %v", err)
+	}
+	defer idp.Close()
+
+	provider := oidc.NewProvider(oidc.Config{IssuerURL: idp.Issuer(), ClientID: "pairwise"}, idp.Client())
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":   idp.Issuer(),
+		"sub":   "user-1",
+		"aud":   []string{"pairwise", "other"},
+		"azp":   "pairwise",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+		"nonce": "nonce-1",
+	}
+
+	token, err := idp.SignIDToken(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), token, "nonce-1"); err != nil {
+		t.Fatalf("Expected a valid token with several audiences, got %v", err)
+	}
+
+	header, payload, _ := strings.Cut(token, ".")
+	payload, signature, _ := strings.Cut(payload, ".")
+	unsigned := func(alg string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"`+alg+`","typ":"JWT"}`)) + "." + payload
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Tampered payload", token: header + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + signature},
+		{name: "Tampered signature", token: header + "." + payload + "." + signature[:len(signature)-4] + "AAAA"},
+		{name: "Algorithm none", token: unsigned("none") + "."},
+		{name: "Algorithm HS256", token: unsigned("HS256") + "." + signature},
+		{name: "Malformed", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := provider.VerifyIDToken(context.Background(), tt.token, "nonce-1"); err == nil {
+				t.Error("Expected the ID token to be rejected")
+			}
+		})
+	}
+}
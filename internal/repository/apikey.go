@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+
+	"pairwise/internal/domain"
+)
+
+// APIKeyRepository handles database operations for API keys
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// apiKeyColumns are the columns scanned by scanAPIKey
+const apiKeyColumns = `id, project_id, name, prefix, key_hash, scopes, last_used_at, revoked_at, created_by, created_at`
+
+// Create stores an API key by its hash
+func (r *APIKeyRepository) Create(key domain.APIKey) (*domain.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (project_id, name, prefix, key_hash, scopes, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
+		RETURNING ` + apiKeyColumns
+
+	return scanAPIKey(r.db.QueryRow(query, key.ProjectID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.CreatedBy))
+}
+
+// GetByID retrieves an API key by ID
+func (r *APIKeyRepository) GetByID(id int) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return key, err
+}
+
+// GetByHash retrieves an API key by the hash of the key
+func (r *APIKeyRepository) GetByHash(keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return key, err
+}
+
+// GetAll retrieves every API key, global keys first
+func (r *APIKeyRepository) GetAll() ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY project_id IS NOT NULL, project_id, id`
+	return r.queryAPIKeys(query)
+}
+
+// GetByProjectID retrieves the API keys scoped to a project
+func (r *APIKeyRepository) GetByProjectID(projectID int) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE project_id = ? ORDER BY id`
+	return r.queryAPIKeys(query, projectID)
+}
+
+// TouchLastUsed records that a key was used. It writes at most once a minute per key so that
+// busy integrations do not turn every request into a write.
+func (r *APIKeyRepository) TouchLastUsed(id int) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = datetime('now')
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < datetime('now', '-1 minute'))
+	`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// Revoke marks an API key as revoked. Revoking an already revoked key keeps the first time.
+func (r *APIKeyRepository) Revoke(id int) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, datetime('now'))
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// queryAPIKeys runs a query selecting apiKeyColumns
+func (r *APIKeyRepository) queryAPIKeys(query string, args ...interface{}) ([]domain.APIKey, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// scanAPIKey scans a row selected with apiKeyColumns
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.ProjectID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
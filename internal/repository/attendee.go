@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"pairwise/internal/domain"
 )
@@ -16,21 +17,24 @@ func NewAttendeeRepository(db *sql.DB) *AttendeeRepository {
 	return &AttendeeRepository{db: db}
 }
 
-// Create creates a new attendee for a project
-func (r *AttendeeRepository) Create(projectID int, req domain.CreateAttendeeRequest) (*domain.Attendee, error) {
+// Create creates a new attendee for a project with an already hashed PIN
+func (r *AttendeeRepository) Create(projectID int, req domain.CreateAttendeeRequest, pinHash string) (*domain.Attendee, error) {
 	query := `
-		INSERT INTO attendees (project_id, name, role, is_facilitator, created_at)
-		VALUES (?, ?, ?, ?, datetime('now'))
-		RETURNING id, project_id, name, role, is_facilitator, created_at
+		INSERT INTO attendees (project_id, name, role, is_facilitator, is_observer, pin_hash, failed_logins, invite_id, user_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, datetime('now'))
+		RETURNING id, project_id, name, role, is_facilitator, is_observer, invite_id, user_id, created_at
 	`
 
 	var attendee domain.Attendee
-	err := r.db.QueryRow(query, projectID, req.Name, req.Role, req.IsFacilitator).Scan(
+	err := r.db.QueryRow(query, projectID, req.Name, req.Role, req.IsFacilitator, req.IsObserver, pinHash, req.InviteID, req.UserID).Scan(
 		&attendee.ID,
 		&attendee.ProjectID,
 		&attendee.Name,
 		&attendee.Role,
 		&attendee.IsFacilitator,
+		&attendee.IsObserver,
+		&attendee.InviteID,
+		&attendee.UserID,
 		&attendee.CreatedAt,
 	)
 
@@ -38,13 +42,16 @@ func (r *AttendeeRepository) Create(projectID int, req domain.CreateAttendeeRequ
 		return nil, err
 	}
 
+	attendee.PinHash = pinHash
 	return &attendee, nil
 }
 
-// GetByID retrieves an attendee by ID
+// GetByID retrieves an attendee by ID, including the PIN hash and login state
 func (r *AttendeeRepository) GetByID(id int) (*domain.Attendee, error) {
 	query := `
-		SELECT id, project_id, name, role, is_facilitator, created_at
+		SELECT id, project_id, name, role, is_facilitator, COALESCE(is_observer, FALSE),
+		       COALESCE(pin_hash, ''), COALESCE(failed_logins, 0), locked_until, COALESCE(token_version, 0),
+		       invite_id, user_id, created_at
 		FROM attendees
 		WHERE id = ?
 	`
@@ -56,6 +63,13 @@ func (r *AttendeeRepository) GetByID(id int) (*domain.Attendee, error) {
 		&attendee.Name,
 		&attendee.Role,
 		&attendee.IsFacilitator,
+		&attendee.IsObserver,
+		&attendee.PinHash,
+		&attendee.FailedLogins,
+		&attendee.LockedUntil,
+		&attendee.TokenVersion,
+		&attendee.InviteID,
+		&attendee.UserID,
 		&attendee.CreatedAt,
 	)
 
@@ -72,7 +86,8 @@ func (r *AttendeeRepository) GetByID(id int) (*domain.Attendee, error) {
 // GetByProjectID retrieves all attendees for a project
 func (r *AttendeeRepository) GetByProjectID(projectID int) ([]domain.Attendee, error) {
 	query := `
-		SELECT id, project_id, name, role, is_facilitator, created_at
+		SELECT id, project_id, name, role, is_facilitator, COALESCE(is_observer, FALSE),
+		       COALESCE(token_version, 0), invite_id, user_id, created_at
 		FROM attendees
 		WHERE project_id = ?
 		ORDER BY created_at ASC
@@ -93,6 +108,10 @@ func (r *AttendeeRepository) GetByProjectID(projectID int) ([]domain.Attendee, e
 			&attendee.Name,
 			&attendee.Role,
 			&attendee.IsFacilitator,
+			&attendee.IsObserver,
+			&attendee.TokenVersion,
+			&attendee.InviteID,
+			&attendee.UserID,
 			&attendee.CreatedAt,
 		)
 		if err != nil {
@@ -104,6 +123,112 @@ func (r *AttendeeRepository) GetByProjectID(projectID int) ([]domain.Attendee, e
 	return attendees, nil
 }
 
+// UpdatePINHash replaces an attendee's PIN hash and clears any login lockout
+func (r *AttendeeRepository) UpdatePINHash(id int, pinHash string) error {
+	query := `
+		UPDATE attendees
+		SET pin_hash = ?, failed_logins = 0, locked_until = NULL
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, pinHash, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// ResetPIN replaces an attendee's PIN hash, clears any login lockout and revokes the
+// attendee's session tokens by bumping their token version, which is returned
+func (r *AttendeeRepository) ResetPIN(id int, pinHash string) (int, error) {
+	query := `
+		UPDATE attendees
+		SET pin_hash = ?, failed_logins = 0, locked_until = NULL,
+			token_version = COALESCE(token_version, 0) + 1
+		WHERE id = ?
+		RETURNING token_version
+	`
+
+	var version int
+	err := r.db.QueryRow(query, pinHash, id).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// RecordFailedLogin counts a failed login in one statement, so that concurrent attempts
+// cannot overwrite each other's count. It follows Attendee.RegisterFailedLogin: counting
+// starts over once a lockout has expired, and MaxFailedLogins failures lock the attendee out
+// until lockedUntil. A lockout still in force is kept. The attendee's count and lockout
+// afterwards are set on the attendee.
+func (r *AttendeeRepository) RecordFailedLogin(attendee *domain.Attendee, now, lockedUntil time.Time) error {
+	query := `
+		UPDATE attendees
+		SET failed_logins = CASE WHEN locked_until <= ? THEN 0 ELSE COALESCE(failed_logins, 0) END + 1,
+			locked_until = CASE
+				WHEN locked_until > ? THEN locked_until
+				WHEN CASE WHEN locked_until <= ? THEN 0 ELSE COALESCE(failed_logins, 0) END + 1 >= ? THEN ?
+			END
+		WHERE id = ?
+		RETURNING failed_logins, locked_until
+	`
+
+	var locked sql.NullTime
+	err := r.db.QueryRow(query,
+		now, now, now, domain.MaxFailedLogins, lockedUntil, attendee.ID,
+	).Scan(&attendee.FailedLogins, &locked)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	attendee.LockedUntil = nil
+	if locked.Valid {
+		attendee.LockedUntil = &locked.Time
+	}
+	return nil
+}
+
+// RecordSuccessfulLogin clears an attendee's failed login count unless a lockout is in
+// force, which may have started since the attendee was read. It reports whether the login
+// was allowed.
+func (r *AttendeeRepository) RecordSuccessfulLogin(id int, now time.Time) (bool, error) {
+	query := `
+		UPDATE attendees
+		SET failed_logins = 0, locked_until = NULL
+		WHERE id = ? AND (locked_until IS NULL OR locked_until <= ?)
+	`
+
+	result, err := r.db.Exec(query, id, now)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
 // Delete deletes an attendee
 func (r *AttendeeRepository) Delete(id int) error {
 	query := `DELETE FROM attendees WHERE id = ?`
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"pairwise/internal/domain"
+)
+
+// AuditRepository handles database operations for audit events. Events can only be
+// appended and read; nothing updates or deletes them.
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create appends an audit event
+func (r *AuditRepository) Create(event domain.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (project_id, actor_type, actor_id, action, entity_type, entity_id,
+		                          before_state, after_state, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	`
+
+	_, err := r.db.Exec(query,
+		event.ProjectID, event.ActorType, event.ActorID, event.Action, event.EntityType, event.EntityID,
+		nullableJSON(event.Before), nullableJSON(event.After), event.RequestID)
+	return err
+}
+
+// List returns a page of a project's audit events matching the filter, newest first, and
+// the number of matching events
+func (r *AuditRepository) List(filter domain.AuditFilter) ([]domain.AuditEvent, int, error) {
+	conditions := []string{"project_id = ?"}
+	args := []interface{}{filter.ProjectID}
+
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, *filter.EntityID)
+	}
+	if filter.ActorType != "" {
+		conditions = append(conditions, "actor_type = ?")
+		args = append(args, filter.ActorType)
+	}
+	if filter.ActorID != nil {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, *filter.ActorID)
+	}
+	if filter.Since != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.Until.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM audit_events`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, project_id, actor_type, actor_id, action, entity_type, entity_id,
+		       before_state, after_state, COALESCE(request_id, ''), created_at
+		FROM audit_events` + where + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var events []domain.AuditEvent
+	for rows.Next() {
+		var event domain.AuditEvent
+		var before, after sql.NullString
+		err := rows.Scan(
+			&event.ID,
+			&event.ProjectID,
+			&event.ActorType,
+			&event.ActorID,
+			&event.Action,
+			&event.EntityType,
+			&event.EntityID,
+			&before,
+			&after,
+			&event.RequestID,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if before.Valid {
+			event.Before = []byte(before.String)
+		}
+		if after.Valid {
+			event.After = []byte(after.String)
+		}
+		events = append(events, event)
+	}
+
+	return events, total, rows.Err()
+}
+
+// nullableJSON stores missing states as NULL rather than an empty string
+func nullableJSON(state []byte) interface{} {
+	if len(state) == 0 {
+		return nil
+	}
+	return string(state)
+}
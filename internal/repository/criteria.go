@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+
+	"pairwise/internal/domain"
+)
+
+// CriteriaRepository handles database operations for project criteria
+type CriteriaRepository struct {
+	db *sql.DB
+}
+
+// NewCriteriaRepository creates a new criteria repository
+func NewCriteriaRepository(db *sql.DB) *CriteriaRepository {
+	return &CriteriaRepository{db: db}
+}
+
+// Create adds a criterion to a project after its existing criteria
+func (r *CriteriaRepository) Create(projectID int, criterion domain.Criterion) (*domain.Criterion, error) {
+	query := `
+		INSERT INTO project_criteria (project_id, key, name, direction, weight, position, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position) + 1, 0) FROM project_criteria WHERE project_id = ?),
+		        datetime('now'), datetime('now'))
+		RETURNING id, project_id, key, name, direction, weight, position, created_at, updated_at
+	`
+
+	var created domain.Criterion
+	err := r.db.QueryRow(query, projectID, criterion.Key, criterion.Name, criterion.Direction, criterion.Weight, projectID).Scan(
+		&created.ID,
+		&created.ProjectID,
+		&created.Key,
+		&created.Name,
+		&created.Direction,
+		&created.Weight,
+		&created.Position,
+		&created.CreatedAt,
+		&created.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// GetByID retrieves a criterion of a project by ID
+func (r *CriteriaRepository) GetByID(projectID, id int) (*domain.Criterion, error) {
+	query := `
+		SELECT id, project_id, key, name, direction, weight, position, created_at, updated_at
+		FROM project_criteria
+		WHERE project_id = ? AND id = ?
+	`
+
+	var criterion domain.Criterion
+	err := r.db.QueryRow(query, projectID, id).Scan(
+		&criterion.ID,
+		&criterion.ProjectID,
+		&criterion.Key,
+		&criterion.Name,
+		&criterion.Direction,
+		&criterion.Weight,
+		&criterion.Position,
+		&criterion.CreatedAt,
+		&criterion.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &criterion, nil
+}
+
+// GetByProjectID retrieves the criteria of a project in workflow order
+func (r *CriteriaRepository) GetByProjectID(projectID int) ([]domain.Criterion, error) {
+	query := `
+		SELECT id, project_id, key, name, direction, weight, position, created_at, updated_at
+		FROM project_criteria
+		WHERE project_id = ?
+		ORDER BY position, id
+	`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var criteria []domain.Criterion
+	for rows.Next() {
+		var criterion domain.Criterion
+		err := rows.Scan(
+			&criterion.ID,
+			&criterion.ProjectID,
+			&criterion.Key,
+			&criterion.Name,
+			&criterion.Direction,
+			&criterion.Weight,
+			&criterion.Position,
+			&criterion.CreatedAt,
+			&criterion.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, criterion)
+	}
+
+	return criteria, rows.Err()
+}
+
+// Update updates a criterion's name, direction and weight
+func (r *CriteriaRepository) Update(projectID, id int, req domain.UpdateCriterionRequest) (*domain.Criterion, error) {
+	query := `
+		UPDATE project_criteria
+		SET name = ?, direction = ?, weight = COALESCE(NULLIF(?, 0), weight), updated_at = datetime('now')
+		WHERE project_id = ? AND id = ?
+		RETURNING id, project_id, key, name, direction, weight, position, created_at, updated_at
+	`
+
+	var criterion domain.Criterion
+	err := r.db.QueryRow(query, req.Name, req.Direction, req.Weight, projectID, id).Scan(
+		&criterion.ID,
+		&criterion.ProjectID,
+		&criterion.Key,
+		&criterion.Name,
+		&criterion.Direction,
+		&criterion.Weight,
+		&criterion.Position,
+		&criterion.CreatedAt,
+		&criterion.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &criterion, nil
+}
+
+// Delete removes a criterion from a project
+func (r *CriteriaRepository) Delete(projectID, id int) error {
+	query := `DELETE FROM project_criteria WHERE project_id = ? AND id = ?`
+
+	result, err := r.db.Exec(query, projectID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,372 @@
+package repository
+
+import (
+	"database/sql"
+
+	"pairwise/internal/domain"
+)
+
+// FibonacciRepository handles database operations for Fibonacci scoring
+type FibonacciRepository struct {
+	db *sql.DB
+}
+
+// NewFibonacciRepository creates a new Fibonacci repository
+func NewFibonacciRepository(db *sql.DB) *FibonacciRepository {
+	return &FibonacciRepository{db: db}
+}
+
+// CreateSession creates a new Fibonacci scoring session
+func (r *FibonacciRepository) CreateSession(projectID int, criterionType domain.CriterionType) (*domain.FibonacciSession, error) {
+	insertQuery := `
+		INSERT INTO fibonacci_sessions (project_id, criterion_type, status, started_at)
+		VALUES (?, ?, ?, datetime('now'))
+	`
+
+	result, err := r.db.Exec(insertQuery, projectID, criterionType, domain.SessionStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetSessionByID(int(sessionID))
+}
+
+// GetSessionByID retrieves a Fibonacci session by ID
+func (r *FibonacciRepository) GetSessionByID(sessionID int) (*domain.FibonacciSession, error) {
+	query := `
+		SELECT id, project_id, criterion_type, status, started_at, completed_at
+		FROM fibonacci_sessions
+		WHERE id = ?
+	`
+
+	var session domain.FibonacciSession
+	err := r.db.QueryRow(query, sessionID).Scan(
+		&session.ID,
+		&session.ProjectID,
+		&session.CriterionType,
+		&session.Status,
+		&session.StartedAt,
+		&session.CompletedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetActiveSessionByProjectAndCriterion gets the active Fibonacci session for a project and criterion
+func (r *FibonacciRepository) GetActiveSessionByProjectAndCriterion(projectID int, criterionType domain.CriterionType) (*domain.FibonacciSession, error) {
+	query := `
+		SELECT id, project_id, criterion_type, status, started_at, completed_at
+		FROM fibonacci_sessions
+		WHERE project_id = ? AND criterion_type = ? AND status = ?
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	var session domain.FibonacciSession
+	err := r.db.QueryRow(query, projectID, criterionType, domain.SessionStatusActive).Scan(
+		&session.ID,
+		&session.ProjectID,
+		&session.CriterionType,
+		&session.Status,
+		&session.StartedAt,
+		&session.CompletedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetLatestSessionByProjectAndCriterion gets the most recently started session regardless of status
+func (r *FibonacciRepository) GetLatestSessionByProjectAndCriterion(projectID int, criterionType domain.CriterionType) (*domain.FibonacciSession, error) {
+	query := `
+		SELECT id, project_id, criterion_type, status, started_at, completed_at
+		FROM fibonacci_sessions
+		WHERE project_id = ? AND criterion_type = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var session domain.FibonacciSession
+	err := r.db.QueryRow(query, projectID, criterionType).Scan(
+		&session.ID,
+		&session.ProjectID,
+		&session.CriterionType,
+		&session.Status,
+		&session.StartedAt,
+		&session.CompletedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// CompleteSession marks a Fibonacci session as completed
+func (r *FibonacciRepository) CompleteSession(sessionID int) error {
+	query := `
+		UPDATE fibonacci_sessions
+		SET status = ?, completed_at = datetime('now')
+		WHERE id = ?
+	`
+
+	_, err := r.db.Exec(query, domain.SessionStatusCompleted, sessionID)
+	return err
+}
+
+// CreateScore creates a new attendee score for a feature
+func (r *FibonacciRepository) CreateScore(score domain.FibonacciScore) (*domain.FibonacciScore, error) {
+	insertQuery := `
+		INSERT INTO fibonacci_scores (session_id, feature_id, attendee_id, score_value, scored_at)
+		VALUES (?, ?, ?, ?, datetime('now'))
+	`
+
+	_, err := r.db.Exec(insertQuery, score.SessionID, score.FeatureID, score.AttendeeID, score.ScoreValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetScore(score.SessionID, score.FeatureID, score.AttendeeID)
+}
+
+// UpdateScore updates an existing attendee score for a feature
+func (r *FibonacciRepository) UpdateScore(score domain.FibonacciScore) error {
+	query := `
+		UPDATE fibonacci_scores
+		SET score_value = ?, scored_at = datetime('now')
+		WHERE session_id = ? AND feature_id = ? AND attendee_id = ?
+	`
+
+	_, err := r.db.Exec(query, score.ScoreValue, score.SessionID, score.FeatureID, score.AttendeeID)
+	return err
+}
+
+// GetScore retrieves an attendee's score for a feature in a session
+func (r *FibonacciRepository) GetScore(sessionID, featureID, attendeeID int) (*domain.FibonacciScore, error) {
+	query := `
+		SELECT id, session_id, feature_id, attendee_id, score_value, scored_at
+		FROM fibonacci_scores
+		WHERE session_id = ? AND feature_id = ? AND attendee_id = ?
+	`
+
+	var score domain.FibonacciScore
+	err := r.db.QueryRow(query, sessionID, featureID, attendeeID).Scan(
+		&score.ID,
+		&score.SessionID,
+		&score.FeatureID,
+		&score.AttendeeID,
+		&score.ScoreValue,
+		&score.ScoredAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &score, nil
+}
+
+// GetScoresBySessionID retrieves all attendee scores for a session
+func (r *FibonacciRepository) GetScoresBySessionID(sessionID int) ([]domain.FibonacciScore, error) {
+	query := `
+		SELECT id, session_id, feature_id, attendee_id, score_value, scored_at
+		FROM fibonacci_scores
+		WHERE session_id = ?
+		ORDER BY feature_id ASC, scored_at ASC
+	`
+
+	rows, err := r.db.Query(query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []domain.FibonacciScore
+	for rows.Next() {
+		var score domain.FibonacciScore
+		err := rows.Scan(
+			&score.ID,
+			&score.SessionID,
+			&score.FeatureID,
+			&score.AttendeeID,
+			&score.ScoreValue,
+			&score.ScoredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
+
+// GetScoresByFeature retrieves all attendee scores for a feature in a session
+func (r *FibonacciRepository) GetScoresByFeature(sessionID, featureID int) ([]domain.FibonacciScore, error) {
+	query := `
+		SELECT id, session_id, feature_id, attendee_id, score_value, scored_at
+		FROM fibonacci_scores
+		WHERE session_id = ? AND feature_id = ?
+		ORDER BY scored_at ASC
+	`
+
+	rows, err := r.db.Query(query, sessionID, featureID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []domain.FibonacciScore
+	for rows.Next() {
+		var score domain.FibonacciScore
+		err := rows.Scan(
+			&score.ID,
+			&score.SessionID,
+			&score.FeatureID,
+			&score.AttendeeID,
+			&score.ScoreValue,
+			&score.ScoredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
+
+// SetConsensusScore records or replaces the final agreed score for a feature
+func (r *FibonacciRepository) SetConsensusScore(sessionID, featureID, finalScore int) (*domain.ConsensusScore, error) {
+	existing, err := r.GetConsensusScore(sessionID, featureID)
+	if err != nil && err != domain.ErrNotFound {
+		return nil, err
+	}
+
+	if existing != nil {
+		query := `
+			UPDATE consensus_scores
+			SET final_score = ?, consensus_reached_at = datetime('now')
+			WHERE session_id = ? AND feature_id = ?
+		`
+		_, err = r.db.Exec(query, finalScore, sessionID, featureID)
+	} else {
+		query := `
+			INSERT INTO consensus_scores (session_id, feature_id, final_score, consensus_reached_at)
+			VALUES (?, ?, ?, datetime('now'))
+		`
+		_, err = r.db.Exec(query, sessionID, featureID, finalScore)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetConsensusScore(sessionID, featureID)
+}
+
+// GetConsensusScore retrieves the final agreed score for a feature in a session
+func (r *FibonacciRepository) GetConsensusScore(sessionID, featureID int) (*domain.ConsensusScore, error) {
+	query := `
+		SELECT id, session_id, feature_id, final_score, consensus_reached_at
+		FROM consensus_scores
+		WHERE session_id = ? AND feature_id = ?
+	`
+
+	var consensus domain.ConsensusScore
+	err := r.db.QueryRow(query, sessionID, featureID).Scan(
+		&consensus.ID,
+		&consensus.SessionID,
+		&consensus.FeatureID,
+		&consensus.FinalScore,
+		&consensus.ConsensusReachedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &consensus, nil
+}
+
+// GetConsensusScoresBySessionID retrieves all final agreed scores for a session
+func (r *FibonacciRepository) GetConsensusScoresBySessionID(sessionID int) ([]domain.ConsensusScore, error) {
+	query := `
+		SELECT id, session_id, feature_id, final_score, consensus_reached_at
+		FROM consensus_scores
+		WHERE session_id = ?
+		ORDER BY feature_id ASC
+	`
+
+	rows, err := r.db.Query(query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []domain.ConsensusScore
+	for rows.Next() {
+		var consensus domain.ConsensusScore
+		err := rows.Scan(
+			&consensus.ID,
+			&consensus.SessionID,
+			&consensus.FeatureID,
+			&consensus.FinalScore,
+			&consensus.ConsensusReachedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, consensus)
+	}
+
+	return scores, rows.Err()
+}
+
+// GetSessionProgress calculates how many features in a session have a consensus score
+func (r *FibonacciRepository) GetSessionProgress(sessionID, totalFeatures int) (*domain.FibonacciSessionProgress, error) {
+	query := `SELECT COUNT(*) FROM consensus_scores WHERE session_id = ?`
+
+	progress := domain.FibonacciSessionProgress{
+		SessionID:     sessionID,
+		TotalFeatures: totalFeatures,
+	}
+	err := r.db.QueryRow(query, sessionID).Scan(&progress.ConsensusFeatures)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.RemainingFeatures = progress.TotalFeatures - progress.ConsensusFeatures
+	if progress.TotalFeatures > 0 {
+		progress.ProgressPercentage = float64(progress.ConsensusFeatures) / float64(progress.TotalFeatures) * 100
+	}
+
+	return &progress, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+
+	"pairwise/internal/domain"
+)
+
+// InviteRepository handles database operations for project invites
+type InviteRepository struct {
+	db *sql.DB
+}
+
+// NewInviteRepository creates a new invite repository
+func NewInviteRepository(db *sql.DB) *InviteRepository {
+	return &InviteRepository{db: db}
+}
+
+// inviteColumns are the columns scanned by scanInvite
+const inviteColumns = `id, project_id, code, role, expires_at, max_uses, COALESCE(uses, 0), revoked_at, created_by, created_at`
+
+// Create creates an invite for a project
+func (r *InviteRepository) Create(invite domain.ProjectInvite) (*domain.ProjectInvite, error) {
+	query := `
+		INSERT INTO project_invites (project_id, code, role, expires_at, max_uses, uses, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?, datetime('now'))
+		RETURNING ` + inviteColumns
+
+	return scanInvite(r.db.QueryRow(query,
+		invite.ProjectID, invite.Code, invite.Role, invite.ExpiresAt, invite.MaxUses, invite.CreatedBy))
+}
+
+// GetByID retrieves an invite of a project by ID
+func (r *InviteRepository) GetByID(projectID, id int) (*domain.ProjectInvite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM project_invites WHERE project_id = ? AND id = ?`
+
+	invite, err := scanInvite(r.db.QueryRow(query, projectID, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return invite, err
+}
+
+// GetByCode retrieves an invite by its join code
+func (r *InviteRepository) GetByCode(code string) (*domain.ProjectInvite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM project_invites WHERE code = ?`
+
+	invite, err := scanInvite(r.db.QueryRow(query, code))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return invite, err
+}
+
+// GetByProjectID retrieves all invites of a project, newest first
+func (r *InviteRepository) GetByProjectID(projectID int) ([]domain.ProjectInvite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM project_invites WHERE project_id = ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []domain.ProjectInvite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// ClaimUse counts a use of an invite if it is not revoked and has uses left. It returns
+// false when the invite could not be claimed, so concurrent joins cannot exceed the maximum.
+func (r *InviteRepository) ClaimUse(id int) (bool, error) {
+	query := `
+		UPDATE project_invites
+		SET uses = uses + 1
+		WHERE id = ? AND revoked_at IS NULL AND (max_uses IS NULL OR uses < max_uses)
+	`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ReleaseUse gives back a use claimed by a join that then failed
+func (r *InviteRepository) ReleaseUse(id int) error {
+	_, err := r.db.Exec(`UPDATE project_invites SET uses = uses - 1 WHERE id = ? AND uses > 0`, id)
+	return err
+}
+
+// Revoke marks an invite as revoked. Revoking an already revoked invite keeps the first time.
+func (r *InviteRepository) Revoke(projectID, id int) error {
+	query := `
+		UPDATE project_invites
+		SET revoked_at = COALESCE(revoked_at, datetime('now'))
+		WHERE project_id = ? AND id = ?
+	`
+
+	result, err := r.db.Exec(query, projectID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// scanInvite scans a row selected with inviteColumns
+func scanInvite(row interface{ Scan(...interface{}) error }) (*domain.ProjectInvite, error) {
+	var invite domain.ProjectInvite
+	err := row.Scan(
+		&invite.ID,
+		&invite.ProjectID,
+		&invite.Code,
+		&invite.Role,
+		&invite.ExpiresAt,
+		&invite.MaxUses,
+		&invite.Uses,
+		&invite.RevokedAt,
+		&invite.CreatedBy,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
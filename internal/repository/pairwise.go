@@ -17,15 +17,16 @@ func NewPairwiseRepository(db *sql.DB) *PairwiseRepository {
 	return &PairwiseRepository{db: db}
 }
 
-// CreateSession creates a new pairwise comparison session
-func (r *PairwiseRepository) CreateSession(projectID int, criterionType domain.CriterionType) (*domain.PairwiseSession, error) {
+// CreateSession creates a new pairwise comparison session, optionally with anonymous ballots
+// or driven by the facilitator
+func (r *PairwiseRepository) CreateSession(projectID int, criterionType domain.CriterionType, strategy domain.SchedulingStrategy, anonymous, driven bool) (*domain.PairwiseSession, error) {
 	// First insert the session
 	insertQuery := `
-		INSERT INTO pairwise_sessions (project_id, criterion_type, status, started_at)
-		VALUES (?, ?, ?, datetime('now'))
+		INSERT INTO pairwise_sessions (project_id, criterion_type, status, strategy, anonymous, driven, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
 	`
 
-	result, err := r.db.Exec(insertQuery, projectID, criterionType, domain.SessionStatusActive)
+	result, err := r.db.Exec(insertQuery, projectID, criterionType, domain.SessionStatusActive, strategy, anonymous, driven)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +39,9 @@ func (r *PairwiseRepository) CreateSession(projectID int, criterionType domain.C
 
 	// Fetch the created session
 	selectQuery := `
-		SELECT id, project_id, criterion_type, status, started_at, completed_at
+		SELECT id, project_id, criterion_type, status, started_at, completed_at,
+		       COALESCE(strategy, 'round_robin'), COALESCE(anonymous, false),
+		       COALESCE(driven, false), focus_comparison_id, COALESCE(focus_phase, '')
 		FROM pairwise_sessions
 		WHERE id = ?
 	`
@@ -51,6 +54,11 @@ func (r *PairwiseRepository) CreateSession(projectID int, criterionType domain.C
 		&session.Status,
 		&session.StartedAt,
 		&session.CompletedAt,
+		&session.Strategy,
+		&session.Anonymous,
+		&session.Driven,
+		&session.FocusComparisonID,
+		&session.FocusPhase,
 	)
 
 	if err != nil {
@@ -63,7 +71,9 @@ func (r *PairwiseRepository) CreateSession(projectID int, criterionType domain.C
 // GetSessionByID retrieves a pairwise session by ID
 func (r *PairwiseRepository) GetSessionByID(sessionID int) (*domain.PairwiseSession, error) {
 	query := `
-		SELECT id, project_id, criterion_type, status, started_at, completed_at
+		SELECT id, project_id, criterion_type, status, started_at, completed_at,
+		       COALESCE(strategy, 'round_robin'), COALESCE(anonymous, false),
+		       COALESCE(driven, false), focus_comparison_id, COALESCE(focus_phase, '')
 		FROM pairwise_sessions
 		WHERE id = ?
 	`
@@ -76,6 +86,11 @@ func (r *PairwiseRepository) GetSessionByID(sessionID int) (*domain.PairwiseSess
 		&session.Status,
 		&session.StartedAt,
 		&session.CompletedAt,
+		&session.Strategy,
+		&session.Anonymous,
+		&session.Driven,
+		&session.FocusComparisonID,
+		&session.FocusPhase,
 	)
 
 	if err != nil {
@@ -88,7 +103,9 @@ func (r *PairwiseRepository) GetSessionByID(sessionID int) (*domain.PairwiseSess
 // GetActiveSessionByProjectAndCriterion gets active session for project and criterion
 func (r *PairwiseRepository) GetActiveSessionByProjectAndCriterion(projectID int, criterionType domain.CriterionType) (*domain.PairwiseSession, error) {
 	query := `
-		SELECT id, project_id, criterion_type, status, started_at, completed_at
+		SELECT id, project_id, criterion_type, status, started_at, completed_at,
+		       COALESCE(strategy, 'round_robin'), COALESCE(anonymous, false),
+		       COALESCE(driven, false), focus_comparison_id, COALESCE(focus_phase, '')
 		FROM pairwise_sessions
 		WHERE project_id = ? AND criterion_type = ? AND status = ?
 		ORDER BY started_at DESC
@@ -103,6 +120,11 @@ func (r *PairwiseRepository) GetActiveSessionByProjectAndCriterion(projectID int
 		&session.Status,
 		&session.StartedAt,
 		&session.CompletedAt,
+		&session.Strategy,
+		&session.Anonymous,
+		&session.Driven,
+		&session.FocusComparisonID,
+		&session.FocusPhase,
 	)
 
 	if err != nil {
@@ -112,6 +134,43 @@ func (r *PairwiseRepository) GetActiveSessionByProjectAndCriterion(projectID int
 	return &session, nil
 }
 
+// GetLatestSessionByProjectAndCriterion gets the most recently started session regardless of status
+func (r *PairwiseRepository) GetLatestSessionByProjectAndCriterion(projectID int, criterionType domain.CriterionType) (*domain.PairwiseSession, error) {
+	query := `
+		SELECT id, project_id, criterion_type, status, started_at, completed_at,
+		       COALESCE(strategy, 'round_robin'), COALESCE(anonymous, false),
+		       COALESCE(driven, false), focus_comparison_id, COALESCE(focus_phase, '')
+		FROM pairwise_sessions
+		WHERE project_id = ? AND criterion_type = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var session domain.PairwiseSession
+	err := r.db.QueryRow(query, projectID, criterionType).Scan(
+		&session.ID,
+		&session.ProjectID,
+		&session.CriterionType,
+		&session.Status,
+		&session.StartedAt,
+		&session.CompletedAt,
+		&session.Strategy,
+		&session.Anonymous,
+		&session.Driven,
+		&session.FocusComparisonID,
+		&session.FocusPhase,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
 // CompleteSession marks a session as completed
 func (r *PairwiseRepository) CompleteSession(sessionID int) error {
 	query := `
@@ -124,15 +183,28 @@ func (r *PairwiseRepository) CompleteSession(sessionID int) error {
 	return err
 }
 
-// CreateComparison creates a new comparison between two features
-func (r *PairwiseRepository) CreateComparison(sessionID, featureAID, featureBID int) (*domain.SessionComparison, error) {
+// UpdateSessionFocus records which comparison a driven session shows and whether voting on
+// it is open. A nil comparison clears the focus.
+func (r *PairwiseRepository) UpdateSessionFocus(sessionID int, comparisonID *int, phase domain.FocusPhase) error {
+	query := `
+		UPDATE pairwise_sessions
+		SET focus_comparison_id = ?, focus_phase = NULLIF(?, '')
+		WHERE id = ?
+	`
+
+	_, err := r.db.Exec(query, comparisonID, phase, sessionID)
+	return err
+}
+
+// CreateComparison creates a new comparison between two features in a scheduling round
+func (r *PairwiseRepository) CreateComparison(sessionID, featureAID, featureBID, round int) (*domain.SessionComparison, error) {
 	// Insert the comparison
 	insertQuery := `
-		INSERT INTO pairwise_comparisons (session_id, feature_a_id, feature_b_id, created_at)
-		VALUES (?, ?, ?, datetime('now'))
+		INSERT INTO pairwise_comparisons (session_id, feature_a_id, feature_b_id, round, created_at)
+		VALUES (?, ?, ?, ?, datetime('now'))
 	`
 
-	result, err := r.db.Exec(insertQuery, sessionID, featureAID, featureBID)
+	result, err := r.db.Exec(insertQuery, sessionID, featureAID, featureBID, round)
 	if err != nil {
 		// Add debug logging
 		fmt.Printf("DEBUG: CreateComparison error: %v, sessionID: %d, featureAID: %d, featureBID: %d\n", err, sessionID, featureAID, featureBID)
@@ -147,7 +219,8 @@ func (r *PairwiseRepository) CreateComparison(sessionID, featureAID, featureBID
 
 	// Retrieve the complete record
 	selectQuery := `
-		SELECT id, session_id, feature_a_id, feature_b_id, winner_id, is_tie, consensus_reached, created_at
+		SELECT id, session_id, feature_a_id, feature_b_id, winner_id, is_tie, consensus_reached, created_at,
+		       COALESCE(consensus_rule, ''), COALESCE(intensity, 0), COALESCE(round, 1)
 		FROM pairwise_comparisons
 		WHERE id = ?
 	`
@@ -164,6 +237,9 @@ func (r *PairwiseRepository) CreateComparison(sessionID, featureAID, featureBID
 		&isTie,
 		&consensusReached,
 		&comparison.CreatedAt,
+		&comparison.ConsensusRule,
+		&comparison.Intensity,
+		&comparison.Round,
 	)
 
 	if err != nil {
@@ -189,14 +265,15 @@ func (r *PairwiseRepository) CreateComparison(sessionID, featureAID, featureBID
 func (r *PairwiseRepository) GetComparisonsBySessionID(sessionID int) ([]domain.SessionComparison, error) {
 	query := `
 		SELECT pc.id, pc.session_id, pc.feature_a_id, pc.feature_b_id, pc.winner_id, 
-		       pc.is_tie, pc.consensus_reached, pc.created_at,
+		       pc.is_tie, pc.consensus_reached, pc.created_at, COALESCE(pc.consensus_rule, ''), COALESCE(pc.intensity, 0),
+		       COALESCE(pc.round, 1),
 		       fa.id, fa.title, fa.description,
 		       fb.id, fb.title, fb.description
 		FROM pairwise_comparisons pc
 		JOIN features fa ON pc.feature_a_id = fa.id
 		JOIN features fb ON pc.feature_b_id = fb.id
 		WHERE pc.session_id = ?
-		ORDER BY pc.created_at ASC
+		ORDER BY COALESCE(pc.round, 1) ASC, pc.created_at ASC, pc.id ASC
 	`
 
 	rows, err := r.db.Query(query, sessionID)
@@ -222,6 +299,9 @@ func (r *PairwiseRepository) GetComparisonsBySessionID(sessionID int) ([]domain.
 			&isTie,
 			&consensusReached,
 			&comparison.CreatedAt,
+			&comparison.ConsensusRule,
+			&comparison.Intensity,
+			&comparison.Round,
 			&featureA.ID,
 			&featureA.Title,
 			&featureA.Description,
@@ -254,7 +334,8 @@ func (r *PairwiseRepository) GetComparisonsBySessionID(sessionID int) ([]domain.
 func (r *PairwiseRepository) GetComparisonByID(comparisonID int) (*domain.SessionComparison, error) {
 	query := `
 		SELECT pc.id, pc.session_id, pc.feature_a_id, pc.feature_b_id, pc.winner_id, 
-		       pc.is_tie, pc.consensus_reached, pc.created_at,
+		       pc.is_tie, pc.consensus_reached, pc.created_at, COALESCE(pc.consensus_rule, ''), COALESCE(pc.intensity, 0),
+		       COALESCE(pc.round, 1),
 		       fa.id, fa.title, fa.description,
 		       fb.id, fb.title, fb.description
 		FROM pairwise_comparisons pc
@@ -277,6 +358,9 @@ func (r *PairwiseRepository) GetComparisonByID(comparisonID int) (*domain.Sessio
 		&isTie,
 		&consensusReached,
 		&comparison.CreatedAt,
+		&comparison.ConsensusRule,
+		&comparison.Intensity,
+		&comparison.Round,
 		&featureA.ID,
 		&featureA.Title,
 		&featureA.Description,
@@ -306,11 +390,11 @@ func (r *PairwiseRepository) GetComparisonByID(comparisonID int) (*domain.Sessio
 func (r *PairwiseRepository) CreateVote(vote domain.AttendeeVote) (*domain.AttendeeVote, error) {
 	// First insert the vote
 	insertQuery := `
-		INSERT INTO attendee_votes (comparison_id, attendee_id, preferred_feature_id, is_tie_vote, voted_at)
-		VALUES (?, ?, ?, ?, datetime('now'))
+		INSERT INTO attendee_votes (comparison_id, attendee_id, preferred_feature_id, is_tie_vote, intensity, voted_at)
+		VALUES (?, ?, ?, ?, ?, datetime('now'))
 	`
 
-	result, err := r.db.Exec(insertQuery, vote.ComparisonID, vote.AttendeeID, vote.PreferredFeatureID, vote.IsTieVote)
+	result, err := r.db.Exec(insertQuery, vote.ComparisonID, vote.AttendeeID, vote.PreferredFeatureID, vote.IsTieVote, vote.Intensity)
 	if err != nil {
 		return nil, err
 	}
@@ -323,7 +407,7 @@ func (r *PairwiseRepository) CreateVote(vote domain.AttendeeVote) (*domain.Atten
 
 	// Fetch the created vote
 	selectQuery := `
-		SELECT id, comparison_id, attendee_id, preferred_feature_id, is_tie_vote, voted_at
+		SELECT id, comparison_id, attendee_id, preferred_feature_id, is_tie_vote, COALESCE(intensity, 0), voted_at
 		FROM attendee_votes
 		WHERE id = ?
 	`
@@ -335,6 +419,7 @@ func (r *PairwiseRepository) CreateVote(vote domain.AttendeeVote) (*domain.Atten
 		&newVote.AttendeeID,
 		&newVote.PreferredFeatureID,
 		&newVote.IsTieVote,
+		&newVote.Intensity,
 		&newVote.VotedAt,
 	)
 
@@ -349,19 +434,20 @@ func (r *PairwiseRepository) CreateVote(vote domain.AttendeeVote) (*domain.Atten
 func (r *PairwiseRepository) UpdateVote(vote domain.AttendeeVote) error {
 	query := `
 		UPDATE attendee_votes
-		SET preferred_feature_id = ?, is_tie_vote = ?, voted_at = datetime('now')
+		SET preferred_feature_id = ?, is_tie_vote = ?, intensity = ?, voted_at = datetime('now')
 		WHERE comparison_id = ? AND attendee_id = ?
 	`
 
-	_, err := r.db.Exec(query, vote.PreferredFeatureID, vote.IsTieVote, vote.ComparisonID, vote.AttendeeID)
+	_, err := r.db.Exec(query, vote.PreferredFeatureID, vote.IsTieVote, vote.Intensity, vote.ComparisonID, vote.AttendeeID)
 	return err
 }
 
-// GetVotesByComparisonID retrieves all votes for a comparison
+// GetVotesByComparisonID retrieves all votes for a comparison. Ballots cast in anonymous
+// sessions come back without an attendee.
 func (r *PairwiseRepository) GetVotesByComparisonID(comparisonID int) ([]domain.AttendeeVote, error) {
 	query := `
 		SELECT av.id, av.comparison_id, av.attendee_id, av.preferred_feature_id, 
-		       av.is_tie_vote, av.voted_at,
+		       av.is_tie_vote, COALESCE(av.intensity, 0), av.voted_at,
 		       a.id, a.name, a.role
 		FROM attendee_votes av
 		JOIN attendees a ON av.attendee_id = a.id
@@ -386,6 +472,7 @@ func (r *PairwiseRepository) GetVotesByComparisonID(comparisonID int) ([]domain.
 			&vote.AttendeeID,
 			&vote.PreferredFeatureID,
 			&vote.IsTieVote,
+			&vote.Intensity,
 			&vote.VotedAt,
 			&attendee.ID,
 			&attendee.Name,
@@ -398,62 +485,47 @@ func (r *PairwiseRepository) GetVotesByComparisonID(comparisonID int) ([]domain.
 		vote.Attendee = &attendee
 		votes = append(votes, vote)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ballots, err := r.getBallotsByComparisonID(comparisonID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ballot := range ballots {
+		votes = append(votes, ballot.ToVote())
+	}
 
 	return votes, nil
 }
 
-// CheckConsensusAndUpdate checks if consensus is reached and updates the comparison
-func (r *PairwiseRepository) CheckConsensusAndUpdate(comparisonID int, totalAttendees int) error {
+// CheckConsensusAndUpdate applies the consensus policy to a comparison's votes and records the result
+func (r *PairwiseRepository) CheckConsensusAndUpdate(comparisonID int, totalAttendees int, policy domain.ConsensusPolicy, threshold float64) error {
 	// Get all votes for this comparison
 	votes, err := r.GetVotesByComparisonID(comparisonID)
 	if err != nil {
 		return err
 	}
 
-	// Check if all attendees have voted
-	if len(votes) != totalAttendees {
-		return nil // Not all attendees have voted yet
-	}
-
-	// Check for consensus
-	var winnerID *int
-	var isTie bool
-	consensusReached := true
-
-	if len(votes) > 0 {
-		firstVote := votes[0]
-		winnerID = firstVote.PreferredFeatureID
-		isTie = firstVote.IsTieVote
-
-		// Check if all votes are the same
-		for _, vote := range votes[1:] {
-			if vote.IsTieVote != isTie {
-				consensusReached = false
-				break
-			}
-			if !vote.IsTieVote && vote.PreferredFeatureID != nil && winnerID != nil {
-				if *vote.PreferredFeatureID != *winnerID {
-					consensusReached = false
-					break
-				}
-			}
-		}
+	outcome := domain.ResolveConsensus(votes, totalAttendees, policy, threshold)
+	if !outcome.Reached {
+		return nil // Not all attendees have voted yet, or the votes are split
 	}
 
-	// Update comparison with consensus result
-	if consensusReached {
-		query := `
-			UPDATE pairwise_comparisons
-			SET winner_id = ?, is_tie = ?, consensus_reached = ?
-			WHERE id = ?
-		`
-		_, err = r.db.Exec(query, winnerID, isTie, consensusReached, comparisonID)
-		if err != nil {
-			return err
-		}
-	}
+	return r.SetComparisonResult(comparisonID, outcome.WinnerID, outcome.IsTie, outcome.Intensity, outcome.Rule)
+}
+
+// SetComparisonResult records the result of a comparison, its intensity and the rule that produced it
+func (r *PairwiseRepository) SetComparisonResult(comparisonID int, winnerID *int, isTie bool, intensity int, rule domain.ConsensusPolicy) error {
+	query := `
+		UPDATE pairwise_comparisons
+		SET winner_id = ?, is_tie = ?, intensity = ?, consensus_reached = ?, consensus_rule = ?
+		WHERE id = ?
+	`
 
-	return nil
+	_, err := r.db.Exec(query, winnerID, isTie, intensity, true, rule, comparisonID)
+	return err
 }
 
 // GetSessionProgress calculates the progress of a pairwise session
@@ -488,7 +560,7 @@ func (r *PairwiseRepository) GetSessionProgress(sessionID int) (*domain.SessionP
 // GetVoteByAttendeeAndComparison checks if an attendee has already voted on a comparison
 func (r *PairwiseRepository) GetVoteByAttendeeAndComparison(comparisonID, attendeeID int) (*domain.AttendeeVote, error) {
 	query := `
-		SELECT id, comparison_id, attendee_id, preferred_feature_id, is_tie_vote, voted_at
+		SELECT id, comparison_id, attendee_id, preferred_feature_id, is_tie_vote, COALESCE(intensity, 0), voted_at
 		FROM attendee_votes
 		WHERE comparison_id = ? AND attendee_id = ?
 	`
@@ -501,6 +573,7 @@ func (r *PairwiseRepository) GetVoteByAttendeeAndComparison(comparisonID, attend
 		&vote.AttendeeID,
 		&preferredFeatureID,
 		&vote.IsTieVote,
+		&vote.Intensity,
 		&vote.VotedAt,
 	)
 
@@ -519,3 +592,145 @@ func (r *PairwiseRepository) GetVoteByAttendeeAndComparison(comparisonID, attend
 
 	return &vote, nil
 }
+
+// SaveAnonymousVote records that an attendee voted and stores or replaces their ballot under
+// its key, in one transaction so that participation and ballots always add up
+func (r *PairwiseRepository) SaveAnonymousVote(participation domain.VoteParticipation, ballot domain.AnonymousBallot) (*domain.VoteParticipation, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var voted bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM vote_participations WHERE comparison_id = ? AND attendee_id = ?)
+	`, participation.ComparisonID, participation.AttendeeID).Scan(&voted)
+	if err != nil {
+		return nil, err
+	}
+
+	if voted {
+		// A changed vote replaces the attendee's ballot. If it cannot be found under its key
+		// the key has changed, and adding a ballot would count the attendee twice.
+		result, err := tx.Exec(`
+			UPDATE anonymous_ballots
+			SET preferred_feature_id = ?, is_tie_vote = ?, intensity = ?
+			WHERE ballot_key = ? AND comparison_id = ?
+		`, ballot.PreferredFeatureID, ballot.IsTieVote, ballot.Intensity, ballot.BallotKey, ballot.ComparisonID)
+		if err != nil {
+			return nil, err
+		}
+		if rows, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if rows == 0 {
+			return nil, domain.ErrBallotNotFound
+		}
+	} else {
+		_, err = tx.Exec(`
+			INSERT INTO anonymous_ballots (ballot_key, comparison_id, preferred_feature_id, is_tie_vote, intensity)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (ballot_key) DO UPDATE
+			SET preferred_feature_id = excluded.preferred_feature_id, is_tie_vote = excluded.is_tie_vote, intensity = excluded.intensity
+		`, ballot.BallotKey, ballot.ComparisonID, ballot.PreferredFeatureID, ballot.IsTieVote, ballot.Intensity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var saved domain.VoteParticipation
+	err = tx.QueryRow(`
+		INSERT INTO vote_participations (comparison_id, attendee_id, voted_at)
+		VALUES (?, ?, datetime('now'))
+		ON CONFLICT (comparison_id, attendee_id) DO UPDATE SET voted_at = excluded.voted_at
+		RETURNING comparison_id, attendee_id, voted_at
+	`, participation.ComparisonID, participation.AttendeeID).Scan(
+		&saved.ComparisonID,
+		&saved.AttendeeID,
+		&saved.VotedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
+
+// GetParticipation checks whether an attendee has voted on a comparison in an anonymous session
+func (r *PairwiseRepository) GetParticipation(comparisonID, attendeeID int) (*domain.VoteParticipation, error) {
+	query := `
+		SELECT comparison_id, attendee_id, voted_at
+		FROM vote_participations
+		WHERE comparison_id = ? AND attendee_id = ?
+	`
+
+	var participation domain.VoteParticipation
+	err := r.db.QueryRow(query, comparisonID, attendeeID).Scan(
+		&participation.ComparisonID,
+		&participation.AttendeeID,
+		&participation.VotedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &participation, nil
+}
+
+// getBallotsByComparisonID retrieves the anonymous ballots on a comparison, ordered by key so
+// that the order says nothing about when they were cast
+func (r *PairwiseRepository) getBallotsByComparisonID(comparisonID int) ([]domain.AnonymousBallot, error) {
+	query := `
+		SELECT ballot_key, comparison_id, preferred_feature_id, is_tie_vote, COALESCE(intensity, 0)
+		FROM anonymous_ballots
+		WHERE comparison_id = ?
+		ORDER BY ballot_key
+	`
+
+	rows, err := r.db.Query(query, comparisonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ballots []domain.AnonymousBallot
+	for rows.Next() {
+		ballot, err := scanBallot(rows)
+		if err != nil {
+			return nil, err
+		}
+		ballots = append(ballots, *ballot)
+	}
+
+	return ballots, rows.Err()
+}
+
+// scanBallot scans an anonymous ballot row
+func scanBallot(row interface{ Scan(...interface{}) error }) (*domain.AnonymousBallot, error) {
+	var ballot domain.AnonymousBallot
+	var preferredFeatureID sql.NullInt64
+	err := row.Scan(
+		&ballot.BallotKey,
+		&ballot.ComparisonID,
+		&preferredFeatureID,
+		&ballot.IsTieVote,
+		&ballot.Intensity,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if preferredFeatureID.Valid {
+		featureID := int(preferredFeatureID.Int64)
+		ballot.PreferredFeatureID = &featureID
+	}
+
+	return &ballot, nil
+}
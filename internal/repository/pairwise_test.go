@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+	"pairwise/internal/testutil"
+)
+
+// newTestComparison creates a project with two voters and two features, and an anonymous
+// session comparing them
+func newTestComparison(t *testing.T, db *sql.DB) (*domain.SessionComparison, []*domain.Attendee) {
+	t.Helper()
+
+	project, err := repository.NewProjectRepository(db).Create(domain.CreateProjectRequest{Name: "Test Project"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	var attendees []*domain.Attendee
+	for _, name := range []string{"Alice", "Bob"} {
+		attendee, err := repository.NewAttendeeRepository(db).Create(project.ID, domain.CreateAttendeeRequest{Name: name}, "")
+		if err != nil {
+			t.Fatalf("Failed to create attendee: %v", err)
+		}
+		attendees = append(attendees, attendee)
+	}
+
+	var featureIDs []int
+	for _, title := range []string{"Search", "Export"} {
+		feature, err := repository.NewFeatureRepository(db).Create(project.ID, domain.CreateFeatureRequest{Title: title, Description: title})
+		if err != nil {
+			t.Fatalf("Failed to create feature: %v", err)
+		}
+		featureIDs = append(featureIDs, feature.ID)
+	}
+
+	pairwise := repository.NewPairwiseRepository(db)
+	session, err := pairwise.CreateSession(project.ID, domain.CriterionTypeValue, domain.SchedulingRoundRobin, true, false)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	comparison, err := pairwise.CreateComparison(session.ID, featureIDs[0], featureIDs[1], 1)
+	if err != nil {
+		t.Fatalf("Failed to create comparison: %v", err)
+	}
+
+	return comparison, attendees
+}
+
+// TestSaveAnonymousVote tests that each voter has exactly one ballot, which a changed vote
+// replaces, and that a vote under a key that no longer finds the ballot is refused
+func TestSaveAnonymousVote(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	repo := repository.NewPairwiseRepository(db)
+	comparison, attendees := newTestComparison(t, db)
+
+	save := func(attendeeID int, key string, preferred int) error {
+		_, err := repo.SaveAnonymousVote(
+			domain.VoteParticipation{ComparisonID: comparison.ID, AttendeeID: attendeeID},
+			domain.AnonymousBallot{BallotKey: key, ComparisonID: comparison.ID, PreferredFeatureID: &preferred},
+		)
+		return err
+	}
+
+	if err := save(attendees[0].ID, "key-alice", comparison.FeatureAID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := save(attendees[1].ID, "key-bob", comparison.FeatureAID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Changed vote replaces the ballot", func(t *testing.T) {
+		if err := save(attendees[0].ID, "key-alice", comparison.FeatureBID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		votes, err := repo.GetVotesByComparisonID(comparison.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(votes) != 2 {
+			t.Fatalf("Expected 2 ballots, got %d", len(votes))
+		}
+		tally := domain.TallyVotes(votes, 2, true)
+		if tally.FeatureVotes[comparison.FeatureAID] != 1 || tally.FeatureVotes[comparison.FeatureBID] != 1 {
+			t.Errorf("Expected one ballot for each feature, got %v", tally.FeatureVotes)
+		}
+		for _, vote := range votes {
+			if vote.AttendeeID != 0 {
+				t.Errorf("Expected ballots to name no attendee, got %d", vote.AttendeeID)
+			}
+		}
+	})
+
+	t.Run("Changed key is refused", func(t *testing.T) {
+		if err := save(attendees[1].ID, "key-bob-rotated", comparison.FeatureBID); err != domain.ErrBallotNotFound {
+			t.Fatalf("Expected ErrBallotNotFound, got %v", err)
+		}
+
+		votes, err := repo.GetVotesByComparisonID(comparison.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(votes) != 2 {
+			t.Errorf("Expected Bob to keep a single ballot, got %d ballots", len(votes))
+		}
+	})
+}
+
+// TestAnonymousBallotsHaveNoRowID tests that ballots are stored by key, so that the order
+// they were cast in cannot be read back and matched against participation
+func TestAnonymousBallotsHaveNoRowID(t *testing.T) {
+	db := testutil.NewTestDB(t)
+
+	if _, err := db.Exec(`SELECT rowid FROM anonymous_ballots`); err == nil {
+		t.Error("Expected the ballots table to have no rowid")
+	}
+	if _, err := db.Exec(`SELECT rowid FROM vote_participations`); err != nil {
+		t.Errorf("Expected participation to be an ordinary table, got %v", err)
+	}
+}
@@ -23,18 +23,69 @@ func (r *PriorityRepository) Create(calc *domain.PriorityCalculation) error {
 	query := `
 		INSERT INTO priority_calculations (
 			project_id, feature_id, w_value, w_complexity, s_value, s_complexity,
-			weighted_value, weighted_complexity, final_priority_score, rank
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			weighted_value, weighted_complexity, final_priority_score, rank, win_count_mode,
+			ranking_model, calculated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
 		RETURNING id, calculated_at`
 
 	err := r.db.QueryRow(
 		query,
 		calc.ProjectID, calc.FeatureID, calc.WValue, calc.WComplexity,
 		calc.SValue, calc.SComplexity, calc.WeightedValue, calc.WeightedComplexity,
-		calc.FinalPriorityScore, calc.Rank,
+		calc.FinalPriorityScore, calc.Rank, calc.WinCountMode, calc.RankingModel,
 	).Scan(&calc.ID, &calc.CalculatedAt)
+	if err != nil {
+		return err
+	}
 
-	return err
+	for i := range calc.Criteria {
+		score := &calc.Criteria[i]
+		score.CalculationID = calc.ID
+		err := r.db.QueryRow(`
+			INSERT INTO priority_criterion_scores (
+				calculation_id, criterion, direction, weight, win_count, score, weighted
+			) VALUES (?, ?, ?, ?, ?, ?, ?)
+			RETURNING id`,
+			score.CalculationID, score.Criterion, score.Direction, score.Weight,
+			score.WinCount, score.Score, score.Weighted,
+		).Scan(&score.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save %s score: %w", score.Criterion, err)
+		}
+	}
+
+	return nil
+}
+
+// getCriterionScores retrieves the per-criterion scores of a project's calculations, keyed by calculation ID
+func (r *PriorityRepository) getCriterionScores(projectID int) (map[int][]domain.CriterionScore, error) {
+	query := `
+		SELECT s.id, s.calculation_id, s.criterion, s.direction, s.weight, s.win_count, s.score, s.weighted
+		FROM priority_criterion_scores s
+		JOIN priority_calculations pc ON s.calculation_id = pc.id
+		WHERE pc.project_id = ?
+		ORDER BY s.id`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := make(map[int][]domain.CriterionScore)
+	for rows.Next() {
+		var score domain.CriterionScore
+		err := rows.Scan(
+			&score.ID, &score.CalculationID, &score.Criterion, &score.Direction,
+			&score.Weight, &score.WinCount, &score.Score, &score.Weighted,
+		)
+		if err != nil {
+			return nil, err
+		}
+		scores[score.CalculationID] = append(scores[score.CalculationID], score)
+	}
+
+	return scores, rows.Err()
 }
 
 // GetByProjectID retrieves all priority calculations for a project, ordered by rank
@@ -42,7 +93,9 @@ func (r *PriorityRepository) GetByProjectID(projectID int) ([]domain.PriorityCal
 	query := `
 		SELECT pc.id, pc.project_id, pc.feature_id, pc.w_value, pc.w_complexity,
 		       pc.s_value, pc.s_complexity, pc.weighted_value, pc.weighted_complexity,
-		       pc.final_priority_score, pc.rank, pc.calculated_at
+		       pc.final_priority_score, pc.rank, COALESCE(pc.win_count_mode, 'consensus'),
+		       COALESCE(pc.ranking_model, CASE pc.win_count_mode WHEN 'vote' THEN 'vote_win_count' ELSE 'win_count' END),
+		       pc.calculated_at
 		FROM priority_calculations pc
 		WHERE pc.project_id = ?
 		ORDER BY pc.rank ASC`
@@ -59,15 +112,26 @@ func (r *PriorityRepository) GetByProjectID(projectID int) ([]domain.PriorityCal
 		err := rows.Scan(
 			&calc.ID, &calc.ProjectID, &calc.FeatureID, &calc.WValue, &calc.WComplexity,
 			&calc.SValue, &calc.SComplexity, &calc.WeightedValue, &calc.WeightedComplexity,
-			&calc.FinalPriorityScore, &calc.Rank, &calc.CalculatedAt,
+			&calc.FinalPriorityScore, &calc.Rank, &calc.WinCountMode, &calc.RankingModel, &calc.CalculatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
 		calculations = append(calculations, calc)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	scores, err := r.getCriterionScores(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range calculations {
+		calculations[i].Criteria = scores[calculations[i].ID]
+	}
 
-	return calculations, rows.Err()
+	return calculations, nil
 }
 
 // GetResultsWithFeatures retrieves priority calculations with feature details
@@ -75,7 +139,9 @@ func (r *PriorityRepository) GetResultsWithFeatures(projectID int) ([]domain.Pri
 	query := `
 		SELECT pc.id, pc.project_id, pc.feature_id, pc.w_value, pc.w_complexity,
 		       pc.s_value, pc.s_complexity, pc.weighted_value, pc.weighted_complexity,
-		       pc.final_priority_score, pc.rank, pc.calculated_at,
+		       pc.final_priority_score, pc.rank, COALESCE(pc.win_count_mode, 'consensus'),
+		       COALESCE(pc.ranking_model, CASE pc.win_count_mode WHEN 'vote' THEN 'vote_win_count' ELSE 'win_count' END),
+		       pc.calculated_at,
 		       f.id, f.project_id, f.title, f.description, f.acceptance_criteria,
 		       f.created_at, f.updated_at
 		FROM priority_calculations pc
@@ -95,7 +161,7 @@ func (r *PriorityRepository) GetResultsWithFeatures(projectID int) ([]domain.Pri
 		err := rows.Scan(
 			&result.ID, &result.ProjectID, &result.FeatureID, &result.WValue, &result.WComplexity,
 			&result.SValue, &result.SComplexity, &result.WeightedValue, &result.WeightedComplexity,
-			&result.FinalPriorityScore, &result.Rank, &result.CalculatedAt,
+			&result.FinalPriorityScore, &result.Rank, &result.WinCountMode, &result.RankingModel, &result.CalculatedAt,
 			&result.Feature.ID, &result.Feature.ProjectID, &result.Feature.Title,
 			&result.Feature.Description, &result.Feature.AcceptanceCriteria,
 			&result.Feature.CreatedAt, &result.Feature.UpdatedAt,
@@ -105,14 +171,32 @@ func (r *PriorityRepository) GetResultsWithFeatures(projectID int) ([]domain.Pri
 		}
 		results = append(results, result)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	scores, err := r.getCriterionScores(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Criteria = scores[results[i].ID]
+	}
 
-	return results, rows.Err()
+	return results, nil
 }
 
 // DeleteByProjectID removes all priority calculations for a project
 func (r *PriorityRepository) DeleteByProjectID(projectID int) error {
+	_, err := r.db.Exec(`
+		DELETE FROM priority_criterion_scores
+		WHERE calculation_id IN (SELECT id FROM priority_calculations WHERE project_id = ?)`, projectID)
+	if err != nil {
+		return err
+	}
+
 	query := "DELETE FROM priority_calculations WHERE project_id = ?"
-	_, err := r.db.Exec(query, projectID)
+	_, err = r.db.Exec(query, projectID)
 	return err
 }
 
@@ -133,7 +217,10 @@ func (r *PriorityRepository) ExistsForProject(projectID int) (bool, error) {
 func (r *PriorityRepository) GetLatestCalculationTime(projectID int) (*domain.PriorityCalculation, error) {
 	query := `
 		SELECT id, project_id, feature_id, w_value, w_complexity, s_value, s_complexity,
-		       weighted_value, weighted_complexity, final_priority_score, rank, calculated_at
+		       weighted_value, weighted_complexity, final_priority_score, rank,
+		       COALESCE(win_count_mode, 'consensus'),
+		       COALESCE(ranking_model, CASE win_count_mode WHEN 'vote' THEN 'vote_win_count' ELSE 'win_count' END),
+		       calculated_at
 		FROM priority_calculations 
 		WHERE project_id = ? 
 		ORDER BY calculated_at DESC 
@@ -143,7 +230,7 @@ func (r *PriorityRepository) GetLatestCalculationTime(projectID int) (*domain.Pr
 	err := r.db.QueryRow(query, projectID).Scan(
 		&calc.ID, &calc.ProjectID, &calc.FeatureID, &calc.WValue, &calc.WComplexity,
 		&calc.SValue, &calc.SComplexity, &calc.WeightedValue, &calc.WeightedComplexity,
-		&calc.FinalPriorityScore, &calc.Rank, &calc.CalculatedAt,
+		&calc.FinalPriorityScore, &calc.Rank, &calc.WinCountMode, &calc.RankingModel, &calc.CalculatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"testing"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+	"pairwise/internal/testutil"
+)
+
+// TestPriorityRankingModel tests that calculations keep the ranking model they were made
+// with, and that calculations from before models were recorded report their mode's model
+func TestPriorityRankingModel(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	repo := repository.NewPriorityRepository(db)
+
+	project, err := repository.NewProjectRepository(db).Create(domain.CreateProjectRequest{Name: "Test Project"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	var featureIDs []int
+	for _, title := range []string{"Search", "Export"} {
+		feature, err := repository.NewFeatureRepository(db).Create(project.ID, domain.CreateFeatureRequest{Title: title, Description: title})
+		if err != nil {
+			t.Fatalf("Failed to create feature: %v", err)
+		}
+		featureIDs = append(featureIDs, feature.ID)
+	}
+
+	err = repo.Create(&domain.PriorityCalculation{
+		ProjectID:    project.ID,
+		FeatureID:    featureIDs[0],
+		Rank:         1,
+		WinCountMode: domain.WinCountModeConsensus,
+		RankingModel: domain.RankingModelBradleyTerry,
+	})
+	if err != nil {
+		t.Fatalf("Failed to save calculation: %v", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO priority_calculations (
+			project_id, feature_id, w_value, w_complexity, s_value, s_complexity,
+			weighted_value, weighted_complexity, final_priority_score, rank, win_count_mode,
+			calculated_at
+		) VALUES (?, ?, 0, 0, 0, 0, 0, 0, 0, 2, 'vote', datetime('now'))`, project.ID, featureIDs[1])
+	if err != nil {
+		t.Fatalf("Failed to save calculation: %v", err)
+	}
+
+	results, err := repo.GetResultsWithFeatures(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get results: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].RankingModel != domain.RankingModelBradleyTerry {
+		t.Errorf("Expected the saved model, got %q", results[0].RankingModel)
+	}
+	if results[1].RankingModel != domain.RankingModelVoteWinCount {
+		t.Errorf("Expected the vote mode's model, got %q", results[1].RankingModel)
+	}
+}
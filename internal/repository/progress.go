@@ -51,14 +51,49 @@ func (r *ProgressRepository) GetProjectProgress(projectID int) (*domain.ProjectP
 		return nil, fmt.Errorf("failed to get project progress: %w", err)
 	}
 
+	progress.CompletedPhases, err = r.getCompletedPhases(projectID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &progress, nil
 }
 
+// getCompletedPhases retrieves the criterion phases a project has completed
+func (r *ProgressRepository) getCompletedPhases(projectID int) ([]domain.WorkflowPhase, error) {
+	query := `
+		SELECT phase
+		FROM project_phase_completions
+		WHERE project_id = ?
+		ORDER BY completed_at, id`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completed phases: %w", err)
+	}
+	defer rows.Close()
+
+	var phases []domain.WorkflowPhase
+	for rows.Next() {
+		var phase domain.WorkflowPhase
+		if err := rows.Scan(&phase); err != nil {
+			return nil, fmt.Errorf("failed to scan completed phase: %w", err)
+		}
+		phases = append(phases, phase)
+	}
+
+	return phases, rows.Err()
+}
+
 // CreateProjectProgress creates an initial progress record for a project
 func (r *ProgressRepository) CreateProjectProgress(projectID int) (*domain.ProjectProgress, error) {
 	query := `
-		INSERT INTO project_progress (project_id, current_phase)
-		VALUES (?, ?)
+		INSERT INTO project_progress (
+			project_id, setup_completed, attendees_added, features_added,
+			pairwise_value_completed, pairwise_complexity_completed,
+			fibonacci_value_completed, fibonacci_complexity_completed,
+			results_calculated, current_phase, last_activity, created_at, updated_at
+		) VALUES (?, FALSE, FALSE, FALSE, FALSE, FALSE, FALSE, FALSE, FALSE, ?, datetime('now'), datetime('now'), datetime('now'))
 		RETURNING project_id, setup_completed, attendees_added, features_added,
 		          pairwise_value_completed, pairwise_complexity_completed,
 		          fibonacci_value_completed, fibonacci_complexity_completed,
@@ -100,13 +135,12 @@ func (r *ProgressRepository) UpdateProjectProgress(progress *domain.ProjectProgr
 			fibonacci_value_completed = ?,
 			fibonacci_complexity_completed = ?,
 			results_calculated = ?,
-			current_phase = ?0,
+			current_phase = ?,
 			updated_at = datetime('now'),
 			last_activity = datetime('now')
 		WHERE project_id = ?`
 
 	_, err := r.db.Exec(query,
-		progress.ProjectID,
 		progress.SetupCompleted,
 		progress.AttendeesAdded,
 		progress.FeaturesAdded,
@@ -116,41 +150,38 @@ func (r *ProgressRepository) UpdateProjectProgress(progress *domain.ProjectProgr
 		progress.FibonacciComplexityCompleted,
 		progress.ResultsCalculated,
 		progress.CurrentPhase,
+		progress.ProjectID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update project progress: %w", err)
 	}
 
+	for _, phase := range progress.CompletedPhases {
+		_, err := r.db.Exec(`
+			INSERT INTO project_phase_completions (project_id, phase, completed_at)
+			SELECT ?, ?, datetime('now')
+			WHERE NOT EXISTS (SELECT 1 FROM project_phase_completions WHERE project_id = ? AND phase = ?)`,
+			progress.ProjectID, phase, progress.ProjectID, phase)
+		if err != nil {
+			return fmt.Errorf("failed to record completed phase %s: %w", phase, err)
+		}
+	}
+
 	return nil
 }
 
-// MarkPhaseCompleted marks a specific phase as completed and advances to the next phase
-func (r *ProgressRepository) MarkPhaseCompleted(projectID int, phase domain.WorkflowPhase) error {
+// MarkPhaseCompleted marks a specific phase as completed and advances to the next phase.
+// criteria are the project's criterion keys, which determine the phase that comes next.
+func (r *ProgressRepository) MarkPhaseCompleted(projectID int, phase domain.WorkflowPhase, criteria []domain.CriterionType) error {
 	progress, err := r.GetProjectProgress(projectID)
 	if err != nil {
 		return fmt.Errorf("failed to get project progress: %w", err)
 	}
+	progress.Criteria = criteria
 
 	// Mark the completed phase
-	switch phase {
-	case domain.PhaseSetup:
-		progress.SetupCompleted = true
-	case domain.PhaseAttendees:
-		progress.AttendeesAdded = true
-	case domain.PhaseFeatures:
-		progress.FeaturesAdded = true
-	case domain.PhasePairwiseValue:
-		progress.PairwiseValueCompleted = true
-	case domain.PhasePairwiseComplexity:
-		progress.PairwiseComplexityCompleted = true
-	case domain.PhaseFibonacciValue:
-		progress.FibonacciValueCompleted = true
-	case domain.PhaseFibonacciComplexity:
-		progress.FibonacciComplexityCompleted = true
-	case domain.PhaseResults:
-		progress.ResultsCalculated = true
-	}
+	progress.MarkPhaseCompleted(phase)
 
 	// Advance to next phase if not at the end
 	if phase != domain.PhaseResults {
@@ -169,5 +200,10 @@ func (r *ProgressRepository) DeleteProjectProgress(projectID int) error {
 		return fmt.Errorf("failed to delete project progress: %w", err)
 	}
 
+	_, err = r.db.Exec(`DELETE FROM project_phase_completions WHERE project_id = ?`, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete completed phases: %w", err)
+	}
+
 	return nil
 }
@@ -19,17 +19,31 @@ func NewProjectRepository(db *sql.DB) *ProjectRepository {
 // Create creates a new project
 func (r *ProjectRepository) Create(req domain.CreateProjectRequest) (*domain.Project, error) {
 	query := `
-		INSERT INTO projects (name, description, status, created_at, updated_at)
-		VALUES (?, ?, 'active', datetime('now'), datetime('now'))
-		RETURNING id, name, description, status, created_at, updated_at
+		INSERT INTO projects (name, description, status, consensus_policy, supermajority_threshold, owner_id, created_at, updated_at)
+		VALUES (?, ?, 'active', ?, ?, ?, datetime('now'), datetime('now'))
+		RETURNING id, name, description, status, consensus_policy, COALESCE(supermajority_threshold, 0), owner_id, created_at, updated_at
 	`
 
+	policy := req.ConsensusPolicy
+	if policy == "" {
+		policy = domain.ConsensusPolicyUnanimous
+	}
+
+	// Without a threshold none is stored, so the default of two thirds applies exactly
+	var threshold *float64
+	if req.SupermajorityThreshold != 0 {
+		threshold = &req.SupermajorityThreshold
+	}
+
 	var project domain.Project
-	err := r.db.QueryRow(query, req.Name, req.Description).Scan(
+	err := r.db.QueryRow(query, req.Name, req.Description, policy, threshold, req.OwnerID).Scan(
 		&project.ID,
 		&project.Name,
 		&project.Description,
 		&project.Status,
+		&project.ConsensusPolicy,
+		&project.SupermajorityThreshold,
+		&project.OwnerID,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
@@ -44,7 +58,9 @@ func (r *ProjectRepository) Create(req domain.CreateProjectRequest) (*domain.Pro
 // GetByID retrieves a project by ID
 func (r *ProjectRepository) GetByID(id int) (*domain.Project, error) {
 	query := `
-		SELECT id, name, description, status, created_at, updated_at
+		SELECT id, name, description, status,
+		       COALESCE(consensus_policy, 'unanimous'), COALESCE(supermajority_threshold, 0),
+		       owner_id, created_at, updated_at
 		FROM projects
 		WHERE id = ?
 	`
@@ -55,6 +71,9 @@ func (r *ProjectRepository) GetByID(id int) (*domain.Project, error) {
 		&project.Name,
 		&project.Description,
 		&project.Status,
+		&project.ConsensusPolicy,
+		&project.SupermajorityThreshold,
+		&project.OwnerID,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
@@ -73,9 +92,14 @@ func (r *ProjectRepository) GetByID(id int) (*domain.Project, error) {
 func (r *ProjectRepository) Update(id int, req domain.UpdateProjectRequest) (*domain.Project, error) {
 	query := `
 		UPDATE projects 
-		SET name = ?, description = ?, status = ?, updated_at = datetime('now')
+		SET name = ?, description = ?, status = ?,
+		    consensus_policy = COALESCE(NULLIF(?, ''), consensus_policy, 'unanimous'),
+		    supermajority_threshold = COALESCE(NULLIF(?, 0), supermajority_threshold),
+		    updated_at = datetime('now')
 		WHERE id = ?
-		RETURNING id, name, description, status, created_at, updated_at
+		RETURNING id, name, description, status,
+		          COALESCE(consensus_policy, 'unanimous'), COALESCE(supermajority_threshold, 0),
+		          owner_id, created_at, updated_at
 	`
 
 	status := req.Status
@@ -84,11 +108,14 @@ func (r *ProjectRepository) Update(id int, req domain.UpdateProjectRequest) (*do
 	}
 
 	var project domain.Project
-	err := r.db.QueryRow(query, req.Name, req.Description, status, id).Scan(
+	err := r.db.QueryRow(query, req.Name, req.Description, status, req.ConsensusPolicy, req.SupermajorityThreshold, id).Scan(
 		&project.ID,
 		&project.Name,
 		&project.Description,
 		&project.Status,
+		&project.ConsensusPolicy,
+		&project.SupermajorityThreshold,
+		&project.OwnerID,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
@@ -127,12 +154,33 @@ func (r *ProjectRepository) Delete(id int) error {
 // List retrieves all projects
 func (r *ProjectRepository) List() ([]domain.Project, error) {
 	query := `
-		SELECT id, name, description, status, created_at, updated_at
+		SELECT id, name, description, status,
+		       COALESCE(consensus_policy, 'unanimous'), COALESCE(supermajority_threshold, 0),
+		       owner_id, created_at, updated_at
 		FROM projects
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query)
+	return r.queryProjects(query)
+}
+
+// GetByOwnerID retrieves the projects owned by a user
+func (r *ProjectRepository) GetByOwnerID(ownerID int) ([]domain.Project, error) {
+	query := `
+		SELECT id, name, description, status,
+		       COALESCE(consensus_policy, 'unanimous'), COALESCE(supermajority_threshold, 0),
+		       owner_id, created_at, updated_at
+		FROM projects
+		WHERE owner_id = ?
+		ORDER BY created_at DESC
+	`
+
+	return r.queryProjects(query, ownerID)
+}
+
+// queryProjects runs a query selecting project rows
+func (r *ProjectRepository) queryProjects(query string, args ...interface{}) ([]domain.Project, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +194,9 @@ func (r *ProjectRepository) List() ([]domain.Project, error) {
 			&project.Name,
 			&project.Description,
 			&project.Status,
+			&project.ConsensusPolicy,
+			&project.SupermajorityThreshold,
+			&project.OwnerID,
 			&project.CreatedAt,
 			&project.UpdatedAt,
 		)
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+
+	"pairwise/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Migrate creates or updates the SQLite schema of every model and adds the triggers that
+// keep the audit log immutable
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&domain.Project{},
+		&domain.Attendee{},
+		&domain.Feature{},
+		&domain.PairwiseSession{},
+		&domain.SessionComparison{},
+		&domain.AttendeeVote{},
+		&domain.FibonacciSession{},
+		&domain.FibonacciScore{},
+		&domain.ConsensusScore{},
+		&domain.PriorityCalculation{},
+		&domain.ProjectProgress{},
+		&domain.Criterion{},
+		&domain.CriterionScore{},
+		&domain.PhaseCompletion{},
+		&domain.ProjectInvite{},
+		&domain.APIKey{},
+		&domain.AuditEvent{},
+		&domain.User{},
+		&domain.VoteParticipation{},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Without a rowid, the storage order of ballots does not reveal the order they were cast in
+	if err := db.Set("gorm:table_options", "WITHOUT ROWID").AutoMigrate(&domain.AnonymousBallot{}); err != nil {
+		return err
+	}
+
+	return protectAuditLog(db)
+}
+
+// protectAuditLog adds triggers that reject changes to recorded audit events
+func protectAuditLog(db *gorm.DB) error {
+	for _, statement := range []string{
+		`CREATE TRIGGER IF NOT EXISTS audit_events_no_update BEFORE UPDATE ON audit_events
+		 BEGIN SELECT RAISE(ABORT, 'audit events are immutable'); END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_events_no_delete BEFORE DELETE ON audit_events
+		 BEGIN SELECT RAISE(ABORT, 'audit events are immutable'); END`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to protect audit log: %w", err)
+		}
+	}
+	return nil
+}
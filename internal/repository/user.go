@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+
+	"pairwise/internal/domain"
+)
+
+// UserRepository handles database operations for users
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// userColumns are the columns scanned by scanUser
+const userColumns = `id, issuer, subject, COALESCE(email, ''), name, last_login_at, created_at`
+
+// Create stores a user signing in for the first time
+func (r *UserRepository) Create(user domain.User) (*domain.User, error) {
+	query := `
+		INSERT INTO users (issuer, subject, email, name, last_login_at, created_at)
+		VALUES (?, ?, ?, ?, datetime('now'), datetime('now'))
+		RETURNING ` + userColumns
+
+	return scanUser(r.db.QueryRow(query, user.Issuer, user.Subject, user.Email, user.Name))
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(id int) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return user, err
+}
+
+// GetByIdentity retrieves a user by the issuer and subject of their OpenID identity
+func (r *UserRepository) GetByIdentity(issuer, subject string) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE issuer = ? AND subject = ?`
+
+	user, err := scanUser(r.db.QueryRow(query, issuer, subject))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return user, err
+}
+
+// RecordLogin refreshes a user's email and name from their latest login
+func (r *UserRepository) RecordLogin(id int, email, name string) (*domain.User, error) {
+	query := `
+		UPDATE users
+		SET email = ?, name = ?, last_login_at = datetime('now')
+		WHERE id = ?
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRow(query, email, name, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return user, err
+}
+
+// scanUser scans a row selected with userColumns
+func scanUser(row interface{ Scan(...interface{}) error }) (*domain.User, error) {
+	var user domain.User
+	err := row.Scan(
+		&user.ID,
+		&user.Issuer,
+		&user.Subject,
+		&user.Email,
+		&user.Name,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+)
+
+// apiKeyBytes is the number of random bytes in an API key
+const apiKeyBytes = 32
+
+// APIKeyService handles API keys for automation and integrations
+type APIKeyService struct {
+	auditHook
+	apiKeyRepo  *repository.APIKeyRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, projectRepo *repository.ProjectRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		projectRepo: projectRepo,
+	}
+}
+
+// CreateAPIKey creates a key, global when projectID is nil. The key is only returned here.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, projectID, createdBy *int, req domain.CreateAPIKeyRequest) (*domain.APIKeyCredentials, error) {
+	if req.Name == "" {
+		return nil, domain.NewAPIError(400, "API key name is required")
+	}
+
+	scopes, err := validateAPIScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if projectID != nil {
+		if _, err := s.projectRepo.GetByID(*projectID); err != nil {
+			if err == domain.ErrNotFound {
+				return nil, domain.NewAPIError(404, "Project not found")
+			}
+			return nil, domain.NewAPIError(500, "Failed to validate project", err.Error())
+		}
+	}
+
+	raw, err := GenerateAPIKey()
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to generate API key", err.Error())
+	}
+
+	key, err := s.apiKeyRepo.Create(domain.APIKey{
+		ProjectID: projectID,
+		Name:      req.Name,
+		Prefix:    raw[:domain.APIKeyDisplayLength],
+		KeyHash:   HashAPIKey(raw),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to create API key", err.Error())
+	}
+
+	s.audit(ctx, optionalID(projectID), domain.AuditActionCreate, domain.AuditEntityAPIKey, key.ID, nil, key)
+
+	return &domain.APIKeyCredentials{APIKey: key, Key: raw}, nil
+}
+
+// EnsureAPIKey stores a key chosen outside the server, such as the bootstrap admin key from
+// the environment, unless it is already stored
+func (s *APIKeyService) EnsureAPIKey(raw, name string, scopes []domain.APIScope) (*domain.APIKey, error) {
+	if !domain.IsAPIKey(raw) || len(raw) < domain.APIKeyDisplayLength+16 {
+		return nil, fmt.Errorf("API keys must start with %q and have at least 16 characters after the prefix", domain.APIKeyPrefix)
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(HashAPIKey(raw))
+	if err == nil {
+		return key, nil
+	}
+	if err != domain.ErrNotFound {
+		return nil, err
+	}
+
+	return s.apiKeyRepo.Create(domain.APIKey{
+		Name:    name,
+		Prefix:  raw[:domain.APIKeyDisplayLength],
+		KeyHash: HashAPIKey(raw),
+		Scopes:  scopes,
+	})
+}
+
+// GetAPIKeys lists the keys scoped to a project, or every key when projectID is nil
+func (s *APIKeyService) GetAPIKeys(projectID *int) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	var err error
+	if projectID == nil {
+		keys, err = s.apiKeyRepo.GetAll()
+	} else {
+		keys, err = s.apiKeyRepo.GetByProjectID(*projectID)
+	}
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to retrieve API keys", err.Error())
+	}
+
+	if keys == nil {
+		keys = []domain.APIKey{}
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key. When projectID is set the key must be scoped to that project.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, projectID *int, id int) (*domain.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "API key not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to retrieve API key", err.Error())
+	}
+
+	if projectID != nil && (key.ProjectID == nil || *key.ProjectID != *projectID) {
+		return nil, domain.NewAPIError(404, "API key not found")
+	}
+
+	if err := s.apiKeyRepo.Revoke(id); err != nil {
+		return nil, domain.NewAPIError(500, "Failed to revoke API key", err.Error())
+	}
+
+	revoked, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to retrieve API key", err.Error())
+	}
+
+	s.audit(ctx, optionalID(key.ProjectID), domain.AuditActionRevoke, domain.AuditEntityAPIKey, id, key, revoked)
+
+	return revoked, nil
+}
+
+// Authenticate returns the key matching a raw API key, recording its use. Unknown and
+// revoked keys return domain.ErrUnauthorized.
+func (s *APIKeyService) Authenticate(raw string) (*domain.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByHash(HashAPIKey(raw))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	if key.RevokedAt != nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(key.ID); err != nil {
+		fmt.Printf("Warning: failed to record use of API key %d: %v\n", key.ID, err)
+	}
+
+	return key, nil
+}
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return domain.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key. Keys are long and random, so unlike PINs
+// they need no salt or slow KDF, and the hash can be looked up directly.
+func HashAPIKey(raw string) string {
+	hash := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(hash[:])
+}
+
+// validateAPIScopes checks scopes and drops duplicates
+func validateAPIScopes(requested []domain.APIScope) (domain.APIScopes, error) {
+	if len(requested) == 0 {
+		return nil, domain.NewAPIError(400, "At least one scope is required")
+	}
+
+	scopes := domain.APIScopes{}
+	for _, scope := range requested {
+		if !domain.IsValidAPIScope(scope) {
+			return nil, domain.NewAPIError(400, fmt.Sprintf("Unknown scope %q", scope))
+		}
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"pairwise/internal/domain"
+)
+
+// TestGenerateAPIKey tests the key format and that only hashes need to be stored
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	other, _ := GenerateAPIKey()
+
+	if !domain.IsAPIKey(key) || key == other {
+		t.Errorf("Expected distinct prefixed keys, got %q and %q", key, other)
+	}
+	if domain.IsAPIKey("eyJwaWQiOjF9.signature") {
+		t.Error("Session tokens should not look like API keys")
+	}
+
+	hash := HashAPIKey(key)
+	if len(hash) != 64 || strings.Contains(hash, key) || HashAPIKey(key) != hash {
+		t.Errorf("Expected a stable hex SHA-256, got %q", hash)
+	}
+}
+
+// TestValidateAPIScopes tests scope validation
+func TestValidateAPIScopes(t *testing.T) {
+	scopes, err := validateAPIScopes([]domain.APIScope{domain.ScopeReadResults, domain.ScopeReadResults, domain.ScopeAdmin})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(scopes) != 2 {
+		t.Errorf("Expected duplicates to be dropped, got %v", scopes)
+	}
+
+	if _, err := validateAPIScopes([]domain.APIScope{"write:results"}); err == nil {
+		t.Error("Expected an unknown scope to be rejected")
+	}
+	if _, err := validateAPIScopes(nil); err == nil {
+		t.Error("Expected at least one scope to be required")
+	}
+}
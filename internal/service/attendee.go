@@ -1,12 +1,17 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"pairwise/internal/domain"
 	"pairwise/internal/repository"
 )
 
 // AttendeeService handles business logic for attendees
 type AttendeeService struct {
+	auditHook
 	attendeeRepo *repository.AttendeeRepository
 }
 
@@ -17,8 +22,9 @@ func NewAttendeeService(attendeeRepo *repository.AttendeeRepository) *AttendeeSe
 	}
 }
 
-// CreateAttendee creates a new attendee for a project
-func (s *AttendeeService) CreateAttendee(projectID int, req domain.CreateAttendeeRequest) (*domain.Attendee, error) {
+// CreateAttendee creates a new attendee for a project. Without a PIN in the request one is
+// generated and returned once in the credentials.
+func (s *AttendeeService) CreateAttendee(ctx context.Context, projectID int, req domain.CreateAttendeeRequest) (*domain.AttendeeCredentials, error) {
 	if projectID <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid project ID")
 	}
@@ -32,15 +38,158 @@ func (s *AttendeeService) CreateAttendee(projectID int, req domain.CreateAttende
 		return nil, domain.NewAPIError(400, "Attendee name must be less than 255 characters")
 	}
 
+	if req.IsFacilitator && req.IsObserver {
+		return nil, domain.NewAPIError(400, "An attendee cannot be both a facilitator and an observer")
+	}
+
+	pin, generated, err := provisionPIN(req.PIN)
+	if err != nil {
+		return nil, err
+	}
+
+	pinHash, err := HashPIN(pin)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to secure PIN", err.Error())
+	}
+
 	// Create the attendee
-	attendee, err := s.attendeeRepo.Create(projectID, req)
+	attendee, err := s.attendeeRepo.Create(projectID, req, pinHash)
 	if err != nil {
 		return nil, domain.NewAPIError(500, "Failed to create attendee", err.Error())
 	}
 
+	s.audit(ctx, projectID, domain.AuditActionCreate, domain.AuditEntityAttendee, attendee.ID, nil, attendee)
+
+	credentials := &domain.AttendeeCredentials{Attendee: attendee}
+	if generated {
+		credentials.PIN = pin
+	}
+	return credentials, nil
+}
+
+// SetPIN replaces an attendee's PIN, clears any login lockout and revokes the session tokens
+// issued to the attendee so far. An empty PIN generates a new one, which is returned once in
+// the credentials.
+func (s *AttendeeService) SetPIN(ctx context.Context, projectID, attendeeID int, pin string) (*domain.AttendeeCredentials, error) {
+	attendee, err := s.getProjectAttendee(projectID, attendeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	pin, generated, err := provisionPIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	pinHash, err := HashPIN(pin)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to secure PIN", err.Error())
+	}
+
+	version, err := s.attendeeRepo.ResetPIN(attendee.ID, pinHash)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to update PIN", err.Error())
+	}
+	before := *attendee
+	attendee.PinHash = pinHash
+	attendee.TokenVersion = version
+	attendee.RegisterSuccessfulLogin()
+
+	s.audit(ctx, projectID, domain.AuditActionSetPIN, domain.AuditEntityAttendee, attendee.ID, before, attendee)
+
+	credentials := &domain.AttendeeCredentials{Attendee: attendee}
+	if generated {
+		credentials.PIN = pin
+	}
+	return credentials, nil
+}
+
+// Login checks an attendee's PIN. Repeated failures lock the attendee out for
+// domain.LoginLockout, and a PIN stored with the legacy SHA-256 hash is rehashed on success.
+func (s *AttendeeService) Login(projectID, attendeeID int, pin string) (*domain.Attendee, error) {
+	attendee, err := s.getProjectAttendee(projectID, attendeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if attendee.IsLocked(now) {
+		return nil, domain.NewAPIError(429, "Too many failed logins. Try again later",
+			"locked until "+attendee.LockedUntil.UTC().Format(time.RFC3339))
+	}
+
+	if attendee.PinHash == "" {
+		return nil, domain.NewAPIError(401, "No PIN has been set for this attendee")
+	}
+
+	ok, needsRehash := VerifyPIN(attendee.PinHash, pin)
+	if !ok {
+		// Counted in the database, since other attempts may have failed during the check
+		if err := s.attendeeRepo.RecordFailedLogin(attendee, now, now.Add(domain.LoginLockout)); err != nil {
+			return nil, domain.NewAPIError(500, "Failed to record login attempt", err.Error())
+		}
+		if attendee.IsLocked(now) {
+			return nil, domain.NewAPIError(429, "Too many failed logins. Try again later",
+				"locked until "+attendee.LockedUntil.UTC().Format(time.RFC3339))
+		}
+		return nil, domain.NewAPIError(401, "Invalid PIN")
+	}
+
+	// Failed attempts running alongside this one may have locked the attendee out
+	allowed, err := s.attendeeRepo.RecordSuccessfulLogin(attendee.ID, now)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to record login attempt", err.Error())
+	}
+	if !allowed {
+		return nil, domain.NewAPIError(429, "Too many failed logins. Try again later")
+	}
+	attendee.RegisterSuccessfulLogin()
+
+	if needsRehash {
+		pinHash, err := HashPIN(pin)
+		if err == nil {
+			err = s.attendeeRepo.UpdatePINHash(attendee.ID, pinHash)
+		}
+		if err != nil {
+			fmt.Printf("Warning: failed to upgrade PIN hash for attendee %d: %v\n", attendee.ID, err)
+		} else {
+			attendee.PinHash = pinHash
+		}
+	}
+
 	return attendee, nil
 }
 
+// getProjectAttendee retrieves an attendee, checking that it belongs to the project
+func (s *AttendeeService) getProjectAttendee(projectID, attendeeID int) (*domain.Attendee, error) {
+	attendee, err := s.GetAttendee(attendeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attendee.ProjectID != projectID {
+		return nil, domain.NewAPIError(404, "Attendee not found in this project")
+	}
+
+	return attendee, nil
+}
+
+// provisionPIN validates a requested PIN, or generates one when none was requested
+func provisionPIN(pin string) (string, bool, error) {
+	if pin == "" {
+		generated, err := GeneratePIN(domain.GeneratedPINLength)
+		if err != nil {
+			return "", false, domain.NewAPIError(500, "Failed to generate PIN", err.Error())
+		}
+		return generated, true, nil
+	}
+
+	if err := domain.ValidatePIN(pin); err != nil {
+		return "", false, domain.NewAPIError(400, "PIN must be 4 to 8 digits")
+	}
+	return pin, false, nil
+}
+
 // GetAttendee retrieves an attendee by ID
 func (s *AttendeeService) GetAttendee(id int) (*domain.Attendee, error) {
 	if id <= 0 {
@@ -58,6 +207,21 @@ func (s *AttendeeService) GetAttendee(id int) (*domain.Attendee, error) {
 	return attendee, nil
 }
 
+// CurrentRole returns the role an attendee's session token grants now. Tokens outlive changes
+// to the attendee, so a token of a deleted attendee, of another project or issued before the
+// attendee's PIN was reset is refused as revoked.
+func (s *AttendeeService) CurrentRole(projectID, attendeeID, tokenVersion int) (domain.Role, error) {
+	attendee, err := s.GetAttendee(attendeeID)
+	if apiErr, ok := err.(*domain.APIError); err != nil && (!ok || apiErr.Code != 404) {
+		return "", err
+	}
+	if err != nil || attendee.ProjectID != projectID || attendee.TokenVersion != tokenVersion {
+		return "", domain.NewAPIError(401, "Session token has been revoked")
+	}
+
+	return attendee.AccessRole(), nil
+}
+
 // GetProjectAttendees retrieves all attendees for a project
 func (s *AttendeeService) GetProjectAttendees(projectID int) ([]domain.Attendee, error) {
 	if projectID <= 0 {
@@ -77,13 +241,29 @@ func (s *AttendeeService) GetProjectAttendees(projectID int) ([]domain.Attendee,
 	return attendees, nil
 }
 
+// HasFacilitator reports whether any attendee of a project is a facilitator
+func (s *AttendeeService) HasFacilitator(projectID int) (bool, error) {
+	attendees, err := s.GetProjectAttendees(projectID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, attendee := range attendees {
+		if attendee.IsFacilitator {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // DeleteAttendee deletes an attendee
-func (s *AttendeeService) DeleteAttendee(id int) error {
-	if id <= 0 {
-		return domain.NewAPIError(400, "Invalid attendee ID")
+func (s *AttendeeService) DeleteAttendee(ctx context.Context, id int) error {
+	before, err := s.GetAttendee(id)
+	if err != nil {
+		return err
 	}
 
-	err := s.attendeeRepo.Delete(id)
+	err = s.attendeeRepo.Delete(id)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			return domain.NewAPIError(404, "Attendee not found")
@@ -91,5 +271,7 @@ func (s *AttendeeService) DeleteAttendee(id int) error {
 		return domain.NewAPIError(500, "Failed to delete attendee", err.Error())
 	}
 
+	s.audit(ctx, before.ProjectID, domain.AuditActionDelete, domain.AuditEntityAttendee, id, before, nil)
+
 	return nil
 }
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+	"pairwise/internal/testutil"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestLoginLockoutConcurrent tests that wrong PINs guessed in parallel are all counted, so
+// that sending guesses at once does not get around the lockout
+func TestLoginLockoutConcurrent(t *testing.T) {
+	pinHashCost = bcrypt.MinCost
+
+	db := testutil.NewTestDB(t)
+	projectRepo := repository.NewProjectRepository(db)
+	attendeeRepo := repository.NewAttendeeRepository(db)
+	attendees := NewAttendeeService(attendeeRepo)
+
+	project, err := projectRepo.Create(domain.CreateProjectRequest{Name: "Test Project"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	pinHash, err := HashPIN("1234")
+	if err != nil {
+		t.Fatalf("Failed to hash PIN: %v", err)
+	}
+	attendee, err := attendeeRepo.Create(project.ID, domain.CreateAttendeeRequest{Name: "Alice"}, pinHash)
+	if err != nil {
+		t.Fatalf("Failed to create attendee: %v", err)
+	}
+
+	guesses := 2 * domain.MaxFailedLogins
+	codes := make(chan int, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := attendees.Login(project.ID, attendee.ID, "9999")
+			if apiErr, ok := err.(*domain.APIError); ok {
+				codes <- apiErr.Code
+			} else {
+				codes <- 0
+			}
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	// Every guess before the lockout is counted, so only the ones before the last allowed
+	// failure are told the PIN is wrong
+	rejected := 0
+	for code := range codes {
+		switch code {
+		case 401:
+			rejected++
+		case 429:
+		default:
+			t.Errorf("Expected wrong guesses to get 401 or 429, got %d", code)
+		}
+	}
+	if rejected >= domain.MaxFailedLogins {
+		t.Errorf("Expected at most %d guesses before the lockout, got %d", domain.MaxFailedLogins-1, rejected)
+	}
+
+	stored, err := attendeeRepo.GetByID(attendee.ID)
+	if err != nil {
+		t.Fatalf("Failed to get attendee: %v", err)
+	}
+	if stored.FailedLogins < domain.MaxFailedLogins || !stored.IsLocked(time.Now()) {
+		t.Errorf("Expected the attendee to be locked out, got %d failures until %v", stored.FailedLogins, stored.LockedUntil)
+	}
+
+	_, err = attendees.Login(project.ID, attendee.ID, "1234")
+	expectAPIError(t, err, 429)
+
+	// Setting a PIN clears the lockout
+	if _, err := attendees.SetPIN(context.Background(), project.ID, attendee.ID, "4321"); err != nil {
+		t.Fatalf("Failed to set PIN: %v", err)
+	}
+	if _, err := attendees.Login(project.ID, attendee.ID, "4321"); err != nil {
+		t.Errorf("Expected the new PIN to log in, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+)
+
+// Auditor records audit events for mutating actions
+type Auditor interface {
+	Record(ctx context.Context, event domain.AuditEvent)
+}
+
+// auditHook is embedded by services whose mutating actions are audited. It does nothing
+// until an auditor is set.
+type auditHook struct {
+	auditor Auditor
+}
+
+// SetAuditor sets the auditor that records the service's mutating actions
+func (h *auditHook) SetAuditor(auditor Auditor) {
+	h.auditor = auditor
+}
+
+// audit records an action on an entity by the actor of ctx. before and after are the states
+// of the entity around the action; pass nil for a state that does not exist.
+func (h *auditHook) audit(ctx context.Context, projectID int, action, entityType string, entityID int, before, after interface{}) {
+	if h.auditor == nil {
+		return
+	}
+
+	actor := domain.ActorFromContext(ctx)
+	event := domain.AuditEvent{
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		Action:     action,
+		EntityType: entityType,
+		Before:     auditState(before),
+		After:      auditState(after),
+		RequestID:  domain.RequestIDFromContext(ctx),
+	}
+	if projectID > 0 {
+		event.ProjectID = &projectID
+	}
+	if entityID > 0 {
+		event.EntityID = &entityID
+	}
+
+	h.auditor.Record(ctx, event)
+}
+
+// auditState marshals an entity state, returning nil for a missing state
+func auditState(state interface{}) json.RawMessage {
+	if state == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// optionalID returns the value of an optional ID, or zero when it is unset
+func optionalID(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// AuditService records and lists audit events
+type AuditService struct {
+	auditRepo   *repository.AuditRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo *repository.AuditRepository, projectRepo *repository.ProjectRepository) *AuditService {
+	return &AuditService{
+		auditRepo:   auditRepo,
+		projectRepo: projectRepo,
+	}
+}
+
+// Record stores an audit event. Failures are logged rather than returned, since the action
+// being audited has already happened.
+func (s *AuditService) Record(ctx context.Context, event domain.AuditEvent) {
+	if err := s.auditRepo.Create(event); err != nil {
+		fmt.Printf("Warning: failed to record audit event %s %s (request %s): %v\n",
+			event.EntityType, event.Action, event.RequestID, err)
+	}
+}
+
+// GetProjectAuditEvents returns a page of a project's audit events, newest first
+func (s *AuditService) GetProjectAuditEvents(filter domain.AuditFilter) (*domain.AuditPage, error) {
+	if _, err := s.projectRepo.GetByID(filter.ProjectID); err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Project not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to validate project", err.Error())
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = domain.DefaultAuditPageSize
+	}
+	if filter.PageSize > domain.MaxAuditPageSize {
+		filter.PageSize = domain.MaxAuditPageSize
+	}
+
+	events, total, err := s.auditRepo.List(filter)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to retrieve audit events", err.Error())
+	}
+
+	if events == nil {
+		events = []domain.AuditEvent{}
+	}
+
+	return &domain.AuditPage{
+		Events:   events,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"pairwise/internal/domain"
+)
+
+// recordingAuditor keeps the events it is given
+type recordingAuditor struct {
+	events []domain.AuditEvent
+}
+
+func (a *recordingAuditor) Record(ctx context.Context, event domain.AuditEvent) {
+	a.events = append(a.events, event)
+}
+
+// TestAuditHook tests that audit events carry the actor, request ID and entity states
+func TestAuditHook(t *testing.T) {
+	var hook auditHook
+	hook.audit(context.Background(), 1, domain.AuditActionCreate, domain.AuditEntityFeature, 2, nil, nil)
+
+	auditor := &recordingAuditor{}
+	hook.SetAuditor(auditor)
+
+	attendeeID := 7
+	ctx := domain.WithActor(context.Background(), domain.Actor{Type: domain.ActorAttendee, ID: &attendeeID})
+	ctx = domain.WithRequestID(ctx, "req_abc")
+
+	before := &domain.Feature{ID: 2, ProjectID: 1, Title: "Old"}
+	after := &domain.Feature{ID: 2, ProjectID: 1, Title: "New"}
+	hook.audit(ctx, 1, domain.AuditActionUpdate, domain.AuditEntityFeature, 2, before, after)
+
+	var missing *domain.Feature
+	hook.audit(context.Background(), 0, domain.AuditActionDelete, domain.AuditEntityAPIKey, 3, after, missing)
+
+	if len(auditor.events) != 2 {
+		t.Fatalf("Expected 2 events after the auditor was set, got %d", len(auditor.events))
+	}
+
+	update := auditor.events[0]
+	if update.ActorType != domain.ActorAttendee || update.ActorID == nil || *update.ActorID != attendeeID {
+		t.Errorf("Expected attendee %d as actor, got %s %v", attendeeID, update.ActorType, update.ActorID)
+	}
+	if update.RequestID != "req_abc" || update.ProjectID == nil || *update.ProjectID != 1 || update.EntityID == nil || *update.EntityID != 2 {
+		t.Errorf("Unexpected event details: %+v", update)
+	}
+	if len(update.Before) == 0 || len(update.After) == 0 || string(update.Before) == string(update.After) {
+		t.Errorf("Expected distinct before and after states, got %s and %s", update.Before, update.After)
+	}
+
+	deletion := auditor.events[1]
+	if deletion.ActorType != domain.ActorAnonymous || deletion.ActorID != nil {
+		t.Errorf("Expected an anonymous actor without a context actor, got %s", deletion.ActorType)
+	}
+	if deletion.ProjectID != nil || deletion.After != nil {
+		t.Errorf("Expected no project and no after state, got %v and %s", deletion.ProjectID, deletion.After)
+	}
+}
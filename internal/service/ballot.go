@@ -0,0 +1,29 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// BallotKeys derives the keys of anonymous ballots with a secret of their own. The secret
+// must stay the same for as long as ballots can be changed: a ballot cast under one secret
+// cannot be found under another. It is kept apart from the session token secret, which is
+// generated on start when not configured and rotated to revoke tokens.
+type BallotKeys struct {
+	secret []byte
+}
+
+// NewBallotKeys creates ballot keys derived with the given secret
+func NewBallotKeys(secret []byte) *BallotKeys {
+	return &BallotKeys{secret: secret}
+}
+
+// BallotKey derives the key of an attendee's anonymous ballot on a comparison. Without the
+// secret the key cannot be linked back to the attendee.
+func (k *BallotKeys) BallotKey(comparisonID, attendeeID int) string {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte(fmt.Sprintf("ballot:%d:%d", comparisonID, attendeeID)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
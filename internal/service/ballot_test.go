@@ -0,0 +1,19 @@
+package service
+
+import "testing"
+
+// TestBallotKey tests that ballot keys are stable per attendee and comparison and depend on the secret
+func TestBallotKey(t *testing.T) {
+	keys := NewBallotKeys([]byte("test-secret"))
+	key := keys.BallotKey(3, 7)
+
+	if key != keys.BallotKey(3, 7) {
+		t.Error("Expected the same key for the same attendee and comparison")
+	}
+	if key == keys.BallotKey(3, 8) || key == keys.BallotKey(4, 7) || key == keys.BallotKey(37, 0) {
+		t.Error("Expected different keys for other attendees and comparisons")
+	}
+	if key == NewBallotKeys([]byte("other-secret")).BallotKey(3, 7) {
+		t.Error("Expected the key to depend on the secret")
+	}
+}
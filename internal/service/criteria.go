@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+)
+
+// CriteriaService handles business logic for project criteria
+type CriteriaService struct {
+	auditHook
+	criteriaRepo  *repository.CriteriaRepository
+	projectRepo   *repository.ProjectRepository
+	pairwiseRepo  *repository.PairwiseRepository
+	fibonacciRepo *repository.FibonacciRepository
+}
+
+// NewCriteriaService creates a new criteria service
+func NewCriteriaService(
+	criteriaRepo *repository.CriteriaRepository,
+	projectRepo *repository.ProjectRepository,
+	pairwiseRepo *repository.PairwiseRepository,
+	fibonacciRepo *repository.FibonacciRepository,
+) *CriteriaService {
+	return &CriteriaService{
+		criteriaRepo:  criteriaRepo,
+		projectRepo:   projectRepo,
+		pairwiseRepo:  pairwiseRepo,
+		fibonacciRepo: fibonacciRepo,
+	}
+}
+
+// loadProjectCriteria retrieves a project's criteria. Projects created before criteria were
+// configurable get the value/complexity template saved on first use.
+func loadProjectCriteria(criteriaRepo *repository.CriteriaRepository, projectID int) ([]domain.Criterion, error) {
+	criteria, err := criteriaRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get project criteria", err.Error())
+	}
+
+	if len(criteria) == 0 {
+		return seedProjectCriteria(criteriaRepo, projectID, domain.DefaultCriteria())
+	}
+
+	return criteria, nil
+}
+
+// seedProjectCriteria saves the initial criteria of a project in order
+func seedProjectCriteria(criteriaRepo *repository.CriteriaRepository, projectID int, criteria []domain.Criterion) ([]domain.Criterion, error) {
+	seeded := make([]domain.Criterion, 0, len(criteria))
+	for _, criterion := range criteria {
+		created, err := criteriaRepo.Create(projectID, criterion)
+		if err != nil {
+			return nil, domain.NewAPIError(500, "Failed to save project criteria", err.Error())
+		}
+		seeded = append(seeded, *created)
+	}
+	return seeded, nil
+}
+
+// requireProjectCriterion checks that a criterion belongs to a project and returns it
+func requireProjectCriterion(criteriaRepo *repository.CriteriaRepository, projectID int, key domain.CriterionType) (domain.Criterion, error) {
+	criteria, err := loadProjectCriteria(criteriaRepo, projectID)
+	if err != nil {
+		return domain.Criterion{}, err
+	}
+
+	criterion, ok := domain.FindCriterion(criteria, key)
+	if !ok {
+		return domain.Criterion{}, domain.NewAPIError(400, fmt.Sprintf("Unknown criterion '%s' for this project", key))
+	}
+
+	return criterion, nil
+}
+
+// validateNewCriteria checks the criteria requested for a new project, filling in the default weight
+func validateNewCriteria(criteria []domain.CreateCriterionRequest) ([]domain.Criterion, error) {
+	validated := make([]domain.Criterion, 0, len(criteria))
+	seen := make(map[domain.CriterionType]bool)
+	for i, req := range criteria {
+		criterion := newCriterion(req)
+		criterion.Position = i
+		if err := domain.ValidateCriterion(criterion); err != nil {
+			return nil, domain.NewAPIError(400, "Invalid criterion", err.Error())
+		}
+		if seen[criterion.Key] {
+			return nil, domain.NewAPIError(400, fmt.Sprintf("Criterion '%s' is defined twice", criterion.Key))
+		}
+		seen[criterion.Key] = true
+		validated = append(validated, criterion)
+	}
+	return validated, nil
+}
+
+// newCriterion builds a criterion from a create request
+func newCriterion(req domain.CreateCriterionRequest) domain.Criterion {
+	weight := req.Weight
+	if weight == 0 {
+		weight = domain.DefaultCriterionWeight
+	}
+	return domain.Criterion{
+		Key:       req.Key,
+		Name:      req.Name,
+		Direction: req.Direction,
+		Weight:    weight,
+	}
+}
+
+// GetProjectCriteria retrieves the criteria of a project in workflow order
+func (s *CriteriaService) GetProjectCriteria(projectID int) ([]domain.Criterion, error) {
+	if err := s.requireProject(projectID); err != nil {
+		return nil, err
+	}
+
+	return loadProjectCriteria(s.criteriaRepo, projectID)
+}
+
+// CreateCriterion adds a criterion to the end of a project's workflow
+func (s *CriteriaService) CreateCriterion(ctx context.Context, projectID int, req domain.CreateCriterionRequest) (*domain.Criterion, error) {
+	if err := s.requireProject(projectID); err != nil {
+		return nil, err
+	}
+
+	criteria, err := loadProjectCriteria(s.criteriaRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	criterion := newCriterion(req)
+	if err := domain.ValidateCriterion(criterion); err != nil {
+		return nil, domain.NewAPIError(400, "Invalid criterion", err.Error())
+	}
+
+	if _, exists := domain.FindCriterion(criteria, criterion.Key); exists {
+		return nil, domain.NewAPIError(409, fmt.Sprintf("Criterion '%s' already exists", criterion.Key))
+	}
+
+	created, err := s.criteriaRepo.Create(projectID, criterion)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to create criterion", err.Error())
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionCreate, domain.AuditEntityCriterion, created.ID, nil, created)
+
+	return created, nil
+}
+
+// UpdateCriterion updates a criterion's name, direction and weight
+func (s *CriteriaService) UpdateCriterion(ctx context.Context, projectID, criterionID int, req domain.UpdateCriterionRequest) (*domain.Criterion, error) {
+	if err := s.requireProject(projectID); err != nil {
+		return nil, err
+	}
+
+	if !domain.IsValidCriterionDirection(req.Direction) {
+		return nil, domain.NewAPIError(400, "Criterion direction must be 'benefit' or 'cost'")
+	}
+
+	if req.Weight < 0 || req.Weight > domain.MaxCriterionWeight {
+		return nil, domain.NewAPIError(400, fmt.Sprintf("Criterion weight must be greater than 0 and at most %g", domain.MaxCriterionWeight))
+	}
+
+	before, err := s.criteriaRepo.GetByID(projectID, criterionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Criterion not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to get criterion", err.Error())
+	}
+
+	criterion, err := s.criteriaRepo.Update(projectID, criterionID, req)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Criterion not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to update criterion", err.Error())
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionUpdate, domain.AuditEntityCriterion, criterionID, before, criterion)
+
+	return criterion, nil
+}
+
+// DeleteCriterion removes a criterion that no session has used yet. A project always keeps
+// at least one criterion.
+func (s *CriteriaService) DeleteCriterion(ctx context.Context, projectID, criterionID int) error {
+	if err := s.requireProject(projectID); err != nil {
+		return err
+	}
+
+	criterion, err := s.criteriaRepo.GetByID(projectID, criterionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.NewAPIError(404, "Criterion not found")
+		}
+		return domain.NewAPIError(500, "Failed to get criterion", err.Error())
+	}
+
+	criteria, err := loadProjectCriteria(s.criteriaRepo, projectID)
+	if err != nil {
+		return err
+	}
+	if len(criteria) <= 1 {
+		return domain.NewAPIError(400, "A project needs at least one criterion")
+	}
+
+	if _, err := s.pairwiseRepo.GetLatestSessionByProjectAndCriterion(projectID, criterion.Key); err != domain.ErrNotFound {
+		return domain.NewAPIError(409, fmt.Sprintf("Criterion '%s' already has pairwise sessions", criterion.Key))
+	}
+	if _, err := s.fibonacciRepo.GetLatestSessionByProjectAndCriterion(projectID, criterion.Key); err != domain.ErrNotFound {
+		return domain.NewAPIError(409, fmt.Sprintf("Criterion '%s' already has Fibonacci sessions", criterion.Key))
+	}
+
+	if err := s.criteriaRepo.Delete(projectID, criterionID); err != nil {
+		if err == domain.ErrNotFound {
+			return domain.NewAPIError(404, "Criterion not found")
+		}
+		return domain.NewAPIError(500, "Failed to delete criterion", err.Error())
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionDelete, domain.AuditEntityCriterion, criterionID, criterion, nil)
+
+	return nil
+}
+
+// requireProject checks that a project exists
+func (s *CriteriaService) requireProject(projectID int) error {
+	if projectID <= 0 {
+		return domain.NewAPIError(400, "Invalid project ID")
+	}
+
+	if _, err := s.projectRepo.GetByID(projectID); err != nil {
+		if err == domain.ErrNotFound {
+			return domain.NewAPIError(404, "Project not found")
+		}
+		return domain.NewAPIError(500, "Failed to validate project", err.Error())
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 
 // FeatureService handles business logic for features
 type FeatureService struct {
+	auditHook
 	featureRepo *repository.FeatureRepository
 	projectRepo *repository.ProjectRepository
 }
@@ -25,7 +27,7 @@ func NewFeatureService(featureRepo *repository.FeatureRepository, projectRepo *r
 }
 
 // CreateFeature creates a new feature with validation
-func (s *FeatureService) CreateFeature(projectID int, req domain.CreateFeatureRequest) (*domain.Feature, error) {
+func (s *FeatureService) CreateFeature(ctx context.Context, projectID int, req domain.CreateFeatureRequest) (*domain.Feature, error) {
 	if projectID <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid project ID")
 	}
@@ -49,6 +51,8 @@ func (s *FeatureService) CreateFeature(projectID int, req domain.CreateFeatureRe
 		return nil, domain.NewAPIError(500, "Failed to create feature", err.Error())
 	}
 
+	s.audit(ctx, projectID, domain.AuditActionCreate, domain.AuditEntityFeature, feature.ID, nil, feature)
+
 	return feature, nil
 }
 
@@ -98,7 +102,7 @@ func (s *FeatureService) GetProjectFeatures(projectID int) ([]domain.Feature, er
 }
 
 // UpdateFeature updates an existing feature
-func (s *FeatureService) UpdateFeature(id int, req domain.UpdateFeatureRequest) (*domain.Feature, error) {
+func (s *FeatureService) UpdateFeature(ctx context.Context, id int, req domain.UpdateFeatureRequest) (*domain.Feature, error) {
 	if id <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid feature ID")
 	}
@@ -108,6 +112,11 @@ func (s *FeatureService) UpdateFeature(id int, req domain.UpdateFeatureRequest)
 		return nil, err
 	}
 
+	before, err := s.GetFeature(id)
+	if err != nil {
+		return nil, err
+	}
+
 	feature, err := s.featureRepo.Update(id, req)
 	if err != nil {
 		if err == domain.ErrNotFound {
@@ -116,16 +125,19 @@ func (s *FeatureService) UpdateFeature(id int, req domain.UpdateFeatureRequest)
 		return nil, domain.NewAPIError(500, "Failed to update feature", err.Error())
 	}
 
+	s.audit(ctx, before.ProjectID, domain.AuditActionUpdate, domain.AuditEntityFeature, id, before, feature)
+
 	return feature, nil
 }
 
 // DeleteFeature deletes a feature
-func (s *FeatureService) DeleteFeature(id int) error {
-	if id <= 0 {
-		return domain.NewAPIError(400, "Invalid feature ID")
+func (s *FeatureService) DeleteFeature(ctx context.Context, id int) error {
+	before, err := s.GetFeature(id)
+	if err != nil {
+		return err
 	}
 
-	err := s.featureRepo.Delete(id)
+	err = s.featureRepo.Delete(id)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			return domain.NewAPIError(404, "Feature not found")
@@ -133,11 +145,13 @@ func (s *FeatureService) DeleteFeature(id int) error {
 		return domain.NewAPIError(500, "Failed to delete feature", err.Error())
 	}
 
+	s.audit(ctx, before.ProjectID, domain.AuditActionDelete, domain.AuditEntityFeature, id, before, nil)
+
 	return nil
 }
 
 // ImportFeaturesFromCSV imports features from CSV data
-func (s *FeatureService) ImportFeaturesFromCSV(projectID int, csvData io.Reader) (*domain.CSVImportResult, error) {
+func (s *FeatureService) ImportFeaturesFromCSV(ctx context.Context, projectID int, csvData io.Reader) (*domain.CSVImportResult, error) {
 	if projectID <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid project ID")
 	}
@@ -217,11 +231,13 @@ func (s *FeatureService) ImportFeaturesFromCSV(projectID int, csvData io.Reader)
 	// Import valid features
 	var importedCount int
 	if len(validFeatures) > 0 {
-		_, err := s.featureRepo.CreateBatch(projectID, validFeatures)
+		imported, err := s.featureRepo.CreateBatch(projectID, validFeatures)
 		if err != nil {
 			return nil, domain.NewAPIError(500, "Failed to import features", err.Error())
 		}
 		importedCount = len(validFeatures)
+
+		s.audit(ctx, projectID, domain.AuditActionImport, domain.AuditEntityFeature, 0, nil, imported)
 	}
 
 	result := &domain.CSVImportResult{
@@ -0,0 +1,476 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+	"pairwise/internal/websocket"
+)
+
+// FibonacciBroadcaster defines the interface for broadcasting Fibonacci scoring updates.
+// Rounds publish to the Fibonacci topic of their project and criterion.
+type FibonacciBroadcaster interface {
+	NotifySessionStarted(topic websocket.Topic, started websocket.SessionStartedMessage)
+	NotifyFibonacciScore(topic websocket.Topic, score websocket.FibonacciScoreUpdateMessage)
+	NotifyFibonacciConsensus(topic websocket.Topic, consensus websocket.FibonacciConsensusMessage)
+	NotifySessionCompleted(topic websocket.Topic, completion websocket.SessionCompletedMessage)
+}
+
+// FibonacciService handles business logic for Fibonacci scoring sessions
+type FibonacciService struct {
+	auditHook
+	fibonacciRepo *repository.FibonacciRepository
+	featureRepo   *repository.FeatureRepository
+	attendeeRepo  *repository.AttendeeRepository
+	projectRepo   *repository.ProjectRepository
+	criteriaRepo  *repository.CriteriaRepository
+	wsBroadcaster FibonacciBroadcaster
+}
+
+// NewFibonacciService creates a new Fibonacci service
+func NewFibonacciService(
+	fibonacciRepo *repository.FibonacciRepository,
+	featureRepo *repository.FeatureRepository,
+	attendeeRepo *repository.AttendeeRepository,
+	projectRepo *repository.ProjectRepository,
+	criteriaRepo *repository.CriteriaRepository,
+) *FibonacciService {
+	return &FibonacciService{
+		fibonacciRepo: fibonacciRepo,
+		featureRepo:   featureRepo,
+		attendeeRepo:  attendeeRepo,
+		projectRepo:   projectRepo,
+		criteriaRepo:  criteriaRepo,
+	}
+}
+
+// SetWebSocketBroadcaster sets the WebSocket broadcaster for real-time notifications
+func (s *FibonacciService) SetWebSocketBroadcaster(broadcaster FibonacciBroadcaster) {
+	s.wsBroadcaster = broadcaster
+}
+
+// StartSession starts a new Fibonacci scoring session for a criterion
+func (s *FibonacciService) StartSession(ctx context.Context, projectID int, criterionType domain.CriterionType) (*domain.FibonacciSession, error) {
+	if projectID <= 0 {
+		return nil, domain.NewAPIError(400, "Invalid project ID")
+	}
+
+	_, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Project not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to validate project", err.Error())
+	}
+
+	if _, err := requireProjectCriterion(s.criteriaRepo, projectID, criterionType); err != nil {
+		return nil, err
+	}
+
+	existingSession, err := s.fibonacciRepo.GetActiveSessionByProjectAndCriterion(projectID, criterionType)
+	if err == nil && existingSession != nil {
+		return nil, domain.NewAPIError(409, fmt.Sprintf("Active %s scoring session already exists", criterionType))
+	}
+
+	features, err := s.featureRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get project features", err.Error())
+	}
+
+	if len(features) == 0 {
+		return nil, domain.NewAPIError(400, "At least 1 feature is required for Fibonacci scoring")
+	}
+
+	session, err := s.fibonacciRepo.CreateSession(projectID, criterionType)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to create Fibonacci session", err.Error())
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionStart, domain.AuditEntityFibonacciSession, session.ID, nil, session)
+
+	if s.wsBroadcaster != nil {
+		go s.wsBroadcaster.NotifySessionStarted(fibonacciTopic(session), websocket.SessionStartedMessage{
+			SessionID:     session.ID,
+			CriterionType: string(session.CriterionType),
+		})
+	}
+
+	return session, nil
+}
+
+// GetActiveSession retrieves the active Fibonacci session for a project and criterion with progress
+func (s *FibonacciService) GetActiveSession(projectID int, criterionType domain.CriterionType) (*domain.FibonacciSession, *domain.FibonacciSessionProgress, error) {
+	if projectID <= 0 {
+		return nil, nil, domain.NewAPIError(400, "Invalid project ID")
+	}
+
+	session, err := s.fibonacciRepo.GetActiveSessionByProjectAndCriterion(projectID, criterionType)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, nil, domain.NewAPIError(404, "No active scoring session found")
+		}
+		return nil, nil, domain.NewAPIError(500, "Failed to get active scoring session", err.Error())
+	}
+
+	progress, err := s.getProgress(session)
+	if err != nil {
+		return session, nil, nil // Return session even if progress calculation fails
+	}
+
+	return session, progress, nil
+}
+
+// GetSnapshot retrieves the latest Fibonacci round for a project and criterion with its
+// progress and scores. Completed rounds are included so that clients see how they ended.
+func (s *FibonacciService) GetSnapshot(projectID int, criterionType domain.CriterionType) (*domain.FibonacciSnapshot, error) {
+	snapshot := &domain.FibonacciSnapshot{Features: []domain.FeatureScoringStatus{}}
+
+	session, err := s.fibonacciRepo.GetLatestSessionByProjectAndCriterion(projectID, criterionType)
+	if err == domain.ErrNotFound {
+		return snapshot, nil
+	}
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get latest scoring session", err.Error())
+	}
+	snapshot.Session = session
+
+	if progress, err := s.getProgress(session); err == nil {
+		snapshot.Progress = progress
+	}
+
+	snapshot.Features, err = s.GetSessionScores(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// GetSessionScores retrieves the scoring state of every feature in a session
+func (s *FibonacciService) GetSessionScores(sessionID int) ([]domain.FeatureScoringStatus, error) {
+	session, err := s.getSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := s.featureRepo.GetByProjectID(session.ProjectID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get project features", err.Error())
+	}
+
+	scores, err := s.fibonacciRepo.GetScoresBySessionID(sessionID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get scores", err.Error())
+	}
+
+	consensusScores, err := s.fibonacciRepo.GetConsensusScoresBySessionID(sessionID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get consensus scores", err.Error())
+	}
+
+	scoresByFeature := make(map[int][]domain.FibonacciScore)
+	for _, score := range scores {
+		scoresByFeature[score.FeatureID] = append(scoresByFeature[score.FeatureID], score)
+	}
+
+	consensusByFeature := make(map[int]domain.ConsensusScore)
+	for _, consensus := range consensusScores {
+		consensusByFeature[consensus.FeatureID] = consensus
+	}
+
+	result := make([]domain.FeatureScoringStatus, 0, len(features))
+	for i := range features {
+		status := domain.FeatureScoringStatus{
+			Feature: &features[i],
+			Scores:  scoresByFeature[features[i].ID],
+		}
+		if status.Scores == nil {
+			status.Scores = []domain.FibonacciScore{}
+		}
+		if consensus, ok := consensusByFeature[features[i].ID]; ok {
+			status.Consensus = &consensus
+		}
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// SubmitScore submits or updates an attendee's Fibonacci score for a feature
+func (s *FibonacciService) SubmitScore(ctx context.Context, sessionID int, req domain.SubmitFibonacciScoreRequest) (*domain.FibonacciScore, error) {
+	session, err := s.getActiveSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateFibonacciScore(req.ScoreValue); err != nil {
+		return nil, domain.NewAPIError(400, "Invalid Fibonacci score", err.Error())
+	}
+
+	if err := s.validateFeature(session, req.FeatureID); err != nil {
+		return nil, err
+	}
+
+	attendee, err := s.attendeeRepo.GetByID(req.AttendeeID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Attendee not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to validate attendee", err.Error())
+	}
+
+	if attendee.ProjectID != session.ProjectID {
+		return nil, domain.NewAPIError(400, "Attendee does not belong to this project")
+	}
+
+	score := domain.FibonacciScore{
+		SessionID:  sessionID,
+		FeatureID:  req.FeatureID,
+		AttendeeID: req.AttendeeID,
+		ScoreValue: req.ScoreValue,
+	}
+
+	existingScore, err := s.fibonacciRepo.GetScore(sessionID, req.FeatureID, req.AttendeeID)
+	if err != nil && err != domain.ErrNotFound {
+		return nil, domain.NewAPIError(500, "Failed to check existing score", err.Error())
+	}
+
+	var saved *domain.FibonacciScore
+	if existingScore != nil {
+		if err := s.fibonacciRepo.UpdateScore(score); err != nil {
+			return nil, domain.NewAPIError(500, "Failed to update score", err.Error())
+		}
+
+		saved, err = s.fibonacciRepo.GetScore(sessionID, req.FeatureID, req.AttendeeID)
+		if err != nil {
+			return nil, domain.NewAPIError(500, "Failed to get updated score", err.Error())
+		}
+	} else {
+		saved, err = s.fibonacciRepo.CreateScore(score)
+		if err != nil {
+			return nil, domain.NewAPIError(500, "Failed to create score", err.Error())
+		}
+	}
+
+	s.audit(ctx, session.ProjectID, domain.AuditActionVote, domain.AuditEntityFibonacciScore, saved.ID, existingScore, saved)
+
+	if s.wsBroadcaster != nil {
+		go s.notifyScore(session, *saved, attendee.Name)
+	}
+
+	// Check for consensus but don't fail the score submission
+	if err := s.checkAndUpdateConsensus(session, req.FeatureID); err != nil {
+		fmt.Printf("Warning: Failed to check Fibonacci consensus: %v\n", err)
+	}
+
+	return saved, nil
+}
+
+// SetConsensusScore records a final score agreed on by the group after discussion
+func (s *FibonacciService) SetConsensusScore(ctx context.Context, sessionID int, req domain.SetConsensusScoreRequest) (*domain.ConsensusScore, error) {
+	session, err := s.getActiveSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateFibonacciScore(req.FinalScore); err != nil {
+		return nil, domain.NewAPIError(400, "Invalid Fibonacci score", err.Error())
+	}
+
+	if err := s.validateFeature(session, req.FeatureID); err != nil {
+		return nil, err
+	}
+
+	before, err := s.fibonacciRepo.GetConsensusScore(sessionID, req.FeatureID)
+	if err != nil && err != domain.ErrNotFound {
+		return nil, domain.NewAPIError(500, "Failed to check existing consensus score", err.Error())
+	}
+
+	consensus, err := s.fibonacciRepo.SetConsensusScore(sessionID, req.FeatureID, req.FinalScore)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to save consensus score", err.Error())
+	}
+
+	s.audit(ctx, session.ProjectID, domain.AuditActionUpdate, domain.AuditEntityConsensusScore, consensus.ID, before, consensus)
+
+	if s.wsBroadcaster != nil {
+		go s.notifyConsensus(session, *consensus, false)
+	}
+
+	return consensus, nil
+}
+
+// CompleteSession completes a Fibonacci session once every feature has a consensus score
+func (s *FibonacciService) CompleteSession(ctx context.Context, sessionID int) error {
+	session, err := s.getActiveSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	progress, err := s.getProgress(session)
+	if err != nil {
+		return domain.NewAPIError(500, "Failed to get session progress", err.Error())
+	}
+
+	if progress.RemainingFeatures > 0 {
+		return domain.NewAPIError(400, fmt.Sprintf("%d features still need a consensus score", progress.RemainingFeatures))
+	}
+
+	if err := s.fibonacciRepo.CompleteSession(sessionID); err != nil {
+		return domain.NewAPIError(500, "Failed to complete scoring session", err.Error())
+	}
+
+	completed, err := s.fibonacciRepo.GetSessionByID(sessionID)
+	if err == nil {
+		s.audit(ctx, session.ProjectID, domain.AuditActionComplete, domain.AuditEntityFibonacciSession, sessionID, session, completed)
+	}
+
+	if s.wsBroadcaster != nil {
+		completion := websocket.SessionCompletedMessage{
+			SessionID:      sessionID,
+			CriterionType:  string(session.CriterionType),
+			TotalConsensus: progress.ConsensusFeatures,
+		}
+		if completed != nil && completed.CompletedAt != nil {
+			completion.CompletedAt = *completed.CompletedAt
+		}
+		go s.wsBroadcaster.NotifySessionCompleted(fibonacciTopic(session), completion)
+	}
+
+	return nil
+}
+
+// checkAndUpdateConsensus records a consensus score when every attendee gave a feature the same score
+func (s *FibonacciService) checkAndUpdateConsensus(session *domain.FibonacciSession, featureID int) error {
+	attendees, err := s.attendeeRepo.GetByProjectID(session.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	scores, err := s.fibonacciRepo.GetScoresByFeature(session.ID, featureID)
+	if err != nil {
+		return err
+	}
+
+	finalScore, reached := domain.DetectFibonacciConsensus(scores, len(domain.Voters(attendees)))
+	if !reached {
+		return nil
+	}
+
+	consensus, err := s.fibonacciRepo.SetConsensusScore(session.ID, featureID, finalScore)
+	if err != nil {
+		return err
+	}
+
+	if s.wsBroadcaster != nil {
+		go s.notifyConsensus(session, *consensus, true)
+	}
+
+	return nil
+}
+
+// fibonacciTopic returns the WebSocket topic that carries a round's updates
+func fibonacciTopic(session *domain.FibonacciSession) websocket.Topic {
+	return websocket.FibonacciTopic(session.ProjectID, session.CriterionType)
+}
+
+// notifyScore sends a WebSocket notification about an attendee's score and how many
+// attendees have scored the feature
+func (s *FibonacciService) notifyScore(session *domain.FibonacciSession, score domain.FibonacciScore, attendeeName string) {
+	scores, err := s.fibonacciRepo.GetScoresByFeature(session.ID, score.FeatureID)
+	if err != nil {
+		fmt.Printf("Failed to get scores for notification: %v\n", err)
+		return
+	}
+
+	attendees, err := s.attendeeRepo.GetByProjectID(session.ProjectID)
+	if err != nil {
+		fmt.Printf("Failed to get attendees for notification: %v\n", err)
+		return
+	}
+
+	s.wsBroadcaster.NotifyFibonacciScore(fibonacciTopic(session), websocket.FibonacciScoreUpdateMessage{
+		SessionID:      session.ID,
+		FeatureID:      score.FeatureID,
+		AttendeeID:     score.AttendeeID,
+		AttendeeName:   attendeeName,
+		ScoreValue:     score.ScoreValue,
+		ScoresReceived: len(scores),
+		TotalAttendees: len(domain.Voters(attendees)),
+	})
+}
+
+// notifyConsensus sends a WebSocket notification about a feature's final score
+func (s *FibonacciService) notifyConsensus(session *domain.FibonacciSession, consensus domain.ConsensusScore, automatic bool) {
+	consensusMsg := websocket.FibonacciConsensusMessage{
+		SessionID:  session.ID,
+		FeatureID:  consensus.FeatureID,
+		FinalScore: consensus.FinalScore,
+		Automatic:  automatic,
+	}
+	if feature, err := s.featureRepo.GetByID(consensus.FeatureID); err == nil {
+		consensusMsg.FeatureName = feature.Title
+	}
+
+	s.wsBroadcaster.NotifyFibonacciConsensus(fibonacciTopic(session), consensusMsg)
+}
+
+// getProgress computes consensus progress against the project's current feature count
+func (s *FibonacciService) getProgress(session *domain.FibonacciSession) (*domain.FibonacciSessionProgress, error) {
+	features, err := s.featureRepo.GetByProjectID(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fibonacciRepo.GetSessionProgress(session.ID, len(features))
+}
+
+// getSession retrieves a session and maps repository errors to API errors
+func (s *FibonacciService) getSession(sessionID int) (*domain.FibonacciSession, error) {
+	if sessionID <= 0 {
+		return nil, domain.NewAPIError(400, "Invalid session ID")
+	}
+
+	session, err := s.fibonacciRepo.GetSessionByID(sessionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Scoring session not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to validate scoring session", err.Error())
+	}
+
+	return session, nil
+}
+
+// getActiveSession retrieves a session and ensures it is still accepting scores
+func (s *FibonacciService) getActiveSession(sessionID int) (*domain.FibonacciSession, error) {
+	session, err := s.getSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != domain.SessionStatusActive {
+		return nil, domain.NewAPIError(400, "Scoring session is not active")
+	}
+
+	return session, nil
+}
+
+// validateFeature ensures the feature exists and belongs to the session's project
+func (s *FibonacciService) validateFeature(session *domain.FibonacciSession, featureID int) error {
+	feature, err := s.featureRepo.GetByID(featureID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.NewAPIError(404, "Feature not found")
+		}
+		return domain.NewAPIError(500, "Failed to validate feature", err.Error())
+	}
+
+	if feature.ProjectID != session.ProjectID {
+		return domain.NewAPIError(400, "Feature does not belong to this project")
+	}
+
+	return nil
+}
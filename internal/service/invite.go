@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+)
+
+// joinCodeAttempts is how many codes are generated before giving up on finding an unused one
+const joinCodeAttempts = 5
+
+// InviteService handles join codes and invite links that let attendees register themselves
+type InviteService struct {
+	auditHook
+	inviteRepo      *repository.InviteRepository
+	attendeeService *AttendeeService
+	tokenService    *TokenService
+}
+
+// NewInviteService creates a new invite service
+func NewInviteService(inviteRepo *repository.InviteRepository, attendeeService *AttendeeService, tokenService *TokenService) *InviteService {
+	return &InviteService{
+		inviteRepo:      inviteRepo,
+		attendeeService: attendeeService,
+		tokenService:    tokenService,
+	}
+}
+
+// CreateInvite creates a join code for a project together with its signed invite token and URL
+func (s *InviteService) CreateInvite(ctx context.Context, projectID int, createdBy *int, req domain.CreateInviteRequest) (*domain.InviteResponse, error) {
+	if projectID <= 0 {
+		return nil, domain.NewAPIError(400, "Invalid project ID")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = domain.RoleAttendee
+	}
+	if role != domain.RoleAttendee && role != domain.RoleObserver {
+		return nil, domain.NewAPIError(400, "Invites can only be for attendees or observers")
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, domain.NewAPIError(400, "Invite expiry must be in the future")
+	}
+
+	if req.MaxUses != nil && *req.MaxUses < 1 {
+		return nil, domain.NewAPIError(400, "Invite must allow at least one use")
+	}
+
+	code, err := s.unusedJoinCode()
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := s.inviteRepo.Create(domain.ProjectInvite{
+		ProjectID: projectID,
+		Code:      code,
+		Role:      role,
+		ExpiresAt: req.ExpiresAt,
+		MaxUses:   req.MaxUses,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to create invite", err.Error())
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionCreate, domain.AuditEntityInvite, invite.ID, nil, invite)
+
+	return s.inviteResponse(invite)
+}
+
+// GetProjectInvites retrieves a project's invites with the attendees who joined with each one
+func (s *InviteService) GetProjectInvites(projectID int) ([]domain.ProjectInvite, error) {
+	if projectID <= 0 {
+		return nil, domain.NewAPIError(400, "Invalid project ID")
+	}
+
+	invites, err := s.inviteRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to retrieve invites", err.Error())
+	}
+
+	attendees, err := s.attendeeService.GetProjectAttendees(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	joined := make(map[int][]domain.Attendee)
+	for _, attendee := range attendees {
+		if attendee.InviteID != nil {
+			joined[*attendee.InviteID] = append(joined[*attendee.InviteID], attendee)
+		}
+	}
+
+	for i := range invites {
+		invites[i].Attendees = joined[invites[i].ID]
+	}
+
+	if invites == nil {
+		invites = []domain.ProjectInvite{}
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite stops an invite from being used. Attendees who already joined keep their access.
+func (s *InviteService) RevokeInvite(ctx context.Context, projectID, inviteID int) (*domain.ProjectInvite, error) {
+	before, err := s.inviteRepo.GetByID(projectID, inviteID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Invite not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to retrieve invite", err.Error())
+	}
+
+	if err := s.inviteRepo.Revoke(projectID, inviteID); err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Invite not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to revoke invite", err.Error())
+	}
+
+	invite, err := s.inviteRepo.GetByID(projectID, inviteID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to retrieve invite", err.Error())
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionRevoke, domain.AuditEntityInvite, inviteID, before, invite)
+
+	return invite, nil
+}
+
+// Join registers an attendee with a join code or a signed invite token. The attendee gets a
+// generated PIN, returned once in the credentials, so that they can log in again later.
+func (s *InviteService) Join(ctx context.Context, req domain.JoinProjectRequest) (*domain.AttendeeCredentials, error) {
+	code := normalizeJoinCode(req.Code)
+	if req.Invite != "" {
+		claims, err := s.tokenService.VerifyInvite(req.Invite)
+		if err != nil {
+			return nil, domain.NewAPIError(400, "Invalid invite link")
+		}
+		code = claims.Code
+	}
+
+	if code == "" {
+		return nil, domain.NewAPIError(400, "A join code or invite link is required")
+	}
+
+	invite, err := s.inviteRepo.GetByCode(code)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Unknown join code")
+		}
+		return nil, domain.NewAPIError(500, "Failed to retrieve invite", err.Error())
+	}
+
+	if err := invite.Check(time.Now()); err != nil {
+		return nil, domain.NewAPIError(410, "This invite can no longer be used", err.Error())
+	}
+
+	claimed, err := s.inviteRepo.ClaimUse(invite.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to use invite", err.Error())
+	}
+	if !claimed {
+		return nil, domain.NewAPIError(410, "This invite can no longer be used", domain.ErrInviteExhausted.Error())
+	}
+
+	credentials, err := s.attendeeService.CreateAttendee(ctx, invite.ProjectID, domain.CreateAttendeeRequest{
+		Name:       req.Name,
+		IsObserver: invite.Role == domain.RoleObserver,
+		InviteID:   &invite.ID,
+	})
+	if err != nil {
+		if releaseErr := s.inviteRepo.ReleaseUse(invite.ID); releaseErr != nil {
+			fmt.Printf("Warning: failed to release use of invite %d: %v\n", invite.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// inviteResponse adds the signed invite token and URL to an invite
+func (s *InviteService) inviteResponse(invite *domain.ProjectInvite) (*domain.InviteResponse, error) {
+	token, err := s.tokenService.SignInvite(invite.ProjectID, invite.Code)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to sign invite", err.Error())
+	}
+
+	return &domain.InviteResponse{
+		ProjectInvite: invite,
+		InviteToken:   token,
+		InviteURL:     "/join?invite=" + url.QueryEscape(token),
+	}, nil
+}
+
+// unusedJoinCode generates a join code that no invite uses yet
+func (s *InviteService) unusedJoinCode() (string, error) {
+	for attempt := 0; attempt < joinCodeAttempts; attempt++ {
+		code, err := GenerateJoinCode()
+		if err != nil {
+			return "", domain.NewAPIError(500, "Failed to generate join code", err.Error())
+		}
+
+		_, err = s.inviteRepo.GetByCode(code)
+		if err == domain.ErrNotFound {
+			return code, nil
+		}
+		if err != nil {
+			return "", domain.NewAPIError(500, "Failed to check join code", err.Error())
+		}
+	}
+
+	return "", domain.NewAPIError(500, "Failed to generate an unused join code")
+}
+
+// GenerateJoinCode returns a random join code from domain.JoinCodeAlphabet
+func GenerateJoinCode() (string, error) {
+	alphabet := big.NewInt(int64(len(domain.JoinCodeAlphabet)))
+	code := make([]byte, domain.JoinCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabet)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate join code: %w", err)
+		}
+		code[i] = domain.JoinCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// normalizeJoinCode accepts codes typed in lowercase or with spaces and dashes
+func normalizeJoinCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
@@ -1,28 +1,43 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	"pairwise/internal/domain"
 	"pairwise/internal/repository"
 	"pairwise/internal/websocket"
 )
 
-// WebSocketBroadcaster defines the interface for WebSocket broadcasting
+// WebSocketBroadcaster defines the interface for WebSocket broadcasting. Sessions publish
+// to the pairwise topic of their project and criterion.
 type WebSocketBroadcaster interface {
-	NotifyVoteSubmitted(sessionID int, voteUpdate websocket.VoteUpdateMessage)
-	NotifyConsensusReached(sessionID int, consensus websocket.ConsensusReachedMessage)
-	NotifySessionProgress(sessionID int, progress websocket.SessionProgressMessage)
-	NotifySessionCompleted(sessionID int, completion websocket.SessionCompletedMessage)
+	NotifySessionStarted(topic websocket.Topic, started websocket.SessionStartedMessage)
+	NotifyVoteSubmitted(topic websocket.Topic, voteUpdate websocket.VoteUpdateMessage)
+	NotifyConsensusReached(topic websocket.Topic, consensus websocket.ConsensusReachedMessage)
+	NotifySessionProgress(topic websocket.Topic, progress websocket.SessionProgressMessage)
+	NotifySessionCompleted(topic websocket.Topic, completion websocket.SessionCompletedMessage)
+	NotifyFocusChanged(topic websocket.Topic, focus websocket.FocusMessage)
+}
+
+// BallotKeyer derives the key of an attendee's anonymous ballot, so that they can change it
+// without the ballot naming them
+type BallotKeyer interface {
+	BallotKey(comparisonID, attendeeID int) string
 }
 
 // PairwiseService handles business logic for pairwise comparisons
 type PairwiseService struct {
+	auditHook
 	pairwiseRepo  *repository.PairwiseRepository
 	featureRepo   *repository.FeatureRepository
 	attendeeRepo  *repository.AttendeeRepository
 	projectRepo   *repository.ProjectRepository
+	criteriaRepo  *repository.CriteriaRepository
 	wsBroadcaster WebSocketBroadcaster
+	ballotKeyer   BallotKeyer
+	locks         sessionLocks
 }
 
 // NewPairwiseService creates a new pairwise service
@@ -31,12 +46,14 @@ func NewPairwiseService(
 	featureRepo *repository.FeatureRepository,
 	attendeeRepo *repository.AttendeeRepository,
 	projectRepo *repository.ProjectRepository,
+	criteriaRepo *repository.CriteriaRepository,
 ) *PairwiseService {
 	return &PairwiseService{
 		pairwiseRepo:  pairwiseRepo,
 		featureRepo:   featureRepo,
 		attendeeRepo:  attendeeRepo,
 		projectRepo:   projectRepo,
+		criteriaRepo:  criteriaRepo,
 		wsBroadcaster: nil, // Will be set via SetWebSocketBroadcaster
 	}
 }
@@ -46,12 +63,31 @@ func (s *PairwiseService) SetWebSocketBroadcaster(broadcaster WebSocketBroadcast
 	s.wsBroadcaster = broadcaster
 }
 
-// StartPairwiseSession starts a new pairwise comparison session
-func (s *PairwiseService) StartPairwiseSession(projectID int, criterionType domain.CriterionType) (*domain.PairwiseSession, error) {
+// SetBallotKeyer sets how anonymous ballots are keyed; anonymous sessions need one
+func (s *PairwiseService) SetBallotKeyer(keyer BallotKeyer) {
+	s.ballotKeyer = keyer
+}
+
+// StartPairwiseSession starts a new pairwise comparison session. The strategy decides
+// which pairs are compared; an empty strategy compares every pair. In anonymous sessions
+// ballots are kept apart from who cast them. Driven sessions are paced by the facilitator
+// with DriveSession.
+func (s *PairwiseService) StartPairwiseSession(ctx context.Context, projectID int, criterionType domain.CriterionType, strategy domain.SchedulingStrategy, anonymous, driven bool) (*domain.PairwiseSession, error) {
 	if projectID <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid project ID")
 	}
 
+	if anonymous && s.ballotKeyer == nil {
+		return nil, domain.NewAPIError(400, "Anonymous voting is not available")
+	}
+
+	if strategy == "" {
+		strategy = domain.SchedulingRoundRobin
+	}
+	if !domain.IsValidSchedulingStrategy(strategy) {
+		return nil, domain.NewAPIError(400, fmt.Sprintf("Invalid scheduling strategy: %s", strategy))
+	}
+
 	// Validate project exists
 	_, err := s.projectRepo.GetByID(projectID)
 	if err != nil {
@@ -61,6 +97,10 @@ func (s *PairwiseService) StartPairwiseSession(projectID int, criterionType doma
 		return nil, domain.NewAPIError(500, "Failed to validate project", err.Error())
 	}
 
+	if _, err := requireProjectCriterion(s.criteriaRepo, projectID, criterionType); err != nil {
+		return nil, err
+	}
+
 	// Check if there's already an active session for this criterion
 	existingSession, err := s.pairwiseRepo.GetActiveSessionByProjectAndCriterion(projectID, criterionType)
 	if err == nil && existingSession != nil {
@@ -78,31 +118,94 @@ func (s *PairwiseService) StartPairwiseSession(projectID int, criterionType doma
 	}
 
 	// Create the session
-	session, err := s.pairwiseRepo.CreateSession(projectID, criterionType)
+	session, err := s.pairwiseRepo.CreateSession(projectID, criterionType, strategy, anonymous, driven)
 	if err != nil {
 		return nil, domain.NewAPIError(500, "Failed to create pairwise session", err.Error())
 	}
 
-	// Generate all unique feature pairs and create comparisons
-	err = s.generateComparisons(session.ID, features)
+	// Generate the first round of comparisons; later rounds follow as results come in
+	_, err = s.scheduleComparisons(session)
 	if err != nil {
 		return nil, domain.NewAPIError(500, "Failed to generate comparisons", err.Error())
 	}
 
+	s.audit(ctx, projectID, domain.AuditActionStart, domain.AuditEntityPairwiseSession, session.ID, nil, session)
+
+	if s.wsBroadcaster != nil {
+		go s.notifySessionStarted(session)
+	}
+
 	return session, nil
 }
 
-// generateComparisons creates all unique pairwise comparisons for features
-func (s *PairwiseService) generateComparisons(sessionID int, features []domain.Feature) error {
-	for i := 0; i < len(features); i++ {
-		for j := i + 1; j < len(features); j++ {
-			_, err := s.pairwiseRepo.CreateComparison(sessionID, features[i].ID, features[j].ID)
-			if err != nil {
-				return fmt.Errorf("failed to create comparison between feature %d and %d: %w", features[i].ID, features[j].ID, err)
-			}
+// scheduleComparisons asks the session's strategy for the next comparisons once every existing
+// comparison has a result, and creates them in a new round. It returns how many were added.
+func (s *PairwiseService) scheduleComparisons(session *domain.PairwiseSession) (int, error) {
+	scheduler, err := domain.NewComparisonScheduler(session.Strategy)
+	if err != nil {
+		return 0, err
+	}
+
+	features, err := s.featureRepo.GetByProjectID(session.ProjectID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get project features: %w", err)
+	}
+
+	featureIDs := make([]int, len(features))
+	for i, feature := range features {
+		featureIDs[i] = feature.ID
+	}
+
+	comparisons, err := s.pairwiseRepo.GetComparisonsBySessionID(session.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get comparisons: %w", err)
+	}
+
+	round := 0
+	compared := make(map[[2]int]bool, len(comparisons))
+	for _, comparison := range comparisons {
+		if !comparison.ConsensusReached {
+			return 0, nil
 		}
+		round = max(round, comparison.Round)
+		compared[[2]int{min(comparison.FeatureAID, comparison.FeatureBID), max(comparison.FeatureAID, comparison.FeatureBID)}] = true
+	}
+
+	added := 0
+	for _, pair := range scheduler.NextPairs(featureIDs, comparisons) {
+		key := [2]int{min(pair.FeatureAID, pair.FeatureBID), max(pair.FeatureAID, pair.FeatureBID)}
+		if pair.FeatureAID == pair.FeatureBID || compared[key] {
+			continue
+		}
+		compared[key] = true
+
+		_, err := s.pairwiseRepo.CreateComparison(session.ID, pair.FeatureAID, pair.FeatureBID, round+1)
+		if err != nil {
+			return added, fmt.Errorf("failed to create comparison between feature %d and %d: %w", pair.FeatureAID, pair.FeatureBID, err)
+		}
+		added++
+	}
+
+	return added, nil
+}
+
+// estimateProgress measures progress against the number of comparisons the session's strategy
+// is expected to need, since adaptive strategies only generate comparisons as results come in
+func (s *PairwiseService) estimateProgress(session *domain.PairwiseSession, progress *domain.SessionProgress) {
+	scheduler, err := domain.NewComparisonScheduler(session.Strategy)
+	if err != nil {
+		return
+	}
+
+	features, err := s.featureRepo.GetByProjectID(session.ProjectID)
+	if err != nil {
+		return
+	}
+
+	progress.EstimatedComparisons = scheduler.EstimatedComparisons(len(features))
+	if session.Status == domain.SessionStatusActive && progress.EstimatedComparisons > progress.TotalComparisons {
+		progress.ProgressPercentage = float64(progress.CompletedComparisons) / float64(progress.EstimatedComparisons) * 100
 	}
-	return nil
 }
 
 // GetActiveSession retrieves the active pairwise session for a project and criterion
@@ -123,6 +226,7 @@ func (s *PairwiseService) GetActiveSession(projectID int, criterionType domain.C
 	if err != nil {
 		return session, nil, nil // Return session even if progress calculation fails
 	}
+	s.estimateProgress(session, progress)
 
 	return session, progress, nil
 }
@@ -145,10 +249,40 @@ func (s *PairwiseService) GetSession(sessionID int) (*domain.PairwiseSession, *d
 	if err != nil {
 		return nil, nil, domain.NewAPIError(500, "Failed to get session progress", err.Error())
 	}
+	s.estimateProgress(session, progress)
 
 	return session, progress, nil
 }
 
+// GetSnapshot retrieves the latest session for a project and criterion with its progress and
+// comparisons. Completed sessions are included so that clients see how they ended.
+func (s *PairwiseService) GetSnapshot(projectID int, criterionType domain.CriterionType) (*domain.PairwiseSnapshot, error) {
+	snapshot := &domain.PairwiseSnapshot{Comparisons: []domain.ComparisonWithVotes{}}
+
+	latest, err := s.pairwiseRepo.GetLatestSessionByProjectAndCriterion(projectID, criterionType)
+	if err == domain.ErrNotFound {
+		return snapshot, nil
+	}
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get latest session", err.Error())
+	}
+
+	snapshot.Session, snapshot.Progress, err = s.GetSession(latest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	comparisons, err := s.GetSessionComparisons(latest.ID)
+	if err != nil {
+		return nil, err
+	}
+	if comparisons != nil {
+		snapshot.Comparisons = comparisons
+	}
+
+	return snapshot, nil
+}
+
 // GetSessionComparisons retrieves all comparisons for a session
 func (s *PairwiseService) GetSessionComparisons(sessionID int) ([]domain.ComparisonWithVotes, error) {
 	if sessionID <= 0 {
@@ -156,7 +290,7 @@ func (s *PairwiseService) GetSessionComparisons(sessionID int) ([]domain.Compari
 	}
 
 	// Validate session exists
-	_, err := s.pairwiseRepo.GetSessionByID(sessionID)
+	session, err := s.pairwiseRepo.GetSessionByID(sessionID)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			return nil, domain.NewAPIError(404, "Session not found")
@@ -169,28 +303,66 @@ func (s *PairwiseService) GetSessionComparisons(sessionID int) ([]domain.Compari
 		return nil, domain.NewAPIError(500, "Failed to get comparisons", err.Error())
 	}
 
+	voters, err := s.countVoters(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []domain.ComparisonWithVotes
 	for _, comparison := range comparisons {
-		votes, err := s.pairwiseRepo.GetVotesByComparisonID(comparison.ID)
+		withVotes, err := s.withVotes(session, comparison, voters)
 		if err != nil {
-			return nil, domain.NewAPIError(500, "Failed to get votes", err.Error())
+			return nil, err
 		}
-
-		result = append(result, domain.ComparisonWithVotes{
-			Comparison: &comparison,
-			Votes:      votes,
-		})
+		result = append(result, *withVotes)
 	}
 
 	return result, nil
 }
 
+// withVotes attaches the votes on a comparison and their tally. Anonymous sessions only get
+// the tally.
+func (s *PairwiseService) withVotes(session *domain.PairwiseSession, comparison domain.SessionComparison, voters int) (*domain.ComparisonWithVotes, error) {
+	votes, err := s.pairwiseRepo.GetVotesByComparisonID(comparison.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get votes", err.Error())
+	}
+
+	hidden := session.HidesVotesOn(comparison)
+	withBreakdown := !hidden && (!session.Anonymous || comparison.ConsensusReached || len(votes) >= voters)
+	tally := domain.TallyVotes(votes, voters, withBreakdown)
+
+	if session.Anonymous || hidden {
+		votes = []domain.AttendeeVote{}
+	}
+
+	return &domain.ComparisonWithVotes{
+		Comparison: &comparison,
+		Votes:      votes,
+		Tally:      &tally,
+	}, nil
+}
+
+// countVoters returns how many attendees of a project vote
+func (s *PairwiseService) countVoters(projectID int) (int, error) {
+	attendees, err := s.attendeeRepo.GetByProjectID(projectID)
+	if err != nil {
+		return 0, domain.NewAPIError(500, "Failed to get attendees", err.Error())
+	}
+	return len(domain.Voters(attendees)), nil
+}
+
 // SubmitVote submits or updates an attendee vote for a comparison
-func (s *PairwiseService) SubmitVote(sessionID int, req domain.SubmitVoteRequest) (*domain.AttendeeVote, error) {
+func (s *PairwiseService) SubmitVote(ctx context.Context, sessionID int, req domain.SubmitVoteRequest) (*domain.AttendeeVote, error) {
 	if sessionID <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid session ID")
 	}
 
+	// The vote is checked against the session and saved while the facilitator cannot close
+	// voting on the comparison, and while no other vote can decide it
+	unlock := s.locks.lock(sessionID)
+	defer unlock()
+
 	// Validate session exists and is active
 	session, err := s.pairwiseRepo.GetSessionByID(sessionID)
 	if err != nil {
@@ -217,6 +389,10 @@ func (s *PairwiseService) SubmitVote(sessionID int, req domain.SubmitVoteRequest
 		return nil, domain.NewAPIError(400, "Comparison does not belong to this session")
 	}
 
+	if !session.AcceptsVotesOn(comparison.ID) {
+		return nil, domain.NewAPIError(409, "Voting is not open on this comparison")
+	}
+
 	// Validate attendee exists and belongs to the project
 	attendee, err := s.attendeeRepo.GetByID(req.AttendeeID)
 	if err != nil {
@@ -245,6 +421,58 @@ func (s *PairwiseService) SubmitVote(sessionID int, req domain.SubmitVoteRequest
 		}
 	}
 
+	if err := domain.ValidateAHPIntensity(req.Intensity); err != nil {
+		return nil, domain.NewAPIError(400, "Invalid intensity", err.Error())
+	}
+
+	if req.IsTieVote && req.Intensity > domain.MinAHPIntensity {
+		return nil, domain.NewAPIError(400, "Tie votes cannot have an intensity above 1")
+	}
+
+	var vote *domain.AttendeeVote
+	if session.Anonymous {
+		vote, err = s.castAnonymousVote(ctx, session, req)
+	} else {
+		vote, err = s.castVote(ctx, session, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for consensus and auto-complete session if needed. Driven sessions decide
+	// comparisons when the facilitator reveals them.
+	if !session.Driven {
+		err = s.checkAndUpdateConsensus(sessionID, req.ComparisonID, session.ProjectID)
+		if err != nil {
+			// Log error but don't fail the vote submission
+			fmt.Printf("Warning: Failed to check consensus: %v\n", err)
+		}
+	}
+
+	// Send WebSocket notification about the vote update
+	if s.wsBroadcaster != nil {
+		go s.notifyVoteUpdate(session, req.ComparisonID, *vote)
+	}
+
+	return vote, nil
+}
+
+// SubmitActiveSessionVote submits a vote in the active session of a project and criterion,
+// for callers that know the criterion rather than the session
+func (s *PairwiseService) SubmitActiveSessionVote(ctx context.Context, projectID int, criterionType domain.CriterionType, req domain.SubmitVoteRequest) (*domain.AttendeeVote, error) {
+	session, err := s.pairwiseRepo.GetActiveSessionByProjectAndCriterion(projectID, criterionType)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(400, "No active pairwise session found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to get active session", err.Error())
+	}
+
+	return s.SubmitVote(ctx, session.ID, req)
+}
+
+// castVote creates or updates an attendee's vote in a session where votes are attributed
+func (s *PairwiseService) castVote(ctx context.Context, session *domain.PairwiseSession, req domain.SubmitVoteRequest) (*domain.AttendeeVote, error) {
 	// Check if attendee has already voted
 	existingVote, err := s.pairwiseRepo.GetVoteByAttendeeAndComparison(req.ComparisonID, req.AttendeeID)
 	var vote *domain.AttendeeVote
@@ -260,6 +488,7 @@ func (s *PairwiseService) SubmitVote(sessionID int, req domain.SubmitVoteRequest
 			AttendeeID:         req.AttendeeID,
 			PreferredFeatureID: req.PreferredFeatureID,
 			IsTieVote:          req.IsTieVote,
+			Intensity:          req.Intensity,
 		}
 
 		err = s.pairwiseRepo.UpdateVote(voteToUpdate)
@@ -279,6 +508,7 @@ func (s *PairwiseService) SubmitVote(sessionID int, req domain.SubmitVoteRequest
 			AttendeeID:         req.AttendeeID,
 			PreferredFeatureID: req.PreferredFeatureID,
 			IsTieVote:          req.IsTieVote,
+			Intensity:          req.Intensity,
 		}
 
 		vote, err = s.pairwiseRepo.CreateVote(newVote)
@@ -287,19 +517,47 @@ func (s *PairwiseService) SubmitVote(sessionID int, req domain.SubmitVoteRequest
 		}
 	}
 
-	// Check for consensus and auto-complete session if needed
-	err = s.checkAndUpdateConsensus(sessionID, req.ComparisonID, session.ProjectID)
-	if err != nil {
-		// Log error but don't fail the vote submission
-		fmt.Printf("Warning: Failed to check consensus: %v\n", err)
+	s.audit(ctx, session.ProjectID, domain.AuditActionVote, domain.AuditEntityVote, vote.ID, existingVote, vote)
+
+	return vote, nil
+}
+
+// castAnonymousVote records that an attendee voted and stores their ballot apart from that
+// record. The ballot key lets them change the ballot later, and the audit log only gets the
+// participation.
+func (s *PairwiseService) castAnonymousVote(ctx context.Context, session *domain.PairwiseSession, req domain.SubmitVoteRequest) (*domain.AttendeeVote, error) {
+	if s.ballotKeyer == nil {
+		return nil, domain.NewAPIError(500, "Anonymous voting is not available")
 	}
 
-	// Send WebSocket notification about the vote update
-	if s.wsBroadcaster != nil {
-		go s.notifyVoteUpdate(sessionID, req.ComparisonID, *vote)
+	participation, err := s.pairwiseRepo.SaveAnonymousVote(
+		domain.VoteParticipation{ComparisonID: req.ComparisonID, AttendeeID: req.AttendeeID},
+		domain.AnonymousBallot{
+			BallotKey:          s.ballotKeyer.BallotKey(req.ComparisonID, req.AttendeeID),
+			ComparisonID:       req.ComparisonID,
+			PreferredFeatureID: req.PreferredFeatureID,
+			IsTieVote:          req.IsTieVote,
+			Intensity:          req.Intensity,
+		},
+	)
+	if err == domain.ErrBallotNotFound {
+		return nil, domain.NewAPIError(409, "Your earlier ballot can no longer be changed")
+	}
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to save vote", err.Error())
 	}
 
-	return vote, nil
+	s.audit(ctx, session.ProjectID, domain.AuditActionVote, domain.AuditEntityComparison, req.ComparisonID, nil, participation)
+
+	// The voter gets their own ballot back; nobody else can see it
+	return &domain.AttendeeVote{
+		ComparisonID:       req.ComparisonID,
+		AttendeeID:         req.AttendeeID,
+		PreferredFeatureID: req.PreferredFeatureID,
+		IsTieVote:          req.IsTieVote,
+		Intensity:          req.Intensity,
+		VotedAt:            participation.VotedAt,
+	}, nil
 }
 
 // checkAndUpdateConsensus checks if consensus is reached and completes session if all comparisons are done
@@ -310,8 +568,20 @@ func (s *PairwiseService) checkAndUpdateConsensus(sessionID, comparisonID, proje
 		return err
 	}
 
+	return s.decideComparison(sessionID, comparisonID, projectID, len(domain.Voters(attendees)))
+}
+
+// decideComparison applies the project's consensus policy to the votes of a comparison cast
+// by the given number of voters, then moves the session on if it has a result
+func (s *PairwiseService) decideComparison(sessionID, comparisonID, projectID, voters int) error {
+	// The project's consensus policy decides how split votes are resolved
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+
 	// Check consensus for this specific comparison
-	err = s.pairwiseRepo.CheckConsensusAndUpdate(comparisonID, len(attendees))
+	err = s.pairwiseRepo.CheckConsensusAndUpdate(comparisonID, voters, project.ConsensusPolicy, project.SupermajorityThreshold)
 	if err != nil {
 		return err
 	}
@@ -327,42 +597,243 @@ func (s *PairwiseService) checkAndUpdateConsensus(sessionID, comparisonID, proje
 		go s.notifyConsensusReached(sessionID, comparisonID, comparison.WinnerID, comparison.IsTie)
 	}
 
-	// Check if all comparisons in the session have reached consensus
+	return s.completeSessionIfDone(sessionID)
+}
+
+// completeSessionIfDone schedules the next comparisons once every comparison has a result,
+// sends a progress update, and completes the session when the strategy needs no more comparisons.
+// Callers hold the session's lock, since the last comparisons may be decided at the same time
+// and only one of them may schedule the next round.
+func (s *PairwiseService) completeSessionIfDone(sessionID int) error {
 	progress, err := s.pairwiseRepo.GetSessionProgress(sessionID)
 	if err != nil {
 		return err
 	}
 
+	if progress.CompletedComparisons < progress.TotalComparisons || progress.TotalComparisons == 0 {
+		// Send progress notification
+		if s.wsBroadcaster != nil {
+			go s.notifySessionProgress(sessionID)
+		}
+		return nil
+	}
+
+	session, err := s.pairwiseRepo.GetSessionByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.Status != domain.SessionStatusActive {
+		return nil
+	}
+
+	// Adaptive strategies add the next comparisons as the current ones are decided
+	added, err := s.scheduleComparisons(session)
+	if err != nil {
+		return err
+	}
+
 	// Send progress notification
 	if s.wsBroadcaster != nil {
 		go s.notifySessionProgress(sessionID)
 	}
 
-	// If all comparisons are completed, mark session as completed
-	if progress.CompletedComparisons == progress.TotalComparisons && progress.TotalComparisons > 0 {
-		err = s.pairwiseRepo.CompleteSession(sessionID)
-		if err != nil {
-			return err
+	if added > 0 {
+		return nil
+	}
+
+	// All comparisons are completed, mark session as completed
+	err = s.pairwiseRepo.CompleteSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Send session completion notification
+	if s.wsBroadcaster != nil {
+		go s.notifySessionCompleted(sessionID, session)
+	}
+
+	return nil
+}
+
+// ResolveComparison records a facilitator's decision on a comparison with split votes.
+// It is only available to projects using the facilitator consensus policy.
+func (s *PairwiseService) ResolveComparison(ctx context.Context, sessionID int, req domain.ResolveComparisonRequest) (*domain.SessionComparison, error) {
+	if sessionID <= 0 {
+		return nil, domain.NewAPIError(400, "Invalid session ID")
+	}
+
+	unlock := s.locks.lock(sessionID)
+	defer unlock()
+
+	session, err := s.pairwiseRepo.GetSessionByID(sessionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Session not found")
 		}
+		return nil, domain.NewAPIError(500, "Failed to validate session", err.Error())
+	}
 
-		// Send session completion notification
-		if s.wsBroadcaster != nil {
-			session, err := s.pairwiseRepo.GetSessionByID(sessionID)
-			if err == nil {
-				go s.notifySessionCompleted(sessionID, session)
+	if session.Status != domain.SessionStatusActive {
+		return nil, domain.NewAPIError(400, "Session is not active")
+	}
+
+	project, err := s.projectRepo.GetByID(session.ProjectID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get project", err.Error())
+	}
+
+	if project.ConsensusPolicy != domain.ConsensusPolicyFacilitator {
+		return nil, domain.NewAPIError(400, "Project consensus policy does not allow facilitator decisions")
+	}
+
+	facilitator, err := s.attendeeRepo.GetByID(req.FacilitatorID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Attendee not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to validate attendee", err.Error())
+	}
+
+	if facilitator.ProjectID != session.ProjectID || !facilitator.IsFacilitator {
+		return nil, domain.NewAPIError(403, "Only the project facilitator can resolve a comparison")
+	}
+
+	comparison, err := s.pairwiseRepo.GetComparisonByID(req.ComparisonID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "Comparison not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to validate comparison", err.Error())
+	}
+
+	if comparison.SessionID != sessionID {
+		return nil, domain.NewAPIError(400, "Comparison does not belong to this session")
+	}
+
+	if comparison.ConsensusReached {
+		return nil, domain.NewAPIError(409, "Comparison already has a result")
+	}
+
+	if req.IsTie && req.WinnerID != nil {
+		return nil, domain.NewAPIError(400, "Cannot specify winner for tie decision")
+	}
+
+	if !req.IsTie && req.WinnerID == nil {
+		return nil, domain.NewAPIError(400, "Must specify winner for non-tie decision")
+	}
+
+	if req.WinnerID != nil && *req.WinnerID != comparison.FeatureAID && *req.WinnerID != comparison.FeatureBID {
+		return nil, domain.NewAPIError(400, "Winner must be one of the compared features")
+	}
+
+	if err := domain.ValidateAHPIntensity(req.Intensity); err != nil {
+		return nil, domain.NewAPIError(400, "Invalid intensity", err.Error())
+	}
+
+	intensity := req.Intensity
+	if req.IsTie {
+		intensity = 0
+	}
+
+	// The facilitator decides only once every attendee has voted and the votes are split
+	attendees, err := s.attendeeRepo.GetByProjectID(session.ProjectID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get attendees", err.Error())
+	}
+
+	votes, err := s.pairwiseRepo.GetVotesByComparisonID(comparison.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get votes", err.Error())
+	}
+
+	if len(votes) != len(domain.Voters(attendees)) {
+		return nil, domain.NewAPIError(400, fmt.Sprintf("%d attendees have not voted yet", len(domain.Voters(attendees))-len(votes)))
+	}
+
+	err = s.pairwiseRepo.SetComparisonResult(comparison.ID, req.WinnerID, req.IsTie, intensity, domain.ConsensusPolicyFacilitator)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to save comparison result", err.Error())
+	}
+
+	resolved, err := s.pairwiseRepo.GetComparisonByID(comparison.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get resolved comparison", err.Error())
+	}
+
+	s.audit(ctx, session.ProjectID, domain.AuditActionResolve, domain.AuditEntityComparison, comparison.ID, comparison, resolved)
+
+	if s.wsBroadcaster != nil {
+		go s.notifyConsensusReached(sessionID, comparison.ID, resolved.WinnerID, resolved.IsTie)
+	}
+
+	// Complete the session if this was the last open comparison
+	if err := s.completeSessionIfDone(sessionID); err != nil {
+		fmt.Printf("Warning: Failed to check session completion: %v\n", err)
+	}
+
+	return resolved, nil
+}
+
+// FindPreferenceCycles detects intransitive consensus results (A beats B, B beats C,
+// C beats A) in the latest pairwise session for a project and criterion
+func (s *PairwiseService) FindPreferenceCycles(projectID int, criterionType domain.CriterionType) (*domain.CycleAnalysis, error) {
+	if projectID <= 0 {
+		return nil, domain.NewAPIError(400, "Invalid project ID")
+	}
+
+	session, err := s.pairwiseRepo.GetLatestSessionByProjectAndCriterion(projectID, criterionType)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "No pairwise session found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to get pairwise session", err.Error())
+	}
+
+	comparisons, err := s.pairwiseRepo.GetComparisonsBySessionID(session.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get comparisons", err.Error())
+	}
+
+	cycles, truncated := domain.FindPreferenceCycles(comparisons, domain.DefaultMaxPreferenceCycles)
+
+	analysis := &domain.CycleAnalysis{
+		ProjectID:             projectID,
+		SessionID:             session.ID,
+		Criterion:             criterionType,
+		Cycles:                cycles,
+		CycleCount:            len(cycles),
+		Truncated:             truncated,
+		InvolvedComparisonIDs: []int{},
+	}
+	if analysis.Cycles == nil {
+		analysis.Cycles = []domain.PreferenceCycle{}
+	}
+
+	seen := make(map[int]bool)
+	for _, cycle := range cycles {
+		for _, comparison := range cycle.Comparisons {
+			if !seen[comparison.ID] {
+				seen[comparison.ID] = true
+				analysis.InvolvedComparisonIDs = append(analysis.InvolvedComparisonIDs, comparison.ID)
 			}
 		}
 	}
+	sort.Ints(analysis.InvolvedComparisonIDs)
 
-	return nil
+	return analysis, nil
 }
 
 // CompleteSession manually completes a pairwise session
-func (s *PairwiseService) CompleteSession(sessionID int) error {
+func (s *PairwiseService) CompleteSession(ctx context.Context, sessionID int) error {
 	if sessionID <= 0 {
 		return domain.NewAPIError(400, "Invalid session ID")
 	}
 
+	// Scheduling may be adding the next round at the same time
+	unlock := s.locks.lock(sessionID)
+	defer unlock()
+
 	// Validate session exists and is active
 	session, err := s.pairwiseRepo.GetSessionByID(sessionID)
 	if err != nil {
@@ -381,6 +852,10 @@ func (s *PairwiseService) CompleteSession(sessionID int) error {
 		return domain.NewAPIError(500, "Failed to complete session", err.Error())
 	}
 
+	if completed, err := s.pairwiseRepo.GetSessionByID(sessionID); err == nil {
+		s.audit(ctx, session.ProjectID, domain.AuditActionComplete, domain.AuditEntityPairwiseSession, sessionID, session, completed)
+	}
+
 	// Send session completion notification
 	if s.wsBroadcaster != nil {
 		go s.notifySessionCompleted(sessionID, session)
@@ -389,7 +864,9 @@ func (s *PairwiseService) CompleteSession(sessionID int) error {
 	return nil
 }
 
-// GetNextComparison finds the next comparison that needs votes from a specific attendee
+// GetNextComparison picks the next comparison that needs a vote from a specific attendee. Each attendee
+// gets their own deterministic order that balances feature exposure and avoids repeating a feature
+// back to back; the features may be presented in either order.
 func (s *PairwiseService) GetNextComparison(sessionID, attendeeID int) (*domain.ComparisonWithVotes, error) {
 	if sessionID <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid session ID")
@@ -418,32 +895,348 @@ func (s *PairwiseService) GetNextComparison(sessionID, attendeeID int) (*domain.
 		return nil, domain.NewAPIError(500, "Failed to get comparisons", err.Error())
 	}
 
-	// Find the first comparison where the attendee hasn't voted
+	// In driven sessions everyone votes on the comparison in focus, once voting on it opens
+	if session.Driven {
+		var focused []domain.SessionComparison
+		for _, comparison := range comparisons {
+			if session.AcceptsVotesOn(comparison.ID) {
+				focused = append(focused, comparison)
+			}
+		}
+		comparisons = focused
+	}
+
+	// Split the open comparisons the attendee still has to vote on from the ones already voted
+	var pending []domain.SessionComparison
+	var voted []domain.SessionComparison
+	attendeeVotes := make(map[int]domain.AttendeeVote)
 	for _, comparison := range comparisons {
-		_, err := s.pairwiseRepo.GetVoteByAttendeeAndComparison(comparison.ID, attendeeID)
+		vote, err := s.getAttendeeVote(session, comparison.ID, attendeeID)
 		if err == domain.ErrNotFound {
-			// Attendee hasn't voted on this comparison
-			votes, err := s.pairwiseRepo.GetVotesByComparisonID(comparison.ID)
-			if err != nil {
-				return nil, domain.NewAPIError(500, "Failed to get votes", err.Error())
+			if !comparison.ConsensusReached {
+				pending = append(pending, comparison)
 			}
-
-			return &domain.ComparisonWithVotes{
-				Comparison: &comparison,
-				Votes:      votes,
-			}, nil
+			continue
 		} else if err != nil {
 			return nil, domain.NewAPIError(500, "Failed to check existing vote", err.Error())
 		}
-		// If we reach here, attendee has already voted on this comparison
+
+		voted = append(voted, comparison)
+		attendeeVotes[comparison.ID] = *vote
+	}
+
+	if len(pending) == 0 {
+		// No comparisons found that need this attendee's vote
+		return nil, domain.NewAPIError(404, "No pending comparisons found")
+	}
+
+	sort.SliceStable(voted, func(i, j int) bool {
+		a, b := attendeeVotes[voted[i].ID], attendeeVotes[voted[j].ID]
+		if !a.VotedAt.Equal(b.VotedAt) {
+			return a.VotedAt.Before(b.VotedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	// Spread feature exposure, avoid back-to-back repeats and randomize placement per attendee
+	next := domain.OrderComparisons(pending, voted, domain.ComparisonSeed(sessionID, attendeeID))[0]
+
+	voters, err := s.countVoters(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVotes(session, next, voters)
+}
+
+// getAttendeeVote finds an attendee's vote on a comparison. In anonymous sessions only the
+// time of voting is known.
+func (s *PairwiseService) getAttendeeVote(session *domain.PairwiseSession, comparisonID, attendeeID int) (*domain.AttendeeVote, error) {
+	if !session.Anonymous {
+		return s.pairwiseRepo.GetVoteByAttendeeAndComparison(comparisonID, attendeeID)
+	}
+
+	participation, err := s.pairwiseRepo.GetParticipation(comparisonID, attendeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AttendeeVote{
+		ComparisonID: participation.ComparisonID,
+		AttendeeID:   participation.AttendeeID,
+		VotedAt:      participation.VotedAt,
+	}, nil
+}
+
+// GetVotingStatus tells which voters have voted on a comparison of the active session of a
+// project and criterion. Without a comparison ID it uses the oldest comparison still
+// waiting for a result, which is the one held up by attendees who have not voted. Only
+// participation is read, so anonymous ballots stay unlinked.
+func (s *PairwiseService) GetVotingStatus(projectID int, criterionType domain.CriterionType, comparisonID int) (*domain.VotingStatus, error) {
+	session, err := s.pairwiseRepo.GetActiveSessionByProjectAndCriterion(projectID, criterionType)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "No active session found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to get active session", err.Error())
+	}
+
+	comparisons, err := s.pairwiseRepo.GetComparisonsBySessionID(session.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get comparisons", err.Error())
+	}
+
+	// Driven sessions are waiting on the comparison in focus
+	if comparisonID == 0 && session.Driven && session.FocusComparisonID != nil {
+		comparisonID = *session.FocusComparisonID
+	}
+
+	status := &domain.VotingStatus{SessionID: session.ID, Voters: []domain.VoterStatus{}}
+	for _, comparison := range comparisons {
+		if comparisonID == 0 && !comparison.ConsensusReached {
+			status.ComparisonID = comparison.ID
+			break
+		}
+		if comparison.ID == comparisonID {
+			status.ComparisonID = comparisonID
+			break
+		}
+	}
+	if comparisonID != 0 && status.ComparisonID == 0 {
+		return nil, domain.NewAPIError(404, "Comparison not found in the active session")
+	}
+
+	attendees, err := s.attendeeRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get attendees", err.Error())
+	}
+
+	for _, voter := range domain.Voters(attendees) {
+		voterStatus := domain.VoterStatus{AttendeeID: voter.ID, AttendeeName: voter.Name}
+		if status.ComparisonID != 0 {
+			_, err := s.getAttendeeVote(session, status.ComparisonID, voter.ID)
+			if err != nil && err != domain.ErrNotFound {
+				return nil, domain.NewAPIError(500, "Failed to check existing vote", err.Error())
+			}
+			voterStatus.Voted = err == nil
+		}
+		status.Voters = append(status.Voters, voterStatus)
+	}
+
+	return status, nil
+}
+
+// GetFocus tells where the active driven session of a project and criterion is: the
+// comparison in focus, if any, and whether voting on it is open
+func (s *PairwiseService) GetFocus(projectID int, criterionType domain.CriterionType) (*domain.SessionFocus, error) {
+	session, err := s.activeDrivenSession(projectID, criterionType)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.focusOf(session)
+}
+
+// DriveSession takes a facilitator's step through the active driven session of a project and
+// criterion and returns where the session is afterwards. Focusing a comparison puts it up for
+// discussion; voting on it is then opened, closed and revealed, which decides it. Next moves
+// the focus to the following comparison without a result.
+func (s *PairwiseService) DriveSession(ctx context.Context, projectID int, criterionType domain.CriterionType, action domain.DriverAction, comparisonID int) (*domain.SessionFocus, error) {
+	session, err := s.activeDrivenSession(projectID, criterionType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Votes on the comparison in focus are either saved before the step or checked against
+	// the session after it, so none are cast after voting closes or missed by the reveal
+	unlock := s.locks.lock(session.ID)
+	defer unlock()
+
+	session, err = s.pairwiseRepo.GetSessionByID(session.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get active session", err.Error())
+	}
+	if session.Status != domain.SessionStatusActive {
+		return nil, domain.NewAPIError(404, "No active session found")
+	}
+	before := *session
+
+	comparisons, err := s.pairwiseRepo.GetComparisonsBySessionID(session.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get comparisons", err.Error())
+	}
+
+	var focused *domain.SessionComparison
+	for i := range comparisons {
+		if session.FocusComparisonID != nil && comparisons[i].ID == *session.FocusComparisonID {
+			focused = &comparisons[i]
+		}
+	}
+
+	switch action {
+	case domain.DriverActionFocus:
+		focused = nil
+		for i := range comparisons {
+			if comparisons[i].ID == comparisonID {
+				focused = &comparisons[i]
+			}
+		}
+		if focused == nil {
+			return nil, domain.NewAPIError(404, "Comparison not found in the active session")
+		}
+		session.FocusComparisonID = &focused.ID
+		session.FocusPhase = domain.FocusPhaseDiscussing
+
+	case domain.DriverActionOpenVoting:
+		if focused == nil {
+			return nil, domain.NewAPIError(409, "No comparison is in focus")
+		}
+		if focused.ConsensusReached {
+			return nil, domain.NewAPIError(409, "Comparison in focus already has a result")
+		}
+		session.FocusPhase = domain.FocusPhaseVoting
+
+	case domain.DriverActionCloseVoting:
+		if focused == nil || session.FocusPhase != domain.FocusPhaseVoting {
+			return nil, domain.NewAPIError(409, "Voting is not open")
+		}
+		session.FocusPhase = domain.FocusPhaseClosed
+
+	case domain.DriverActionReveal:
+		if focused == nil || (session.FocusPhase != domain.FocusPhaseVoting && session.FocusPhase != domain.FocusPhaseClosed) {
+			return nil, domain.NewAPIError(409, "No votes are waiting to be revealed")
+		}
+		session.FocusPhase = domain.FocusPhaseRevealed
+
+	case domain.DriverActionNext:
+		var next *domain.SessionComparison
+		start := 0
+		for i := range comparisons {
+			if focused != nil && comparisons[i].ID == focused.ID {
+				start = i + 1
+			}
+		}
+		// Look after the comparison in focus first, then wrap around to any left behind
+		for i := range comparisons {
+			candidate := &comparisons[(start+i)%len(comparisons)]
+			if !candidate.ConsensusReached && (focused == nil || candidate.ID != focused.ID) {
+				next = candidate
+				break
+			}
+		}
+		if next == nil {
+			session.FocusComparisonID = nil
+			session.FocusPhase = ""
+		} else {
+			session.FocusComparisonID = &next.ID
+			session.FocusPhase = domain.FocusPhaseDiscussing
+		}
+
+	default:
+		return nil, domain.NewAPIError(400, fmt.Sprintf("Invalid driver action: %s", action))
+	}
+
+	err = s.pairwiseRepo.UpdateSessionFocus(session.ID, session.FocusComparisonID, session.FocusPhase)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to update session focus", err.Error())
+	}
+
+	// Revealing decides the comparison among the attendees who voted in the meeting
+	if action == domain.DriverActionReveal {
+		votes, err := s.pairwiseRepo.GetVotesByComparisonID(focused.ID)
+		if err != nil {
+			return nil, domain.NewAPIError(500, "Failed to get votes", err.Error())
+		}
+		if len(votes) > 0 {
+			err = s.decideComparison(session.ID, focused.ID, projectID, len(votes))
+			if err != nil {
+				// Log error but still reveal the votes
+				fmt.Printf("Warning: Failed to check consensus: %v\n", err)
+			}
+		}
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionDrive, domain.AuditEntityPairwiseSession, session.ID, before, session)
+
+	focus, err := s.focusOf(session)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.wsBroadcaster != nil {
+		go s.notifyFocusChanged(session, focus)
+	}
+
+	return focus, nil
+}
+
+// activeDrivenSession retrieves the active session for a project and criterion, which must be
+// driven by the facilitator
+func (s *PairwiseService) activeDrivenSession(projectID int, criterionType domain.CriterionType) (*domain.PairwiseSession, error) {
+	session, err := s.pairwiseRepo.GetActiveSessionByProjectAndCriterion(projectID, criterionType)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "No active session found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to get active session", err.Error())
+	}
+
+	if !session.Driven {
+		return nil, domain.NewAPIError(400, "Session is not driven by the facilitator")
+	}
+
+	return session, nil
+}
+
+// focusOf describes where a driven session is, with the comparison in focus and its votes
+// once they are revealed
+func (s *PairwiseService) focusOf(session *domain.PairwiseSession) (*domain.SessionFocus, error) {
+	focus := &domain.SessionFocus{SessionID: session.ID, Phase: session.FocusPhase}
+	if session.FocusComparisonID == nil {
+		return focus, nil
+	}
+
+	comparison, err := s.pairwiseRepo.GetComparisonByID(*session.FocusComparisonID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to get comparison in focus", err.Error())
+	}
+
+	voters, err := s.countVoters(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	focus.Comparison, err = s.withVotes(session, *comparison, voters)
+	if err != nil {
+		return nil, err
+	}
+
+	return focus, nil
+}
+
+// sessionTopic returns the WebSocket topic that carries a session's updates
+func (s *PairwiseService) sessionTopic(sessionID int) (websocket.Topic, error) {
+	session, err := s.pairwiseRepo.GetSessionByID(sessionID)
+	if err != nil {
+		return websocket.Topic{}, err
 	}
+	return websocket.PairwiseTopic(session.ProjectID, session.CriterionType), nil
+}
 
-	// No comparisons found that need this attendee's vote
-	return nil, domain.NewAPIError(404, "No pending comparisons found")
+// notifySessionStarted sends a WebSocket notification about a new session
+func (s *PairwiseService) notifySessionStarted(session *domain.PairwiseSession) {
+	s.wsBroadcaster.NotifySessionStarted(websocket.PairwiseTopic(session.ProjectID, session.CriterionType), websocket.SessionStartedMessage{
+		SessionID:     session.ID,
+		CriterionType: string(session.CriterionType),
+		Anonymous:     session.Anonymous,
+		Driven:        session.Driven,
+	})
 }
 
-// notifyVoteUpdate sends a WebSocket notification about a vote update
-func (s *PairwiseService) notifyVoteUpdate(sessionID, comparisonID int, vote domain.AttendeeVote) {
+// notifyVoteUpdate sends a WebSocket notification about a vote update. In anonymous sessions
+// it only carries the vote count, and in driven sessions it leaves out the choice until the
+// facilitator reveals the comparison.
+func (s *PairwiseService) notifyVoteUpdate(session *domain.PairwiseSession, comparisonID int, vote domain.AttendeeVote) {
 	// Get attendee information
 	attendee, err := s.attendeeRepo.GetByID(vote.AttendeeID)
 	if err != nil {
@@ -473,21 +1266,33 @@ func (s *PairwiseService) notifyVoteUpdate(sessionID, comparisonID int, vote dom
 	}
 
 	voteUpdate := websocket.VoteUpdateMessage{
-		ComparisonID:       comparisonID,
-		AttendeeID:         vote.AttendeeID,
-		AttendeeName:       attendee.Name,
-		PreferredFeatureID: vote.PreferredFeatureID,
-		IsTieVote:          vote.IsTieVote,
-		VotesReceived:      len(votes),
-		TotalAttendees:     len(attendees),
-		ConsensusReached:   comparison.ConsensusReached,
+		SessionID:        session.ID,
+		ComparisonID:     comparisonID,
+		VotesReceived:    len(votes),
+		TotalAttendees:   len(domain.Voters(attendees)),
+		ConsensusReached: comparison.ConsensusReached,
+		Anonymous:        session.Anonymous,
+	}
+	if !session.Anonymous {
+		voteUpdate.AttendeeID = vote.AttendeeID
+		voteUpdate.AttendeeName = attendee.Name
+	}
+	if !session.Anonymous && !session.Driven {
+		voteUpdate.PreferredFeatureID = vote.PreferredFeatureID
+		voteUpdate.IsTieVote = vote.IsTieVote
 	}
 
-	s.wsBroadcaster.NotifyVoteSubmitted(sessionID, voteUpdate)
+	s.wsBroadcaster.NotifyVoteSubmitted(websocket.PairwiseTopic(session.ProjectID, session.CriterionType), voteUpdate)
 }
 
 // notifyConsensusReached sends a WebSocket notification about consensus
 func (s *PairwiseService) notifyConsensusReached(sessionID, comparisonID int, winnerID *int, isTie bool) {
+	topic, err := s.sessionTopic(sessionID)
+	if err != nil {
+		fmt.Printf("Failed to get session for consensus notification: %v\n", err)
+		return
+	}
+
 	// Get comparison details
 	comparison, err := s.pairwiseRepo.GetComparisonByID(comparisonID)
 	if err != nil {
@@ -496,6 +1301,7 @@ func (s *PairwiseService) notifyConsensusReached(sessionID, comparisonID int, wi
 	}
 
 	consensusMsg := websocket.ConsensusReachedMessage{
+		SessionID:    sessionID,
 		ComparisonID: comparisonID,
 		WinnerID:     winnerID,
 		IsTie:        isTie,
@@ -512,11 +1318,22 @@ func (s *PairwiseService) notifyConsensusReached(sessionID, comparisonID int, wi
 		consensusMsg.WinnerName = comparison.Winner.Title
 	}
 
-	s.wsBroadcaster.NotifyConsensusReached(sessionID, consensusMsg)
+	s.wsBroadcaster.NotifyConsensusReached(topic, consensusMsg)
+}
+
+// notifyFocusChanged sends a WebSocket notification about where a driven session is
+func (s *PairwiseService) notifyFocusChanged(session *domain.PairwiseSession, focus *domain.SessionFocus) {
+	s.wsBroadcaster.NotifyFocusChanged(websocket.PairwiseTopic(session.ProjectID, session.CriterionType), websocket.FocusMessage{Focus: focus})
 }
 
 // notifySessionProgress sends a WebSocket notification about session progress
 func (s *PairwiseService) notifySessionProgress(sessionID int) {
+	topic, err := s.sessionTopic(sessionID)
+	if err != nil {
+		fmt.Printf("Failed to get session for progress notification: %v\n", err)
+		return
+	}
+
 	progress, err := s.pairwiseRepo.GetSessionProgress(sessionID)
 	if err != nil {
 		fmt.Printf("Failed to get session progress for notification: %v\n", err)
@@ -531,7 +1348,7 @@ func (s *PairwiseService) notifySessionProgress(sessionID int) {
 		RemainingComparisons: progress.RemainingComparisons,
 	}
 
-	s.wsBroadcaster.NotifySessionProgress(sessionID, progressMsg)
+	s.wsBroadcaster.NotifySessionProgress(topic, progressMsg)
 }
 
 // notifySessionCompleted sends a WebSocket notification about session completion
@@ -550,5 +1367,5 @@ func (s *PairwiseService) notifySessionCompleted(sessionID int, session *domain.
 		TotalConsensus: progress.CompletedComparisons,
 	}
 
-	s.wsBroadcaster.NotifySessionCompleted(sessionID, completionMsg)
+	s.wsBroadcaster.NotifySessionCompleted(websocket.PairwiseTopic(session.ProjectID, session.CriterionType), completionMsg)
 }
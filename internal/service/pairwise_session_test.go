@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/repository"
+	"pairwise/internal/testutil"
+)
+
+// pairwiseFixture is a pairwise service on a migrated SQLite database with a project, its
+// voters and features
+type pairwiseFixture struct {
+	service    *PairwiseService
+	pairwise   *repository.PairwiseRepository
+	projectID  int
+	voterIDs   []int
+	featureIDs []int
+}
+
+// newPairwiseFixture creates a project with the given voters and features in a temporary
+// database
+func newPairwiseFixture(t *testing.T, voters []string, features []string) *pairwiseFixture {
+	t.Helper()
+
+	sqlDB := testutil.NewTestDB(t)
+	projectRepo := repository.NewProjectRepository(sqlDB)
+	criteriaRepo := repository.NewCriteriaRepository(sqlDB)
+	attendeeRepo := repository.NewAttendeeRepository(sqlDB)
+	featureRepo := repository.NewFeatureRepository(sqlDB)
+	fixture := &pairwiseFixture{pairwise: repository.NewPairwiseRepository(sqlDB)}
+	fixture.service = NewPairwiseService(fixture.pairwise, featureRepo, attendeeRepo, projectRepo, criteriaRepo)
+
+	project, err := NewProjectService(projectRepo, criteriaRepo).CreateProject(context.Background(), domain.CreateProjectRequest{Name: "Test Project"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	fixture.projectID = project.ID
+
+	for _, name := range voters {
+		attendee, err := attendeeRepo.Create(project.ID, domain.CreateAttendeeRequest{Name: name}, "")
+		if err != nil {
+			t.Fatalf("Failed to create attendee: %v", err)
+		}
+		fixture.voterIDs = append(fixture.voterIDs, attendee.ID)
+	}
+	for _, title := range features {
+		feature, err := featureRepo.Create(project.ID, domain.CreateFeatureRequest{Title: title, Description: title})
+		if err != nil {
+			t.Fatalf("Failed to create feature: %v", err)
+		}
+		fixture.featureIDs = append(fixture.featureIDs, feature.ID)
+	}
+
+	return fixture
+}
+
+// comparisons returns the comparisons of a session with their votes as the API shows them
+func (f *pairwiseFixture) comparisons(t *testing.T, sessionID int) []domain.ComparisonWithVotes {
+	t.Helper()
+
+	comparisons, err := f.service.GetSessionComparisons(sessionID)
+	if err != nil {
+		t.Fatalf("Failed to get comparisons: %v", err)
+	}
+	return comparisons
+}
+
+// expectAPIError checks that an error is an API error with the given status code
+func expectAPIError(t *testing.T, err error, code int) {
+	t.Helper()
+
+	var apiErr *domain.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != code {
+		t.Fatalf("Expected a %d, got %v", code, err)
+	}
+}
+
+// TestAnonymousPairwiseSession tests that anonymous ballots are counted once per voter, can
+// be changed, and are only broken down by choice once everyone has voted
+func TestAnonymousPairwiseSession(t *testing.T) {
+	ctx := context.Background()
+	f := newPairwiseFixture(t, []string{"Alice", "Bob"}, []string{"Search", "Export"})
+	alice, bob := f.voterIDs[0], f.voterIDs[1]
+	search, export := f.featureIDs[0], f.featureIDs[1]
+
+	_, err := f.service.StartPairwiseSession(ctx, f.projectID, domain.CriterionTypeValue, "", true, false)
+	expectAPIError(t, err, 400)
+
+	f.service.SetBallotKeyer(NewBallotKeys([]byte("ballot-secret")))
+	session, err := f.service.StartPairwiseSession(ctx, f.projectID, domain.CriterionTypeValue, "", true, false)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	comparisonID := f.comparisons(t, session.ID)[0].Comparison.ID
+
+	vote := func(attendeeID, preferred int) error {
+		_, err := f.service.SubmitVote(ctx, session.ID, domain.SubmitVoteRequest{
+			ComparisonID:       comparisonID,
+			AttendeeID:         attendeeID,
+			PreferredFeatureID: &preferred,
+		})
+		return err
+	}
+
+	t.Run("Tally hides choices until everyone voted", func(t *testing.T) {
+		if err := vote(alice, search); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		comparison := f.comparisons(t, session.ID)[0]
+		if len(comparison.Votes) != 0 {
+			t.Errorf("Expected no attributed votes, got %+v", comparison.Votes)
+		}
+		if comparison.Tally.VotesReceived != 1 || comparison.Tally.TieVotes != nil || comparison.Tally.FeatureVotes != nil {
+			t.Errorf("Expected only a count of 1, got %+v", comparison.Tally)
+		}
+	})
+
+	t.Run("Changed vote replaces the ballot", func(t *testing.T) {
+		if err := vote(alice, export); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if received := f.comparisons(t, session.ID)[0].Tally.VotesReceived; received != 1 {
+			t.Errorf("Expected 1 ballot after the change, got %d", received)
+		}
+	})
+
+	t.Run("Vote under another secret is refused", func(t *testing.T) {
+		f.service.SetBallotKeyer(NewBallotKeys([]byte("rotated-secret")))
+		defer f.service.SetBallotKeyer(NewBallotKeys([]byte("ballot-secret")))
+
+		expectAPIError(t, vote(alice, search), 409)
+		if received := f.comparisons(t, session.ID)[0].Tally.VotesReceived; received != 1 {
+			t.Errorf("Expected Alice to keep a single ballot, got %d", received)
+		}
+	})
+
+	t.Run("Everyone voted", func(t *testing.T) {
+		if err := vote(bob, export); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		comparison := f.comparisons(t, session.ID)[0]
+		if comparison.Tally.FeatureVotes[export] != 2 || comparison.Tally.FeatureVotes[search] != 0 {
+			t.Errorf("Expected both ballots for Export, got %+v", comparison.Tally)
+		}
+		if !comparison.Comparison.ConsensusReached || comparison.Comparison.WinnerID == nil || *comparison.Comparison.WinnerID != export {
+			t.Errorf("Expected Export to win, got %+v", comparison.Comparison)
+		}
+		if len(comparison.Votes) != 0 {
+			t.Errorf("Expected no attributed votes, got %+v", comparison.Votes)
+		}
+	})
+
+	t.Run("Participation is known without the ballot", func(t *testing.T) {
+		status, err := f.pairwise.GetParticipation(comparisonID, bob)
+		if err != nil || status.AttendeeID != bob {
+			t.Fatalf("Expected Bob's participation, got %+v %v", status, err)
+		}
+	})
+}
+
+// TestConcurrentRoundScheduling tests that when the last comparisons of a round are decided
+// at the same time, only one of them schedules the next round
+func TestConcurrentRoundScheduling(t *testing.T) {
+	ctx := context.Background()
+	f := newPairwiseFixture(t, []string{"Alice", "Bob", "Carol"}, []string{"Search", "Export", "Import", "Sync"})
+
+	session, err := f.service.StartPairwiseSession(ctx, f.projectID, domain.CriterionTypeValue, domain.SchedulingSwiss, false, false)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, comparison := range f.comparisons(t, session.ID) {
+		for _, attendeeID := range f.voterIDs {
+			wg.Add(1)
+			go func(c *domain.SessionComparison, attendeeID int) {
+				defer wg.Done()
+				<-start
+				_, err := f.service.SubmitVote(ctx, session.ID, domain.SubmitVoteRequest{
+					ComparisonID:       c.ID,
+					AttendeeID:         attendeeID,
+					PreferredFeatureID: &c.FeatureAID,
+				})
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}(comparison.Comparison, attendeeID)
+		}
+	}
+	close(start)
+	wg.Wait()
+
+	rounds := make(map[int]int)
+	pairs := make(map[[2]int]bool)
+	for _, comparison := range f.comparisons(t, session.ID) {
+		c := comparison.Comparison
+		pair := [2]int{min(c.FeatureAID, c.FeatureBID), max(c.FeatureAID, c.FeatureBID)}
+		if pairs[pair] {
+			t.Errorf("Features %d and %d were scheduled twice", pair[0], pair[1])
+		}
+		pairs[pair] = true
+		rounds[c.Round]++
+	}
+	if len(rounds) != 2 || rounds[2] == 0 {
+		t.Errorf("Expected a single second round, got comparisons per round %v", rounds)
+	}
+}
+
+// TestDrivenVotesRaceReveal tests that votes cast while the facilitator reveals a comparison
+// are either counted by the reveal or refused
+func TestDrivenVotesRaceReveal(t *testing.T) {
+	ctx := context.Background()
+	f := newPairwiseFixture(t, []string{"Alice", "Bob", "Carol", "Dave", "Erin", "Frank"}, []string{"Search", "Export", "Import"})
+
+	session, err := f.service.StartPairwiseSession(ctx, f.projectID, domain.CriterionTypeValue, "", false, true)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	comparison := f.comparisons(t, session.ID)[0].Comparison
+	for _, action := range []domain.DriverAction{domain.DriverActionFocus, domain.DriverActionOpenVoting} {
+		if _, err := f.service.DriveSession(ctx, f.projectID, domain.CriterionTypeValue, action, comparison.ID); err != nil {
+			t.Fatalf("Failed to %s: %v", action, err)
+		}
+	}
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	var revealed *domain.SessionFocus
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		var err error
+		revealed, err = f.service.DriveSession(ctx, f.projectID, domain.CriterionTypeValue, domain.DriverActionReveal, 0)
+		if err != nil {
+			t.Errorf("Failed to reveal: %v", err)
+		}
+	}()
+	for _, attendeeID := range f.voterIDs {
+		wg.Add(1)
+		go func(attendeeID int) {
+			defer wg.Done()
+			<-start
+			_, err := f.service.SubmitVote(ctx, session.ID, domain.SubmitVoteRequest{
+				ComparisonID:       comparison.ID,
+				AttendeeID:         attendeeID,
+				PreferredFeatureID: &comparison.FeatureAID,
+			})
+			var apiErr *domain.APIError
+			if err != nil && (!errors.As(err, &apiErr) || apiErr.Code != 409) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}(attendeeID)
+	}
+	close(start)
+	wg.Wait()
+
+	if revealed == nil {
+		t.FailNow()
+	}
+	stored := 0
+	for _, c := range f.comparisons(t, session.ID) {
+		if c.Comparison.ID == comparison.ID {
+			stored = len(c.Votes)
+		}
+	}
+	if counted := len(revealed.Comparison.Votes); counted != stored {
+		t.Errorf("Expected the reveal to show all %d stored votes, got %d", stored, counted)
+	}
+	if stored > 0 && !revealed.Comparison.Comparison.ConsensusReached {
+		t.Errorf("Expected the revealed comparison to be decided, got %+v", revealed.Comparison.Comparison)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// pinHashCost is the bcrypt cost for PIN hashes. bcrypt salts every hash, and its cost keeps
+// offline guessing of short PINs slow; lockouts limit online guessing.
+var pinHashCost = 12
+
+// HashPIN returns a salted bcrypt hash of a PIN
+func HashPIN(pin string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), pinHashCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash PIN: %w", err)
+	}
+	return string(hash), nil
+}
+
+// VerifyPIN checks a PIN against a stored hash. needsRehash is true when the PIN matched a
+// legacy unsalted SHA-256 hash, which should then be replaced by HashPIN.
+func VerifyPIN(hash, pin string) (ok bool, needsRehash bool) {
+	if hash == "" {
+		return false, false
+	}
+
+	if strings.HasPrefix(hash, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pin)) == nil, false
+	}
+
+	legacy := sha256.Sum256([]byte(pin))
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(hash)), []byte(hex.EncodeToString(legacy[:]))) == 1 {
+		return true, true
+	}
+	return false, false
+}
+
+// GeneratePIN returns a random numeric PIN of the given length
+func GeneratePIN(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate PIN: %w", err)
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
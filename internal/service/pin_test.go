@@ -0,0 +1,70 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Test that PINs are hashed with a salt and verified
+func TestHashPIN(t *testing.T) {
+	pinHashCost = bcrypt.MinCost
+
+	first, err := HashPIN("1234")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := HashPIN("1234")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if first == second {
+		t.Error("Expected hashes of the same PIN to differ by salt")
+	}
+	if !strings.HasPrefix(first, "$2") {
+		t.Errorf("Expected a bcrypt hash, got %s", first)
+	}
+
+	if ok, needsRehash := VerifyPIN(first, "1234"); !ok || needsRehash {
+		t.Errorf("Expected the PIN to verify without a rehash, got ok=%v needsRehash=%v", ok, needsRehash)
+	}
+	if ok, _ := VerifyPIN(first, "4321"); ok {
+		t.Error("Expected a wrong PIN to fail")
+	}
+	if ok, _ := VerifyPIN("", "1234"); ok {
+		t.Error("Expected an empty hash to fail")
+	}
+}
+
+// Test that legacy SHA-256 hashes verify and are flagged for upgrade
+func TestVerifyPINLegacyHash(t *testing.T) {
+	sum := sha256.Sum256([]byte("5678"))
+	legacy := hex.EncodeToString(sum[:])
+
+	if ok, needsRehash := VerifyPIN(legacy, "5678"); !ok || !needsRehash {
+		t.Errorf("Expected the legacy hash to verify and need a rehash, got ok=%v needsRehash=%v", ok, needsRehash)
+	}
+	if ok, needsRehash := VerifyPIN(legacy, "8765"); ok || needsRehash {
+		t.Errorf("Expected a wrong PIN to fail without a rehash, got ok=%v needsRehash=%v", ok, needsRehash)
+	}
+}
+
+// Test that generated PINs are numeric and of the requested length
+func TestGeneratePIN(t *testing.T) {
+	for _, length := range []int{4, 6, 8} {
+		pin, err := GeneratePIN(length)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pin) != length {
+			t.Errorf("Expected a %d digit PIN, got %q", length, pin)
+		}
+		if strings.Trim(pin, "0123456789") != "" {
+			t.Errorf("Expected only digits, got %q", pin)
+		}
+	}
+}
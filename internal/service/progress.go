@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"pairwise/internal/domain"
@@ -8,21 +9,40 @@ import (
 )
 
 type ProgressService struct {
+	auditHook
 	progressRepo *repository.ProgressRepository
 	projectRepo  *repository.ProjectRepository
 	attendeeRepo *repository.AttendeeRepository
 	featureRepo  *repository.FeatureRepository
+	criteriaRepo *repository.CriteriaRepository
 }
 
-func NewProgressService(progressRepo *repository.ProgressRepository, projectRepo *repository.ProjectRepository, attendeeRepo *repository.AttendeeRepository, featureRepo *repository.FeatureRepository) *ProgressService {
+func NewProgressService(progressRepo *repository.ProgressRepository, projectRepo *repository.ProjectRepository, attendeeRepo *repository.AttendeeRepository, featureRepo *repository.FeatureRepository, criteriaRepo *repository.CriteriaRepository) *ProgressService {
 	return &ProgressService{
 		progressRepo: progressRepo,
 		projectRepo:  projectRepo,
 		attendeeRepo: attendeeRepo,
 		featureRepo:  featureRepo,
+		criteriaRepo: criteriaRepo,
 	}
 }
 
+// loadProgress retrieves a project's progress along with the criteria that shape its phases
+func (s *ProgressService) loadProgress(projectID int) (*domain.ProjectProgress, error) {
+	progress, err := s.progressRepo.GetProjectProgress(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	criteria, err := loadProjectCriteria(s.criteriaRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+	progress.Criteria = domain.CriterionKeys(criteria)
+
+	return progress, nil
+}
+
 // GetProjectProgress retrieves the current progress for a project
 func (s *ProgressService) GetProjectProgress(projectID int) (*domain.ProjectProgress, error) {
 	// Ensure project exists
@@ -31,7 +51,7 @@ func (s *ProgressService) GetProjectProgress(projectID int) (*domain.ProjectProg
 		return nil, fmt.Errorf("project not found: %w", err)
 	}
 
-	progress, err := s.progressRepo.GetProjectProgress(projectID)
+	progress, err := s.loadProgress(projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project progress: %w", err)
 	}
@@ -46,8 +66,8 @@ func (s *ProgressService) GetProjectProgress(projectID int) (*domain.ProjectProg
 }
 
 // AdvanceToPhase attempts to advance the project to a specific phase
-func (s *ProgressService) AdvanceToPhase(projectID int, phase domain.WorkflowPhase) error {
-	progress, err := s.progressRepo.GetProjectProgress(projectID)
+func (s *ProgressService) AdvanceToPhase(ctx context.Context, projectID int, phase domain.WorkflowPhase) error {
+	progress, err := s.loadProgress(projectID)
 	if err != nil {
 		return fmt.Errorf("failed to get project progress: %w", err)
 	}
@@ -57,14 +77,25 @@ func (s *ProgressService) AdvanceToPhase(projectID int, phase domain.WorkflowPha
 	}
 
 	// Update current phase
+	before := *progress
 	progress.CurrentPhase = string(phase)
-	return s.progressRepo.UpdateProjectProgress(progress)
+	if err := s.progressRepo.UpdateProjectProgress(progress); err != nil {
+		return err
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionAdvance, domain.AuditEntityPhase, 0, before, progress)
+	return nil
 }
 
 // CompletePhase marks a phase as completed and advances to the next phase
-func (s *ProgressService) CompletePhase(projectID int, phase domain.WorkflowPhase) error {
+func (s *ProgressService) CompletePhase(ctx context.Context, projectID int, phase domain.WorkflowPhase) error {
+	progress, err := s.loadProgress(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project progress: %w", err)
+	}
+
 	// Validate prerequisites are met
-	isValid, err := s.validatePhaseCompletion(projectID, phase)
+	isValid, err := s.validatePhaseCompletion(progress, phase)
 	if err != nil {
 		return fmt.Errorf("failed to validate phase completion: %w", err)
 	}
@@ -73,60 +104,48 @@ func (s *ProgressService) CompletePhase(projectID int, phase domain.WorkflowPhas
 		return fmt.Errorf("phase %s cannot be completed: requirements not met", phase)
 	}
 
-	return s.progressRepo.MarkPhaseCompleted(projectID, phase)
+	if err := s.progressRepo.MarkPhaseCompleted(projectID, phase, progress.Criteria); err != nil {
+		return err
+	}
+
+	if completed, err := s.loadProgress(projectID); err == nil {
+		s.audit(ctx, projectID, domain.AuditActionComplete, domain.AuditEntityPhase, 0, progress, completed)
+	}
+	return nil
 }
 
 // validatePhaseCompletion checks if a phase can actually be completed based on data
-func (s *ProgressService) validatePhaseCompletion(projectID int, phase domain.WorkflowPhase) (bool, error) {
+func (s *ProgressService) validatePhaseCompletion(progress *domain.ProjectProgress, phase domain.WorkflowPhase) (bool, error) {
 	switch phase {
 	case domain.PhaseSetup:
 		// Project must exist (already validated in calling functions)
 		return true, nil
 
 	case domain.PhaseAttendees:
-		attendees, err := s.attendeeRepo.GetByProjectID(projectID)
+		attendees, err := s.attendeeRepo.GetByProjectID(progress.ProjectID)
 		if err != nil {
 			return false, err
 		}
 		// Require at least 2 attendees for pairwise comparisons
-		return len(attendees) >= 2, nil
+		return len(domain.Voters(attendees)) >= 2, nil
 
 	case domain.PhaseFeatures:
-		features, err := s.featureRepo.GetByProjectID(projectID)
+		features, err := s.featureRepo.GetByProjectID(progress.ProjectID)
 		if err != nil {
 			return false, err
 		}
 		// Require at least 2 features for comparisons
 		return len(features) >= 2, nil
 
-	case domain.PhasePairwiseValue:
-		// Check if all value comparisons are complete
-		// This would require checking the pairwise comparison data
-		// For now, assume it's valid if we reach this point
-		return true, nil
-
-	case domain.PhasePairwiseComplexity:
-		// Check if all complexity comparisons are complete
-		return true, nil
-
-	case domain.PhaseFibonacciValue:
-		// Check if all value scores are assigned
-		return true, nil
-
-	case domain.PhaseFibonacciComplexity:
-		// Check if all complexity scores are assigned
-		return true, nil
-
 	case domain.PhaseResults:
 		// All previous phases must be completed
-		progressData, err := s.progressRepo.GetProjectProgress(projectID)
-		if err != nil {
-			return false, err
-		}
-		return progressData.FibonacciComplexityCompleted, nil
+		return progress.CanProgressTo(domain.PhaseResults), nil
 
 	default:
-		return false, nil
+		// Pairwise and Fibonacci phases exist for each of the project's criteria.
+		// Checking that every comparison or score is complete would require the session
+		// data, so for now any phase of the workflow is valid if we reach this point.
+		return progress.HasPhase(phase), nil
 	}
 }
 
@@ -136,7 +155,7 @@ func (s *ProgressService) updateProgressBasedOnData(progress *domain.ProjectProg
 
 	// Check attendees
 	attendees, err := s.attendeeRepo.GetByProjectID(progress.ProjectID)
-	if err == nil && len(attendees) >= 2 && !progress.AttendeesAdded {
+	if err == nil && len(domain.Voters(attendees)) >= 2 && !progress.AttendeesAdded {
 		progress.AttendeesAdded = true
 		updated = true
 	}
@@ -164,25 +183,14 @@ func (s *ProgressService) updateProgressBasedOnData(progress *domain.ProjectProg
 
 // GetAvailablePhases returns the phases that can be accessed based on current progress
 func (s *ProgressService) GetAvailablePhases(projectID int) ([]domain.WorkflowPhase, error) {
-	progress, err := s.progressRepo.GetProjectProgress(projectID)
+	progress, err := s.loadProgress(projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project progress: %w", err)
 	}
 
 	var availablePhases []domain.WorkflowPhase
 
-	phases := []domain.WorkflowPhase{
-		domain.PhaseSetup,
-		domain.PhaseAttendees,
-		domain.PhaseFeatures,
-		domain.PhasePairwiseValue,
-		domain.PhasePairwiseComplexity,
-		domain.PhaseFibonacciValue,
-		domain.PhaseFibonacciComplexity,
-		domain.PhaseResults,
-	}
-
-	for _, phase := range phases {
+	for _, phase := range progress.Phases() {
 		if progress.CanProgressTo(phase) {
 			availablePhases = append(availablePhases, phase)
 		}
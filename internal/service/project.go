@@ -1,24 +1,29 @@
 package service
 
 import (
+	"context"
+
 	"pairwise/internal/domain"
 	"pairwise/internal/repository"
 )
 
 // ProjectService handles business logic for projects
 type ProjectService struct {
-	projectRepo *repository.ProjectRepository
+	auditHook
+	projectRepo  *repository.ProjectRepository
+	criteriaRepo *repository.CriteriaRepository
 }
 
 // NewProjectService creates a new project service
-func NewProjectService(projectRepo *repository.ProjectRepository) *ProjectService {
+func NewProjectService(projectRepo *repository.ProjectRepository, criteriaRepo *repository.CriteriaRepository) *ProjectService {
 	return &ProjectService{
-		projectRepo: projectRepo,
+		projectRepo:  projectRepo,
+		criteriaRepo: criteriaRepo,
 	}
 }
 
 // CreateProject creates a new project with validation
-func (s *ProjectService) CreateProject(req domain.CreateProjectRequest) (*domain.Project, error) {
+func (s *ProjectService) CreateProject(ctx context.Context, req domain.CreateProjectRequest) (*domain.Project, error) {
 	// Basic validation
 	if req.Name == "" {
 		return nil, domain.NewAPIError(400, "Project name is required")
@@ -28,12 +33,31 @@ func (s *ProjectService) CreateProject(req domain.CreateProjectRequest) (*domain
 		return nil, domain.NewAPIError(400, "Project name must be less than 255 characters")
 	}
 
+	if err := validateConsensusSettings(req.ConsensusPolicy, req.SupermajorityThreshold); err != nil {
+		return nil, err
+	}
+
+	criteria := domain.DefaultCriteria()
+	if len(req.Criteria) > 0 {
+		var err error
+		criteria, err = validateNewCriteria(req.Criteria)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the project
 	project, err := s.projectRepo.Create(req)
 	if err != nil {
 		return nil, domain.NewAPIError(500, "Failed to create project", err.Error())
 	}
 
+	if _, err := seedProjectCriteria(s.criteriaRepo, project.ID, criteria); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, project.ID, domain.AuditActionCreate, domain.AuditEntityProject, project.ID, nil, project)
+
 	return project, nil
 }
 
@@ -55,7 +79,7 @@ func (s *ProjectService) GetProject(id int) (*domain.Project, error) {
 }
 
 // UpdateProject updates an existing project
-func (s *ProjectService) UpdateProject(id int, req domain.UpdateProjectRequest) (*domain.Project, error) {
+func (s *ProjectService) UpdateProject(ctx context.Context, id int, req domain.UpdateProjectRequest) (*domain.Project, error) {
 	if id <= 0 {
 		return nil, domain.NewAPIError(400, "Invalid project ID")
 	}
@@ -81,6 +105,15 @@ func (s *ProjectService) UpdateProject(id int, req domain.UpdateProjectRequest)
 		}
 	}
 
+	if err := validateConsensusSettings(req.ConsensusPolicy, req.SupermajorityThreshold); err != nil {
+		return nil, err
+	}
+
+	before, err := s.GetProject(id)
+	if err != nil {
+		return nil, err
+	}
+
 	project, err := s.projectRepo.Update(id, req)
 	if err != nil {
 		if err == domain.ErrNotFound {
@@ -89,16 +122,19 @@ func (s *ProjectService) UpdateProject(id int, req domain.UpdateProjectRequest)
 		return nil, domain.NewAPIError(500, "Failed to update project", err.Error())
 	}
 
+	s.audit(ctx, id, domain.AuditActionUpdate, domain.AuditEntityProject, id, before, project)
+
 	return project, nil
 }
 
 // DeleteProject deletes a project
-func (s *ProjectService) DeleteProject(id int) error {
-	if id <= 0 {
-		return domain.NewAPIError(400, "Invalid project ID")
+func (s *ProjectService) DeleteProject(ctx context.Context, id int) error {
+	before, err := s.GetProject(id)
+	if err != nil {
+		return err
 	}
 
-	err := s.projectRepo.Delete(id)
+	err = s.projectRepo.Delete(id)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			return domain.NewAPIError(404, "Project not found")
@@ -106,6 +142,8 @@ func (s *ProjectService) DeleteProject(id int) error {
 		return domain.NewAPIError(500, "Failed to delete project", err.Error())
 	}
 
+	s.audit(ctx, id, domain.AuditActionDelete, domain.AuditEntityProject, id, before, nil)
+
 	return nil
 }
 
@@ -123,3 +161,16 @@ func (s *ProjectService) ListProjects() ([]domain.Project, error) {
 
 	return projects, nil
 }
+
+// validateConsensusSettings validates an optional consensus policy and supermajority threshold
+func validateConsensusSettings(policy domain.ConsensusPolicy, threshold float64) error {
+	if policy != "" && !domain.IsValidConsensusPolicy(policy) {
+		return domain.NewAPIError(400, "Invalid consensus policy. Must be one of: unanimous, majority, supermajority, facilitator")
+	}
+
+	if threshold != 0 && (threshold <= 0.5 || threshold > 1) {
+		return domain.NewAPIError(400, "Supermajority threshold must be greater than 0.5 and at most 1")
+	}
+
+	return nil
+}
@@ -23,6 +23,44 @@ func (s *PWVCService) CalculateProjectPWVC(
 	fibonacciScores map[int]domain.FeatureScore, // featureID -> scores
 	pairwiseComparisons []domain.PairwiseComparison,
 ) (*domain.PWVCCalculationResult, error) {
+	return s.CalculateProjectPWVCWithMode(featureIDs, fibonacciScores, pairwiseComparisons, domain.WinCountModeConsensus)
+}
+
+// CalculateProjectPWVCWithMode performs complete P-WVC calculation using the selected win-count mode.
+// In vote mode pairwiseComparisons holds one entry per attendee ballot.
+func (s *PWVCService) CalculateProjectPWVCWithMode(
+	featureIDs []int,
+	fibonacciScores map[int]domain.FeatureScore, // featureID -> scores
+	pairwiseComparisons []domain.PairwiseComparison,
+	mode domain.WinCountMode,
+) (*domain.PWVCCalculationResult, error) {
+
+	model, err := domain.RankingModelForMode(mode)
+	if err != nil {
+		return nil, domain.NewAPIError(400, fmt.Sprintf("Invalid win-count mode: %s", mode))
+	}
+
+	result, err := s.CalculateProjectPWVCWithModel(featureIDs, fibonacciScores, pairwiseComparisons, model)
+	if err != nil {
+		return nil, err
+	}
+
+	result.WinCountMode = mode
+	return result, nil
+}
+
+// CalculateProjectPWVCWithModel performs complete P-WVC calculation, deriving
+// W_value and W_complexity from the given ranking model
+func (s *PWVCService) CalculateProjectPWVCWithModel(
+	featureIDs []int,
+	fibonacciScores map[int]domain.FeatureScore, // featureID -> scores
+	pairwiseComparisons []domain.PairwiseComparison,
+	model domain.RankingModel,
+) (*domain.PWVCCalculationResult, error) {
+
+	if model == nil {
+		return nil, domain.NewAPIError(400, "No ranking model provided")
+	}
 
 	if len(featureIDs) == 0 {
 		return nil, domain.NewAPIError(400, "No features provided for calculation")
@@ -38,7 +76,7 @@ func (s *PWVCService) CalculateProjectPWVC(
 	}
 
 	// Calculate win-counts for value criterion
-	valueWinCounts, err := domain.CalculateWinCountsForAllFeatures(
+	valueWinCounts, err := model.Estimate(
 		featureIDs,
 		pairwiseComparisons,
 		domain.CriterionValue,
@@ -48,7 +86,7 @@ func (s *PWVCService) CalculateProjectPWVC(
 	}
 
 	// Calculate win-counts for complexity criterion
-	complexityWinCounts, err := domain.CalculateWinCountsForAllFeatures(
+	complexityWinCounts, err := model.Estimate(
 		featureIDs,
 		pairwiseComparisons,
 		domain.CriterionComplexity,
@@ -72,6 +110,7 @@ func (s *PWVCService) CalculateProjectPWVC(
 	rankedFeatures := s.rankFeaturesByFPS(featureScores)
 
 	return &domain.PWVCCalculationResult{
+		RankingModel:        model.Type(),
 		FeatureScores:       featureScores,
 		ValueWinCounts:      valueWinCounts,
 		ComplexityWinCounts: complexityWinCounts,
@@ -227,6 +227,97 @@ func TestPWVCService_CalculateProjectPWVC(t *testing.T) {
 	}
 }
 
+func TestPWVCService_CalculateProjectPWVCWithVoteMode(t *testing.T) {
+	service := NewPWVCService()
+
+	featureIDs := []int{1, 2}
+
+	fibonacciScores := map[int]domain.FeatureScore{
+		1: {ValueScore: 8, ComplexityScore: 3},
+		2: {ValueScore: 5, ComplexityScore: 8},
+	}
+
+	// Four attendees prefer feature 1 for value, one prefers feature 2
+	var ballots []domain.PairwiseComparison
+	for userID := 1; userID <= 5; userID++ {
+		result := domain.ResultAWins
+		if userID == 5 {
+			result = domain.ResultBWins
+		}
+		ballots = append(ballots,
+			domain.PairwiseComparison{FeatureAID: 1, FeatureBID: 2, Criterion: domain.CriterionValue, Result: result, UserID: userID},
+			domain.PairwiseComparison{FeatureAID: 1, FeatureBID: 2, Criterion: domain.CriterionComplexity, Result: domain.ResultBWins, UserID: userID},
+		)
+	}
+
+	result, err := service.CalculateProjectPWVCWithMode(featureIDs, fibonacciScores, ballots, domain.WinCountModeVote)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.WinCountMode != domain.WinCountModeVote {
+		t.Errorf("Expected win-count mode %s, got %s", domain.WinCountModeVote, result.WinCountMode)
+	}
+
+	for _, wc := range result.ValueWinCounts {
+		expected := 0.8
+		if wc.FeatureID == 2 {
+			expected = 0.2
+		}
+		if math.Abs(wc.WinCount-expected) > 0.0001 {
+			t.Errorf("Expected feature %d value win count %.2f, got %.2f", wc.FeatureID, expected, wc.WinCount)
+		}
+	}
+
+	if _, err := service.CalculateProjectPWVCWithMode(featureIDs, fibonacciScores, ballots, "unknown"); err == nil {
+		t.Error("Expected error for unknown win-count mode")
+	}
+}
+
+func TestPWVCService_CalculateProjectPWVCWithModel(t *testing.T) {
+	service := NewPWVCService()
+
+	featureIDs := []int{1, 2, 3}
+
+	fibonacciScores := map[int]domain.FeatureScore{
+		1: {ValueScore: 8, ComplexityScore: 3},
+		2: {ValueScore: 5, ComplexityScore: 5},
+		3: {ValueScore: 3, ComplexityScore: 8},
+	}
+
+	// An incomplete comparison graph: feature 1 and 3 are never compared
+	comparisons := []domain.PairwiseComparison{
+		{FeatureAID: 1, FeatureBID: 2, Criterion: domain.CriterionValue, Result: domain.ResultAWins},
+		{FeatureAID: 2, FeatureBID: 3, Criterion: domain.CriterionValue, Result: domain.ResultAWins},
+		{FeatureAID: 1, FeatureBID: 2, Criterion: domain.CriterionComplexity, Result: domain.ResultBWins},
+		{FeatureAID: 2, FeatureBID: 3, Criterion: domain.CriterionComplexity, Result: domain.ResultBWins},
+	}
+
+	for _, modelType := range []domain.RankingModelType{domain.RankingModelBradleyTerry, domain.RankingModelElo} {
+		model, err := domain.NewRankingModel(modelType)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		result, err := service.CalculateProjectPWVCWithModel(featureIDs, fibonacciScores, comparisons, model)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", modelType, err)
+		}
+
+		if result.RankingModel != modelType {
+			t.Errorf("Expected ranking model %s, got %s", modelType, result.RankingModel)
+		}
+
+		if result.RankedFeatures[0].FeatureID != 1 {
+			t.Errorf("%s: expected feature 1 to rank first, got %d", modelType, result.RankedFeatures[0].FeatureID)
+		}
+	}
+
+	if _, err := service.CalculateProjectPWVCWithModel(featureIDs, fibonacciScores, comparisons, nil); err == nil {
+		t.Error("Expected error when no ranking model is provided")
+	}
+}
+
 func TestPWVCService_GetValidFibonacciScores(t *testing.T) {
 	service := NewPWVCService()
 
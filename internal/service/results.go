@@ -1,19 +1,31 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"time"
 
 	"pairwise/internal/domain"
 	"pairwise/internal/repository"
+	"pairwise/internal/websocket"
 )
 
+// ResultsBroadcaster defines the interface for broadcasting recalculated results on the
+// project's results topic
+type ResultsBroadcaster interface {
+	NotifyResultsUpdated(topic websocket.Topic, results websocket.ResultsUpdatedMessage)
+}
+
 // ResultsService handles P-WVC results calculation and management
 type ResultsService struct {
-	priorityRepo *repository.PriorityRepository
-	featureRepo  *repository.FeatureRepository
-	pairwiseRepo *repository.PairwiseRepository
+	auditHook
+	priorityRepo  *repository.PriorityRepository
+	featureRepo   *repository.FeatureRepository
+	pairwiseRepo  *repository.PairwiseRepository
+	fibonacciRepo *repository.FibonacciRepository
+	criteriaRepo  *repository.CriteriaRepository
+	wsBroadcaster ResultsBroadcaster
 }
 
 // NewResultsService creates a new results service
@@ -21,16 +33,44 @@ func NewResultsService(
 	priorityRepo *repository.PriorityRepository,
 	featureRepo *repository.FeatureRepository,
 	pairwiseRepo *repository.PairwiseRepository,
+	fibonacciRepo *repository.FibonacciRepository,
+	criteriaRepo *repository.CriteriaRepository,
 ) *ResultsService {
 	return &ResultsService{
-		priorityRepo: priorityRepo,
-		featureRepo:  featureRepo,
-		pairwiseRepo: pairwiseRepo,
+		priorityRepo:  priorityRepo,
+		featureRepo:   featureRepo,
+		pairwiseRepo:  pairwiseRepo,
+		fibonacciRepo: fibonacciRepo,
+		criteriaRepo:  criteriaRepo,
 	}
 }
 
-// CalculateResults performs the complete P-WVC calculation for a project
-func (s *ResultsService) CalculateResults(projectID int) (*domain.ProjectResults, error) {
+// SetWebSocketBroadcaster sets the WebSocket broadcaster for real-time notifications
+func (s *ResultsService) SetWebSocketBroadcaster(broadcaster ResultsBroadcaster) {
+	s.wsBroadcaster = broadcaster
+}
+
+// CalculateResults performs the complete P-WVC calculation for a project.
+// The mode selects whether win-count weights come from consensus results or individual votes,
+// and the ranking model how they are estimated from those; without a model, the win-count
+// model of the mode is used.
+func (s *ResultsService) CalculateResults(ctx context.Context, projectID int, mode domain.WinCountMode, modelType domain.RankingModelType) (*domain.ProjectResults, error) {
+	if mode == "" {
+		mode = domain.WinCountModeConsensus
+	}
+
+	if !domain.IsValidWinCountMode(mode) {
+		return nil, domain.NewAPIError(400, "Invalid win-count mode. Must be 'consensus' or 'vote'")
+	}
+
+	model, err := domain.RankingModelForMode(mode)
+	if modelType != "" {
+		model, err = domain.NewRankingModel(modelType)
+	}
+	if err != nil {
+		return nil, domain.NewAPIError(400, "Invalid ranking model", err.Error())
+	}
+
 	// 1. Get all features for the project
 	features, err := s.featureRepo.GetByProjectID(projectID)
 	if err != nil {
@@ -41,55 +81,69 @@ func (s *ResultsService) CalculateResults(projectID int) (*domain.ProjectResults
 		return nil, domain.NewAPIError(400, "No features found for this project")
 	}
 
-	// 2. Get win-count weights from pairwise comparisons
-	valueWeights, err := s.calculateWinCountWeights(projectID, "value")
-	if err != nil {
-		return nil, domain.NewAPIError(500, "Failed to calculate value weights", err.Error())
-	}
-
-	complexityWeights, err := s.calculateWinCountWeights(projectID, "complexity")
+	criteria, err := loadProjectCriteria(s.criteriaRepo, projectID)
 	if err != nil {
-		return nil, domain.NewAPIError(500, "Failed to calculate complexity weights", err.Error())
+		return nil, err
 	}
 
-	// 3. Get Fibonacci consensus scores (placeholder - we'll implement this)
-	valueScores, err := s.getFibonacciScores(projectID, "value")
-	if err != nil {
-		return nil, domain.NewAPIError(500, "Failed to get value scores", err.Error())
+	// 2. Get win-count weights from pairwise comparisons for each criterion
+	weights := make(map[domain.CriterionType]map[int]float64, len(criteria))
+	for _, criterion := range criteria {
+		weights[criterion.Key], err = s.calculateWinCountWeights(projectID, criterion.Key, features, mode, model)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	complexityScores, err := s.getFibonacciScores(projectID, "complexity")
-	if err != nil {
-		return nil, domain.NewAPIError(500, "Failed to get complexity scores", err.Error())
+	// 3. Get Fibonacci consensus scores
+	scores := make(map[domain.CriterionType]map[int]int, len(criteria))
+	for _, criterion := range criteria {
+		scores[criterion.Key], err = s.getFibonacciScores(projectID, criterion.Key, features)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// 4. Calculate Final Priority Scores
 	var calculations []domain.PriorityCalculation
 	for _, feature := range features {
-		wValue := valueWeights[feature.ID]
-		wComplexity := complexityWeights[feature.ID]
-		sValue := valueScores[feature.ID]
-		sComplexity := complexityScores[feature.ID]
-
-		// Prevent division by zero
-		if wComplexity == 0 || sComplexity == 0 {
-			return nil, domain.NewAPIError(400, fmt.Sprintf("Invalid complexity values for feature %d", feature.ID))
+		inputs := make([]domain.CriterionInput, len(criteria))
+		for i, criterion := range criteria {
+			inputs[i] = domain.CriterionInput{
+				Criterion: criterion,
+				Score:     scores[criterion.Key][feature.ID],
+				WinCount:  weights[criterion.Key][feature.ID],
+			}
 		}
 
-		weightedValue := float64(sValue) * wValue
-		weightedComplexity := float64(sComplexity) * wComplexity
-		finalScore := weightedValue / weightedComplexity
+		// The domain calculation handles a zero cost weight (a feature that lost every
+		// comparison on a cost criterion) without dividing by zero
+		fps, criterionScores, err := domain.CalculateMultiCriteriaScore(inputs)
+		if err != nil {
+			return nil, domain.NewAPIError(400, fmt.Sprintf("Invalid scores for feature %d", feature.ID), err.Error())
+		}
 
 		calculation := domain.PriorityCalculation{
 			ProjectID:          projectID,
 			FeatureID:          feature.ID,
-			WValue:             wValue,
-			WComplexity:        wComplexity,
-			SValue:             sValue,
-			SComplexity:        sComplexity,
-			WeightedValue:      weightedValue,
-			WeightedComplexity: weightedComplexity,
-			FinalPriorityScore: finalScore,
+			FinalPriorityScore: fps,
+			WinCountMode:       mode,
+			RankingModel:       model.Type(),
+			Criteria:           criterionScores,
+		}
+
+		// The value and complexity columns stay filled for clients of the two-criterion model
+		for _, score := range criterionScores {
+			switch score.Criterion {
+			case domain.CriterionTypeValue:
+				calculation.WValue = score.WinCount
+				calculation.SValue = score.Score
+				calculation.WeightedValue = score.Weighted
+			case domain.CriterionTypeComplexity:
+				calculation.WComplexity = score.WinCount
+				calculation.SComplexity = score.Score
+				calculation.WeightedComplexity = score.Weighted
+			}
 		}
 
 		calculations = append(calculations, calculation)
@@ -126,13 +180,25 @@ func (s *ResultsService) CalculateResults(projectID int) (*domain.ProjectResults
 	// 8. Calculate summary statistics
 	summary := s.calculateSummary(results)
 
-	return &domain.ProjectResults{
+	projectResults := &domain.ProjectResults{
 		ProjectID:     projectID,
 		Results:       results,
 		CalculatedAt:  time.Now(),
 		TotalFeatures: len(results),
 		Summary:       summary,
-	}, nil
+	}
+
+	s.audit(ctx, projectID, domain.AuditActionCalculate, domain.AuditEntityResults, 0, nil, projectResults)
+
+	if s.wsBroadcaster != nil {
+		go s.wsBroadcaster.NotifyResultsUpdated(websocket.ResultsTopic(projectID), websocket.ResultsUpdatedMessage{
+			ProjectID:     projectID,
+			TotalFeatures: projectResults.TotalFeatures,
+			CalculatedAt:  projectResults.CalculatedAt,
+		})
+	}
+
+	return projectResults, nil
 }
 
 // GetResults retrieves existing calculated results for a project
@@ -157,49 +223,225 @@ func (s *ResultsService) GetResults(projectID int) (*domain.ProjectResults, erro
 	}, nil
 }
 
-// calculateWinCountWeights calculates win-count weights from pairwise comparison results
-func (s *ResultsService) calculateWinCountWeights(projectID int, criterionType string) (map[int]float64, error) {
-	// Get all pairwise comparison results for this project and criterion
-	// This is a simplified implementation - in reality we'd need to aggregate votes properly
-
+// GetAHPAnalysis derives AHP priority weights and consistency ratios from the attendee
+// votes in the project's latest pairwise session for each criterion
+func (s *ResultsService) GetAHPAnalysis(projectID int) (*domain.AHPAnalysis, error) {
 	features, err := s.featureRepo.GetByProjectID(projectID)
 	if err != nil {
-		return nil, err
+		return nil, domain.NewAPIError(500, "Failed to get project features", err.Error())
 	}
 
-	weights := make(map[int]float64)
+	if len(features) < 2 {
+		return nil, domain.NewAPIError(400, "At least 2 features are required for AHP analysis")
+	}
 
-	// For now, assign mock weights - this should be calculated from actual pairwise results
-	totalFeatures := len(features)
+	featureIDs := make([]int, len(features))
 	for i, feature := range features {
-		// Mock calculation: higher ranking features get higher weights
-		weight := 1.0 - (float64(i) / float64(totalFeatures))
-		if weight < 0.1 {
-			weight = 0.1 // Minimum weight
+		featureIDs[i] = feature.ID
+	}
+
+	analysis := &domain.AHPAnalysis{
+		ProjectID: projectID,
+		Criteria:  []domain.AHPResult{},
+	}
+
+	criteria, err := loadProjectCriteria(s.criteriaRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, criterionType := range domain.CriterionKeys(criteria) {
+		session, err := s.pairwiseRepo.GetLatestSessionByProjectAndCriterion(projectID, criterionType)
+		if err == domain.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s pairwise session", criterionType), err.Error())
+		}
+
+		sessionComparisons, err := s.pairwiseRepo.GetComparisonsBySessionID(session.ID)
+		if err != nil {
+			return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s comparisons", criterionType), err.Error())
+		}
+
+		criterion := domain.ComparisonCriterion(criterionType)
+		ballots, _, err := s.collectBallots(sessionComparisons, criterion)
+		if err != nil {
+			return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s votes", criterionType), err.Error())
+		}
+
+		if len(ballots) == 0 {
+			continue
+		}
+
+		result, err := domain.CalculateAHP(featureIDs, ballots, criterion)
+		if err != nil {
+			return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to calculate %s AHP weights", criterionType), err.Error())
 		}
-		weights[feature.ID] = weight
+
+		analysis.Criteria = append(analysis.Criteria, *result)
+	}
+
+	if len(analysis.Criteria) == 0 {
+		return nil, domain.NewBusinessError(
+			domain.ErrTypeInsufficientData,
+			"No pairwise votes found for this project",
+			map[string]interface{}{"project_id": projectID},
+		)
+	}
+
+	return analysis, nil
+}
+
+// calculateWinCountWeights estimates win-count weights with the ranking model from the
+// project's latest pairwise session for the criterion, using either consensus results or
+// individual ballots
+func (s *ResultsService) calculateWinCountWeights(projectID int, criterionType domain.CriterionType, features []domain.Feature, mode domain.WinCountMode, model domain.RankingModel) (map[int]float64, error) {
+	session, err := s.pairwiseRepo.GetLatestSessionByProjectAndCriterion(projectID, criterionType)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewBusinessError(
+				domain.ErrTypeInsufficientData,
+				fmt.Sprintf("No %s pairwise session found for this project", criterionType),
+				map[string]interface{}{"criterion": criterionType},
+			)
+		}
+		return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s pairwise session", criterionType), err.Error())
+	}
+
+	// Vote mode works from individual ballots, so split comparisons don't need to be resolved
+	if mode != domain.WinCountModeVote && session.Status != domain.SessionStatusCompleted {
+		return nil, domain.NewBusinessError(
+			domain.ErrTypePhaseNotComplete,
+			fmt.Sprintf("The %s pairwise session has not been completed", criterionType),
+			map[string]interface{}{"criterion": criterionType, "session_id": session.ID},
+		)
+	}
+
+	sessionComparisons, err := s.pairwiseRepo.GetComparisonsBySessionID(session.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s comparisons", criterionType), err.Error())
+	}
+
+	criterion := domain.ComparisonCriterion(criterionType)
+	var comparisons []domain.PairwiseComparison
+	var pending int
+	if mode == domain.WinCountModeVote {
+		comparisons, pending, err = s.collectBallots(sessionComparisons, criterion)
+		if err != nil {
+			return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s votes", criterionType), err.Error())
+		}
+	} else {
+		comparisons, pending = collectConsensusResults(sessionComparisons, criterion)
+	}
+
+	if pending > 0 || len(comparisons) == 0 {
+		message := fmt.Sprintf("The %s pairwise session has %d comparisons without consensus", criterionType, pending)
+		if mode == domain.WinCountModeVote {
+			message = fmt.Sprintf("The %s pairwise session has %d comparisons without votes", criterionType, pending)
+		}
+		return nil, domain.NewBusinessError(
+			domain.ErrTypePhaseNotComplete,
+			message,
+			map[string]interface{}{
+				"criterion":           criterionType,
+				"session_id":          session.ID,
+				"win_count_mode":      mode,
+				"pending_comparisons": pending,
+				"total_comparisons":   len(sessionComparisons),
+			},
+		)
+	}
+
+	featureIDs := make([]int, len(features))
+	for i, feature := range features {
+		featureIDs[i] = feature.ID
+	}
+
+	winCounts, err := model.Estimate(featureIDs, comparisons, criterion)
+	if err != nil {
+		return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to calculate %s win-counts", criterionType), err.Error())
+	}
+
+	weights := make(map[int]float64, len(winCounts))
+	for _, winCount := range winCounts {
+		weights[winCount.FeatureID] = winCount.WinCount
 	}
 
 	return weights, nil
 }
 
-// getFibonacciScores retrieves consensus Fibonacci scores for features
-func (s *ResultsService) getFibonacciScores(projectID int, criterionType string) (map[int]int, error) {
-	// This should get actual Fibonacci consensus scores
-	// For now, return mock scores
+// collectConsensusResults converts comparisons with consensus into calculation inputs and
+// counts the comparisons that are still open
+func collectConsensusResults(sessionComparisons []domain.SessionComparison, criterion domain.ComparisonCriterion) ([]domain.PairwiseComparison, int) {
+	comparisons := make([]domain.PairwiseComparison, 0, len(sessionComparisons))
+	pending := 0
+	for _, sessionComparison := range sessionComparisons {
+		comparison, ok := sessionComparison.ToPairwiseComparison(criterion)
+		if !ok {
+			pending++
+			continue
+		}
+		comparisons = append(comparisons, comparison)
+	}
+
+	return comparisons, pending
+}
 
-	features, err := s.featureRepo.GetByProjectID(projectID)
+// collectBallots converts every attendee vote into a calculation input and counts the
+// comparisons that have no votes yet
+func (s *ResultsService) collectBallots(sessionComparisons []domain.SessionComparison, criterion domain.ComparisonCriterion) ([]domain.PairwiseComparison, int, error) {
+	var ballots []domain.PairwiseComparison
+	pending := 0
+	for _, sessionComparison := range sessionComparisons {
+		votes, err := s.pairwiseRepo.GetVotesByComparisonID(sessionComparison.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		cast := 0
+		for _, vote := range votes {
+			ballot, ok := vote.ToBallot(sessionComparison, criterion)
+			if !ok {
+				continue
+			}
+			ballots = append(ballots, ballot)
+			cast++
+		}
+
+		if cast == 0 {
+			pending++
+		}
+	}
+
+	return ballots, pending, nil
+}
+
+// getFibonacciScores retrieves consensus Fibonacci scores for features from the latest scoring session
+func (s *ResultsService) getFibonacciScores(projectID int, criterionType domain.CriterionType, features []domain.Feature) (map[int]int, error) {
+	session, err := s.fibonacciRepo.GetLatestSessionByProjectAndCriterion(projectID, criterionType)
 	if err != nil {
-		return nil, err
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(400, fmt.Sprintf("No %s scoring session found for this project", criterionType))
+		}
+		return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s scoring session", criterionType), err.Error())
+	}
+
+	consensusScores, err := s.fibonacciRepo.GetConsensusScoresBySessionID(session.ID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, fmt.Sprintf("Failed to get %s scores", criterionType), err.Error())
 	}
 
 	scores := make(map[int]int)
-	fibValues := []int{1, 2, 3, 5, 8, 13, 21}
+	for _, consensus := range consensusScores {
+		scores[consensus.FeatureID] = consensus.FinalScore
+	}
 
-	for i, feature := range features {
-		// Mock: assign random but deterministic Fibonacci values
-		scoreIndex := (feature.ID + i) % len(fibValues)
-		scores[feature.ID] = fibValues[scoreIndex]
+	// Every feature needs an agreed score before results can be calculated
+	for _, feature := range features {
+		if _, ok := scores[feature.ID]; !ok {
+			return nil, domain.NewAPIError(400, fmt.Sprintf("Feature %q has no consensus %s score", feature.Title, criterionType))
+		}
 	}
 
 	return scores, nil
@@ -273,10 +515,21 @@ func (s *ResultsService) ExportResults(projectID int, format domain.ExportFormat
 
 // exportToCSV converts results to CSV format
 func (s *ResultsService) exportToCSV(results *domain.ProjectResults) [][]string {
-	csv := [][]string{
-		{"rank", "feature_title", "description", "final_priority_score", "s_value", "s_complexity", "w_value", "w_complexity"},
+	header := []string{"rank", "feature_title", "description", "final_priority_score", "s_value", "s_complexity", "w_value", "w_complexity"}
+
+	// Criteria beyond value and complexity get their own score and weight columns
+	var extra []domain.CriterionType
+	if len(results.Results) > 0 {
+		for _, score := range results.Results[0].Criteria {
+			if score.Criterion != domain.CriterionTypeValue && score.Criterion != domain.CriterionTypeComplexity {
+				extra = append(extra, score.Criterion)
+				header = append(header, "s_"+string(score.Criterion), "w_"+string(score.Criterion))
+			}
+		}
 	}
 
+	csv := [][]string{header}
+
 	for _, result := range results.Results {
 		row := []string{
 			fmt.Sprintf("%d", result.Rank),
@@ -288,6 +541,13 @@ func (s *ResultsService) exportToCSV(results *domain.ProjectResults) [][]string
 			fmt.Sprintf("%.6f", result.WValue),
 			fmt.Sprintf("%.6f", result.WComplexity),
 		}
+		for _, criterion := range extra {
+			for _, score := range result.Criteria {
+				if score.Criterion == criterion {
+					row = append(row, fmt.Sprintf("%d", score.Score), fmt.Sprintf("%.6f", score.WinCount))
+				}
+			}
+		}
 		csv = append(csv, row)
 	}
 
@@ -0,0 +1,45 @@
+package service
+
+import "sync"
+
+// sessionLocks serializes the steps that move a pairwise session on, such as casting a
+// vote, deciding a comparison, scheduling the next round and driving the focus. Each
+// session has its own lock, which is dropped once nobody holds or waits for it.
+type sessionLocks struct {
+	mu    sync.Mutex
+	locks map[int]*sessionLock
+}
+
+// sessionLock is the lock of one session and the number of callers holding or waiting for it
+type sessionLock struct {
+	sync.Mutex
+	users int
+}
+
+// lock blocks until the caller holds the session's lock, and returns the function that
+// releases it
+func (l *sessionLocks) lock(sessionID int) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int]*sessionLock)
+	}
+	entry, ok := l.locks[sessionID]
+	if !ok {
+		entry = &sessionLock{}
+		l.locks[sessionID] = entry
+	}
+	entry.users++
+	l.mu.Unlock()
+
+	entry.Lock()
+	return func() {
+		entry.Unlock()
+
+		l.mu.Lock()
+		entry.users--
+		if entry.users == 0 {
+			delete(l.locks, sessionID)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/websocket"
+)
+
+// TopicSnapshots builds the current state of WebSocket topics from the services that publish
+// to them, for clients that reconnect too far behind to replay what they missed
+type TopicSnapshots struct {
+	pairwiseService  *PairwiseService
+	fibonacciService *FibonacciService
+	resultsService   *ResultsService
+}
+
+// NewTopicSnapshots creates a new topic snapshot builder
+func NewTopicSnapshots(pairwiseService *PairwiseService, fibonacciService *FibonacciService, resultsService *ResultsService) *TopicSnapshots {
+	return &TopicSnapshots{
+		pairwiseService:  pairwiseService,
+		fibonacciService: fibonacciService,
+		resultsService:   resultsService,
+	}
+}
+
+// Snapshot returns what a client of the topic would load over the REST API
+func (t *TopicSnapshots) Snapshot(topic websocket.Topic) (interface{}, error) {
+	switch topic.Kind() {
+	case websocket.TopicKindPairwise:
+		return t.pairwiseService.GetSnapshot(topic.ProjectID, topic.Criterion())
+	case websocket.TopicKindFibonacci:
+		return t.fibonacciService.GetSnapshot(topic.ProjectID, topic.Criterion())
+	case websocket.TopicKindResults:
+		results, err := t.resultsService.GetResults(topic.ProjectID)
+		if apiErr, ok := err.(*domain.APIError); ok && apiErr.Code == 404 {
+			// Results have not been calculated yet
+			return nil, nil
+		}
+		return results, err
+	default:
+		return nil, fmt.Errorf("no snapshot for topic %s", topic)
+	}
+}
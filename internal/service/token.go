@@ -0,0 +1,202 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pairwise/internal/domain"
+)
+
+// TokenService issues and verifies HMAC-signed session tokens. A token is the base64url
+// encoded JSON claims and the base64url encoded HMAC-SHA256 of that encoding, joined by a dot.
+type TokenService struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewTokenService creates a token service signing with the given secret. A zero TTL uses
+// domain.DefaultTokenTTL.
+func NewTokenService(secret []byte, ttl time.Duration) *TokenService {
+	if ttl <= 0 {
+		ttl = domain.DefaultTokenTTL
+	}
+	return &TokenService{
+		secret: secret,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// GenerateTokenSecret returns a random signing secret for servers started without one
+func GenerateTokenSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate token secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Issue creates a token for an attendee, scoped to the attendee's project and carrying their
+// role and token version
+func (s *TokenService) Issue(attendee *domain.Attendee) (string, *domain.TokenClaims, error) {
+	now := s.now()
+	claims := &domain.TokenClaims{
+		ProjectID:  attendee.ProjectID,
+		AttendeeID: attendee.ID,
+		Role:       attendee.AccessRole(),
+		Version:    attendee.TokenVersion,
+		IssuedAt:   now.Unix(),
+		ExpiresAt:  now.Add(s.ttl).Unix(),
+	}
+
+	token, err := s.encode(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// Verify checks a token's signature and expiry and returns its claims
+func (s *TokenService) Verify(token string) (*domain.TokenClaims, error) {
+	var claims domain.TokenClaims
+	if err := s.decode(token, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.ProjectID <= 0 || !domain.IsValidRole(claims.Role) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if claims.Expired(s.now()) {
+		return nil, domain.ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+// IssueUser creates a user session token, which is not scoped to any project
+func (s *TokenService) IssueUser(userID int) (string, *domain.UserClaims, error) {
+	now := s.now()
+	claims := &domain.UserClaims{
+		UserID:    userID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.ttl).Unix(),
+	}
+
+	token, err := s.encode(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// VerifyUser checks a user session token's signature and expiry and returns its claims
+func (s *TokenService) VerifyUser(token string) (*domain.UserClaims, error) {
+	var claims domain.UserClaims
+	if err := s.decode(token, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.UserID <= 0 {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if claims.Expired(s.now()) {
+		return nil, domain.ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+// SignLoginState signs the state of an OpenID Connect login, expiring after
+// domain.OIDCLoginTTL
+func (s *TokenService) SignLoginState(state domain.OIDCLoginState) (string, error) {
+	state.ExpiresAt = s.now().Add(domain.OIDCLoginTTL).Unix()
+	return s.encode(state)
+}
+
+// VerifyLoginState checks a signed login state and returns it
+func (s *TokenService) VerifyLoginState(token string) (*domain.OIDCLoginState, error) {
+	var state domain.OIDCLoginState
+	if err := s.decode(token, &state); err != nil {
+		return nil, err
+	}
+
+	if state.State == "" || state.Nonce == "" || state.CodeVerifier == "" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if s.now().Unix() >= state.ExpiresAt {
+		return nil, domain.ErrTokenExpired
+	}
+
+	return &state, nil
+}
+
+// SignInvite creates a signed invite token for a join code. The token does not expire by
+// itself; the invite it names carries the expiry and can be revoked.
+func (s *TokenService) SignInvite(projectID int, code string) (string, error) {
+	return s.encode(domain.InviteClaims{ProjectID: projectID, Code: code})
+}
+
+// VerifyInvite checks an invite token's signature and returns its claims
+func (s *TokenService) VerifyInvite(token string) (*domain.InviteClaims, error) {
+	var claims domain.InviteClaims
+	if err := s.decode(token, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.ProjectID <= 0 || claims.Code == "" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+// encode signs the JSON encoding of claims
+func (s *TokenService) encode(claims interface{}) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), nil
+}
+
+// decode checks a token's signature and decodes its claims
+func (s *TokenService) decode(token string, claims interface{}) error {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || encoded == "" || signature == "" {
+		return domain.ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return domain.ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// sign returns the base64url encoded HMAC-SHA256 of the encoded claims
+func (s *TokenService) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pairwise/internal/domain"
+)
+
+// Test issuing and verifying session tokens
+func TestTokenService(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := NewTokenService([]byte("test-secret"), time.Hour)
+	tokens.now = func() time.Time { return now }
+
+	token, issued, err := tokens.Issue(&domain.Attendee{ID: 7, ProjectID: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Valid token", func(t *testing.T) {
+		claims, err := tokens.Verify(token)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if *claims != *issued {
+			t.Errorf("Expected claims %+v, got %+v", issued, claims)
+		}
+		if claims.ProjectID != 3 || claims.AttendeeID != 7 || claims.Role != domain.RoleAttendee {
+			t.Errorf("Unexpected claims %+v", claims)
+		}
+	})
+
+	t.Run("Token version", func(t *testing.T) {
+		versioned, _, _ := tokens.Issue(&domain.Attendee{ID: 7, ProjectID: 3, TokenVersion: 2})
+		claims, err := tokens.Verify(versioned)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if claims.Version != 2 {
+			t.Errorf("Expected token version 2, got %d", claims.Version)
+		}
+	})
+
+	t.Run("Tampered claims", func(t *testing.T) {
+		other, _, _ := tokens.Issue(&domain.Attendee{ID: 7, ProjectID: 4})
+		payload, _, _ := strings.Cut(other, ".")
+		_, signature, _ := strings.Cut(token, ".")
+		if _, err := tokens.Verify(payload + "." + signature); err != domain.ErrInvalidToken {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("Different secret", func(t *testing.T) {
+		forger := NewTokenService([]byte("other-secret"), time.Hour)
+		forged, _, _ := forger.Issue(&domain.Attendee{ID: 7, ProjectID: 3})
+		if _, err := tokens.Verify(forged); err != domain.ErrInvalidToken {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("Legacy token", func(t *testing.T) {
+		if _, err := tokens.Verify("3:7"); err != domain.ErrInvalidToken {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		defer func() { now = now.Add(-time.Hour) }()
+		if _, err := tokens.Verify(token); err != domain.ErrTokenExpired {
+			t.Errorf("Expected ErrTokenExpired, got %v", err)
+		}
+	})
+}
+
+// TestInviteTokens tests that invite tokens round-trip and cannot stand in for session tokens
+func TestInviteTokens(t *testing.T) {
+	tokens := NewTokenService([]byte("test-secret"), time.Hour)
+
+	invite, err := tokens.SignInvite(3, "ABCD2345")
+	if err != nil {
+		t.Fatalf("Failed to sign invite: %v", err)
+	}
+
+	claims, err := tokens.VerifyInvite(invite)
+	if err != nil {
+		t.Fatalf("Failed to verify invite: %v", err)
+	}
+	if claims.ProjectID != 3 || claims.Code != "ABCD2345" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	if _, err := tokens.Verify(invite); err != domain.ErrInvalidToken {
+		t.Errorf("Expected an invite token to be rejected as a session token, got %v", err)
+	}
+
+	session, _, _ := tokens.Issue(&domain.Attendee{ID: 7, ProjectID: 3})
+	if _, err := tokens.VerifyInvite(session); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a session token to be rejected as an invite token, got %v", err)
+	}
+
+	forger := NewTokenService([]byte("other-secret"), time.Hour)
+	forged, _ := forger.SignInvite(3, "ABCD2345")
+	if _, err := tokens.VerifyInvite(forged); err != domain.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for a forged invite, got %v", err)
+	}
+}
+
+// TestGenerateJoinCode tests that join codes use the unambiguous alphabet
+func TestGenerateJoinCode(t *testing.T) {
+	code, err := GenerateJoinCode()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(code) != domain.JoinCodeLength || strings.Trim(code, domain.JoinCodeAlphabet) != "" {
+		t.Errorf("Unexpected join code %q", code)
+	}
+	if normalized := normalizeJoinCode("abcd-23 45"); normalized != "ABCD2345" {
+		t.Errorf("Expected typed codes to be normalized, got %q", normalized)
+	}
+}
+
+// TestUserTokens tests user session tokens and OpenID Connect login state, and that neither
+// can stand in for a project session token or for each other
+func TestUserTokens(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := NewTokenService([]byte("test-secret"), time.Hour)
+	tokens.now = func() time.Time { return now }
+
+	userToken, issued, err := tokens.IssueUser(4)
+	if err != nil {
+		t.Fatalf("Failed to issue user token: %v", err)
+	}
+	claims, err := tokens.VerifyUser(userToken)
+	if err != nil || *claims != *issued || claims.UserID != 4 {
+		t.Fatalf("Expected user claims %+v, got %+v (%v)", issued, claims, err)
+	}
+
+	stateToken, err := tokens.SignLoginState(domain.OIDCLoginState{State: "st", Nonce: "n", CodeVerifier: "cv"})
+	if err != nil {
+		t.Fatalf("Failed to sign login state: %v", err)
+	}
+	state, err := tokens.VerifyLoginState(stateToken)
+	if err != nil || state.State != "st" || state.Nonce != "n" || state.CodeVerifier != "cv" {
+		t.Fatalf("Unexpected login state %+v (%v)", state, err)
+	}
+
+	session, _, _ := tokens.Issue(&domain.Attendee{ID: 7, ProjectID: 3, IsFacilitator: true})
+	if _, err := tokens.Verify(userToken); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a user token to be rejected as a session token, got %v", err)
+	}
+	if _, err := tokens.VerifyUser(session); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a session token to be rejected as a user token, got %v", err)
+	}
+	if _, err := tokens.VerifyUser(stateToken); err != domain.ErrInvalidToken {
+		t.Errorf("Expected login state to be rejected as a user token, got %v", err)
+	}
+	if _, err := tokens.VerifyLoginState(userToken); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a user token to be rejected as login state, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := tokens.VerifyUser(userToken); err != domain.ErrTokenExpired {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+	if _, err := tokens.VerifyLoginState(stateToken); err != domain.ErrTokenExpired {
+		t.Errorf("Expected expired login state, got %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"pairwise/internal/domain"
+	"pairwise/internal/oidc"
+	"pairwise/internal/repository"
+)
+
+// maxUserNameLength matches the longest attendee name, since users act through attendees
+const maxUserNameLength = 255
+
+// IdentityProvider is an OpenID provider that signs users in with the authorization code flow
+type IdentityProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*domain.OIDCIdentity, error)
+}
+
+// UserService handles facilitator accounts signed in with OpenID Connect and the projects
+// they own
+type UserService struct {
+	auditHook
+	userRepo        *repository.UserRepository
+	projectRepo     *repository.ProjectRepository
+	projectService  *ProjectService
+	attendeeService *AttendeeService
+	tokenService    *TokenService
+	provider        IdentityProvider
+	postLoginURL    string
+}
+
+// NewUserService creates a new user service. Logins fail until EnableOIDC is called.
+func NewUserService(
+	userRepo *repository.UserRepository,
+	projectRepo *repository.ProjectRepository,
+	projectService *ProjectService,
+	attendeeService *AttendeeService,
+	tokenService *TokenService,
+) *UserService {
+	return &UserService{
+		userRepo:        userRepo,
+		projectRepo:     projectRepo,
+		projectService:  projectService,
+		attendeeService: attendeeService,
+		tokenService:    tokenService,
+	}
+}
+
+// EnableOIDC turns on logins with an OpenID provider. After a login the browser is sent to
+// postLoginURL with the session token, or gets the token as JSON when it is empty.
+func (s *UserService) EnableOIDC(provider IdentityProvider, postLoginURL string) {
+	s.provider = provider
+	s.postLoginURL = postLoginURL
+}
+
+// PostLoginURL returns where browsers go after a login, if anywhere
+func (s *UserService) PostLoginURL() string {
+	return s.postLoginURL
+}
+
+// BeginLogin starts a login, returning the provider URL to send the browser to and the
+// signed login state to keep in a cookie until the provider sends the browser back
+func (s *UserService) BeginLogin(ctx context.Context) (authURL, stateToken string, err error) {
+	if s.provider == nil {
+		return "", "", domain.NewAPIError(404, domain.ErrOIDCNotConfigured.Error())
+	}
+
+	var state domain.OIDCLoginState
+	for _, value := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		if *value, err = oidc.RandomString(); err != nil {
+			return "", "", domain.NewAPIError(500, "Failed to start login", err.Error())
+		}
+	}
+
+	authURL, err = s.provider.AuthCodeURL(ctx, state.State, state.Nonce, oidc.CodeChallenge(state.CodeVerifier))
+	if err != nil {
+		return "", "", domain.NewAPIError(502, "Failed to reach the identity provider", err.Error())
+	}
+
+	stateToken, err = s.tokenService.SignLoginState(state)
+	if err != nil {
+		return "", "", domain.NewAPIError(500, "Failed to start login", err.Error())
+	}
+
+	return authURL, stateToken, nil
+}
+
+// CompleteLogin finishes a login when the provider sends the browser back with a code,
+// creating the user on their first login
+func (s *UserService) CompleteLogin(ctx context.Context, stateToken, state, code string) (*domain.User, error) {
+	if s.provider == nil {
+		return nil, domain.NewAPIError(404, domain.ErrOIDCNotConfigured.Error())
+	}
+
+	loginState, err := s.tokenService.VerifyLoginState(stateToken)
+	if err != nil || subtle.ConstantTimeCompare([]byte(loginState.State), []byte(state)) != 1 {
+		return nil, domain.NewAPIError(400, domain.ErrOIDCLoginState.Error())
+	}
+
+	if code == "" {
+		return nil, domain.NewAPIError(400, "Missing authorization code")
+	}
+
+	identity, err := s.provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, domain.NewAPIError(401, "OpenID Connect login failed", err.Error())
+	}
+
+	return s.recordLogin(ctx, identity)
+}
+
+// recordLogin creates or refreshes the user for a verified identity
+func (s *UserService) recordLogin(ctx context.Context, identity *domain.OIDCIdentity) (*domain.User, error) {
+	// Unverified addresses could belong to anyone, so they are not kept
+	email := identity.Email
+	if !identity.EmailVerified {
+		email = ""
+	}
+
+	name := identity.DisplayName()
+	if len(name) > maxUserNameLength {
+		name = name[:maxUserNameLength]
+	}
+
+	existing, err := s.userRepo.GetByIdentity(identity.Issuer, identity.Subject)
+	if err == nil {
+		user, err := s.userRepo.RecordLogin(existing.ID, email, name)
+		if err != nil {
+			return nil, domain.NewAPIError(500, "Failed to record login", err.Error())
+		}
+		return user, nil
+	}
+	if err != domain.ErrNotFound {
+		return nil, domain.NewAPIError(500, "Failed to retrieve user", err.Error())
+	}
+
+	user, err := s.userRepo.Create(domain.User{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		Email:   email,
+		Name:    name,
+	})
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to create user", err.Error())
+	}
+
+	ctx = domain.WithActor(ctx, domain.Actor{Type: domain.ActorUser, ID: &user.ID})
+	s.audit(ctx, 0, domain.AuditActionCreate, domain.AuditEntityUser, user.ID, nil, user)
+
+	return user, nil
+}
+
+// GetProfile returns a user with the projects they own
+func (s *UserService) GetProfile(userID int) (*domain.UserProfile, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.projectRepo.GetByOwnerID(userID)
+	if err != nil {
+		return nil, domain.NewAPIError(500, "Failed to retrieve projects", err.Error())
+	}
+
+	if projects == nil {
+		projects = []domain.Project{}
+	}
+
+	return &domain.UserProfile{User: user, Projects: projects}, nil
+}
+
+// CreateProject creates a project owned by a user, with the user as its facilitator
+func (s *UserService) CreateProject(ctx context.Context, userID int, req domain.CreateProjectRequest) (*domain.Project, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.OwnerID = &user.ID
+	project, err := s.projectService.CreateProject(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ensureFacilitator(ctx, user, project.ID); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// FacilitatorFor returns the facilitator attendee a user acts through in a project they own,
+// creating it the first time
+func (s *UserService) FacilitatorFor(ctx context.Context, userID, projectID int) (*domain.Attendee, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectService.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.OwnerID == nil || *project.OwnerID != user.ID {
+		return nil, domain.NewAPIError(403, "You do not own this project")
+	}
+
+	return s.ensureFacilitator(ctx, user, projectID)
+}
+
+// ensureFacilitator finds or creates the facilitator attendee linked to a user in a project.
+// Its generated PIN is discarded, since the user signs in through the identity provider.
+func (s *UserService) ensureFacilitator(ctx context.Context, user *domain.User, projectID int) (*domain.Attendee, error) {
+	attendees, err := s.attendeeService.GetProjectAttendees(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range attendees {
+		if attendees[i].UserID != nil && *attendees[i].UserID == user.ID && attendees[i].IsFacilitator {
+			return &attendees[i], nil
+		}
+	}
+
+	credentials, err := s.attendeeService.CreateAttendee(ctx, projectID, domain.CreateAttendeeRequest{
+		Name:          user.Name,
+		Role:          "Owner",
+		IsFacilitator: true,
+		UserID:        &user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.Attendee, nil
+}
+
+// getUser retrieves a user by ID
+func (s *UserService) getUser(userID int) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewAPIError(404, "User not found")
+		}
+		return nil, domain.NewAPIError(500, "Failed to retrieve user", err.Error())
+	}
+	return user, nil
+}
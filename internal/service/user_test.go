@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"pairwise/internal/domain"
+)
+
+// stubIdentityProvider records the login it was asked to start and fails exchanges
+type stubIdentityProvider struct {
+	state, nonce, challenge string
+}
+
+func (p *stubIdentityProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	p.state, p.nonce, p.challenge = state, nonce, codeChallenge
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state), nil
+}
+
+func (p *stubIdentityProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*domain.OIDCIdentity, error) {
+	return nil, errors.New("exchange refused")
+}
+
+// TestUserServiceLogin tests that logins check the provider is configured and that the
+// browser comes back with the state it was sent with
+func TestUserServiceLogin(t *testing.T) {
+	tokens := NewTokenService([]byte("test-secret"), time.Hour)
+	users := NewUserService(nil, nil, nil, nil, tokens)
+
+	if _, _, err := users.BeginLogin(context.Background()); apiErrorCode(err) != 404 {
+		t.Errorf("Expected 404 before OpenID Connect is enabled, got %v", err)
+	}
+
+	provider := &stubIdentityProvider{}
+	users.EnableOIDC(provider, "")
+
+	_, stateToken, err := users.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	state, err := tokens.VerifyLoginState(stateToken)
+	if err != nil {
+		t.Fatalf("Expected signed login state, got %v", err)
+	}
+	if state.State != provider.state || state.Nonce != provider.nonce || state.State == state.Nonce {
+		t.Errorf("Expected distinct state and nonce to reach the provider, got %+v", state)
+	}
+	if provider.challenge == state.CodeVerifier {
+		t.Error("Expected the provider to get the code challenge, not the verifier")
+	}
+
+	tests := []struct {
+		name       string
+		stateToken string
+		state      string
+		code       string
+		expected   int
+	}{
+		{name: "Missing cookie", stateToken: "", state: provider.state, code: "code", expected: 400},
+		{name: "Mismatched state", stateToken: stateToken, state: "other", code: "code", expected: 400},
+		{name: "Missing code", stateToken: stateToken, state: provider.state, code: "", expected: 400},
+		{name: "Refused exchange", stateToken: stateToken, state: provider.state, code: "code", expected: 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := users.CompleteLogin(context.Background(), tt.stateToken, tt.state, tt.code)
+			if code := apiErrorCode(err); code != tt.expected {
+				t.Errorf("Expected %d, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+// apiErrorCode returns the status code of an API error, or 0
+func apiErrorCode(err error) int {
+	var apiErr *domain.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
@@ -3,33 +3,51 @@ package service
 import (
 	"testing"
 
+	"pairwise/internal/domain"
 	"pairwise/internal/websocket"
 )
 
 // MockWebSocketBroadcaster implements WebSocketBroadcaster for testing
 type MockWebSocketBroadcaster struct {
+	Topics                  []websocket.Topic
+	StartedNotifications    []websocket.SessionStartedMessage
 	VoteNotifications       []websocket.VoteUpdateMessage
 	ConsensusNotifications  []websocket.ConsensusReachedMessage
 	ProgressNotifications   []websocket.SessionProgressMessage
 	CompletionNotifications []websocket.SessionCompletedMessage
+	FocusNotifications      []websocket.FocusMessage
 }
 
-func (m *MockWebSocketBroadcaster) NotifyVoteSubmitted(sessionID int, voteUpdate websocket.VoteUpdateMessage) {
+func (m *MockWebSocketBroadcaster) NotifySessionStarted(topic websocket.Topic, started websocket.SessionStartedMessage) {
+	m.Topics = append(m.Topics, topic)
+	m.StartedNotifications = append(m.StartedNotifications, started)
+}
+
+func (m *MockWebSocketBroadcaster) NotifyVoteSubmitted(topic websocket.Topic, voteUpdate websocket.VoteUpdateMessage) {
+	m.Topics = append(m.Topics, topic)
 	m.VoteNotifications = append(m.VoteNotifications, voteUpdate)
 }
 
-func (m *MockWebSocketBroadcaster) NotifyConsensusReached(sessionID int, consensus websocket.ConsensusReachedMessage) {
+func (m *MockWebSocketBroadcaster) NotifyConsensusReached(topic websocket.Topic, consensus websocket.ConsensusReachedMessage) {
+	m.Topics = append(m.Topics, topic)
 	m.ConsensusNotifications = append(m.ConsensusNotifications, consensus)
 }
 
-func (m *MockWebSocketBroadcaster) NotifySessionProgress(sessionID int, progress websocket.SessionProgressMessage) {
+func (m *MockWebSocketBroadcaster) NotifySessionProgress(topic websocket.Topic, progress websocket.SessionProgressMessage) {
+	m.Topics = append(m.Topics, topic)
 	m.ProgressNotifications = append(m.ProgressNotifications, progress)
 }
 
-func (m *MockWebSocketBroadcaster) NotifySessionCompleted(sessionID int, completion websocket.SessionCompletedMessage) {
+func (m *MockWebSocketBroadcaster) NotifySessionCompleted(topic websocket.Topic, completion websocket.SessionCompletedMessage) {
+	m.Topics = append(m.Topics, topic)
 	m.CompletionNotifications = append(m.CompletionNotifications, completion)
 }
 
+func (m *MockWebSocketBroadcaster) NotifyFocusChanged(topic websocket.Topic, focus websocket.FocusMessage) {
+	m.Topics = append(m.Topics, topic)
+	m.FocusNotifications = append(m.FocusNotifications, focus)
+}
+
 func TestPairwiseService_SetWebSocketBroadcaster(t *testing.T) {
 	// Create mock broadcaster
 	mockBroadcaster := &MockWebSocketBroadcaster{}
@@ -59,18 +77,21 @@ func TestWebSocketBroadcasterInterface(t *testing.T) {
 	var broadcaster WebSocketBroadcaster = mock
 
 	// Test each method
+	topic := websocket.PairwiseTopic(1, domain.CriterionTypeValue)
+	broadcaster.NotifySessionStarted(topic, websocket.SessionStartedMessage{SessionID: 1, CriterionType: "value"})
+
 	voteUpdate := websocket.VoteUpdateMessage{
 		ComparisonID: 1,
 		AttendeeID:   1,
 		AttendeeName: "Test User",
 	}
-	broadcaster.NotifyVoteSubmitted(1, voteUpdate)
+	broadcaster.NotifyVoteSubmitted(topic, voteUpdate)
 
 	consensus := websocket.ConsensusReachedMessage{
 		ComparisonID: 1,
 		WinnerID:     &[]int{1}[0],
 	}
-	broadcaster.NotifyConsensusReached(1, consensus)
+	broadcaster.NotifyConsensusReached(topic, consensus)
 
 	progress := websocket.SessionProgressMessage{
 		SessionID:            1,
@@ -78,15 +99,19 @@ func TestWebSocketBroadcasterInterface(t *testing.T) {
 		TotalComparisons:     10,
 		ProgressPercentage:   10.0,
 	}
-	broadcaster.NotifySessionProgress(1, progress)
+	broadcaster.NotifySessionProgress(topic, progress)
 
 	completion := websocket.SessionCompletedMessage{
 		SessionID:     1,
 		CriterionType: "value",
 	}
-	broadcaster.NotifySessionCompleted(1, completion)
+	broadcaster.NotifySessionCompleted(topic, completion)
 
 	// Verify notifications were received
+	if len(mock.StartedNotifications) != 1 {
+		t.Errorf("Expected 1 session started notification, got %d", len(mock.StartedNotifications))
+	}
+
 	if len(mock.VoteNotifications) != 1 {
 		t.Errorf("Expected 1 vote notification, got %d", len(mock.VoteNotifications))
 	}
@@ -102,4 +127,10 @@ func TestWebSocketBroadcasterInterface(t *testing.T) {
 	if len(mock.CompletionNotifications) != 1 {
 		t.Errorf("Expected 1 completion notification, got %d", len(mock.CompletionNotifications))
 	}
+
+	for _, received := range mock.Topics {
+		if received != topic {
+			t.Errorf("Expected notifications on %s, got %s", topic, received)
+		}
+	}
 }
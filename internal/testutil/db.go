@@ -0,0 +1,34 @@
+// Package testutil holds helpers shared by the tests of several packages
+package testutil
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"pairwise/internal/repository"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// NewTestDB opens a migrated SQLite database in a temporary directory, closed when the test
+// ends
+func NewTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := repository.Migrate(db); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get SQL DB: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB
+}
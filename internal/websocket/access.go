@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"time"
+
+	"pairwise/internal/domain"
+
+	"github.com/gorilla/websocket"
+)
+
+// AccessChecker tells the role an attendee's session token grants now, refusing tokens that
+// were revoked since they were issued
+type AccessChecker interface {
+	CurrentRole(projectID, attendeeID, tokenVersion int) (domain.Role, error)
+}
+
+// SetAccessChecker sets how connections are checked against their attendee's current state
+// before privileged messages. Without one, a connection keeps the role it was opened with.
+func (h *Hub) SetAccessChecker(checker AccessChecker) {
+	h.accessChecker = checker
+}
+
+// CurrentRole returns the role a client's session token grants now. The attendee may have
+// been demoted, deleted or had their PIN reset since the connection was opened.
+func (h *Hub) CurrentRole(client *Client) (domain.Role, error) {
+	if h.accessChecker == nil {
+		return client.GetRole(), nil
+	}
+
+	return h.accessChecker.CurrentRole(client.projectID, client.attendeeID, client.tokenVersion)
+}
+
+// authorize checks that the client's attendee currently has the required role, replying
+// with the refusal when not. A revoked session token closes the connection instead, as it
+// would no longer be accepted to open one; the close frame gives the reason.
+func (c *Client) authorize(message *Message, required domain.Role, refusal string) bool {
+	role, err := c.hub.CurrentRole(c)
+	if err != nil {
+		apiErr, ok := err.(*domain.APIError)
+		if !ok {
+			c.replyError(message, 500, "Failed to check access", err.Error())
+			return false
+		}
+
+		if apiErr.Code == 401 {
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, apiErr.Message),
+				time.Now().Add(writeWait))
+			c.close()
+			return false
+		}
+		c.replyError(message, apiErr.Code, apiErr.Message, apiErr.Details)
+		return false
+	}
+
+	if !role.Allows(required) {
+		c.replyError(message, 403, refusal, "")
+		return false
+	}
+	return true
+}
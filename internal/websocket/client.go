@@ -2,18 +2,25 @@ package websocket
 
 import (
 	"context"
+	"errors"
 	"log"
-	"net/http"
+	"sort"
 	"sync"
 	"time"
 
+	"pairwise/internal/domain"
+
 	"github.com/gorilla/websocket"
 )
 
 // HubInterface defines the interface for the WebSocket hub
 type HubInterface interface {
-	BroadcastToSession(sessionID int, message *Message)
-	HandleClientJoin(client *Client)
+	SubmitVote(ctx context.Context, client *Client, topic Topic, vote SubmitVoteMessage) (*domain.AttendeeVote, error)
+	Subscribe(client *Client, topics []Topic, resume *Resume)
+	Unsubscribe(client *Client, topics []Topic)
+	Presence(topic Topic, comparisonID int) (*PresenceMessage, error)
+	DriveSession(ctx context.Context, client *Client, topic Topic, action domain.DriverAction, comparisonID int) (*domain.SessionFocus, error)
+	CurrentRole(client *Client) (domain.Role, error)
 	UnregisterClient(client *Client)
 }
 
@@ -25,7 +32,11 @@ const (
 	pongWait = 60 * time.Second
 
 	// Send pings to peer with this period. Must be less than pongWait
-	pingPeriod = (pongWait * 9) / 10
+	pingPeriod = pongWait / 4
+
+	// A connection that has not answered for this long is shown as idle until it answers
+	// again or pongWait passes and it is closed
+	idleAfter = 2 * pingPeriod
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 512
@@ -34,16 +45,6 @@ const (
 	clientBufferSize = 256
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// Allow connections from any origin for now
-		// In production, implement proper origin validation
-		return true
-	},
-}
-
 // Client represents a WebSocket client connection
 type Client struct {
 	// The websocket connection
@@ -56,14 +57,25 @@ type Client struct {
 	hub HubInterface
 
 	// Client identification
-	sessionID  int
-	attendeeID int
+	projectID    int
+	attendeeID   int
+	attendeeName string
+	role         domain.Role
+	tokenVersion int
+
+	// Topics the client is subscribed to, all within its project
+	topics map[Topic]bool
 
 	// Client metadata
 	userAgent   string
 	remoteAddr  string
 	connectedAt time.Time
 
+	// Last heartbeat or message from the peer, and whether that was long enough ago to be
+	// reported as idle
+	lastSeen time.Time
+	idle     bool
+
 	// Context for cancellation
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -75,37 +87,124 @@ type Client struct {
 	isConnected bool
 }
 
-// NewClient creates a new WebSocket client
-func NewClient(hub HubInterface, conn *websocket.Conn, sessionID, attendeeID int) *Client {
+// NewClient creates a new WebSocket client for an attendee of a project, subscribed to the
+// given topics once it is registered with the hub
+func NewClient(hub HubInterface, conn *websocket.Conn, attendee *domain.Attendee, role domain.Role, topics ...Topic) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Client{
-		conn:        conn,
-		send:        make(chan *Message, clientBufferSize),
-		hub:         hub,
-		sessionID:   sessionID,
-		attendeeID:  attendeeID,
-		userAgent:   "", // Will be set during upgrade
-		remoteAddr:  conn.RemoteAddr().String(),
-		connectedAt: time.Now(),
-		ctx:         ctx,
-		cancel:      cancel,
-		isConnected: true,
+	client := &Client{
+		conn:         conn,
+		send:         make(chan *Message, clientBufferSize),
+		hub:          hub,
+		projectID:    attendee.ProjectID,
+		attendeeID:   attendee.ID,
+		attendeeName: attendee.Name,
+		role:         role,
+		tokenVersion: attendee.TokenVersion,
+		topics:       make(map[Topic]bool),
+		remoteAddr:   conn.RemoteAddr().String(),
+		connectedAt:  time.Now(),
+		lastSeen:     time.Now(),
+		ctx:          ctx,
+		cancel:       cancel,
+		isConnected:  true,
+	}
+	for _, topic := range topics {
+		if topic.ProjectID == client.projectID {
+			client.topics[topic] = true
+		}
+	}
+
+	return client
+}
+
+// SetUserAgent records the client's user agent for connection info
+func (c *Client) SetUserAgent(userAgent string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.userAgent = userAgent
+}
+
+// GetProjectID returns the project the client is connected to
+func (c *Client) GetProjectID() int {
+	return c.projectID
+}
+
+// GetAttendeeName returns the name of the client's attendee
+func (c *Client) GetAttendeeName() string {
+	return c.attendeeName
+}
+
+// GetRole returns the role the client's session token grants
+func (c *Client) GetRole() domain.Role {
+	return c.role
+}
+
+// Topics returns the topics the client is subscribed to, ordered by name
+func (c *Client) Topics() []Topic {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	topics := make([]Topic, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
 	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	return topics
 }
 
-// GetSessionID returns the client's session ID
-func (c *Client) GetSessionID() int {
+// IsSubscribed returns whether the client is subscribed to a topic
+func (c *Client) IsSubscribed(topic Topic) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.sessionID
+	return c.topics[topic]
+}
+
+// setSubscribed adds or removes a topic, returning whether that changed anything
+func (c *Client) setSubscribed(topic Topic, subscribed bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.topics[topic] == subscribed || topic.ProjectID != c.projectID {
+		return false
+	}
+	if subscribed {
+		c.topics[topic] = true
+	} else {
+		delete(c.topics, topic)
+	}
+	return true
 }
 
 // GetAttendeeID returns the client's attendee ID
 func (c *Client) GetAttendeeID() int {
+	return c.attendeeID
+}
+
+// LastSeen returns when the peer last answered a heartbeat or sent a message
+func (c *Client) LastSeen() time.Time {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.attendeeID
+	return c.lastSeen
+}
+
+// touch records that the peer is still there
+func (c *Client) touch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastSeen = time.Now()
+}
+
+// updateIdle records whether the client is idle at the given time, returning whether that
+// changed since the last check
+func (c *Client) updateIdle(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	idle := now.Sub(c.lastSeen) > idleAfter
+	changed := idle != c.idle
+	c.idle = idle
+	return changed
 }
 
 // IsConnected returns whether the client is currently connected
@@ -115,17 +214,23 @@ func (c *Client) IsConnected() bool {
 	return c.isConnected
 }
 
-// Send sends a message to the client
+// Send sends a message to the client. The read lock keeps the send channel open while the
+// message is queued.
 func (c *Client) Send(message *Message) {
-	if !c.IsConnected() {
+	c.mu.RLock()
+	if !c.isConnected {
+		c.mu.RUnlock()
 		return
 	}
 
 	select {
 	case c.send <- message:
+		c.mu.RUnlock()
 	default:
-		// Channel is full, close the client
-		c.close()
+		// Channel is full, close the client. Closing waits on the hub, which may be the
+		// caller, so it happens separately.
+		c.mu.RUnlock()
+		go c.close()
 	}
 }
 
@@ -149,9 +254,8 @@ func (c *Client) Start() {
 // close closes the client connection and cleans up resources
 func (c *Client) close() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	if !c.isConnected {
+		c.mu.Unlock()
 		return
 	}
 
@@ -163,6 +267,7 @@ func (c *Client) close() {
 
 	// Close the send channel
 	close(c.send)
+	c.mu.Unlock()
 
 	// Notify the hub
 	c.hub.UnregisterClient(c)
@@ -176,6 +281,7 @@ func (c *Client) readPump() {
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.touch()
 		return nil
 	})
 
@@ -195,6 +301,8 @@ func (c *Client) readPump() {
 			break
 		}
 
+		c.touch()
+
 		// Set timestamp if not provided
 		if message.Timestamp.IsZero() {
 			message.Timestamp = time.Now()
@@ -244,12 +352,16 @@ func (c *Client) handleMessage(message *Message) {
 	log.Printf("Received message from client %d: %s", c.attendeeID, message.Type)
 
 	switch message.Type {
-	case MessageTypeJoinSession:
-		c.handleJoinSession(message)
-	case MessageTypeLeaveSession:
-		c.handleLeaveSession(message)
-	case MessageTypeVoteSubmitted:
-		c.handleVoteSubmitted(message)
+	case MessageTypeSubscribe:
+		c.handleSubscribe(message, true)
+	case MessageTypeUnsubscribe:
+		c.handleSubscribe(message, false)
+	case MessageTypeSubmitVote:
+		c.handleSubmitVote(message)
+	case MessageTypeGetPresence:
+		c.handleGetPresence(message)
+	case MessageTypeFocusComparison, MessageTypeOpenVoting, MessageTypeCloseVoting, MessageTypeReveal, MessageTypeNext:
+		c.handleDrive(message)
 	default:
 		// Send error for unknown message type
 		c.SendJSON(MessageTypeError, ErrorMessage{
@@ -260,79 +372,181 @@ func (c *Client) handleMessage(message *Message) {
 	}
 }
 
-// handleJoinSession handles join session requests
-func (c *Client) handleJoinSession(message *Message) {
-	var joinMsg JoinSessionMessage
-	if err := message.ParseMessageData(&joinMsg); err != nil {
+// handleSubscribe handles subscribe and unsubscribe requests. Topic names are resolved
+// within the client's project, so a client cannot follow another project.
+func (c *Client) handleSubscribe(message *Message, subscribe bool) {
+	var subscribeMsg SubscribeMessage
+	if err := message.ParseMessageData(&subscribeMsg); err != nil {
 		c.SendJSON(MessageTypeError, ErrorMessage{
 			Code:    400,
-			Message: "Invalid join session message",
+			Message: "Invalid subscription message",
 			Details: err.Error(),
 		})
 		return
 	}
 
-	// Validate session ID matches
-	if joinMsg.SessionID != c.sessionID {
+	topics, err := ParseTopics(c.projectID, subscribeMsg.Topics)
+	if err != nil {
 		c.SendJSON(MessageTypeError, ErrorMessage{
 			Code:    400,
-			Message: "Session ID mismatch",
+			Message: "Invalid topic",
+			Details: err.Error(),
 		})
 		return
 	}
 
-	// Validate attendee ID matches
-	if joinMsg.AttendeeID != c.attendeeID {
-		c.SendJSON(MessageTypeError, ErrorMessage{
-			Code:    400,
-			Message: "Attendee ID mismatch",
-		})
+	if subscribe {
+		var resume *Resume
+		if len(subscribeMsg.LastSeq) > 0 {
+			resume = &Resume{Epoch: subscribeMsg.Epoch, LastSeq: make(map[Topic]uint64)}
+			for name, seq := range subscribeMsg.LastSeq {
+				topic, err := ParseTopic(c.projectID, name)
+				if err != nil {
+					c.SendJSON(MessageTypeError, ErrorMessage{
+						Code:    400,
+						Message: "Invalid topic",
+						Details: err.Error(),
+					})
+					return
+				}
+				resume.LastSeq[topic] = seq
+				topics = append(topics, topic)
+			}
+		}
+		c.hub.Subscribe(c, topics, resume)
+	} else {
+		c.hub.Unsubscribe(c, topics)
+	}
+}
+
+// handleSubmitVote casts a vote for the client's attendee and answers with an ack or an
+// error carrying the request's message ID. Other clients only hear about the vote from the
+// vote_update the server sends once it is saved.
+func (c *Client) handleSubmitVote(message *Message) {
+	if !c.authorize(message, domain.RoleAttendee, "Observers cannot vote") {
+		return
+	}
+
+	var voteMsg SubmitVoteMessage
+	if err := message.ParseMessageData(&voteMsg); err != nil {
+		c.replyError(message, 400, "Invalid vote message", err.Error())
+		return
+	}
+
+	topic, err := ParseTopic(c.projectID, message.Topic)
+	if err != nil || topic.Kind() != TopicKindPairwise {
+		c.replyError(message, 400, "Votes need a pairwise topic", message.Topic)
 		return
 	}
 
-	// Notify hub of successful join
-	c.hub.HandleClientJoin(c)
+	vote, err := c.hub.SubmitVote(c.requestContext(message), c, topic, voteMsg)
+	if err != nil {
+		var apiErr *domain.APIError
+		if errors.As(err, &apiErr) {
+			c.replyError(message, apiErr.Code, apiErr.Message, apiErr.Details)
+		} else {
+			c.replyError(message, 500, "Failed to submit vote", err.Error())
+		}
+		return
+	}
+
+	c.reply(message, MessageTypeAck, VoteAckMessage{Vote: vote})
 }
 
-// handleLeaveSession handles leave session requests
-func (c *Client) handleLeaveSession(message *Message) {
-	var leaveMsg LeaveSessionMessage
-	if err := message.ParseMessageData(&leaveMsg); err != nil {
-		c.SendJSON(MessageTypeError, ErrorMessage{
-			Code:    400,
-			Message: "Invalid leave session message",
-			Details: err.Error(),
-		})
+// handleDrive takes a facilitator's step through the driven session of a pairwise topic. The
+// resulting focus is acknowledged to the facilitator and broadcast to the topic.
+func (c *Client) handleDrive(message *Message) {
+	if !c.authorize(message, domain.RoleFacilitator, "Only facilitators can drive a session") {
 		return
 	}
 
-	// Close the connection
-	c.close()
+	var driveMsg DriveMessage
+	if err := message.ParseMessageData(&driveMsg); err != nil {
+		c.replyError(message, 400, "Invalid drive message", err.Error())
+		return
+	}
+
+	topic, err := ParseTopic(c.projectID, message.Topic)
+	if err != nil || topic.Kind() != TopicKindPairwise {
+		c.replyError(message, 400, "Driving a session needs a pairwise topic", message.Topic)
+		return
+	}
+
+	focus, err := c.hub.DriveSession(c.requestContext(message), c, topic, domain.DriverAction(message.Type), driveMsg.ComparisonID)
+	if err != nil {
+		var apiErr *domain.APIError
+		if errors.As(err, &apiErr) {
+			c.replyError(message, apiErr.Code, apiErr.Message, apiErr.Details)
+		} else {
+			c.replyError(message, 500, "Failed to drive session", err.Error())
+		}
+		return
+	}
+
+	c.reply(message, MessageTypeAck, FocusMessage{Focus: focus})
 }
 
-// handleVoteSubmitted handles vote submission notifications
-func (c *Client) handleVoteSubmitted(message *Message) {
-	var voteMsg VoteSubmittedMessage
-	if err := message.ParseMessageData(&voteMsg); err != nil {
-		c.SendJSON(MessageTypeError, ErrorMessage{
-			Code:    400,
-			Message: "Invalid vote submitted message",
-			Details: err.Error(),
-		})
+// requestContext returns the context for acting on a client's message. Audit entries name
+// the attendee as the actor, as over the REST API, and the message ID as the request. As
+// with the X-Request-ID header, a missing or overlong message ID is replaced by a server
+// generated one; replies still carry the client's ID.
+func (c *Client) requestContext(message *Message) context.Context {
+	attendeeID := c.attendeeID
+	ctx := domain.WithActor(c.ctx, domain.Actor{Type: domain.ActorAttendee, ID: &attendeeID})
+	return domain.WithRequestID(ctx, domain.RequestIDFrom(message.ID))
+}
+
+// handleGetPresence answers a facilitator's request for the presence on a topic
+func (c *Client) handleGetPresence(message *Message) {
+	if !c.authorize(message, domain.RoleFacilitator, "Only facilitators can see presence") {
 		return
 	}
 
-	// Validate attendee ID matches
-	if voteMsg.AttendeeID != c.attendeeID {
-		c.SendJSON(MessageTypeError, ErrorMessage{
-			Code:    400,
-			Message: "Attendee ID mismatch",
-		})
+	var presenceMsg GetPresenceMessage
+	if err := message.ParseMessageData(&presenceMsg); err != nil {
+		c.replyError(message, 400, "Invalid presence message", err.Error())
+		return
+	}
+
+	topic, err := ParseTopic(c.projectID, message.Topic)
+	if err != nil {
+		c.replyError(message, 400, "Invalid topic", err.Error())
+		return
+	}
+
+	presence, err := c.hub.Presence(topic, presenceMsg.ComparisonID)
+	if err != nil {
+		var apiErr *domain.APIError
+		if errors.As(err, &apiErr) {
+			c.replyError(message, apiErr.Code, apiErr.Message, apiErr.Details)
+		} else {
+			c.replyError(message, 500, "Failed to get presence", err.Error())
+		}
 		return
 	}
 
-	// Forward to hub for broadcasting
-	c.hub.BroadcastToSession(c.sessionID, message)
+	c.reply(message, MessageTypePresence, presence)
+}
+
+// reply sends a message correlated with a client's request by its ID
+func (c *Client) reply(request *Message, msgType MessageType, data interface{}) {
+	message, err := NewMessageBuilder(msgType).WithData(data).WithID(request.ID).Build()
+	if err != nil {
+		log.Printf("Failed to create %s reply: %v", msgType, err)
+		return
+	}
+	message.Topic = request.Topic
+
+	c.Send(message)
+}
+
+// replyError sends an error correlated with a client's request by its ID
+func (c *Client) replyError(request *Message, code int, message, details string) {
+	c.reply(request, MessageTypeError, ErrorMessage{
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
 }
 
 // GetConnectionInfo returns connection information for debugging
@@ -340,9 +554,15 @@ func (c *Client) GetConnectionInfo() map[string]interface{} {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	topics := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic.Name)
+	}
+
 	return map[string]interface{}{
-		"session_id":   c.sessionID,
+		"project_id":   c.projectID,
 		"attendee_id":  c.attendeeID,
+		"topics":       topics,
 		"remote_addr":  c.remoteAddr,
 		"user_agent":   c.userAgent,
 		"connected_at": c.connectedAt,
@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"context"
+	"log"
+
+	"pairwise/internal/domain"
+)
+
+// SessionDriver lets the facilitator of a driven pairwise session pace it, and tells where
+// the active driven session of a project and criterion is
+type SessionDriver interface {
+	DriveSession(ctx context.Context, projectID int, criterionType domain.CriterionType, action domain.DriverAction, comparisonID int) (*domain.SessionFocus, error)
+	GetFocus(projectID int, criterionType domain.CriterionType) (*domain.SessionFocus, error)
+}
+
+// SetSessionDriver sets how facilitators drive sessions over connections. Without one,
+// driven sessions cannot be driven over WebSocket.
+func (h *Hub) SetSessionDriver(driver SessionDriver) {
+	h.sessionDriver = driver
+}
+
+// DriveSession takes a facilitator's step through the driven session of a pairwise topic.
+// The server's focus notification follows a successful step.
+func (h *Hub) DriveSession(ctx context.Context, client *Client, topic Topic, action domain.DriverAction, comparisonID int) (*domain.SessionFocus, error) {
+	if h.sessionDriver == nil {
+		return nil, domain.NewAPIError(503, "Driving sessions over WebSocket is not available")
+	}
+
+	return h.sessionDriver.DriveSession(ctx, client.GetProjectID(), topic.Criterion(), action, comparisonID)
+}
+
+// NotifyFocusChanged notifies a topic's clients that the facilitator moved a driven session
+// on, which also changes whose vote presence is waiting for
+func (h *Hub) NotifyFocusChanged(topic Topic, focus FocusMessage) {
+	h.publish(topic, MessageTypeFocus, focus)
+	h.pushPresence(topic)
+}
+
+// sendFocus tells a client that just joined pairwise topics where their driven sessions are,
+// so that it shows the comparison in focus without waiting for the facilitator's next step.
+// Topics without an active driven session are skipped.
+func (h *Hub) sendFocus(client *Client, topics []Topic) {
+	if h.sessionDriver == nil {
+		return
+	}
+
+	for _, topic := range topics {
+		if topic.Kind() != TopicKindPairwise {
+			continue
+		}
+
+		focus, err := h.sessionDriver.GetFocus(topic.ProjectID, topic.Criterion())
+		if err != nil {
+			continue
+		}
+
+		message, err := CreateMessage(MessageTypeFocus, FocusMessage{Focus: focus})
+		if err != nil {
+			log.Printf("Failed to create focus message: %v", err)
+			continue
+		}
+		message.Topic = topic.Name
+
+		client.Send(message)
+	}
+}
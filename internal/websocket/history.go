@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"log"
+	"sort"
+	"time"
+)
+
+// replayBufferSize is how many recent messages the hub keeps per topic for clients that
+// reconnect. A client further behind gets a snapshot instead.
+const replayBufferSize = 256
+
+// historyTTL is how long the hub keeps the messages of a topic that nobody is subscribed to
+// after its last broadcast, such as the topics of completed sessions and deleted projects
+const historyTTL = 30 * time.Minute
+
+// historyCheckPeriod is how often the hub looks for histories to drop
+const historyCheckPeriod = time.Minute
+
+// Resume asks for the messages a reconnecting client missed on its topics
+type Resume struct {
+	Epoch   string           // Epoch of the hub the client last saw messages from
+	LastSeq map[Topic]uint64 // Last seq the client saw on each topic
+}
+
+// topics returns the topics being resumed, ordered by name
+func (r *Resume) topics() []Topic {
+	topics := make([]Topic, 0, len(r.LastSeq))
+	for topic := range r.LastSeq {
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	return topics
+}
+
+// topicHistory numbers the messages broadcast to a topic and keeps the most recent ones
+type topicHistory struct {
+	seq          uint64
+	recent       []*Message
+	lastRecorded time.Time
+}
+
+// record gives a message the topic's next seq and keeps it
+func (t *topicHistory) record(message *Message) {
+	t.seq++
+	message.Seq = t.seq
+	t.lastRecorded = time.Now()
+
+	t.recent = append(t.recent, message)
+	if len(t.recent) > replayBufferSize {
+		t.recent = t.recent[len(t.recent)-replayBufferSize:]
+	}
+}
+
+// lastSeq returns the seq of the last message broadcast to the topic
+func (t *topicHistory) lastSeq() uint64 {
+	if t == nil {
+		return 0
+	}
+	return t.seq
+}
+
+// covers reports whether every message after lastSeq is still kept
+func (t *topicHistory) covers(lastSeq uint64) bool {
+	if lastSeq > t.lastSeq() {
+		// The client saw messages this history never had
+		return false
+	}
+	if t == nil || lastSeq == t.seq {
+		return true
+	}
+	oldest := t.seq - uint64(len(t.recent)) + 1
+	return lastSeq+1 >= oldest
+}
+
+// after returns the kept messages with a seq greater than lastSeq
+func (t *topicHistory) after(lastSeq uint64) []*Message {
+	if t == nil || lastSeq >= t.seq {
+		return nil
+	}
+	missed := t.seq - lastSeq
+	if missed > uint64(len(t.recent)) {
+		return t.recent
+	}
+	return t.recent[uint64(len(t.recent))-missed:]
+}
+
+// evictHistory drops the histories of topics that nobody is subscribed to and that have had
+// no broadcast for historyTTL. A topic that gets messages again numbers them after every seq
+// dropped so far, so that its seq never goes back and clients resuming from before get a
+// snapshot.
+func (h *Hub) evictHistory(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	evicted := 0
+	for topic, history := range h.history {
+		if len(h.rooms[topic]) > 0 || now.Sub(history.lastRecorded) < historyTTL {
+			continue
+		}
+
+		h.evictedSeq = max(h.evictedSeq, history.seq)
+		delete(h.history, topic)
+		evicted++
+	}
+
+	if evicted > 0 {
+		log.Printf("Dropped the message history of %d inactive topics", evicted)
+	}
+}
@@ -1,17 +1,23 @@
 package websocket
 
 import (
+	"context"
 	"log"
+	"strconv"
 	"sync"
 	"time"
 
 	"pairwise/internal/domain"
 )
 
-// Hub maintains the set of active clients and broadcasts messages to the clients
+// Hub maintains the set of active clients and broadcasts messages to the clients subscribed
+// to a topic
 type Hub struct {
-	// Registered clients organized by session ID
-	sessions map[int]map[*Client]bool
+	// Registered clients
+	clients map[*Client]bool
+
+	// Subscribed clients organized by topic
+	rooms map[Topic]map[*Client]bool
 
 	// Register requests from the clients
 	register chan *Client
@@ -19,11 +25,33 @@ type Hub struct {
 	// Unregister requests from clients
 	unregister chan *Client
 
-	// Broadcast messages to specific sessions
+	// Broadcast messages to specific topics
 	broadcast chan *BroadcastMessage
 
-	// Attendee repository for getting attendee info
-	attendeeRepo AttendeeRepository
+	// Recent messages of each topic, kept for clients that reconnect
+	history map[Topic]*topicHistory
+
+	// Highest seq of the histories dropped for inactivity, which new histories number after
+	evictedSeq uint64
+
+	// Identifies this run of the hub, since seq numbering starts over on restart
+	epoch string
+
+	// Casts votes sent by clients
+	voteSubmitter VoteSubmitter
+
+	// Builds the state of topics for clients too far behind to replay
+	snapshotProvider SnapshotProvider
+
+	// Tells presence who has voted, and keeps presence pushes in order
+	votingStatusProvider VotingStatusProvider
+	presenceMu           sync.Mutex
+
+	// Carries out facilitators' steps through driven sessions
+	sessionDriver SessionDriver
+
+	// Checks connections against their attendee's current role before privileged messages
+	accessChecker AccessChecker
 
 	// Mutex for concurrent safety
 	mu sync.RWMutex
@@ -32,9 +60,9 @@ type Hub struct {
 	stats HubStats
 }
 
-// BroadcastMessage represents a message to broadcast to a session
+// BroadcastMessage represents a message to broadcast to a topic
 type BroadcastMessage struct {
-	SessionID     int
+	Topic         Topic
 	Message       *Message
 	ExcludeClient *Client // Optional: exclude this client from broadcast
 }
@@ -43,7 +71,7 @@ type BroadcastMessage struct {
 type HubStats struct {
 	TotalConnections    int
 	ActiveConnections   int
-	ActiveSessions      int
+	ActiveTopics        int
 	MessagesSent        int64
 	MessagesReceived    int64
 	ConnectionsAccepted int64
@@ -51,19 +79,28 @@ type HubStats struct {
 	LastActivity        time.Time
 }
 
-// AttendeeRepository interface for getting attendee information
-type AttendeeRepository interface {
-	GetByID(attendeeID int) (*domain.Attendee, error)
+// VoteSubmitter casts votes in the active pairwise session of a project and criterion. The
+// server's vote_update notification follows a successful vote.
+type VoteSubmitter interface {
+	SubmitActiveSessionVote(ctx context.Context, projectID int, criterionType domain.CriterionType, req domain.SubmitVoteRequest) (*domain.AttendeeVote, error)
+}
+
+// SnapshotProvider builds the current state of a topic, as a client would load it over the
+// REST API
+type SnapshotProvider interface {
+	Snapshot(topic Topic) (interface{}, error)
 }
 
 // NewHub creates a new WebSocket hub
-func NewHub(attendeeRepo AttendeeRepository) *Hub {
+func NewHub() *Hub {
 	return &Hub{
-		sessions:     make(map[int]map[*Client]bool),
-		register:     make(chan *Client),
-		unregister:   make(chan *Client),
-		broadcast:    make(chan *BroadcastMessage),
-		attendeeRepo: attendeeRepo,
+		clients:    make(map[*Client]bool),
+		rooms:      make(map[Topic]map[*Client]bool),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan *BroadcastMessage),
+		history:    make(map[Topic]*topicHistory),
+		epoch:      strconv.FormatInt(time.Now().UnixNano(), 36),
 		stats: HubStats{
 			LastActivity: time.Now(),
 		},
@@ -74,6 +111,12 @@ func NewHub(attendeeRepo AttendeeRepository) *Hub {
 func (h *Hub) Run() {
 	log.Println("WebSocket Hub started")
 
+	presenceTicker := time.NewTicker(presenceCheckPeriod)
+	defer presenceTicker.Stop()
+
+	historyTicker := time.NewTicker(historyCheckPeriod)
+	defer historyTicker.Stop()
+
 	for {
 		select {
 		case client := <-h.register:
@@ -84,10 +127,48 @@ func (h *Hub) Run() {
 
 		case broadcast := <-h.broadcast:
 			h.broadcastMessage(broadcast)
+
+		case <-presenceTicker.C:
+			h.checkIdle()
+
+		case now := <-historyTicker.C:
+			h.evictHistory(now)
 		}
 	}
 }
 
+// SetVoteSubmitter sets how votes sent over connections are cast. Without one, clients can
+// only vote over the REST API.
+func (h *Hub) SetVoteSubmitter(submitter VoteSubmitter) {
+	h.voteSubmitter = submitter
+}
+
+// SetSnapshotProvider sets how resuming clients that missed too much catch up. Without one,
+// they are told to reload over the REST API.
+func (h *Hub) SetSnapshotProvider(provider SnapshotProvider) {
+	h.snapshotProvider = provider
+}
+
+// Epoch returns the identifier of this run of the hub
+func (h *Hub) Epoch() string {
+	return h.epoch
+}
+
+// SubmitVote casts a vote sent by a client on behalf of the client's attendee
+func (h *Hub) SubmitVote(ctx context.Context, client *Client, topic Topic, vote SubmitVoteMessage) (*domain.AttendeeVote, error) {
+	if h.voteSubmitter == nil {
+		return nil, domain.NewAPIError(503, "Voting over WebSocket is not available")
+	}
+
+	return h.voteSubmitter.SubmitActiveSessionVote(ctx, client.GetProjectID(), topic.Criterion(), domain.SubmitVoteRequest{
+		ComparisonID:       vote.ComparisonID,
+		AttendeeID:         client.GetAttendeeID(),
+		PreferredFeatureID: vote.PreferredFeatureID,
+		IsTieVote:          vote.IsTieVote,
+		Intensity:          vote.Intensity,
+	})
+}
+
 // RegisterClient registers a new client connection
 func (h *Hub) RegisterClient(client *Client) {
 	h.register <- client
@@ -98,240 +179,399 @@ func (h *Hub) UnregisterClient(client *Client) {
 	h.unregister <- client
 }
 
-// BroadcastToSession broadcasts a message to all clients in a session
-func (h *Hub) BroadcastToSession(sessionID int, message *Message) {
+// BroadcastToTopic broadcasts a message to all clients subscribed to a topic
+func (h *Hub) BroadcastToTopic(topic Topic, message *Message) {
 	h.broadcast <- &BroadcastMessage{
-		SessionID: sessionID,
-		Message:   message,
+		Topic:   topic,
+		Message: message,
 	}
 }
 
-// BroadcastToSessionExcept broadcasts a message to all clients in a session except one
-func (h *Hub) BroadcastToSessionExcept(sessionID int, message *Message, excludeClient *Client) {
+// BroadcastToTopicExcept broadcasts a message to all clients subscribed to a topic except one
+func (h *Hub) BroadcastToTopicExcept(topic Topic, message *Message, excludeClient *Client) {
 	h.broadcast <- &BroadcastMessage{
-		SessionID:     sessionID,
+		Topic:         topic,
 		Message:       message,
 		ExcludeClient: excludeClient,
 	}
 }
 
-// HandleClientJoin handles when a client successfully joins a session
-func (h *Hub) HandleClientJoin(client *Client) {
-	attendee, err := h.attendeeRepo.GetByID(client.GetAttendeeID())
-	if err != nil {
-		log.Printf("Failed to get attendee %d: %v", client.GetAttendeeID(), err)
-		client.SendJSON(MessageTypeError, ErrorMessage{
-			Code:    500,
-			Message: "Failed to get attendee information",
-		})
-		return
+// Subscribe adds a client to topics of its project, confirms its subscriptions and tells
+// the topics' other clients that the attendee joined. With a resume, the client then gets
+// the messages it missed on the resumed topics, or a snapshot of a topic's state when they
+// are no longer kept. A client joining a driven session's topic is sent its focus.
+func (h *Hub) Subscribe(client *Client, topics []Topic, resume *Resume) {
+	// Snapshots read from the database, so they are built before taking the lock
+	catchUp := h.buildSnapshots(resume)
+
+	h.mu.Lock()
+	var joined []Topic
+	registered := h.clients[client]
+	if registered {
+		for _, topic := range topics {
+			if client.setSubscribed(topic, true) {
+				h.addToRoom(topic, client)
+				joined = append(joined, topic)
+			}
+		}
 	}
+	h.updateStats(false, false)
 
-	sessionID := client.GetSessionID()
+	// Replaying under the lock keeps broadcasts from slipping in between missed messages
+	h.sendSubscriptions(client)
+	if registered && resume != nil {
+		h.replay(client, resume, catchUp)
+	}
+	h.mu.Unlock()
 
-	// Send welcome message to the joining client
-	welcomeMsg := WelcomeMessage{
-		SessionID:      sessionID,
-		AttendeeID:     client.GetAttendeeID(),
-		AttendeeName:   attendee.Name,
-		ConnectedCount: h.getSessionClientCount(sessionID),
-		SessionStatus:  "active", // TODO: Get actual session status
+	for _, topic := range joined {
+		h.notifyAttendeeStatus(topic, client, AttendeeStatusJoined)
 	}
+	h.sendFocus(client, joined)
+}
 
-	client.SendJSON(MessageTypeWelcome, welcomeMsg)
+// Unsubscribe removes a client from topics, confirms its subscriptions and tells the topics'
+// other clients that the attendee left
+func (h *Hub) Unsubscribe(client *Client, topics []Topic) {
+	h.mu.Lock()
+	var left []Topic
+	for _, topic := range topics {
+		if client.setSubscribed(topic, false) {
+			h.removeFromRoom(topic, client)
+			left = append(left, topic)
+		}
+	}
+	h.updateStats(false, false)
+	h.mu.Unlock()
 
-	// Broadcast attendee joined status to other clients in the session
-	statusMsg := AttendeeStatusMessage{
-		AttendeeID:   client.GetAttendeeID(),
-		AttendeeName: attendee.Name,
-		Status:       AttendeeStatusJoined,
-		SessionID:    sessionID,
+	h.sendSubscriptions(client)
+	for _, topic := range left {
+		h.notifyAttendeeStatus(topic, client, AttendeeStatusLeft)
 	}
+}
 
-	// Broadcast to all other clients in the session
-	message, _ := CreateMessage(MessageTypeAttendeeStatus, statusMsg)
-	h.BroadcastToSessionExcept(sessionID, message, client)
+// NotifySessionStarted notifies a topic's clients that a pairwise session or Fibonacci round started
+func (h *Hub) NotifySessionStarted(topic Topic, started SessionStartedMessage) {
+	h.publish(topic, MessageTypeSessionStarted, started)
+	h.pushPresence(topic)
+}
 
-	log.Printf("Client %d (%s) joined session %d", client.GetAttendeeID(), attendee.Name, sessionID)
+// NotifyVoteSubmitted notifies a topic's clients about a vote submission
+func (h *Hub) NotifyVoteSubmitted(topic Topic, voteUpdate VoteUpdateMessage) {
+	h.publish(topic, MessageTypeVoteUpdate, voteUpdate)
+	h.pushPresence(topic)
 }
 
-// NotifyVoteSubmitted notifies all clients in a session about a vote submission
-func (h *Hub) NotifyVoteSubmitted(sessionID int, voteUpdate VoteUpdateMessage) {
-	message, err := CreateMessage(MessageTypeVoteUpdate, voteUpdate)
-	if err != nil {
-		log.Printf("Failed to create vote update message: %v", err)
-		return
-	}
+// NotifyConsensusReached notifies a topic's clients about consensus, which moves presence on
+// to the next comparison
+func (h *Hub) NotifyConsensusReached(topic Topic, consensus ConsensusReachedMessage) {
+	h.publish(topic, MessageTypeConsensusReached, consensus)
+	h.pushPresence(topic)
+}
 
-	h.BroadcastToSession(sessionID, message)
-	h.updateStats(false, true)
+// NotifySessionProgress notifies a topic's clients about session progress
+func (h *Hub) NotifySessionProgress(topic Topic, progress SessionProgressMessage) {
+	h.publish(topic, MessageTypeSessionProgress, progress)
 }
 
-// NotifyConsensusReached notifies all clients in a session about consensus
-func (h *Hub) NotifyConsensusReached(sessionID int, consensus ConsensusReachedMessage) {
-	message, err := CreateMessage(MessageTypeConsensusReached, consensus)
-	if err != nil {
-		log.Printf("Failed to create consensus message: %v", err)
-		return
-	}
+// NotifySessionCompleted notifies a topic's clients that a session is completed
+func (h *Hub) NotifySessionCompleted(topic Topic, completion SessionCompletedMessage) {
+	h.publish(topic, MessageTypeSessionCompleted, completion)
+	h.pushPresence(topic)
+}
 
-	h.BroadcastToSession(sessionID, message)
-	h.updateStats(false, true)
+// NotifyFibonacciScore notifies a topic's clients about a Fibonacci score
+func (h *Hub) NotifyFibonacciScore(topic Topic, score FibonacciScoreUpdateMessage) {
+	h.publish(topic, MessageTypeFibonacciScoreUpdate, score)
 }
 
-// NotifySessionProgress notifies all clients about session progress
-func (h *Hub) NotifySessionProgress(sessionID int, progress SessionProgressMessage) {
-	message, err := CreateMessage(MessageTypeSessionProgress, progress)
-	if err != nil {
-		log.Printf("Failed to create progress message: %v", err)
-		return
-	}
+// NotifyFibonacciConsensus notifies a topic's clients about a feature's final Fibonacci score
+func (h *Hub) NotifyFibonacciConsensus(topic Topic, consensus FibonacciConsensusMessage) {
+	h.publish(topic, MessageTypeFibonacciConsensus, consensus)
+}
 
-	h.BroadcastToSession(sessionID, message)
-	h.updateStats(false, true)
+// NotifyResultsUpdated notifies a topic's clients that project results were recalculated
+func (h *Hub) NotifyResultsUpdated(topic Topic, results ResultsUpdatedMessage) {
+	h.publish(topic, MessageTypeResultsUpdated, results)
 }
 
-// NotifySessionCompleted notifies all clients that a session is completed
-func (h *Hub) NotifySessionCompleted(sessionID int, completion SessionCompletedMessage) {
-	message, err := CreateMessage(MessageTypeSessionCompleted, completion)
+// publish creates a message and broadcasts it to a topic
+func (h *Hub) publish(topic Topic, msgType MessageType, data interface{}) {
+	message, err := CreateMessage(msgType, data)
 	if err != nil {
-		log.Printf("Failed to create session completed message: %v", err)
+		log.Printf("Failed to create %s message: %v", msgType, err)
 		return
 	}
 
-	h.BroadcastToSession(sessionID, message)
-	h.updateStats(false, true)
+	h.BroadcastToTopic(topic, message)
 }
 
-// registerClient handles client registration
+// registerClient handles client registration, subscribing the client to the topics it
+// asked for when connecting
 func (h *Hub) registerClient(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	sessionID := client.GetSessionID()
-
-	// Initialize session map if it doesn't exist
-	if h.sessions[sessionID] == nil {
-		h.sessions[sessionID] = make(map[*Client]bool)
+	h.clients[client] = true
+	topics := client.Topics()
+	for _, topic := range topics {
+		h.addToRoom(topic, client)
 	}
 
-	// Add client to session
-	h.sessions[sessionID][client] = true
-
+	h.stats.ConnectionsAccepted++
 	h.updateStats(true, false)
+	connected := h.countProjectClients(client.GetProjectID())
+	total := h.stats.ActiveConnections
+	h.mu.Unlock()
+
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+	client.SendJSON(MessageTypeWelcome, WelcomeMessage{
+		ProjectID:      client.GetProjectID(),
+		AttendeeID:     client.GetAttendeeID(),
+		AttendeeName:   client.GetAttendeeName(),
+		Role:           string(client.GetRole()),
+		ConnectedCount: connected,
+		Topics:         names,
+		Epoch:          h.epoch,
+	})
+
+	// Broadcasts go through the hub's loop, so they cannot be sent from it
+	go func() {
+		for _, topic := range topics {
+			h.notifyAttendeeStatus(topic, client, AttendeeStatusJoined)
+		}
+		h.sendFocus(client, topics)
+	}()
 
-	log.Printf("Client registered: session=%d, attendee=%d, total_clients=%d",
-		sessionID, client.GetAttendeeID(), h.stats.ActiveConnections)
+	log.Printf("Client registered: project=%d, attendee=%d, topics=%d, total_clients=%d",
+		client.GetProjectID(), client.GetAttendeeID(), len(topics), total)
 }
 
 // unregisterClient handles client unregistration
 func (h *Hub) unregisterClient(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	sessionID := client.GetSessionID()
-
-	if clients, exists := h.sessions[sessionID]; exists {
-		if _, exists := clients[client]; exists {
-			// Remove client from session
-			delete(clients, client)
-
-			// Clean up empty sessions
-			if len(clients) == 0 {
-				delete(h.sessions, sessionID)
-			}
+	if !h.clients[client] {
+		h.mu.Unlock()
+		return
+	}
 
-			h.updateStats(false, false)
+	delete(h.clients, client)
+	topics := client.Topics()
+	for _, topic := range topics {
+		h.removeFromRoom(topic, client)
+	}
+	h.updateStats(false, false)
+	total := h.stats.ActiveConnections
+	h.mu.Unlock()
 
-			log.Printf("Client unregistered: session=%d, attendee=%d, total_clients=%d",
-				sessionID, client.GetAttendeeID(), h.stats.ActiveConnections)
+	log.Printf("Client unregistered: project=%d, attendee=%d, total_clients=%d",
+		client.GetProjectID(), client.GetAttendeeID(), total)
 
-			// Notify other clients about attendee leaving
-			go h.notifyAttendeeLeft(sessionID, client)
+	// Notify other clients about attendee leaving
+	go func() {
+		for _, topic := range topics {
+			h.notifyAttendeeStatus(topic, client, AttendeeStatusLeft)
 		}
-	}
+	}()
 }
 
 // broadcastMessage handles message broadcasting
 func (h *Hub) broadcastMessage(broadcast *BroadcastMessage) {
-	h.mu.RLock()
-	clients, exists := h.sessions[broadcast.SessionID]
-	h.mu.RUnlock()
+	// Every recipient learns which of its topics the message belongs to
+	message := *broadcast.Message
+	message.Topic = broadcast.Topic.Name
 
-	if !exists {
-		return
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Messages are numbered and kept even when nobody is subscribed, so that clients that
+	// were away can catch up
+	if h.history[broadcast.Topic] == nil {
+		h.history[broadcast.Topic] = &topicHistory{seq: h.evictedSeq}
 	}
+	h.history[broadcast.Topic].record(&message)
 
 	messagesSent := 0
-	for client := range clients {
-		// Skip excluded client if specified
-		if broadcast.ExcludeClient != nil && client == broadcast.ExcludeClient {
+	for client := range h.rooms[broadcast.Topic] {
+		// Skip disconnected clients and the excluded client if specified
+		if !client.IsConnected() || client == broadcast.ExcludeClient {
 			continue
 		}
 
-		if client.IsConnected() {
-			client.Send(broadcast.Message)
-			messagesSent++
-		}
+		client.Send(&message)
+		messagesSent++
 	}
 
 	if messagesSent > 0 {
-		log.Printf("Broadcast message %s to session %d (%d clients)",
-			broadcast.Message.Type, broadcast.SessionID, messagesSent)
+		h.updateStats(false, true)
+
+		log.Printf("Broadcast message %s to %s (%d clients)",
+			message.Type, broadcast.Topic, messagesSent)
 	}
 }
 
-// notifyAttendeeLeft notifies other clients that an attendee has left
-func (h *Hub) notifyAttendeeLeft(sessionID int, leftClient *Client) {
-	attendee, err := h.attendeeRepo.GetByID(leftClient.GetAttendeeID())
+// notifyAttendeeStatus tells a topic's other clients that an attendee joined or left it, and
+// its facilitators how presence changed
+func (h *Hub) notifyAttendeeStatus(topic Topic, client *Client, status AttendeeStatus) {
+	message, err := CreateMessage(MessageTypeAttendeeStatus, AttendeeStatusMessage{
+		AttendeeID:   client.GetAttendeeID(),
+		AttendeeName: client.GetAttendeeName(),
+		Status:       status,
+	})
 	if err != nil {
-		log.Printf("Failed to get attendee %d for leave notification: %v", leftClient.GetAttendeeID(), err)
+		log.Printf("Failed to create attendee status message: %v", err)
 		return
 	}
 
-	statusMsg := AttendeeStatusMessage{
-		AttendeeID:   leftClient.GetAttendeeID(),
-		AttendeeName: attendee.Name,
-		Status:       AttendeeStatusLeft,
-		SessionID:    sessionID,
+	h.BroadcastToTopicExcept(topic, message, client)
+	h.pushPresence(topic)
+}
+
+// buildSnapshots builds the snapshot, or the error, that resumed topics which cannot be
+// replayed start with
+func (h *Hub) buildSnapshots(resume *Resume) map[Topic]*Message {
+	if resume == nil {
+		return nil
 	}
 
-	message, err := CreateMessage(MessageTypeAttendeeStatus, statusMsg)
-	if err != nil {
-		log.Printf("Failed to create attendee left message: %v", err)
-		return
+	catchUp := make(map[Topic]*Message)
+	for _, topic := range resume.topics() {
+		h.mu.RLock()
+		history := h.history[topic]
+		replayable := resume.Epoch == h.epoch && history.covers(resume.LastSeq[topic])
+		seq := history.lastSeq()
+		h.mu.RUnlock()
+
+		if replayable {
+			continue
+		}
+
+		var message *Message
+		var err error
+		if h.snapshotProvider == nil {
+			message, err = CreateMessage(MessageTypeError, ErrorMessage{
+				Code:    410,
+				Message: "Missed messages are no longer available",
+				Details: "reload the topic's state over the REST API",
+			})
+		} else if state, snapshotErr := h.snapshotProvider.Snapshot(topic); snapshotErr != nil {
+			log.Printf("Failed to build snapshot of %s: %v", topic, snapshotErr)
+			message, err = CreateMessage(MessageTypeError, ErrorMessage{
+				Code:    500,
+				Message: "Failed to load topic state",
+				Details: snapshotErr.Error(),
+			})
+		} else {
+			message, err = CreateMessage(MessageTypeSnapshot, SnapshotMessage{State: state})
+		}
+		if err != nil {
+			log.Printf("Failed to create catch-up message for %s: %v", topic, err)
+			continue
+		}
+
+		// The state is at least as new as the last message broadcast before building it
+		message.Topic = topic.Name
+		if message.Type == MessageTypeSnapshot {
+			message.Seq = seq
+		}
+		catchUp[topic] = message
 	}
 
-	h.BroadcastToSession(sessionID, message)
+	return catchUp
 }
 
-// getSessionClientCount returns the number of clients in a session
-func (h *Hub) getSessionClientCount(sessionID int) int {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// replay sends a resuming client what it missed on each subscribed topic: a snapshot when
+// one was built, then the kept messages after the last seq it has. The caller holds the lock.
+func (h *Hub) replay(client *Client, resume *Resume, catchUp map[Topic]*Message) {
+	for _, topic := range resume.topics() {
+		if !client.IsSubscribed(topic) {
+			continue
+		}
 
-	if clients, exists := h.sessions[sessionID]; exists {
-		return len(clients)
+		lastSeq := resume.LastSeq[topic]
+		if message, built := catchUp[topic]; built {
+			client.Send(message)
+			if message.Type != MessageTypeSnapshot {
+				continue
+			}
+			lastSeq = message.Seq
+		}
+
+		missed := h.history[topic].after(lastSeq)
+		for _, message := range missed {
+			client.Send(message)
+		}
+
+		if len(missed) > 0 {
+			log.Printf("Replayed %d messages of %s to attendee %d", len(missed), topic, client.GetAttendeeID())
+		}
+	}
+}
+
+// sendSubscriptions tells a client which topics it is subscribed to
+func (h *Hub) sendSubscriptions(client *Client) {
+	topics := client.Topics()
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+
+	client.SendJSON(MessageTypeSubscribed, SubscriptionsMessage{Topics: names})
+}
+
+// addToRoom adds a client to a topic's room. The caller holds the lock.
+func (h *Hub) addToRoom(topic Topic, client *Client) {
+	if h.rooms[topic] == nil {
+		h.rooms[topic] = make(map[*Client]bool)
+	}
+	h.rooms[topic][client] = true
+}
+
+// removeFromRoom removes a client from a topic's room, dropping empty rooms. The caller
+// holds the lock.
+func (h *Hub) removeFromRoom(topic Topic, client *Client) {
+	if clients, exists := h.rooms[topic]; exists {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.rooms, topic)
+		}
 	}
-	return 0
 }
 
-// GetSessionClients returns the list of clients in a session
-func (h *Hub) GetSessionClients(sessionID int) []*Client {
+// countProjectClients returns the number of clients connected to a project. The caller
+// holds the lock.
+func (h *Hub) countProjectClients(projectID int) int {
+	count := 0
+	for client := range h.clients {
+		if client.GetProjectID() == projectID {
+			count++
+		}
+	}
+	return count
+}
+
+// GetTopicClients returns the connected clients subscribed to a topic
+func (h *Hub) GetTopicClients(topic Topic) []*Client {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	var clients []*Client
-	if sessionClients, exists := h.sessions[sessionID]; exists {
-		for client := range sessionClients {
-			if client.IsConnected() {
-				clients = append(clients, client)
-			}
+	for client := range h.rooms[topic] {
+		if client.IsConnected() {
+			clients = append(clients, client)
 		}
 	}
 	return clients
 }
 
+// RecordRejectedConnection counts a connection refused before it was upgraded
+func (h *Hub) RecordRejectedConnection() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stats.ConnectionsRejected++
+}
+
 // GetStats returns hub statistics
 func (h *Hub) GetStats() HubStats {
 	h.mu.RLock()
@@ -339,16 +579,12 @@ func (h *Hub) GetStats() HubStats {
 	return h.stats
 }
 
-// updateStats updates hub statistics
-func (h *Hub) updateStats(connectionChange bool, messageSent bool) {
-	if connectionChange {
-		h.stats.ActiveConnections = 0
-		h.stats.ActiveSessions = len(h.sessions)
-
-		for _, clients := range h.sessions {
-			h.stats.ActiveConnections += len(clients)
-		}
+// updateStats updates hub statistics. The caller holds the lock.
+func (h *Hub) updateStats(connected bool, messageSent bool) {
+	h.stats.ActiveConnections = len(h.clients)
+	h.stats.ActiveTopics = len(h.rooms)
 
+	if connected {
 		h.stats.TotalConnections++
 	}
 
@@ -367,16 +603,15 @@ func (h *Hub) Shutdown() {
 	log.Println("Shutting down WebSocket Hub...")
 
 	// Close all client connections
-	for sessionID, clients := range h.sessions {
-		for client := range clients {
-			client.SendJSON(MessageTypeError, ErrorMessage{
-				Code:    503,
-				Message: "Server shutting down",
-			})
-			time.Sleep(100 * time.Millisecond) // Give time for message to send
-		}
-		delete(h.sessions, sessionID)
+	for client := range h.clients {
+		client.SendJSON(MessageTypeError, ErrorMessage{
+			Code:    503,
+			Message: "Server shutting down",
+		})
+		time.Sleep(100 * time.Millisecond) // Give time for message to send
+		delete(h.clients, client)
 	}
+	h.rooms = make(map[Topic]map[*Client]bool)
 
 	log.Println("WebSocket Hub shutdown complete")
 }
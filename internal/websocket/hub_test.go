@@ -0,0 +1,934 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pairwise/internal/domain"
+
+	"github.com/gorilla/websocket"
+)
+
+// testConnection is the browser side of a connection to a test hub
+type testConnection struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+// connect opens a connection for an attendee through a test server that registers it with
+// the hub, subscribed to the given topics
+func connect(t *testing.T, hub *Hub, attendee *domain.Attendee, role domain.Role, topics ...Topic) *testConnection {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := NewClient(hub, conn, attendee, role, topics...)
+		hub.RegisterClient(client)
+		client.Start()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	tc := &testConnection{t: t, conn: conn}
+	tc.expect(MessageTypeWelcome)
+	return tc
+}
+
+// send writes a message to the server
+func (tc *testConnection) send(msgType MessageType, data interface{}) {
+	tc.t.Helper()
+
+	message, err := CreateMessage(msgType, data)
+	if err != nil {
+		tc.t.Fatalf("Failed to create message: %v", err)
+	}
+	if err := tc.conn.WriteJSON(message); err != nil {
+		tc.t.Fatalf("Failed to send message: %v", err)
+	}
+}
+
+// next reads the next message, failing the test after a timeout
+func (tc *testConnection) next() *Message {
+	tc.t.Helper()
+
+	tc.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var message Message
+	if err := tc.conn.ReadJSON(&message); err != nil {
+		tc.t.Fatalf("Failed to read message: %v", err)
+	}
+	return &message
+}
+
+// expect reads the next message and checks its type
+func (tc *testConnection) expect(msgType MessageType) *Message {
+	tc.t.Helper()
+
+	message := tc.next()
+	if message.Type != msgType {
+		tc.t.Fatalf("Expected a %s message, got %s %s", msgType, message.Type, message.Data)
+	}
+	return message
+}
+
+// expectNothing checks that no message is waiting. A read timeout would break the
+// connection, so it asks for the subscriptions and expects them to be the next message.
+func (tc *testConnection) expectNothing() {
+	tc.t.Helper()
+
+	tc.send(MessageTypeSubscribe, SubscribeMessage{})
+	tc.expect(MessageTypeSubscribed)
+}
+
+// TestHubTopics tests that one connection per project receives the updates of exactly the
+// topics it is subscribed to
+func TestHubTopics(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	value := PairwiseTopic(1, domain.CriterionTypeValue)
+	complexity := PairwiseTopic(1, domain.CriterionTypeComplexity)
+
+	alice := connect(t, hub, &domain.Attendee{ID: 1, ProjectID: 1, Name: "Alice"}, domain.RoleAttendee, value)
+	bob := connect(t, hub, &domain.Attendee{ID: 2, ProjectID: 1, Name: "Bob"}, domain.RoleAttendee)
+	mallory := connect(t, hub, &domain.Attendee{ID: 3, ProjectID: 2, Name: "Mallory"}, domain.RoleAttendee)
+
+	t.Run("Subscribe", func(t *testing.T) {
+		bob.send(MessageTypeSubscribe, SubscribeMessage{Topics: []string{"pairwise:complexity", "pairwise:value", "results"}})
+
+		var subscriptions SubscriptionsMessage
+		if err := bob.expect(MessageTypeSubscribed).ParseMessageData(&subscriptions); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Join(subscriptions.Topics, ",") != "pairwise:complexity,pairwise:value,results" {
+			t.Errorf("Unexpected subscriptions %v", subscriptions.Topics)
+		}
+
+		joined := alice.expect(MessageTypeAttendeeStatus)
+		if joined.Topic != "pairwise:value" {
+			t.Errorf("Expected the join on pairwise:value, got %q", joined.Topic)
+		}
+	})
+
+	t.Run("Broadcast reaches subscribers", func(t *testing.T) {
+		hub.NotifyVoteSubmitted(complexity, VoteUpdateMessage{SessionID: 4, ComparisonID: 9})
+
+		message := bob.expect(MessageTypeVoteUpdate)
+		if message.Topic != "pairwise:complexity" {
+			t.Errorf("Expected the topic on the message, got %q", message.Topic)
+		}
+		alice.expectNothing()
+		mallory.expectNothing()
+	})
+
+	t.Run("Other projects stay apart", func(t *testing.T) {
+		mallory.send(MessageTypeSubscribe, SubscribeMessage{Topics: []string{"pairwise:value"}})
+		mallory.expect(MessageTypeSubscribed)
+
+		hub.NotifySessionProgress(value, SessionProgressMessage{SessionID: 3})
+		alice.expect(MessageTypeSessionProgress)
+		bob.expect(MessageTypeSessionProgress)
+		mallory.expectNothing()
+	})
+
+	t.Run("Invalid topic", func(t *testing.T) {
+		bob.send(MessageTypeSubscribe, SubscribeMessage{Topics: []string{"pairwise"}})
+		bob.expect(MessageTypeError)
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		bob.send(MessageTypeUnsubscribe, SubscribeMessage{Topics: []string{"pairwise:value"}})
+		bob.expect(MessageTypeSubscribed)
+
+		left := alice.expect(MessageTypeAttendeeStatus)
+		var status AttendeeStatusMessage
+		if err := left.ParseMessageData(&status); err != nil || status.Status != AttendeeStatusLeft || status.AttendeeID != 2 {
+			t.Errorf("Expected Bob to leave, got %s", left.Data)
+		}
+
+		hub.NotifyResultsUpdated(ResultsTopic(1), ResultsUpdatedMessage{ProjectID: 1})
+		bob.expect(MessageTypeResultsUpdated)
+		hub.NotifyConsensusReached(value, ConsensusReachedMessage{SessionID: 3})
+		alice.expect(MessageTypeConsensusReached)
+		bob.expectNothing()
+	})
+
+	t.Run("Disconnect", func(t *testing.T) {
+		alice.conn.Close()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for hub.GetStats().ActiveConnections != 2 {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected the connection to be unregistered, got %+v", hub.GetStats())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		mallory.expectNothing()
+	})
+}
+
+// stubVoteSubmitter records votes, failing on comparisons it does not know
+type stubVoteSubmitter struct {
+	criteria   []domain.CriterionType
+	requests   []domain.SubmitVoteRequest
+	actors     []domain.Actor
+	requestIDs []string
+}
+
+func (s *stubVoteSubmitter) SubmitActiveSessionVote(ctx context.Context, projectID int, criterionType domain.CriterionType, req domain.SubmitVoteRequest) (*domain.AttendeeVote, error) {
+	if req.ComparisonID != 5 {
+		return nil, domain.NewAPIError(404, "Comparison not found")
+	}
+
+	s.criteria = append(s.criteria, criterionType)
+	s.requests = append(s.requests, req)
+	s.actors = append(s.actors, domain.ActorFromContext(ctx))
+	s.requestIDs = append(s.requestIDs, domain.RequestIDFromContext(ctx))
+	return &domain.AttendeeVote{ID: 1, ComparisonID: req.ComparisonID, AttendeeID: req.AttendeeID, PreferredFeatureID: req.PreferredFeatureID}, nil
+}
+
+// TestHubSubmitVote tests that votes sent over a connection are cast for the connection's
+// attendee and answered with a correlated ack or error, without being relayed to others
+func TestHubSubmitVote(t *testing.T) {
+	hub := NewHub()
+	submitter := &stubVoteSubmitter{}
+	hub.SetVoteSubmitter(submitter)
+	go hub.Run()
+
+	value := PairwiseTopic(1, domain.CriterionTypeValue)
+	alice := connect(t, hub, &domain.Attendee{ID: 1, ProjectID: 1, Name: "Alice"}, domain.RoleAttendee, value)
+	bob := connect(t, hub, &domain.Attendee{ID: 2, ProjectID: 1, Name: "Bob"}, domain.RoleAttendee, value)
+	olivia := connect(t, hub, &domain.Attendee{ID: 3, ProjectID: 1, Name: "Olivia"}, domain.RoleObserver, value)
+	alice.expect(MessageTypeAttendeeStatus)
+	alice.expect(MessageTypeAttendeeStatus)
+	bob.expect(MessageTypeAttendeeStatus)
+
+	preferred := 7
+	submit := func(t *testing.T, tc *testConnection, id, topic string, vote SubmitVoteMessage) *Message {
+		t.Helper()
+
+		message, err := NewMessageBuilder(MessageTypeSubmitVote).WithData(vote).WithID(id).Build()
+		if err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+		message.Topic = topic
+		if err := tc.conn.WriteJSON(message); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+
+		reply := tc.next()
+		if reply.ID != id {
+			t.Errorf("Expected the reply to carry ID %q, got %q", id, reply.ID)
+		}
+		return reply
+	}
+
+	t.Run("Ack", func(t *testing.T) {
+		reply := submit(t, alice, "vote-1", "pairwise:value", SubmitVoteMessage{ComparisonID: 5, PreferredFeatureID: &preferred})
+		if reply.Type != MessageTypeAck {
+			t.Fatalf("Expected an ack, got %s %s", reply.Type, reply.Data)
+		}
+
+		var ack VoteAckMessage
+		if err := reply.ParseMessageData(&ack); err != nil || ack.Vote == nil || ack.Vote.AttendeeID != 1 {
+			t.Errorf("Expected Alice's vote in the ack, got %s", reply.Data)
+		}
+		if len(submitter.requests) != 1 || submitter.criteria[0] != domain.CriterionTypeValue {
+			t.Fatalf("Expected one value vote, got %+v", submitter.requests)
+		}
+		if actor := submitter.actors[0]; actor.Type != domain.ActorAttendee || actor.ID == nil || *actor.ID != 1 {
+			t.Errorf("Expected Alice as the actor, got %+v", actor)
+		}
+		if submitter.requestIDs[0] != "vote-1" {
+			t.Errorf("Expected the message ID as the request ID, got %q", submitter.requestIDs[0])
+		}
+
+		// Only the server's vote_update reaches other clients
+		bob.expectNothing()
+	})
+
+	t.Run("Overlong ID", func(t *testing.T) {
+		id := strings.Repeat("x", domain.MaxRequestIDLength+1)
+		reply := submit(t, alice, id, "pairwise:value", SubmitVoteMessage{ComparisonID: 5, IsTieVote: true})
+		if reply.Type != MessageTypeAck {
+			t.Fatalf("Expected an ack, got %s %s", reply.Type, reply.Data)
+		}
+
+		requestID := submitter.requestIDs[len(submitter.requestIDs)-1]
+		if requestID == id || requestID == "" || len(requestID) > domain.MaxRequestIDLength {
+			t.Errorf("Expected a generated request ID, got %q", requestID)
+		}
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		reply := submit(t, alice, "vote-2", "pairwise:value", SubmitVoteMessage{ComparisonID: 6, IsTieVote: true})
+
+		var apiErr ErrorMessage
+		if err := reply.ParseMessageData(&apiErr); reply.Type != MessageTypeError || err != nil || apiErr.Code != 404 {
+			t.Errorf("Expected the service's 404, got %s %s", reply.Type, reply.Data)
+		}
+	})
+
+	t.Run("Not a pairwise topic", func(t *testing.T) {
+		reply := submit(t, alice, "vote-3", "results", SubmitVoteMessage{ComparisonID: 5, IsTieVote: true})
+		if reply.Type != MessageTypeError {
+			t.Errorf("Expected an error, got %s", reply.Type)
+		}
+	})
+
+	t.Run("Observer", func(t *testing.T) {
+		reply := submit(t, olivia, "vote-4", "pairwise:value", SubmitVoteMessage{ComparisonID: 5, IsTieVote: true})
+
+		var apiErr ErrorMessage
+		if err := reply.ParseMessageData(&apiErr); reply.Type != MessageTypeError || err != nil || apiErr.Code != 403 {
+			t.Errorf("Expected a 403, got %s %s", reply.Type, reply.Data)
+		}
+	})
+
+	t.Run("Relayed vote notification", func(t *testing.T) {
+		alice.send("vote_submitted", map[string]interface{}{"comparison_id": 5, "attendee_id": 1})
+		alice.expect(MessageTypeError)
+		bob.expectNothing()
+	})
+
+	if len(submitter.requests) != 2 {
+		t.Errorf("Expected only the valid votes to be cast, got %d", len(submitter.requests))
+	}
+}
+
+// stubSnapshotProvider returns a state naming the topic
+type stubSnapshotProvider struct{}
+
+func (stubSnapshotProvider) Snapshot(topic Topic) (interface{}, error) {
+	return map[string]string{"topic": topic.Name}, nil
+}
+
+// waitForSeq waits until the hub has numbered a topic's messages up to seq
+func waitForSeq(t *testing.T, hub *Hub, topic Topic, seq uint64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		hub.mu.RLock()
+		last := hub.history[topic].lastSeq()
+		hub.mu.RUnlock()
+		if last >= seq {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s to reach seq %d, got %d", topic, seq, last)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestHubReplay tests that a reconnecting client gets the messages it missed on a topic, or
+// a snapshot of the topic when they are no longer kept
+func TestHubReplay(t *testing.T) {
+	hub := NewHub()
+	hub.SetSnapshotProvider(stubSnapshotProvider{})
+	go hub.Run()
+
+	value := PairwiseTopic(1, domain.CriterionTypeValue)
+	complexity := PairwiseTopic(1, domain.CriterionTypeComplexity)
+
+	// Messages are numbered while nobody is listening
+	for i := 1; i <= 3; i++ {
+		hub.NotifySessionProgress(value, SessionProgressMessage{SessionID: i})
+	}
+	waitForSeq(t, hub, value, 3)
+
+	expectSnapshot := func(t *testing.T, tc *testConnection, topic Topic) *Message {
+		t.Helper()
+
+		message := tc.expect(MessageTypeSnapshot)
+		var snapshot struct {
+			State map[string]string `json:"state"`
+		}
+		if err := message.ParseMessageData(&snapshot); err != nil || snapshot.State["topic"] != topic.Name {
+			t.Errorf("Expected the state of %s, got %s", topic.Name, message.Data)
+		}
+		if message.Topic != topic.Name {
+			t.Errorf("Expected the snapshot on %s, got %q", topic.Name, message.Topic)
+		}
+		return message
+	}
+
+	bob := connect(t, hub, &domain.Attendee{ID: 2, ProjectID: 1, Name: "Bob"}, domain.RoleAttendee)
+
+	t.Run("Replays missed messages", func(t *testing.T) {
+		bob.send(MessageTypeSubscribe, SubscribeMessage{
+			LastSeq: map[string]uint64{"pairwise:value": 1},
+			Epoch:   hub.Epoch(),
+		})
+		bob.expect(MessageTypeSubscribed)
+
+		for seq := uint64(2); seq <= 3; seq++ {
+			message := bob.expect(MessageTypeSessionProgress)
+			var progress SessionProgressMessage
+			if err := message.ParseMessageData(&progress); err != nil || message.Seq != seq || progress.SessionID != int(seq) {
+				t.Errorf("Expected seq %d, got %d %s", seq, message.Seq, message.Data)
+			}
+		}
+		bob.expectNothing()
+	})
+
+	t.Run("Live messages continue the sequence", func(t *testing.T) {
+		// Bob's join was number 4
+		waitForSeq(t, hub, value, 4)
+
+		hub.NotifyConsensusReached(value, ConsensusReachedMessage{SessionID: 1})
+		if message := bob.expect(MessageTypeConsensusReached); message.Seq != 5 {
+			t.Errorf("Expected seq 5, got %d", message.Seq)
+		}
+	})
+
+	carol := connect(t, hub, &domain.Attendee{ID: 3, ProjectID: 1, Name: "Carol"}, domain.RoleAttendee)
+
+	t.Run("Gap beyond the buffer", func(t *testing.T) {
+		total := uint64(replayBufferSize + 10)
+		for i := uint64(0); i < total; i++ {
+			hub.NotifySessionProgress(complexity, SessionProgressMessage{SessionID: 9})
+		}
+		waitForSeq(t, hub, complexity, total)
+
+		carol.send(MessageTypeSubscribe, SubscribeMessage{
+			LastSeq: map[string]uint64{"pairwise:complexity": 1},
+			Epoch:   hub.Epoch(),
+		})
+		carol.expect(MessageTypeSubscribed)
+
+		// Seqs are counted per topic
+		if snapshot := expectSnapshot(t, carol, complexity); snapshot.Seq != total {
+			t.Errorf("Expected the snapshot at seq %d, got %d", total, snapshot.Seq)
+		}
+		carol.expectNothing()
+	})
+
+	t.Run("Server restarted", func(t *testing.T) {
+		carol.send(MessageTypeSubscribe, SubscribeMessage{
+			LastSeq: map[string]uint64{"pairwise:value": 3},
+			Epoch:   "previous",
+		})
+		carol.expect(MessageTypeSubscribed)
+		expectSnapshot(t, carol, value)
+		carol.expectNothing()
+	})
+
+	t.Run("Seq ahead of the server", func(t *testing.T) {
+		carol.send(MessageTypeSubscribe, SubscribeMessage{
+			LastSeq: map[string]uint64{"pairwise:value": 1000},
+			Epoch:   hub.Epoch(),
+		})
+		carol.expect(MessageTypeSubscribed)
+		expectSnapshot(t, carol, value)
+		carol.expectNothing()
+	})
+
+	t.Run("Up to date", func(t *testing.T) {
+		waitForSeq(t, hub, value, 6)
+		hub.mu.RLock()
+		last := hub.history[value].lastSeq()
+		hub.mu.RUnlock()
+
+		carol.send(MessageTypeSubscribe, SubscribeMessage{
+			LastSeq: map[string]uint64{"pairwise:value": last},
+			Epoch:   hub.Epoch(),
+		})
+		carol.expect(MessageTypeSubscribed)
+		carol.expectNothing()
+	})
+
+	t.Run("Invalid topic", func(t *testing.T) {
+		carol.send(MessageTypeSubscribe, SubscribeMessage{LastSeq: map[string]uint64{"pairwise": 1}})
+		carol.expect(MessageTypeError)
+	})
+}
+
+// stubVotingStatus reports Alice as having voted on comparison 5, and Bob and Dave as not
+type stubVotingStatus struct{}
+
+func (stubVotingStatus) GetVotingStatus(projectID int, criterionType domain.CriterionType, comparisonID int) (*domain.VotingStatus, error) {
+	if criterionType != domain.CriterionTypeValue {
+		return nil, domain.NewAPIError(404, "No active session found")
+	}
+	if comparisonID != 0 && comparisonID != 5 {
+		return nil, domain.NewAPIError(404, "Comparison not found in the active session")
+	}
+	return &domain.VotingStatus{SessionID: 2, ComparisonID: 5, Voters: []domain.VoterStatus{
+		{AttendeeID: 1, AttendeeName: "Alice", Voted: true},
+		{AttendeeID: 2, AttendeeName: "Bob"},
+		{AttendeeID: 4, AttendeeName: "Dave"},
+	}}, nil
+}
+
+// TestHubEvictHistory tests that the messages of topics nobody follows are dropped once the
+// topics have been quiet for a while, and that their seq keeps counting up afterwards
+func TestHubEvictHistory(t *testing.T) {
+	hub := NewHub()
+	hub.SetSnapshotProvider(stubSnapshotProvider{})
+	go hub.Run()
+
+	value := PairwiseTopic(1, domain.CriterionTypeValue)
+	complexity := PairwiseTopic(1, domain.CriterionTypeComplexity)
+	for i := 1; i <= 3; i++ {
+		hub.NotifySessionProgress(value, SessionProgressMessage{SessionID: i})
+		hub.NotifySessionProgress(complexity, SessionProgressMessage{SessionID: i})
+	}
+	waitForSeq(t, hub, value, 3)
+	waitForSeq(t, hub, complexity, 3)
+
+	// Someone still follows the complexity topic
+	connect(t, hub, &domain.Attendee{ID: 1, ProjectID: 1, Name: "Alice"}, domain.RoleAttendee, complexity)
+
+	hub.evictHistory(time.Now())
+	hub.mu.RLock()
+	kept := len(hub.history)
+	hub.mu.RUnlock()
+	if kept != 2 {
+		t.Fatalf("Expected recent histories to be kept, got %d", kept)
+	}
+
+	hub.evictHistory(time.Now().Add(historyTTL))
+	hub.mu.RLock()
+	_, valueKept := hub.history[value]
+	_, complexityKept := hub.history[complexity]
+	hub.mu.RUnlock()
+	if valueKept || !complexityKept {
+		t.Fatalf("Expected only the unfollowed topic to be dropped, got value %v and complexity %v", valueKept, complexityKept)
+	}
+
+	hub.NotifySessionProgress(value, SessionProgressMessage{SessionID: 4})
+	waitForSeq(t, hub, value, 4)
+
+	t.Run("Resume from before the eviction", func(t *testing.T) {
+		bob := connect(t, hub, &domain.Attendee{ID: 2, ProjectID: 1, Name: "Bob"}, domain.RoleAttendee)
+		bob.send(MessageTypeSubscribe, SubscribeMessage{
+			Topics:  []string{value.Name},
+			LastSeq: map[string]uint64{value.Name: 2},
+			Epoch:   hub.Epoch(),
+		})
+		bob.expect(MessageTypeSubscribed)
+
+		if message := bob.expect(MessageTypeSnapshot); message.Seq != 4 {
+			t.Errorf("Expected a snapshot at seq 4, got %d", message.Seq)
+		}
+	})
+}
+
+// TestHubPresence tests that facilitators see who follows a topic, who is idle and who has
+// voted, while attendees cannot
+func TestHubPresence(t *testing.T) {
+	hub := NewHub()
+	hub.SetVotingStatusProvider(stubVotingStatus{})
+	go hub.Run()
+
+	value := PairwiseTopic(1, domain.CriterionTypeValue)
+	fran := connect(t, hub, &domain.Attendee{ID: 3, ProjectID: 1, Name: "Fran"}, domain.RoleFacilitator, value)
+	alice := connect(t, hub, &domain.Attendee{ID: 1, ProjectID: 1, Name: "Alice"}, domain.RoleAttendee, value)
+	connect(t, hub, &domain.Attendee{ID: 2, ProjectID: 1, Name: "Bob"}, domain.RoleAttendee, value)
+
+	// request asks for the presence on a topic and returns the reply, skipping pushed updates
+	request := func(t *testing.T, tc *testConnection, id, topic string, comparisonID int) *Message {
+		t.Helper()
+
+		message, err := NewMessageBuilder(MessageTypeGetPresence).WithData(GetPresenceMessage{ComparisonID: comparisonID}).WithID(id).Build()
+		if err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+		message.Topic = topic
+		if err := tc.conn.WriteJSON(message); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+
+		for {
+			if reply := tc.next(); reply.ID == id {
+				return reply
+			}
+		}
+	}
+
+	attendees := func(t *testing.T, message *Message) map[string]AttendeePresence {
+		t.Helper()
+
+		var presence PresenceMessage
+		if err := message.ParseMessageData(&presence); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		byName := make(map[string]AttendeePresence)
+		for _, attendee := range presence.Attendees {
+			byName[attendee.AttendeeName] = attendee
+		}
+		return byName
+	}
+
+	t.Run("Who has voted", func(t *testing.T) {
+		reply := request(t, fran, "p-1", "pairwise:value", 0)
+		if reply.Type != MessageTypePresence {
+			t.Fatalf("Expected presence, got %s %s", reply.Type, reply.Data)
+		}
+
+		byName := attendees(t, reply)
+		expected := map[string]struct {
+			status PresenceStatus
+			voted  *bool
+		}{
+			"Alice": {PresenceConnected, boolPtr(true)},
+			"Bob":   {PresenceConnected, boolPtr(false)},
+			"Dave":  {PresenceOffline, boolPtr(false)},
+			"Fran":  {PresenceConnected, nil},
+		}
+		if len(byName) != len(expected) {
+			t.Fatalf("Expected %d attendees, got %s", len(expected), reply.Data)
+		}
+		for name, want := range expected {
+			got := byName[name]
+			if got.Status != want.status || (got.Voted == nil) != (want.voted == nil) || (got.Voted != nil && *got.Voted != *want.voted) {
+				t.Errorf("Unexpected presence of %s: %+v", name, got)
+			}
+		}
+	})
+
+	t.Run("No active session", func(t *testing.T) {
+		complexity := "pairwise:complexity"
+		fran.send(MessageTypeSubscribe, SubscribeMessage{Topics: []string{complexity}})
+
+		reply := request(t, fran, "p-2", complexity, 0)
+		byName := attendees(t, reply)
+		if len(byName) != 1 || byName["Fran"].Voted != nil {
+			t.Errorf("Expected only Fran without votes, got %s", reply.Data)
+		}
+	})
+
+	t.Run("Unknown comparison", func(t *testing.T) {
+		reply := request(t, fran, "p-3", "pairwise:value", 6)
+		if reply.Type != MessageTypeError {
+			t.Errorf("Expected an error, got %s", reply.Type)
+		}
+	})
+
+	t.Run("Attendees cannot see presence", func(t *testing.T) {
+		reply := request(t, alice, "p-4", "pairwise:value", 0)
+
+		var apiErr ErrorMessage
+		if err := reply.ParseMessageData(&apiErr); reply.Type != MessageTypeError || err != nil || apiErr.Code != 403 {
+			t.Errorf("Expected a 403, got %s %s", reply.Type, reply.Data)
+		}
+	})
+
+	t.Run("Idle connection is pushed", func(t *testing.T) {
+		for _, client := range hub.GetTopicClients(value) {
+			if client.GetAttendeeID() == 1 {
+				client.mu.Lock()
+				client.lastSeen = time.Now().Add(-2 * idleAfter)
+				client.mu.Unlock()
+			}
+		}
+		hub.checkIdle()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			message := fran.next()
+			if message.Type == MessageTypePresence && message.ID == "" && attendees(t, message)["Alice"].Status == PresenceIdle {
+				return
+			}
+		}
+		t.Error("Expected a presence update with Alice idle")
+	})
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// stubSessionDriver keeps the focus of one driven session and announces its changes like the
+// pairwise service does
+type stubSessionDriver struct {
+	hub *Hub
+
+	mu      sync.Mutex
+	focus   *domain.SessionFocus
+	actions []domain.DriverAction
+}
+
+func (s *stubSessionDriver) DriveSession(ctx context.Context, projectID int, criterionType domain.CriterionType, action domain.DriverAction, comparisonID int) (*domain.SessionFocus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case action == domain.DriverActionFocus && comparisonID == 5:
+		s.focus = &domain.SessionFocus{
+			SessionID:  1,
+			Phase:      domain.FocusPhaseDiscussing,
+			Comparison: &domain.ComparisonWithVotes{Comparison: &domain.SessionComparison{ID: 5}},
+		}
+	case action == domain.DriverActionFocus:
+		return nil, domain.NewAPIError(404, "Comparison not found in the active session")
+	case action == domain.DriverActionOpenVoting && s.focus != nil:
+		s.focus.Phase = domain.FocusPhaseVoting
+	default:
+		return nil, domain.NewAPIError(409, "No comparison is in focus")
+	}
+	s.actions = append(s.actions, action)
+
+	focus := *s.focus
+	go s.hub.NotifyFocusChanged(PairwiseTopic(projectID, criterionType), FocusMessage{Focus: &focus})
+	return &focus, nil
+}
+
+func (s *stubSessionDriver) GetFocus(projectID int, criterionType domain.CriterionType) (*domain.SessionFocus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.focus == nil || criterionType != domain.CriterionTypeValue {
+		return nil, domain.NewAPIError(404, "No active session found")
+	}
+	focus := *s.focus
+	return &focus, nil
+}
+
+// TestHubDriver tests that facilitators drive a session with control messages, that everyone
+// on the topic follows the focus and that attendees joining later are sent it
+func TestHubDriver(t *testing.T) {
+	hub := NewHub()
+	driver := &stubSessionDriver{hub: hub}
+	hub.SetSessionDriver(driver)
+	go hub.Run()
+
+	value := PairwiseTopic(1, domain.CriterionTypeValue)
+	fran := connect(t, hub, &domain.Attendee{ID: 3, ProjectID: 1, Name: "Fran"}, domain.RoleFacilitator, value)
+	alice := connect(t, hub, &domain.Attendee{ID: 1, ProjectID: 1, Name: "Alice"}, domain.RoleAttendee, value)
+
+	// drive sends a control message and returns the reply, skipping pushed updates
+	drive := func(t *testing.T, tc *testConnection, id, topic string, action MessageType, comparisonID int) *Message {
+		t.Helper()
+
+		message, err := NewMessageBuilder(action).WithData(DriveMessage{ComparisonID: comparisonID}).WithID(id).Build()
+		if err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+		message.Topic = topic
+		if err := tc.conn.WriteJSON(message); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+
+		for {
+			if reply := tc.next(); reply.ID == id {
+				return reply
+			}
+		}
+	}
+
+	// nextFocus skips other updates until the next focus and returns it
+	nextFocus := func(t *testing.T, tc *testConnection) (*Message, *domain.SessionFocus) {
+		t.Helper()
+
+		for {
+			message := tc.next()
+			if message.Type != MessageTypeFocus {
+				continue
+			}
+			var focus FocusMessage
+			if err := message.ParseMessageData(&focus); err != nil || focus.Focus == nil {
+				t.Fatalf("Expected a focus, got %s", message.Data)
+			}
+			return message, focus.Focus
+		}
+	}
+
+	errorCode := func(t *testing.T, reply *Message) int {
+		t.Helper()
+
+		var apiErr ErrorMessage
+		if reply.Type != MessageTypeError || reply.ParseMessageData(&apiErr) != nil {
+			t.Fatalf("Expected an error, got %s %s", reply.Type, reply.Data)
+		}
+		return apiErr.Code
+	}
+
+	t.Run("Facilitator focuses a comparison", func(t *testing.T) {
+		reply := drive(t, fran, "d-1", "pairwise:value", MessageTypeFocusComparison, 5)
+		if reply.Type != MessageTypeAck {
+			t.Fatalf("Expected an ack, got %s %s", reply.Type, reply.Data)
+		}
+
+		var ack FocusMessage
+		if err := reply.ParseMessageData(&ack); err != nil || ack.Focus == nil || ack.Focus.Phase != domain.FocusPhaseDiscussing {
+			t.Errorf("Expected the focus in the ack, got %s", reply.Data)
+		}
+
+		message, focus := nextFocus(t, alice)
+		if message.Seq == 0 || message.Topic != "pairwise:value" || focus.Comparison.Comparison.ID != 5 {
+			t.Errorf("Expected comparison 5 in focus on the topic, got %+v %s", message, message.Data)
+		}
+	})
+
+	t.Run("Facilitator opens voting", func(t *testing.T) {
+		reply := drive(t, fran, "d-2", "pairwise:value", MessageTypeOpenVoting, 0)
+		if reply.Type != MessageTypeAck {
+			t.Fatalf("Expected an ack, got %s %s", reply.Type, reply.Data)
+		}
+
+		if _, focus := nextFocus(t, alice); focus.Phase != domain.FocusPhaseVoting {
+			t.Errorf("Expected voting to be open, got %s", focus.Phase)
+		}
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		reply := drive(t, fran, "d-3", "pairwise:value", MessageTypeFocusComparison, 6)
+		if code := errorCode(t, reply); code != 404 {
+			t.Errorf("Expected the service's 404, got %d", code)
+		}
+	})
+
+	t.Run("Attendees cannot drive", func(t *testing.T) {
+		reply := drive(t, alice, "d-4", "pairwise:value", MessageTypeNext, 0)
+		if code := errorCode(t, reply); code != 403 {
+			t.Errorf("Expected a 403, got %d", code)
+		}
+	})
+
+	t.Run("Not a pairwise topic", func(t *testing.T) {
+		reply := drive(t, fran, "d-5", "results", MessageTypeReveal, 0)
+		if code := errorCode(t, reply); code != 400 {
+			t.Errorf("Expected a 400, got %d", code)
+		}
+	})
+
+	t.Run("Late joiner is sent the focus", func(t *testing.T) {
+		bob := connect(t, hub, &domain.Attendee{ID: 2, ProjectID: 1, Name: "Bob"}, domain.RoleAttendee, value)
+
+		message, focus := nextFocus(t, bob)
+		if message.Seq != 0 || focus.Phase != domain.FocusPhaseVoting || focus.Comparison.Comparison.ID != 5 {
+			t.Errorf("Expected the current focus, got %+v %s", message, message.Data)
+		}
+	})
+
+	t.Run("Subscribing later is sent the focus", func(t *testing.T) {
+		carol := connect(t, hub, &domain.Attendee{ID: 4, ProjectID: 1, Name: "Carol"}, domain.RoleAttendee)
+		carol.send(MessageTypeSubscribe, SubscribeMessage{Topics: []string{"pairwise:value", "pairwise:complexity"}})
+		carol.expect(MessageTypeSubscribed)
+
+		if _, focus := nextFocus(t, carol); focus.Comparison.Comparison.ID != 5 {
+			t.Errorf("Expected comparison 5 in focus, got %+v", focus)
+		}
+		// The complexity topic has no driven session
+		carol.expectNothing()
+	})
+
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	if len(driver.actions) != 2 {
+		t.Errorf("Expected only the facilitator's valid steps to be taken, got %v", driver.actions)
+	}
+}
+
+// stubAccessChecker holds the current role and token version of each attendee, as the
+// attendee service does
+type stubAccessChecker struct {
+	mu       sync.Mutex
+	roles    map[int]domain.Role
+	versions map[int]int
+}
+
+func (s *stubAccessChecker) set(attendeeID int, role domain.Role, tokenVersion int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roles[attendeeID] = role
+	s.versions[attendeeID] = tokenVersion
+}
+
+func (s *stubAccessChecker) CurrentRole(projectID, attendeeID, tokenVersion int) (domain.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, ok := s.roles[attendeeID]
+	if !ok || s.versions[attendeeID] != tokenVersion {
+		return "", domain.NewAPIError(401, "Session token has been revoked")
+	}
+	return role, nil
+}
+
+// TestHubAccessChecker tests that an open connection loses the privileges its attendee lost
+// and is closed once the attendee's session token is revoked
+func TestHubAccessChecker(t *testing.T) {
+	hub := NewHub()
+	checker := &stubAccessChecker{roles: map[int]domain.Role{}, versions: map[int]int{}}
+	hub.SetAccessChecker(checker)
+	hub.SetVotingStatusProvider(stubVotingStatus{})
+	go hub.Run()
+
+	fran := &domain.Attendee{ID: 3, ProjectID: 1, Name: "Fran", TokenVersion: 1}
+	checker.set(fran.ID, domain.RoleFacilitator, 1)
+	tc := connect(t, hub, fran, domain.RoleFacilitator)
+
+	// presence asks for the presence on the value topic and returns the reply
+	presence := func(t *testing.T, id string) *Message {
+		t.Helper()
+
+		message, err := NewMessageBuilder(MessageTypeGetPresence).WithData(GetPresenceMessage{}).WithID(id).Build()
+		if err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+		message.Topic = "pairwise:value"
+		if err := tc.conn.WriteJSON(message); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		return tc.next()
+	}
+
+	errorCode := func(t *testing.T, reply *Message) int {
+		t.Helper()
+
+		var apiErr ErrorMessage
+		if reply.Type != MessageTypeError || reply.ParseMessageData(&apiErr) != nil {
+			t.Fatalf("Expected an error, got %s %s", reply.Type, reply.Data)
+		}
+		return apiErr.Code
+	}
+
+	t.Run("Facilitator sees presence", func(t *testing.T) {
+		if reply := presence(t, "p-1"); reply.Type != MessageTypePresence {
+			t.Errorf("Expected presence, got %s %s", reply.Type, reply.Data)
+		}
+	})
+
+	t.Run("Demoted facilitator is refused", func(t *testing.T) {
+		checker.set(fran.ID, domain.RoleAttendee, 1)
+		if code := errorCode(t, presence(t, "p-2")); code != 403 {
+			t.Errorf("Expected a 403, got %d", code)
+		}
+	})
+
+	t.Run("Revoked token closes the connection", func(t *testing.T) {
+		checker.set(fran.ID, domain.RoleFacilitator, 2)
+		message, err := NewMessageBuilder(MessageTypeGetPresence).WithData(GetPresenceMessage{}).WithID("p-3").Build()
+		if err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+		message.Topic = "pairwise:value"
+		if err := tc.conn.WriteJSON(message); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+
+		tc.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, data, err := tc.conn.ReadMessage()
+		closeErr, ok := err.(*websocket.CloseError)
+		if !ok || closeErr.Code != websocket.ClosePolicyViolation || closeErr.Text != "Session token has been revoked" {
+			t.Errorf("Expected the connection to be closed as revoked, got %v %s", err, data)
+		}
+	})
+}
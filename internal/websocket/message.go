@@ -3,6 +3,8 @@ package websocket
 import (
 	"encoding/json"
 	"time"
+
+	"pairwise/internal/domain"
 )
 
 // MessageType represents the type of WebSocket message
@@ -10,18 +12,35 @@ type MessageType string
 
 const (
 	// Client to server messages
-	MessageTypeJoinSession   MessageType = "join_session"
-	MessageTypeLeaveSession  MessageType = "leave_session"
-	MessageTypeVoteSubmitted MessageType = "vote_submitted"
+	MessageTypeSubscribe   MessageType = "subscribe"
+	MessageTypeUnsubscribe MessageType = "unsubscribe"
+	MessageTypeSubmitVote  MessageType = "submit_vote"
+	MessageTypeGetPresence MessageType = "get_presence"
+
+	// Facilitator control of a driven pairwise session; the types match domain.DriverAction
+	MessageTypeFocusComparison MessageType = "focus_comparison"
+	MessageTypeOpenVoting      MessageType = "open_voting"
+	MessageTypeCloseVoting     MessageType = "close_voting"
+	MessageTypeReveal          MessageType = "reveal"
+	MessageTypeNext            MessageType = "next"
 
 	// Server to client messages
-	MessageTypeConsensusReached MessageType = "consensus_reached"
-	MessageTypeSessionProgress  MessageType = "session_progress"
-	MessageTypeAttendeeStatus   MessageType = "attendee_status"
-	MessageTypeVoteUpdate       MessageType = "vote_update"
-	MessageTypeSessionCompleted MessageType = "session_completed"
-	MessageTypeError            MessageType = "error"
-	MessageTypeWelcome          MessageType = "welcome"
+	MessageTypeAck                  MessageType = "ack"
+	MessageTypeSubscribed           MessageType = "subscribed"
+	MessageTypeSessionStarted       MessageType = "session_started"
+	MessageTypeConsensusReached     MessageType = "consensus_reached"
+	MessageTypeSessionProgress      MessageType = "session_progress"
+	MessageTypeAttendeeStatus       MessageType = "attendee_status"
+	MessageTypeVoteUpdate           MessageType = "vote_update"
+	MessageTypeSessionCompleted     MessageType = "session_completed"
+	MessageTypeFibonacciScoreUpdate MessageType = "fibonacci_score_update"
+	MessageTypeFibonacciConsensus   MessageType = "fibonacci_consensus"
+	MessageTypeResultsUpdated       MessageType = "results_updated"
+	MessageTypeSnapshot             MessageType = "snapshot"
+	MessageTypePresence             MessageType = "presence"
+	MessageTypeFocus                MessageType = "focus"
+	MessageTypeError                MessageType = "error"
+	MessageTypeWelcome              MessageType = "welcome"
 )
 
 // AttendeeStatus represents the status of an attendee
@@ -32,37 +51,64 @@ const (
 	AttendeeStatusLeft   AttendeeStatus = "left"
 )
 
+// PresenceStatus represents how present an attendee is on a topic
+type PresenceStatus string
+
+const (
+	PresenceConnected PresenceStatus = "connected"
+	PresenceIdle      PresenceStatus = "idle"    // Connected, but the connection has missed heartbeats
+	PresenceOffline   PresenceStatus = "offline" // A voter who is not following the topic
+)
+
 // Message represents a WebSocket message
 type Message struct {
 	Type      MessageType     `json:"type"`
+	Topic     string          `json:"topic,omitempty"` // Topic name of broadcast messages, e.g. "pairwise:value"
+	Seq       uint64          `json:"seq,omitempty"`   // Position of a broadcast message in its topic, counting from 1
 	Data      json.RawMessage `json:"data,omitempty"`
 	Timestamp time.Time       `json:"timestamp"`
-	ID        string          `json:"id,omitempty"` // For message tracking
+	ID        string          `json:"id,omitempty"` // Set by clients on requests and echoed on the ack or error
 }
 
-// JoinSessionMessage represents a request to join a session
-type JoinSessionMessage struct {
-	SessionID  int `json:"session_id"`
-	AttendeeID int `json:"attendee_id"`
+// SubscribeMessage represents a request to subscribe to or unsubscribe from topics. A client
+// that reconnects resumes its topics by sending the last seq it saw of each, along with the
+// epoch of its previous connection's welcome; topics in LastSeq are subscribed to as well.
+type SubscribeMessage struct {
+	Topics  []string          `json:"topics"`
+	LastSeq map[string]uint64 `json:"last_seq,omitempty"`
+	Epoch   string            `json:"epoch,omitempty"`
 }
 
-// LeaveSessionMessage represents a request to leave a session
-type LeaveSessionMessage struct {
-	SessionID  int `json:"session_id"`
-	AttendeeID int `json:"attendee_id"`
+// SubscriptionsMessage lists the topics a client is subscribed to after a change
+type SubscriptionsMessage struct {
+	Topics []string `json:"topics"`
 }
 
-// VoteSubmittedMessage represents a vote submission notification
-type VoteSubmittedMessage struct {
-	ComparisonID       int    `json:"comparison_id"`
-	AttendeeID         int    `json:"attendee_id"`
-	PreferredFeatureID *int   `json:"preferred_feature_id,omitempty"`
-	IsTieVote          bool   `json:"is_tie_vote"`
-	AttendeeName       string `json:"attendee_name,omitempty"`
+// SessionStartedMessage represents a new pairwise session or Fibonacci round on a topic
+type SessionStartedMessage struct {
+	SessionID     int    `json:"session_id"`
+	CriterionType string `json:"criterion_type"`
+	Anonymous     bool   `json:"anonymous,omitempty"`
+	Driven        bool   `json:"driven,omitempty"`
+}
+
+// SubmitVoteMessage represents a vote cast over the connection in the active session of the
+// message's pairwise topic. The voter is always the connection's attendee.
+type SubmitVoteMessage struct {
+	ComparisonID       int  `json:"comparison_id"`
+	PreferredFeatureID *int `json:"preferred_feature_id,omitempty"`
+	IsTieVote          bool `json:"is_tie_vote"`
+	Intensity          int  `json:"intensity,omitempty"`
+}
+
+// VoteAckMessage confirms a vote submitted over the connection
+type VoteAckMessage struct {
+	Vote *domain.AttendeeVote `json:"vote"`
 }
 
 // ConsensusReachedMessage represents consensus achievement notification
 type ConsensusReachedMessage struct {
+	SessionID    int    `json:"session_id"`
 	ComparisonID int    `json:"comparison_id"`
 	WinnerID     *int   `json:"winner_id,omitempty"`
 	IsTie        bool   `json:"is_tie"`
@@ -80,24 +126,25 @@ type SessionProgressMessage struct {
 	RemainingComparisons int     `json:"remaining_comparisons"`
 }
 
-// AttendeeStatusMessage represents attendee presence update
+// AttendeeStatusMessage represents an attendee joining or leaving a topic
 type AttendeeStatusMessage struct {
 	AttendeeID   int            `json:"attendee_id"`
 	AttendeeName string         `json:"attendee_name"`
 	Status       AttendeeStatus `json:"status"`
-	SessionID    int            `json:"session_id"`
 }
 
 // VoteUpdateMessage represents a real-time vote update
 type VoteUpdateMessage struct {
+	SessionID          int    `json:"session_id"`
 	ComparisonID       int    `json:"comparison_id"`
-	AttendeeID         int    `json:"attendee_id"`
-	AttendeeName       string `json:"attendee_name"`
+	AttendeeID         int    `json:"attendee_id,omitempty"`   // Omitted in anonymous sessions
+	AttendeeName       string `json:"attendee_name,omitempty"` // Omitted in anonymous sessions
 	PreferredFeatureID *int   `json:"preferred_feature_id,omitempty"`
 	IsTieVote          bool   `json:"is_tie_vote"`
 	VotesReceived      int    `json:"votes_received"`
 	TotalAttendees     int    `json:"total_attendees"`
 	ConsensusReached   bool   `json:"consensus_reached"`
+	Anonymous          bool   `json:"anonymous,omitempty"`
 }
 
 // SessionCompletedMessage represents session completion notification
@@ -109,6 +156,76 @@ type SessionCompletedMessage struct {
 	TotalConsensus int       `json:"total_consensus"`
 }
 
+// FibonacciScoreUpdateMessage represents an attendee's Fibonacci score for a feature
+type FibonacciScoreUpdateMessage struct {
+	SessionID      int    `json:"session_id"`
+	FeatureID      int    `json:"feature_id"`
+	AttendeeID     int    `json:"attendee_id"`
+	AttendeeName   string `json:"attendee_name,omitempty"`
+	ScoreValue     int    `json:"score_value"`
+	ScoresReceived int    `json:"scores_received"`
+	TotalAttendees int    `json:"total_attendees"`
+}
+
+// FibonacciConsensusMessage represents a feature's final score in a Fibonacci round
+type FibonacciConsensusMessage struct {
+	SessionID   int    `json:"session_id"`
+	FeatureID   int    `json:"feature_id"`
+	FeatureName string `json:"feature_name,omitempty"`
+	FinalScore  int    `json:"final_score"`
+	Automatic   bool   `json:"automatic"` // Every attendee gave the same score, rather than the facilitator setting it
+}
+
+// ResultsUpdatedMessage represents newly calculated project results
+type ResultsUpdatedMessage struct {
+	ProjectID     int       `json:"project_id"`
+	TotalFeatures int       `json:"total_features"`
+	CalculatedAt  time.Time `json:"calculated_at"`
+}
+
+// SnapshotMessage carries the current state of a topic to a resuming client that missed more
+// events than the server keeps. Its seq is that of the last event the state includes; events
+// that follow may repeat changes already in the state.
+type SnapshotMessage struct {
+	State interface{} `json:"state"`
+}
+
+// GetPresenceMessage asks for the presence on the message's topic. For pairwise topics the
+// comparison whose voters are listed defaults to the one waiting longest for a result.
+type GetPresenceMessage struct {
+	ComparisonID int `json:"comparison_id,omitempty"`
+}
+
+// PresenceMessage lists the attendees following a topic and, for pairwise topics with an
+// active session, which voters have voted on a comparison. Votes themselves are never shown.
+type PresenceMessage struct {
+	SessionID    int                `json:"session_id,omitempty"`
+	ComparisonID int                `json:"comparison_id,omitempty"`
+	Attendees    []AttendeePresence `json:"attendees"`
+}
+
+// AttendeePresence represents an attendee's presence on a topic
+type AttendeePresence struct {
+	AttendeeID   int            `json:"attendee_id"`
+	AttendeeName string         `json:"attendee_name"`
+	Role         string         `json:"role,omitempty"` // Omitted for offline voters
+	Status       PresenceStatus `json:"status"`
+	LastSeen     *time.Time     `json:"last_seen,omitempty"` // Last heartbeat or message of a connected attendee
+	Voted        *bool          `json:"voted,omitempty"`     // Set for voters while a comparison is open
+}
+
+// DriveMessage is a facilitator's step through the driven session of the message's pairwise
+// topic. Only focus_comparison names a comparison.
+type DriveMessage struct {
+	ComparisonID int `json:"comparison_id,omitempty"`
+}
+
+// FocusMessage tells where a driven session is. Votes on the comparison in focus are withheld
+// until the facilitator reveals them.
+type FocusMessage struct {
+	Focus *domain.SessionFocus `json:"focus"`
+}
+
 // ErrorMessage represents an error notification
 type ErrorMessage struct {
 	Code    int    `json:"code"`
@@ -118,12 +235,13 @@ type ErrorMessage struct {
 
 // WelcomeMessage represents a welcome message after successful connection
 type WelcomeMessage struct {
-	SessionID      int    `json:"session_id"`
-	AttendeeID     int    `json:"attendee_id"`
-	AttendeeName   string `json:"attendee_name"`
-	CriterionType  string `json:"criterion_type"`
-	ConnectedCount int    `json:"connected_count"`
-	SessionStatus  string `json:"session_status"`
+	ProjectID      int      `json:"project_id"`
+	AttendeeID     int      `json:"attendee_id"`
+	AttendeeName   string   `json:"attendee_name"`
+	Role           string   `json:"role"`
+	ConnectedCount int      `json:"connected_count"` // Connections to the project, including this one
+	Topics         []string `json:"topics"`          // Topics subscribed to when connecting
+	Epoch          string   `json:"epoch"`           // Changes when the server restarts and seq numbering starts over
 }
 
 // CreateMessage creates a new WebSocket message
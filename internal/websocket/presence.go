@@ -0,0 +1,166 @@
+package websocket
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"pairwise/internal/domain"
+)
+
+// presenceCheckPeriod is how often the hub looks for connections that went idle or came back
+const presenceCheckPeriod = 5 * time.Second
+
+// VotingStatusProvider tells which voters have voted on a comparison of the active pairwise
+// session of a project and criterion
+type VotingStatusProvider interface {
+	GetVotingStatus(projectID int, criterionType domain.CriterionType, comparisonID int) (*domain.VotingStatus, error)
+}
+
+// SetVotingStatusProvider sets how presence learns who has voted. Without one, presence only
+// covers connections.
+func (h *Hub) SetVotingStatusProvider(provider VotingStatusProvider) {
+	h.votingStatusProvider = provider
+}
+
+// Presence lists the attendees following a topic, whether their connections are idle and,
+// for pairwise topics with an active session, which voters have voted on a comparison.
+// Voters who are not following the topic are listed as offline.
+func (h *Hub) Presence(topic Topic, comparisonID int) (*PresenceMessage, error) {
+	presence := &PresenceMessage{Attendees: []AttendeePresence{}}
+
+	var voting *domain.VotingStatus
+	if topic.Kind() == TopicKindPairwise && h.votingStatusProvider != nil {
+		status, err := h.votingStatusProvider.GetVotingStatus(topic.ProjectID, topic.Criterion(), comparisonID)
+		var apiErr *domain.APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.Code == 404 && comparisonID == 0) {
+			return nil, err
+		}
+		// Without an active session there is nothing to vote on
+		if err == nil {
+			voting = status
+			presence.SessionID = status.SessionID
+			presence.ComparisonID = status.ComparisonID
+		}
+	}
+
+	now := time.Now()
+	byAttendee := make(map[int]*AttendeePresence)
+
+	h.mu.RLock()
+	for client := range h.rooms[topic] {
+		if !client.IsConnected() {
+			continue
+		}
+
+		lastSeen := client.LastSeen()
+		status := PresenceConnected
+		if now.Sub(lastSeen) > idleAfter {
+			status = PresenceIdle
+		}
+
+		// An attendee with several connections is as present as the liveliest one
+		entry, exists := byAttendee[client.GetAttendeeID()]
+		if exists && !lastSeen.After(*entry.LastSeen) {
+			continue
+		}
+		if !exists {
+			entry = &AttendeePresence{
+				AttendeeID:   client.GetAttendeeID(),
+				AttendeeName: client.GetAttendeeName(),
+				Role:         string(client.GetRole()),
+			}
+			byAttendee[entry.AttendeeID] = entry
+		}
+		entry.Status = status
+		entry.LastSeen = &lastSeen
+	}
+	h.mu.RUnlock()
+
+	if voting != nil {
+		for _, voter := range voting.Voters {
+			entry, exists := byAttendee[voter.AttendeeID]
+			if !exists {
+				entry = &AttendeePresence{
+					AttendeeID:   voter.AttendeeID,
+					AttendeeName: voter.AttendeeName,
+					Status:       PresenceOffline,
+				}
+				byAttendee[voter.AttendeeID] = entry
+			}
+			if voting.ComparisonID != 0 {
+				voted := voter.Voted
+				entry.Voted = &voted
+			}
+		}
+	}
+
+	for _, entry := range byAttendee {
+		presence.Attendees = append(presence.Attendees, *entry)
+	}
+	sort.Slice(presence.Attendees, func(i, j int) bool {
+		a, b := presence.Attendees[i], presence.Attendees[j]
+		if a.AttendeeName != b.AttendeeName {
+			return a.AttendeeName < b.AttendeeName
+		}
+		return a.AttendeeID < b.AttendeeID
+	})
+
+	return presence, nil
+}
+
+// pushPresence sends the presence on a topic to the facilitators following it. Pushes are
+// made one at a time, so a facilitator never gets an older presence after a newer one.
+func (h *Hub) pushPresence(topic Topic) {
+	h.presenceMu.Lock()
+	defer h.presenceMu.Unlock()
+
+	var facilitators []*Client
+	for _, client := range h.GetTopicClients(topic) {
+		if client.GetRole().Allows(domain.RoleFacilitator) {
+			facilitators = append(facilitators, client)
+		}
+	}
+	if len(facilitators) == 0 {
+		return
+	}
+
+	presence, err := h.Presence(topic, 0)
+	if err != nil {
+		log.Printf("Failed to get presence on %s: %v", topic, err)
+		return
+	}
+
+	message, err := CreateMessage(MessageTypePresence, presence)
+	if err != nil {
+		log.Printf("Failed to create presence message: %v", err)
+		return
+	}
+	message.Topic = topic.Name
+
+	for _, client := range facilitators {
+		client.Send(message)
+	}
+}
+
+// checkIdle pushes the presence on the topics of connections that went idle or came back
+// since the last check
+func (h *Hub) checkIdle() {
+	now := time.Now()
+	changed := make(map[Topic]bool)
+
+	h.mu.RLock()
+	for client := range h.clients {
+		if client.updateIdle(now) {
+			for _, topic := range client.Topics() {
+				changed[topic] = true
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for topic := range changed {
+		go h.pushPresence(topic)
+	}
+}
@@ -0,0 +1,91 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+
+	"pairwise/internal/domain"
+)
+
+// Topic kinds that clients can subscribe to
+const (
+	TopicKindPairwise  = "pairwise"
+	TopicKindFibonacci = "fibonacci"
+	TopicKindResults   = "results"
+)
+
+// Topic is a stream of updates within a project. Clients name topics without the project,
+// e.g. "pairwise:value", "fibonacci:complexity" or "results", and are only ever subscribed
+// to topics of the project their connection belongs to.
+type Topic struct {
+	ProjectID int
+	Name      string
+}
+
+// PairwiseTopic carries the pairwise sessions of a criterion, one after another
+func PairwiseTopic(projectID int, criterion domain.CriterionType) Topic {
+	return Topic{ProjectID: projectID, Name: TopicKindPairwise + ":" + string(criterion)}
+}
+
+// FibonacciTopic carries the Fibonacci scoring rounds of a criterion
+func FibonacciTopic(projectID int, criterion domain.CriterionType) Topic {
+	return Topic{ProjectID: projectID, Name: TopicKindFibonacci + ":" + string(criterion)}
+}
+
+// ResultsTopic carries recalculated project results
+func ResultsTopic(projectID int) Topic {
+	return Topic{ProjectID: projectID, Name: TopicKindResults}
+}
+
+// ParseTopic parses a topic name sent by a client connected to a project
+func ParseTopic(projectID int, name string) (Topic, error) {
+	kind, criterion, hasCriterion := strings.Cut(strings.TrimSpace(name), ":")
+
+	switch kind {
+	case TopicKindPairwise, TopicKindFibonacci:
+		if !hasCriterion || !domain.IsValidCriterionKey(domain.CriterionType(criterion)) {
+			return Topic{}, fmt.Errorf("topic %q needs a criterion, e.g. %s:value", name, kind)
+		}
+		if kind == TopicKindPairwise {
+			return PairwiseTopic(projectID, domain.CriterionType(criterion)), nil
+		}
+		return FibonacciTopic(projectID, domain.CriterionType(criterion)), nil
+	case TopicKindResults:
+		if hasCriterion {
+			return Topic{}, fmt.Errorf("topic %q takes no criterion", name)
+		}
+		return ResultsTopic(projectID), nil
+	default:
+		return Topic{}, fmt.Errorf("unknown topic %q", name)
+	}
+}
+
+// ParseTopics parses a list of topic names, stopping at the first invalid one
+func ParseTopics(projectID int, names []string) ([]Topic, error) {
+	topics := make([]Topic, 0, len(names))
+	for _, name := range names {
+		topic, err := ParseTopic(projectID, name)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
+
+// Kind returns the kind of updates the topic carries
+func (t Topic) Kind() string {
+	kind, _, _ := strings.Cut(t.Name, ":")
+	return kind
+}
+
+// Criterion returns the criterion of a pairwise or Fibonacci topic
+func (t Topic) Criterion() domain.CriterionType {
+	_, criterion, _ := strings.Cut(t.Name, ":")
+	return domain.CriterionType(criterion)
+}
+
+// String identifies the topic in logs
+func (t Topic) String() string {
+	return fmt.Sprintf("project %d %s", t.ProjectID, t.Name)
+}
@@ -0,0 +1,39 @@
+package websocket
+
+import "testing"
+
+// TestParseTopic tests resolving topic names sent by clients within their project
+func TestParseTopic(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Topic
+		valid    bool
+	}{
+		{name: "Value pairwise", input: "pairwise:value", expected: Topic{ProjectID: 7, Name: "pairwise:value"}, valid: true},
+		{name: "Custom criterion", input: " fibonacci:risk_level ", expected: Topic{ProjectID: 7, Name: "fibonacci:risk_level"}, valid: true},
+		{name: "Results", input: "results", expected: Topic{ProjectID: 7, Name: "results"}, valid: true},
+		{name: "Missing criterion", input: "pairwise", valid: false},
+		{name: "Invalid criterion", input: "fibonacci:Value!", valid: false},
+		{name: "Results with criterion", input: "results:value", valid: false},
+		{name: "Other project", input: "project:8:results", valid: false},
+		{name: "Unknown kind", input: "chat:value", valid: false},
+		{name: "Empty", input: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, err := ParseTopic(7, tt.input)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Expected valid=%v, got error %v", tt.valid, err)
+			}
+			if tt.valid && topic != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, topic)
+			}
+		})
+	}
+
+	if _, err := ParseTopics(7, []string{"results", "bogus"}); err == nil {
+		t.Error("Expected a list with an invalid topic to be rejected")
+	}
+}
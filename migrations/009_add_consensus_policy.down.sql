@@ -0,0 +1,4 @@
+-- Remove consensus policy fields
+ALTER TABLE pairwise_comparisons DROP COLUMN consensus_rule;
+ALTER TABLE projects DROP COLUMN supermajority_threshold;
+ALTER TABLE projects DROP COLUMN consensus_policy;
@@ -0,0 +1,12 @@
+-- Add project-level consensus policy for pairwise comparisons
+ALTER TABLE projects ADD COLUMN consensus_policy VARCHAR(20) DEFAULT 'unanimous'
+    CHECK (consensus_policy IN ('unanimous', 'majority', 'supermajority', 'facilitator'));
+-- Without a threshold the application's default of two thirds applies; a rounded default
+-- such as 0.667 would require slightly more than two thirds
+ALTER TABLE projects ADD COLUMN supermajority_threshold DOUBLE PRECISION;
+
+-- Record which rule produced each comparison result
+ALTER TABLE pairwise_comparisons ADD COLUMN consensus_rule VARCHAR(20);
+
+-- Existing results were all reached unanimously
+UPDATE pairwise_comparisons SET consensus_rule = 'unanimous' WHERE consensus_reached = TRUE;
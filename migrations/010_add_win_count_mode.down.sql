@@ -0,0 +1,2 @@
+-- Remove win-count mode from priority calculations
+ALTER TABLE priority_calculations DROP COLUMN win_count_mode;
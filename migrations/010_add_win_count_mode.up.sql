@@ -0,0 +1,3 @@
+-- Record how win-count weights were derived for each calculation
+ALTER TABLE priority_calculations ADD COLUMN win_count_mode VARCHAR(20) DEFAULT 'consensus'
+    CHECK (win_count_mode IN ('consensus', 'vote'));
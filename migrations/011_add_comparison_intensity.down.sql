@@ -0,0 +1,3 @@
+-- Remove AHP intensity from votes and comparison results
+ALTER TABLE pairwise_comparisons DROP COLUMN intensity;
+ALTER TABLE attendee_votes DROP COLUMN intensity;
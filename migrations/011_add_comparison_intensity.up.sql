@@ -0,0 +1,3 @@
+-- Add optional AHP intensity (Saaty's 1-9 scale) to votes and comparison results
+ALTER TABLE attendee_votes ADD COLUMN intensity INTEGER CHECK (intensity IS NULL OR intensity BETWEEN 0 AND 9);
+ALTER TABLE pairwise_comparisons ADD COLUMN intensity INTEGER CHECK (intensity IS NULL OR intensity BETWEEN 0 AND 9);
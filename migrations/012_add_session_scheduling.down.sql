@@ -0,0 +1,4 @@
+-- Remove session scheduling strategy and comparison rounds
+DROP INDEX IF EXISTS idx_pairwise_comparisons_session_round;
+ALTER TABLE pairwise_comparisons DROP COLUMN round;
+ALTER TABLE pairwise_sessions DROP COLUMN strategy;
@@ -0,0 +1,6 @@
+-- Add scheduling strategy to pairwise sessions and the round each comparison was generated in
+ALTER TABLE pairwise_sessions ADD COLUMN strategy VARCHAR(20) NOT NULL DEFAULT 'round_robin'
+    CHECK (strategy IN ('round_robin', 'merge_insertion', 'swiss', 'active'));
+ALTER TABLE pairwise_comparisons ADD COLUMN round INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_pairwise_comparisons_session_round ON pairwise_comparisons(session_id, round);
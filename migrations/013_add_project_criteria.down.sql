@@ -0,0 +1,13 @@
+-- Remove project-defined criteria
+DROP TABLE IF EXISTS project_phase_completions;
+DROP TABLE IF EXISTS priority_criterion_scores;
+
+DELETE FROM pairwise_sessions WHERE criterion_type NOT IN ('value', 'complexity');
+DELETE FROM fibonacci_sessions WHERE criterion_type NOT IN ('value', 'complexity');
+ALTER TABLE pairwise_sessions ADD CONSTRAINT pairwise_sessions_criterion_type_check
+    CHECK (criterion_type IN ('value', 'complexity'));
+ALTER TABLE fibonacci_sessions ADD CONSTRAINT fibonacci_sessions_criterion_type_check
+    CHECK (criterion_type IN ('value', 'complexity'));
+
+DROP INDEX IF EXISTS idx_project_criteria_project_position;
+DROP TABLE IF EXISTS project_criteria;
@@ -0,0 +1,47 @@
+-- Add project-defined criteria with a direction and weight; value and complexity become the default template
+CREATE TABLE project_criteria (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    key VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('benefit', 'cost')),
+    weight DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (weight > 0 AND weight <= 10),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (project_id, key)
+);
+
+CREATE INDEX idx_project_criteria_project_position ON project_criteria(project_id, position);
+
+INSERT INTO project_criteria (project_id, key, name, direction, weight, position)
+SELECT id, 'value', 'Value', 'benefit', 1, 0 FROM projects
+UNION ALL
+SELECT id, 'complexity', 'Complexity', 'cost', 1, 1 FROM projects;
+
+-- Sessions may now score any criterion key of their project
+ALTER TABLE pairwise_sessions DROP CONSTRAINT IF EXISTS pairwise_sessions_criterion_type_check;
+ALTER TABLE fibonacci_sessions DROP CONSTRAINT IF EXISTS fibonacci_sessions_criterion_type_check;
+
+-- Per-criterion breakdown of each Final Priority Score
+CREATE TABLE priority_criterion_scores (
+    id SERIAL PRIMARY KEY,
+    calculation_id INTEGER NOT NULL REFERENCES priority_calculations(id) ON DELETE CASCADE,
+    criterion VARCHAR(20) NOT NULL,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('benefit', 'cost')),
+    weight DOUBLE PRECISION NOT NULL,
+    win_count DECIMAL(10,6) NOT NULL,
+    score INTEGER NOT NULL,
+    weighted DECIMAL(10,6) NOT NULL
+);
+
+CREATE INDEX idx_priority_criterion_scores_calculation_id ON priority_criterion_scores(calculation_id);
+
+-- Completed pairwise and Fibonacci phases for criteria without a dedicated progress column
+CREATE TABLE project_phase_completions (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    phase VARCHAR(50) NOT NULL,
+    completed_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (project_id, phase)
+);
@@ -0,0 +1,5 @@
+-- Rollback: Remove login lockout tracking
+-- pin_hash is left at VARCHAR(100), since upgraded bcrypt hashes no longer fit in 64 characters
+
+ALTER TABLE attendees DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE attendees DROP COLUMN IF EXISTS failed_logins;
@@ -0,0 +1,6 @@
+-- Migration: Store salted PIN hashes and track failed logins
+-- bcrypt hashes are 60 characters; legacy SHA-256 hashes are upgraded on next login
+
+ALTER TABLE attendees ALTER COLUMN pin_hash TYPE VARCHAR(100);
+ALTER TABLE attendees ADD COLUMN failed_logins INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE attendees ADD COLUMN locked_until TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Rollback: Remove read-only observers
+ALTER TABLE attendees DROP CONSTRAINT IF EXISTS attendees_facilitator_or_observer_check;
+ALTER TABLE attendees DROP COLUMN IF EXISTS is_observer;
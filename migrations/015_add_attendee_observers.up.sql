@@ -0,0 +1,6 @@
+-- Migration: Add read-only observers
+-- Facilitators are already marked by is_facilitator; everyone else votes unless marked an observer
+
+ALTER TABLE attendees ADD COLUMN is_observer BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE attendees ADD CONSTRAINT attendees_facilitator_or_observer_check
+    CHECK (NOT (is_facilitator AND is_observer));
@@ -0,0 +1,4 @@
+-- Remove join codes and invite links
+ALTER TABLE attendees DROP COLUMN IF EXISTS invite_id;
+DROP INDEX IF EXISTS idx_project_invites_project_id;
+DROP TABLE IF EXISTS project_invites;
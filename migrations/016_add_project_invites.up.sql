@@ -0,0 +1,18 @@
+-- Migration: Add join codes and invite links for attendee self-registration
+CREATE TABLE project_invites (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    code VARCHAR(16) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL DEFAULT 'attendee' CHECK (role IN ('attendee', 'observer')),
+    expires_at TIMESTAMP,
+    max_uses INTEGER CHECK (max_uses IS NULL OR max_uses > 0),
+    uses INTEGER NOT NULL DEFAULT 0,
+    revoked_at TIMESTAMP,
+    created_by INTEGER REFERENCES attendees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_project_invites_project_id ON project_invites(project_id);
+
+-- Attendees remember the invite they joined with
+ALTER TABLE attendees ADD COLUMN invite_id INTEGER REFERENCES project_invites(id) ON DELETE SET NULL;
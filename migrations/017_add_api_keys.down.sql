@@ -0,0 +1,3 @@
+-- Remove scoped API keys
+DROP INDEX IF EXISTS idx_api_keys_project_id;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,16 @@
+-- Migration: Add scoped API keys for automation and integrations
+-- Keys are stored as SHA-256 hashes; a key without a project is global
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_by INTEGER REFERENCES attendees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_project_id ON api_keys(project_id);
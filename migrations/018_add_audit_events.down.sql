@@ -0,0 +1,5 @@
+-- Remove the audit log
+DROP TRIGGER IF EXISTS audit_events_immutable ON audit_events;
+DROP FUNCTION IF EXISTS reject_audit_event_change();
+DROP INDEX IF EXISTS idx_audit_events_project_id;
+DROP TABLE IF EXISTS audit_events;
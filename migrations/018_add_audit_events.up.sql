@@ -0,0 +1,27 @@
+-- Migration: Add an append-only audit log of mutating actions
+-- Events keep their project ID without a foreign key so that history outlives deleted projects
+CREATE TABLE audit_events (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER,
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id INTEGER,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER,
+    before_state JSONB,
+    after_state JSONB,
+    request_id VARCHAR(128),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_project_id ON audit_events(project_id, id);
+
+CREATE FUNCTION reject_audit_event_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_immutable
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
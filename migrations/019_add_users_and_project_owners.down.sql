@@ -0,0 +1,6 @@
+-- Remove facilitator accounts and project owners
+ALTER TABLE attendees DROP COLUMN IF EXISTS user_id;
+DROP INDEX IF EXISTS idx_projects_owner_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS owner_id;
+DROP INDEX IF EXISTS idx_users_identity;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,20 @@
+-- Migration: Add facilitator accounts signed in through OpenID Connect
+-- A user is identified by the issuer and subject of their ID token
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    name VARCHAR(255) NOT NULL,
+    last_login_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_users_identity ON users(issuer, subject);
+
+-- Projects created by a user are owned by them
+ALTER TABLE projects ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_projects_owner_id ON projects(owner_id);
+
+-- Users act in the projects they own through a linked facilitator attendee
+ALTER TABLE attendees ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
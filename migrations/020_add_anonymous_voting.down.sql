@@ -0,0 +1,5 @@
+-- Remove anonymous pairwise sessions
+DROP INDEX IF EXISTS idx_anonymous_ballots_comparison_id;
+DROP TABLE IF EXISTS anonymous_ballots;
+DROP TABLE IF EXISTS vote_participations;
+ALTER TABLE pairwise_sessions DROP COLUMN IF EXISTS anonymous;
@@ -0,0 +1,23 @@
+-- Migration: Add anonymous pairwise sessions
+-- Participation records who voted; ballots record what was voted, keyed by an HMAC of the
+-- comparison and attendee under a server secret, so that the two cannot be joined
+ALTER TABLE pairwise_sessions ADD COLUMN anonymous BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE vote_participations (
+    comparison_id INTEGER NOT NULL REFERENCES pairwise_comparisons(id) ON DELETE CASCADE,
+    attendee_id INTEGER NOT NULL REFERENCES attendees(id) ON DELETE CASCADE,
+    voted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comparison_id, attendee_id)
+);
+
+-- Ballots have no serial column or timestamp; the key is their only identifier. The server
+-- creates the SQLite table WITHOUT ROWID, so that its storage order is key order.
+CREATE TABLE anonymous_ballots (
+    ballot_key VARCHAR(64) PRIMARY KEY,
+    comparison_id INTEGER NOT NULL REFERENCES pairwise_comparisons(id) ON DELETE CASCADE,
+    preferred_feature_id INTEGER REFERENCES features(id), -- NULL if tie vote
+    is_tie_vote BOOLEAN NOT NULL DEFAULT FALSE,
+    intensity INTEGER CHECK (intensity IS NULL OR intensity BETWEEN 0 AND 9)
+);
+
+CREATE INDEX idx_anonymous_ballots_comparison_id ON anonymous_ballots(comparison_id);
@@ -0,0 +1,4 @@
+-- Remove facilitator-driven pairwise sessions
+ALTER TABLE pairwise_sessions DROP COLUMN IF EXISTS focus_phase;
+ALTER TABLE pairwise_sessions DROP COLUMN IF EXISTS focus_comparison_id;
+ALTER TABLE pairwise_sessions DROP COLUMN IF EXISTS driven;
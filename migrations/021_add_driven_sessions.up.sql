@@ -0,0 +1,6 @@
+-- Migration: Add facilitator-driven pairwise sessions
+-- A driven session shows one comparison to everyone at a time; the focus columns record
+-- which one and whether voting on it is open, closed or revealed
+ALTER TABLE pairwise_sessions ADD COLUMN driven BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE pairwise_sessions ADD COLUMN focus_comparison_id INTEGER REFERENCES pairwise_comparisons(id) ON DELETE SET NULL;
+ALTER TABLE pairwise_sessions ADD COLUMN focus_phase VARCHAR(20) CHECK (focus_phase IS NULL OR focus_phase IN ('discussing', 'voting', 'closed', 'revealed'));
@@ -0,0 +1,2 @@
+-- Remove attendee token versions
+ALTER TABLE attendees DROP COLUMN IF EXISTS token_version;
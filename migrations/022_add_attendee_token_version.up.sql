@@ -0,0 +1,4 @@
+-- Migration: Add attendee token versions
+-- Session tokens carry the attendee's token version when they were issued; bumping it, as
+-- setting a new PIN does, revokes every token issued before
+ALTER TABLE attendees ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Allow a pair of features to be compared more than once per session
+DROP INDEX IF EXISTS idx_pairwise_comparisons_pair;
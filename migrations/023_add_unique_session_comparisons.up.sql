@@ -0,0 +1,3 @@
+-- Migration: Compare each pair of features at most once per session
+-- Backs up the session lock that keeps concurrent final votes from both scheduling the next round
+CREATE UNIQUE INDEX idx_pairwise_comparisons_pair ON pairwise_comparisons(session_id, feature_a_id, feature_b_id);
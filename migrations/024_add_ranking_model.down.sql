@@ -0,0 +1,2 @@
+-- Remove ranking model from priority calculations
+ALTER TABLE priority_calculations DROP COLUMN ranking_model;
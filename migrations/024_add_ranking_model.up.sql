@@ -0,0 +1,7 @@
+-- Record which ranking model estimated the win-count weights of each calculation
+ALTER TABLE priority_calculations ADD COLUMN ranking_model VARCHAR(20)
+    CHECK (ranking_model IN ('win_count', 'vote_win_count', 'bradley_terry', 'elo', 'ahp'));
+
+-- Existing calculations used the win-count model of their mode
+UPDATE priority_calculations
+SET ranking_model = CASE win_count_mode WHEN 'vote' THEN 'vote_win_count' ELSE 'win_count' END;